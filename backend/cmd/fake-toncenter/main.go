@@ -0,0 +1,109 @@
+// fake-toncenter 以記憶體模擬鏈提供 toncenter HTTP API，讓後端不連線 TON 網路即可在本地執行
+//
+// 合約地址、錢包與抽獎參數與後端讀取相同的環境變數，啟動時部署 CatLottery 與 CatNFT 並為錢包注資。
+// 狀態只保存在記憶體中，重新啟動後回到初始狀態。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/internal/wallet"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+func main() {
+	var options toncenter.Options
+	addr := flag.String("addr", ":8081", "HTTP 監聽地址")
+	walletBalance := flag.Float64("wallet-balance", 100, "後端錢包的初始餘額 (TON)")
+	flag.DurationVar(&options.Latency, "latency", time.Second, "外部訊息上鏈前的延遲")
+	flag.DurationVar(&options.ResponseDelay, "response-delay", 0, "每個 API 請求回應前的延遲")
+	flag.Float64Var(&options.ErrorRate, "error-rate", 0, "以 HTTP 500 回應的請求比例 (0 ~ 1)")
+	flag.Float64Var(&options.RateLimitRate, "rate-limit-rate", 0, "以 HTTP 429 回應的請求比例 (0 ~ 1)")
+	flag.DurationVar(&options.RetryAfter, "retry-after", time.Second, "429 回應的 Retry-After")
+	flag.Int64Var(&options.Seed, "seed", 0, "故障注入的亂數種子，0 表示以目前時間為種子")
+	flag.Parse()
+
+	// 載入配置
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("載入配置失敗: %v", err)
+	}
+
+	appLogger := logger.New(cfg.LogLevel)
+
+	chain, err := newChain(cfg, appLogger, tonToNano(*walletBalance))
+	if err != nil {
+		appLogger.Fatal("初始化模擬鏈失敗", "error", err)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           toncenter.NewServer(chain, options, appLogger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Fatal("模擬 toncenter 啟動失敗", "error", err)
+		}
+	}()
+	appLogger.Info("🧪 模擬 toncenter 已啟動",
+		"addr", *addr,
+		"lottery", cfg.LotteryContractAddress,
+		"nft", cfg.NFTContractAddress,
+		"latency", options.Latency,
+	)
+
+	// 等待信號以優雅關閉
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("關閉模擬 toncenter 失敗", "error", err)
+	}
+	appLogger.Info("✅ 模擬 toncenter 已關閉")
+}
+
+// newChain 部署配置中的抽獎與 NFT 合約，並註冊為後端錢包注資
+func newChain(cfg *config.Config, log *logger.Logger, walletBalance int64) (*emulator.Chain, error) {
+	owner, err := wallet.NewManager(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := emulator.New()
+	if err := chain.AddWallet(owner.GetAddress(), owner.GetVersion(), owner.GetWalletID(), owner.GetPublicKey()); err != nil {
+		return nil, err
+	}
+	if err := chain.Fund(owner.GetAddress(), walletBalance); err != nil {
+		return nil, err
+	}
+	if err := chain.DeployLottery(cfg.LotteryContractAddress, owner.GetAddress(), tonToNano(cfg.EntryFeeTON), cfg.MaxParticipants); err != nil {
+		return nil, err
+	}
+	if err := chain.DeployNFT(cfg.NFTContractAddress, cfg.LotteryContractAddress); err != nil {
+		return nil, err
+	}
+
+	log.Info("📋 模擬鏈初始化完成", "wallet", owner.GetAddress(), "balance", walletBalance)
+	return chain, nil
+}
+
+// tonToNano 將 TON 轉換為 nanoTON
+func tonToNano(amount float64) int64 {
+	return int64(math.Round(amount * 1e9))
+}
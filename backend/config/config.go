@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"ton-cat-lottery-backend/internal/ton/address"
 )
 
 // Config 包含所有應用程式配置
@@ -18,13 +22,35 @@ type Config struct {
 	TONAPIEndpoint string `json:"ton_api_endpoint"`
 	TONNetwork     string `json:"ton_network"` // testnet, mainnet
 
+	// 多個 TON API 端點，格式為以逗號分隔的 url 或 url|api_key，依優先順序排列；空值時只使用 TONAPIEndpoint
+	TONAPIEndpoints string `json:"-"`
+
+	// TON API 請求配置
+	TONAPITimeout        time.Duration `json:"ton_api_timeout"`          // 單次請求的逾時
+	TONAPIRateLimit      float64       `json:"ton_api_rate_limit"`       // 每秒請求數上限，0 表示不限制
+	TONAPIBurst          int           `json:"ton_api_burst"`            // 可連續發送的請求數
+	TONAPIMaxRetries     int           `json:"ton_api_max_retries"`      // 可重試錯誤的最大重試次數
+	TONAPIRetryBaseDelay time.Duration `json:"ton_api_retry_base_delay"` // 第一次重試的基本延遲，之後每次加倍
+	TONAPIRetryMaxDelay  time.Duration `json:"ton_api_retry_max_delay"`  // 重試延遲上限，Retry-After 超過此值時不重試
+	TONAPIHedgeDelay     time.Duration `json:"ton_api_hedge_delay"`      // 讀取請求超過此時間未回應時同時向下一個端點發送，0 表示停用
+
+	// TON API key，來自 TON_API_KEYS 與 TON_API_KEY_FILE，用於未在 TON_API_ENDPOINTS 指定 key 的端點
+	TONAPIKeys        []string `json:"-"`
+	TONAPIKeyRotation string   `json:"ton_api_key_rotation"` // 多個 key 的輪替方式：on_429（預設）或 round_robin
+
 	// 合約地址
 	LotteryContractAddress string `json:"lottery_contract_address"`
 	NFTContractAddress     string `json:"nft_contract_address"`
 
 	// 錢包配置
-	WalletPrivateKey string `json:"wallet_private_key"`
-	WalletMnemonic   string `json:"wallet_mnemonic"`
+	WalletPrivateKey       string `json:"wallet_private_key"`
+	WalletMnemonic         string `json:"wallet_mnemonic"`          // 24 個單字的 TON 助記詞
+	WalletMnemonicPassword string `json:"wallet_mnemonic_password"` // 受密碼保護的助記詞所需的密碼
+	WalletVersion          string `json:"wallet_version"`           // v3r2, v4r2, v5r1
+	WalletSubwalletID      uint32 `json:"wallet_subwallet_id"`      // 0 表示使用錢包版本的預設值
+
+	// walletSubwalletIDSpec WALLET_SUBWALLET_ID 的原始值，由 validate 檢查
+	walletSubwalletIDSpec string
 
 	// 抽獎配置
 	DrawInterval    time.Duration `json:"draw_interval"`    // 抽獎間隔
@@ -34,6 +60,170 @@ type Config struct {
 	AutoDraw        bool          `json:"auto_draw"`        // 是否自動抽獎
 	RetryCount      int           `json:"retry_count"`      // 重試次數
 	RetryDelay      time.Duration `json:"retry_delay"`      // 重試延遲
+
+	// 錢包餘額門檻 (TON)，低於此值時拒絕發送抽獎交易，0 表示不檢查
+	MinWalletBalanceTON float64 `json:"min_wallet_balance_ton"`
+
+	// 管理 API 金鑰，格式為 role:key,role:key；空值表示停用管理 API
+	AdminAPIKeys string `json:"-"`
+
+	// 資料檔，保存輪次、參與者、中獎記錄與已發送的交易；空值表示只保存在記憶體
+	StoreFile string `json:"store_file"`
+
+	// 事件索引配置
+	IndexerPollInterval time.Duration `json:"indexer_poll_interval"` // 輪詢合約交易的間隔，0 表示停用索引器
+	IndexerStateFile    string        `json:"indexer_state_file"`    // 保存索引游標的檔案
+}
+
+// 管理 API 角色
+const (
+	AdminRoleAdmin    = "admin"    // 所有管理操作
+	AdminRoleOperator = "operator" // 抽獎與開始新輪次
+)
+
+// minAdminAPIKeyLength 管理 API 金鑰的最短長度
+const minAdminAPIKeyLength = 16
+
+// AdminAPIKey 管理 API 金鑰及其角色
+type AdminAPIKey struct {
+	Role string
+	Key  string
+}
+
+// ParseAdminAPIKeys 解析 ADMIN_API_KEYS，格式為以逗號分隔的 role:key
+//
+// 錯誤訊息不包含金鑰內容。
+func ParseAdminAPIKeys(spec string) ([]AdminAPIKey, error) {
+	var keys []AdminAPIKey
+	seen := make(map[string]bool)
+
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, key, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 個金鑰格式應為 role:key", i+1)
+		}
+
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != AdminRoleAdmin && role != AdminRoleOperator {
+			return nil, fmt.Errorf("第 %d 個金鑰的角色必須是 admin 或 operator", i+1)
+		}
+
+		key = strings.TrimSpace(key)
+		if len(key) < minAdminAPIKeyLength {
+			return nil, fmt.Errorf("第 %d 個金鑰長度不能少於 %d 個字元", i+1, minAdminAPIKeyLength)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("第 %d 個金鑰重複", i+1)
+		}
+		seen[key] = true
+
+		keys = append(keys, AdminAPIKey{Role: role, Key: key})
+	}
+
+	return keys, nil
+}
+
+// APIEndpoint TON API 端點及其 API key
+type APIEndpoint struct {
+	URL    string // 以 / 結尾的 API 根路徑，例如 https://toncenter.com/api/v2/
+	APIKey string // 以 X-API-Key 標頭發送，空值表示不使用
+}
+
+// ParseTONAPIEndpoints 解析 TON_API_ENDPOINTS，格式為以逗號分隔的 url 或 url|api_key
+//
+// URL 缺少結尾的 / 時自動補上。錯誤訊息不包含 API key。
+func ParseTONAPIEndpoints(spec string) ([]APIEndpoint, error) {
+	var endpoints []APIEndpoint
+	seen := make(map[string]bool)
+
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		rawURL, key, _ := strings.Cut(entry, "|")
+		rawURL = strings.TrimSpace(rawURL)
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("第 %d 個端點必須是 http 或 https URL", i+1)
+		}
+		if !strings.HasSuffix(rawURL, "/") {
+			rawURL += "/"
+		}
+		if seen[rawURL] {
+			return nil, fmt.Errorf("第 %d 個端點重複", i+1)
+		}
+		seen[rawURL] = true
+
+		endpoints = append(endpoints, APIEndpoint{URL: rawURL, APIKey: strings.TrimSpace(key)})
+	}
+
+	return endpoints, nil
+}
+
+// TONAPIEndpointList 返回依優先順序排列的 TON API 端點
+//
+// 未設定 TONAPIEndpoints 或格式無效（Load 會先驗證）時只包含 TONAPIEndpoint。
+func (c *Config) TONAPIEndpointList() []APIEndpoint {
+	if endpoints, err := ParseTONAPIEndpoints(c.TONAPIEndpoints); err == nil && len(endpoints) > 0 {
+		return endpoints
+	}
+	return []APIEndpoint{{URL: c.TONAPIEndpoint}}
+}
+
+// TON API key 的輪替方式
+const (
+	KeyRotationOn429      = "on_429"      // 使用同一個 key 直到收到 429
+	KeyRotationRoundRobin = "round_robin" // 每個請求依序使用下一個 key
+)
+
+// ParseTONAPIKeys 解析以逗號或換行分隔的 API key，忽略空白、重複的 key 與 # 開頭的註解行
+func ParseTONAPIKeys(spec string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, key := range strings.Split(line, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// LoadTONAPIKeys 合併 spec（TON_API_KEYS）與 file（TON_API_KEY_FILE，每行一個 key）中的 API key
+//
+// file 為空時不讀取檔案；檔案無法讀取或不包含任何 key 時返回錯誤，錯誤訊息不包含檔案內容。
+func LoadTONAPIKeys(spec, file string) ([]string, error) {
+	if file == "" {
+		return ParseTONAPIKeys(spec), nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("讀取 API key 檔案失敗: %w", err)
+	}
+	fileKeys := ParseTONAPIKeys(string(data))
+	if len(fileKeys) == 0 {
+		return nil, fmt.Errorf("API key 檔案 %s 不包含任何 key", file)
+	}
+
+	return ParseTONAPIKeys(spec + "\n" + string(data)), nil
 }
 
 // Load 從環境變數載入配置
@@ -44,10 +234,22 @@ func Load() (*Config, error) {
 		Port:                   getEnvString("PORT", "8080"),
 		TONAPIEndpoint:         getEnvString("TON_API_ENDPOINT", "https://testnet.toncenter.com/api/v2/"),
 		TONNetwork:             getEnvString("TON_NETWORK", "testnet"),
+		TONAPIEndpoints:        getEnvString("TON_API_ENDPOINTS", ""),
+		TONAPITimeout:          getEnvDuration("TON_API_TIMEOUT", 30*time.Second),
+		TONAPIRateLimit:        getEnvFloat64("TON_API_RATE_LIMIT", 1),
+		TONAPIBurst:            getEnvInt("TON_API_BURST", 1),
+		TONAPIMaxRetries:       getEnvInt("TON_API_MAX_RETRIES", 3),
+		TONAPIRetryBaseDelay:   getEnvDuration("TON_API_RETRY_BASE_DELAY", 500*time.Millisecond),
+		TONAPIRetryMaxDelay:    getEnvDuration("TON_API_RETRY_MAX_DELAY", 10*time.Second),
+		TONAPIHedgeDelay:       getEnvDuration("TON_API_HEDGE_DELAY", 0),
+		TONAPIKeyRotation:      getEnvString("TON_API_KEY_ROTATION", KeyRotationOn429),
 		LotteryContractAddress: getEnvString("LOTTERY_CONTRACT_ADDRESS", ""),
 		NFTContractAddress:     getEnvString("NFT_CONTRACT_ADDRESS", ""),
 		WalletPrivateKey:       getEnvString("WALLET_PRIVATE_KEY", ""),
 		WalletMnemonic:         getEnvString("WALLET_MNEMONIC", ""),
+		WalletMnemonicPassword: getEnvString("WALLET_MNEMONIC_PASSWORD", ""),
+		WalletVersion:          getEnvString("WALLET_VERSION", "v4r2"),
+		walletSubwalletIDSpec:  getEnvString("WALLET_SUBWALLET_ID", ""),
 		DrawInterval:           getEnvDuration("DRAW_INTERVAL", 30*time.Minute),
 		MaxParticipants:        getEnvInt("MAX_PARTICIPANTS", 10),
 		MinParticipants:        getEnvInt("MIN_PARTICIPANTS", 2),
@@ -55,8 +257,22 @@ func Load() (*Config, error) {
 		AutoDraw:               getEnvBool("AUTO_DRAW", true),
 		RetryCount:             getEnvInt("RETRY_COUNT", 3),
 		RetryDelay:             getEnvDuration("RETRY_DELAY", 5*time.Second),
+		MinWalletBalanceTON:    getEnvFloat64("MIN_WALLET_BALANCE_TON", 0.2),
+		AdminAPIKeys:           getEnvString("ADMIN_API_KEYS", ""),
+		StoreFile:              getEnvString("STORE_FILE", "data/lottery.json"),
+		IndexerPollInterval:    getEnvDuration("INDEXER_POLL_INTERVAL", 15*time.Second),
+		IndexerStateFile:       getEnvString("INDEXER_STATE_FILE", "data/indexer_cursor.json"),
 	}
 
+	keys, err := LoadTONAPIKeys(getEnvString("TON_API_KEYS", ""), getEnvString("TON_API_KEY_FILE", ""))
+	if err != nil {
+		return nil, fmt.Errorf("載入 TON API key 失敗: %w", err)
+	}
+	cfg.TONAPIKeys = keys
+
+	// 無效的值由 validate 拒絕，不可截斷成另一個錢包 id（即另一個錢包地址）
+	cfg.WalletSubwalletID, _ = parseSubwalletID(cfg.walletSubwalletIDSpec)
+
 	// 驗證必要配置
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("配置驗證失敗: %w", err)
@@ -79,10 +295,32 @@ func (c *Config) validate() error {
 		return fmt.Errorf("WALLET_PRIVATE_KEY 或 WALLET_MNEMONIC 必須設定其中一個")
 	}
 
+	if c.WalletMnemonicPassword != "" && c.WalletMnemonic == "" {
+		return fmt.Errorf("WALLET_MNEMONIC_PASSWORD 僅能與 WALLET_MNEMONIC 一起使用")
+	}
+
 	if c.TONNetwork != "testnet" && c.TONNetwork != "mainnet" {
 		return fmt.Errorf("TON_NETWORK 必須是 testnet 或 mainnet")
 	}
 
+	if _, err := parseSubwalletID(c.walletSubwalletIDSpec); err != nil {
+		return err
+	}
+
+	switch c.WalletVersion {
+	case "", "v3r2", "v4r2", "v5r1": // 空值使用預設版本
+	default:
+		return fmt.Errorf("WALLET_VERSION 必須是 v3r2、v4r2 或 v5r1")
+	}
+
+	if err := c.validateAddress("LOTTERY_CONTRACT_ADDRESS", c.LotteryContractAddress); err != nil {
+		return err
+	}
+
+	if err := c.validateAddress("NFT_CONTRACT_ADDRESS", c.NFTContractAddress); err != nil {
+		return err
+	}
+
 	if c.MinParticipants < 1 {
 		return fmt.Errorf("MIN_PARTICIPANTS 必須大於 0")
 	}
@@ -91,9 +329,81 @@ func (c *Config) validate() error {
 		return fmt.Errorf("MAX_PARTICIPANTS 必須大於或等於 MIN_PARTICIPANTS")
 	}
 
+	if _, err := ParseTONAPIEndpoints(c.TONAPIEndpoints); err != nil {
+		return fmt.Errorf("TON_API_ENDPOINTS 格式無效: %w", err)
+	}
+
+	switch c.TONAPIKeyRotation {
+	case "", KeyRotationOn429, KeyRotationRoundRobin: // 空值使用 on_429
+	default:
+		return fmt.Errorf("TON_API_KEY_ROTATION 必須是 on_429 或 round_robin")
+	}
+
+	if c.TONAPIHedgeDelay < 0 {
+		return fmt.Errorf("TON_API_HEDGE_DELAY 不能為負數")
+	}
+
+	if c.TONAPITimeout < 0 {
+		return fmt.Errorf("TON_API_TIMEOUT 不能為負數")
+	}
+
+	if c.TONAPIRateLimit < 0 {
+		return fmt.Errorf("TON_API_RATE_LIMIT 不能為負數")
+	}
+
+	if c.TONAPIMaxRetries < 0 {
+		return fmt.Errorf("TON_API_MAX_RETRIES 不能為負數")
+	}
+
+	if c.TONAPIRetryBaseDelay < 0 || c.TONAPIRetryMaxDelay < c.TONAPIRetryBaseDelay {
+		return fmt.Errorf("TON_API_RETRY_BASE_DELAY 不能為負數，且不能大於 TON_API_RETRY_MAX_DELAY")
+	}
+
+	if c.MinWalletBalanceTON < 0 {
+		return fmt.Errorf("MIN_WALLET_BALANCE_TON 不能為負數")
+	}
+
+	if c.IndexerPollInterval < 0 {
+		return fmt.Errorf("INDEXER_POLL_INTERVAL 不能為負數")
+	}
+
+	if c.IndexerPollInterval > 0 && c.IndexerStateFile == "" {
+		return fmt.Errorf("啟用事件索引器時 INDEXER_STATE_FILE 不能為空")
+	}
+
+	if _, err := ParseAdminAPIKeys(c.AdminAPIKeys); err != nil {
+		return fmt.Errorf("ADMIN_API_KEYS 格式無效: %w", err)
+	}
+
 	return nil
 }
 
+// validateAddress 驗證合約地址格式，並檢查測試網專用地址不會用於主網
+func (c *Config) validateAddress(name, value string) error {
+	addr, err := address.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%s 格式無效: %w", name, err)
+	}
+
+	if c.TONNetwork == "mainnet" && addr.IsTestnetOnly() {
+		return fmt.Errorf("%s 是測試網專用地址，不能用於 mainnet", name)
+	}
+
+	return nil
+}
+
+// parseSubwalletID 解析 WALLET_SUBWALLET_ID，空值表示使用錢包版本的預設值 (0)
+func parseSubwalletID(spec string) (uint32, error) {
+	if spec == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(spec, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("WALLET_SUBWALLET_ID 必須是 0 到 4294967295 的整數: %q", spec)
+	}
+	return uint32(id), nil
+}
+
 // 輔助函數：取得環境變數 (字串)
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
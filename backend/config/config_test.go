@@ -2,18 +2,27 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
+// 測試用合約地址（合法的使用者友善格式）
+const (
+	testLotteryAddress = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	testNFTAddress     = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+)
+
 func TestLoad(t *testing.T) {
 	// 清除環境變數
 	envVars := []string{
 		"ENVIRONMENT", "LOG_LEVEL", "PORT", "TON_API_ENDPOINT", "TON_NETWORK",
 		"LOTTERY_CONTRACT_ADDRESS", "NFT_CONTRACT_ADDRESS",
-		"WALLET_PRIVATE_KEY", "WALLET_MNEMONIC",
+		"WALLET_PRIVATE_KEY", "WALLET_MNEMONIC", "WALLET_MNEMONIC_PASSWORD", "WALLET_VERSION", "WALLET_SUBWALLET_ID",
 		"DRAW_INTERVAL", "MAX_PARTICIPANTS", "MIN_PARTICIPANTS",
-		"ENTRY_FEE_TON", "AUTO_DRAW", "RETRY_COUNT", "RETRY_DELAY",
+		"ENTRY_FEE_TON", "AUTO_DRAW", "RETRY_COUNT", "RETRY_DELAY", "MIN_WALLET_BALANCE_TON",
+		"ADMIN_API_KEYS", "TON_API_KEYS", "TON_API_KEY_FILE", "TON_API_KEY_ROTATION",
 	}
 
 	// 保存原始環境變數
@@ -36,8 +45,8 @@ func TestLoad(t *testing.T) {
 
 	t.Run("should load with default values", func(t *testing.T) {
 		// 設定必要的環境變數
-		os.Setenv("LOTTERY_CONTRACT_ADDRESS", "test_lottery_address")
-		os.Setenv("NFT_CONTRACT_ADDRESS", "test_nft_address")
+		os.Setenv("LOTTERY_CONTRACT_ADDRESS", testLotteryAddress)
+		os.Setenv("NFT_CONTRACT_ADDRESS", testNFTAddress)
 		os.Setenv("WALLET_PRIVATE_KEY", "test_private_key")
 
 		cfg, err := Load()
@@ -64,6 +73,62 @@ func TestLoad(t *testing.T) {
 		if cfg.DrawInterval != 30*time.Minute {
 			t.Errorf("Expected DrawInterval=30m, got %v", cfg.DrawInterval)
 		}
+		if cfg.WalletVersion != "v4r2" {
+			t.Errorf("Expected WalletVersion='v4r2', got %s", cfg.WalletVersion)
+		}
+		if cfg.WalletSubwalletID != 0 {
+			t.Errorf("Expected WalletSubwalletID=0, got %d", cfg.WalletSubwalletID)
+		}
+		if cfg.MinWalletBalanceTON != 0.2 {
+			t.Errorf("Expected MinWalletBalanceTON=0.2, got %f", cfg.MinWalletBalanceTON)
+		}
+		if cfg.StoreFile != "data/lottery.json" {
+			t.Errorf("Expected StoreFile=data/lottery.json, got %s", cfg.StoreFile)
+		}
+		if cfg.IndexerPollInterval != 15*time.Second || cfg.IndexerStateFile != "data/indexer_cursor.json" {
+			t.Errorf("Expected indexer defaults 15s and data/indexer_cursor.json, got %v and %s", cfg.IndexerPollInterval, cfg.IndexerStateFile)
+		}
+		if cfg.TONAPITimeout != 30*time.Second || cfg.TONAPIRateLimit != 1 || cfg.TONAPIBurst != 1 {
+			t.Errorf("Expected TON API defaults 30s, 1 rps, burst 1, got %v, %v, %d", cfg.TONAPITimeout, cfg.TONAPIRateLimit, cfg.TONAPIBurst)
+		}
+		if cfg.TONAPIMaxRetries != 3 || cfg.TONAPIRetryBaseDelay != 500*time.Millisecond || cfg.TONAPIRetryMaxDelay != 10*time.Second {
+			t.Errorf("Expected TON API retry defaults 3, 500ms, 10s, got %d, %v, %v", cfg.TONAPIMaxRetries, cfg.TONAPIRetryBaseDelay, cfg.TONAPIRetryMaxDelay)
+		}
+		if len(cfg.TONAPIKeys) != 0 || cfg.TONAPIKeyRotation != KeyRotationOn429 {
+			t.Errorf("Expected no TON API keys with on_429 rotation, got %d keys and %s", len(cfg.TONAPIKeys), cfg.TONAPIKeyRotation)
+		}
+	})
+
+	t.Run("should load TON API keys from env and file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "toncenter_keys")
+		if err := os.WriteFile(file, []byte("# toncenter\nfile-key-1\n\nfile-key-2\nenv-key\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("TON_API_KEYS", "env-key, env-key-2")
+		os.Setenv("TON_API_KEY_FILE", file)
+		os.Setenv("TON_API_KEY_ROTATION", KeyRotationRoundRobin)
+		defer func() {
+			os.Unsetenv("TON_API_KEYS")
+			os.Unsetenv("TON_API_KEY_FILE")
+			os.Unsetenv("TON_API_KEY_ROTATION")
+		}()
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := []string{"env-key", "env-key-2", "file-key-1", "file-key-2"}
+		if strings.Join(cfg.TONAPIKeys, ",") != strings.Join(want, ",") {
+			t.Errorf("TONAPIKeys = %v, want %v", cfg.TONAPIKeys, want)
+		}
+		if cfg.TONAPIKeyRotation != KeyRotationRoundRobin {
+			t.Errorf("Expected round_robin rotation, got %s", cfg.TONAPIKeyRotation)
+		}
+
+		os.Setenv("TON_API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+		if _, err := Load(); err == nil {
+			t.Error("Expected Load() to fail with a missing key file")
+		}
 	})
 
 	t.Run("should load from environment variables", func(t *testing.T) {
@@ -72,8 +137,8 @@ func TestLoad(t *testing.T) {
 		os.Setenv("LOG_LEVEL", "debug")
 		os.Setenv("PORT", "9000")
 		os.Setenv("TON_NETWORK", "mainnet")
-		os.Setenv("LOTTERY_CONTRACT_ADDRESS", "EQTest123")
-		os.Setenv("NFT_CONTRACT_ADDRESS", "EQTestNFT456")
+		os.Setenv("LOTTERY_CONTRACT_ADDRESS", testLotteryAddress)
+		os.Setenv("NFT_CONTRACT_ADDRESS", testNFTAddress)
 		os.Setenv("WALLET_PRIVATE_KEY", "abcd1234")
 		os.Setenv("MAX_PARTICIPANTS", "20")
 		os.Setenv("MIN_PARTICIPANTS", "5")
@@ -116,6 +181,30 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("should parse wallet subwallet id", func(t *testing.T) {
+		os.Setenv("LOTTERY_CONTRACT_ADDRESS", testLotteryAddress)
+		os.Setenv("NFT_CONTRACT_ADDRESS", testNFTAddress)
+		os.Setenv("WALLET_PRIVATE_KEY", "abcd1234")
+		defer os.Unsetenv("WALLET_SUBWALLET_ID")
+
+		os.Setenv("WALLET_SUBWALLET_ID", "4294967295")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.WalletSubwalletID != 4294967295 {
+			t.Errorf("Expected WalletSubwalletID=4294967295, got %d", cfg.WalletSubwalletID)
+		}
+
+		// 負數與超出範圍的值不可截斷成另一個錢包 id
+		for _, value := range []string{"-1", "4294967296", "abc", "1.5"} {
+			os.Setenv("WALLET_SUBWALLET_ID", value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "WALLET_SUBWALLET_ID") {
+				t.Errorf("Expected Load() to reject WALLET_SUBWALLET_ID=%s, got %v", value, err)
+			}
+		}
+	})
+
 	t.Run("should fail with missing required config", func(t *testing.T) {
 		// 清除所有環境變數
 		for _, key := range envVars {
@@ -123,7 +212,7 @@ func TestLoad(t *testing.T) {
 		}
 
 		// 只設定部分必要配置
-		os.Setenv("LOTTERY_CONTRACT_ADDRESS", "test_address")
+		os.Setenv("LOTTERY_CONTRACT_ADDRESS", testLotteryAddress)
 		// 缺少 NFT_CONTRACT_ADDRESS 和 WALLET_PRIVATE_KEY
 
 		_, err := Load()
@@ -142,8 +231,8 @@ func TestValidate(t *testing.T) {
 		{
 			name: "valid config",
 			config: &Config{
-				LotteryContractAddress: "EQTest123",
-				NFTContractAddress:     "EQTestNFT456",
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
 				WalletPrivateKey:       "test_key",
 				TONNetwork:             "testnet",
 				MinParticipants:        2,
@@ -154,7 +243,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "missing lottery contract address",
 			config: &Config{
-				NFTContractAddress: "EQTestNFT456",
+				NFTContractAddress: testNFTAddress,
 				WalletPrivateKey:   "test_key",
 				TONNetwork:         "testnet",
 				MinParticipants:    2,
@@ -165,7 +254,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "missing NFT contract address",
 			config: &Config{
-				LotteryContractAddress: "EQTest123",
+				LotteryContractAddress: testLotteryAddress,
 				WalletPrivateKey:       "test_key",
 				TONNetwork:             "testnet",
 				MinParticipants:        2,
@@ -176,8 +265,8 @@ func TestValidate(t *testing.T) {
 		{
 			name: "missing wallet credentials",
 			config: &Config{
-				LotteryContractAddress: "EQTest123",
-				NFTContractAddress:     "EQTestNFT456",
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
 				TONNetwork:             "testnet",
 				MinParticipants:        2,
 				MaxParticipants:        10,
@@ -187,8 +276,8 @@ func TestValidate(t *testing.T) {
 		{
 			name: "invalid TON network",
 			config: &Config{
-				LotteryContractAddress: "EQTest123",
-				NFTContractAddress:     "EQTestNFT456",
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
 				WalletPrivateKey:       "test_key",
 				TONNetwork:             "invalid",
 				MinParticipants:        2,
@@ -199,8 +288,8 @@ func TestValidate(t *testing.T) {
 		{
 			name: "invalid min participants",
 			config: &Config{
-				LotteryContractAddress: "EQTest123",
-				NFTContractAddress:     "EQTestNFT456",
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
 				WalletPrivateKey:       "test_key",
 				TONNetwork:             "testnet",
 				MinParticipants:        0,
@@ -209,10 +298,176 @@ func TestValidate(t *testing.T) {
 			wantError: true,
 		},
 		{
-			name: "max participants less than min",
+			name: "malformed lottery contract address",
 			config: &Config{
 				LotteryContractAddress: "EQTest123",
-				NFTContractAddress:     "EQTestNFT456",
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: true,
+		},
+		{
+			name: "NFT contract address with bad checksum",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress[:47] + "B",
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: true,
+		},
+		{
+			name: "raw contract addresses",
+			config: &Config{
+				LotteryContractAddress: "0:4f07abb23458c88942c201069a71d5120bd4aa48910ca3f339c7c1ab1e63e11f",
+				NFTContractAddress:     "0:a679ec7764e9db880c4ab604897fe9d5f07f3a72ffa36634975ab1eb31aefa03",
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: false,
+		},
+		{
+			name: "testnet-only address on mainnet",
+			config: &Config{
+				LotteryContractAddress: "kQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhH5HX",
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "mainnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: true,
+		},
+		{
+			name: "mnemonic password without mnemonic",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				WalletMnemonicPassword: "meow",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: true,
+		},
+		{
+			name: "unsupported wallet version",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				WalletVersion:          "v2r1",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative wallet balance threshold",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				MinWalletBalanceTON:    -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid admin api key role",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				AdminAPIKeys:           "root:0123456789abcdef0123",
+			},
+			wantError: true,
+		},
+		{
+			name: "indexer without state file",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				IndexerPollInterval:    15 * time.Second,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative TON API rate limit",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				TONAPIRateLimit:        -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "TON API retry base delay above max",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				TONAPIRetryBaseDelay:   time.Second,
+				TONAPIRetryMaxDelay:    100 * time.Millisecond,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid TON API endpoints",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				TONAPIEndpoints:        "not a url",
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid TON API key rotation",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
+				WalletPrivateKey:       "test_key",
+				TONNetwork:             "testnet",
+				MinParticipants:        2,
+				MaxParticipants:        10,
+				TONAPIKeyRotation:      "random",
+			},
+			wantError: true,
+		},
+		{
+			name: "max participants less than min",
+			config: &Config{
+				LotteryContractAddress: testLotteryAddress,
+				NFTContractAddress:     testNFTAddress,
 				WalletPrivateKey:       "test_key",
 				TONNetwork:             "testnet",
 				MinParticipants:        10,
@@ -232,6 +487,125 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestParseAdminAPIKeys(t *testing.T) {
+	t.Run("valid keys", func(t *testing.T) {
+		keys, err := ParseAdminAPIKeys(" admin:admin-key-0123456789 , Operator:op:key:0123456789,")
+		if err != nil {
+			t.Fatalf("ParseAdminAPIKeys() failed: %v", err)
+		}
+
+		expected := []AdminAPIKey{
+			{Role: AdminRoleAdmin, Key: "admin-key-0123456789"},
+			{Role: AdminRoleOperator, Key: "op:key:0123456789"},
+		}
+		if len(keys) != len(expected) {
+			t.Fatalf("Expected %d keys, got %d", len(expected), len(keys))
+		}
+		for i := range expected {
+			if keys[i] != expected[i] {
+				t.Errorf("keys[%d] = %+v, want %+v", i, keys[i], expected[i])
+			}
+		}
+	})
+
+	t.Run("empty spec disables admin api", func(t *testing.T) {
+		keys, err := ParseAdminAPIKeys("")
+		if err != nil || len(keys) != 0 {
+			t.Errorf("Expected no keys and no error, got %v, %v", keys, err)
+		}
+	})
+
+	invalid := map[string]string{
+		"missing role":  "admin-key-0123456789",
+		"unknown role":  "root:admin-key-0123456789",
+		"short key":     "admin:short",
+		"duplicate key": "admin:admin-key-0123456789,operator:admin-key-0123456789",
+	}
+	for name, spec := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAdminAPIKeys(spec)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if strings.Contains(err.Error(), "admin-key-0123456789") {
+				t.Error("Error message should not contain the key")
+			}
+		})
+	}
+}
+
+func TestParseTONAPIEndpoints(t *testing.T) {
+	t.Run("valid endpoints", func(t *testing.T) {
+		endpoints, err := ParseTONAPIEndpoints(" https://toncenter.com/api/v2|secret-key-1 , http://backup.local:8081/api/v2/,")
+		if err != nil {
+			t.Fatalf("ParseTONAPIEndpoints() failed: %v", err)
+		}
+
+		expected := []APIEndpoint{
+			{URL: "https://toncenter.com/api/v2/", APIKey: "secret-key-1"},
+			{URL: "http://backup.local:8081/api/v2/"},
+		}
+		if len(endpoints) != len(expected) {
+			t.Fatalf("Expected %d endpoints, got %d", len(expected), len(endpoints))
+		}
+		for i := range expected {
+			if endpoints[i] != expected[i] {
+				t.Errorf("endpoints[%d] = %+v, want %+v", i, endpoints[i], expected[i])
+			}
+		}
+	})
+
+	invalid := map[string]string{
+		"missing scheme": "toncenter.com/api/v2/|secret-key-1",
+		"ftp scheme":     "ftp://toncenter.com/|secret-key-1",
+		"duplicate":      "https://toncenter.com/api/v2/|secret-key-1,https://toncenter.com/api/v2|secret-key-1",
+	}
+	for name, spec := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTONAPIEndpoints(spec)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if strings.Contains(err.Error(), "secret-key-1") {
+				t.Error("Error message should not contain the API key")
+			}
+		})
+	}
+
+	t.Run("falls back to single endpoint", func(t *testing.T) {
+		cfg := &Config{TONAPIEndpoint: "https://testnet.toncenter.com/api/v2/"}
+		if list := cfg.TONAPIEndpointList(); len(list) != 1 || list[0].URL != cfg.TONAPIEndpoint || list[0].APIKey != "" {
+			t.Errorf("Unexpected endpoint list: %+v", list)
+		}
+
+		cfg.TONAPIEndpoints = "https://a.example/api/v2/,https://b.example/api/v2/"
+		if list := cfg.TONAPIEndpointList(); len(list) != 2 || list[0].URL != "https://a.example/api/v2/" {
+			t.Errorf("Expected TONAPIEndpoints to take precedence, got %+v", list)
+		}
+	})
+}
+
+func TestLoadTONAPIKeys(t *testing.T) {
+	if keys := ParseTONAPIKeys(" a ,b,,a\n# comment,c\n c "); strings.Join(keys, ",") != "a,b,c" {
+		t.Errorf("ParseTONAPIKeys() = %v, want [a b c]", keys)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, []byte("# no keys yet\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTONAPIKeys("secret-key-1", empty); err == nil {
+		t.Error("Expected error for a key file without keys")
+	} else if strings.Contains(err.Error(), "secret-key-1") {
+		t.Error("Error message should not contain the API key")
+	}
+
+	keys, err := LoadTONAPIKeys("secret-key-1", "")
+	if err != nil || len(keys) != 1 {
+		t.Errorf("Expected keys from spec only, got %v, %v", keys, err)
+	}
+}
+
 func TestGetEnvHelpers(t *testing.T) {
 	t.Run("getEnvString", func(t *testing.T) {
 		// 測試默認值
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/ton/address"
+)
+
+// maxAdminRequestBody 管理 API 請求 body 的大小上限
+const maxAdminRequestBody = 4 << 10
+
+// 交易記錄查詢每頁的筆數
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+// OperationResponse 管理操作的執行結果
+type OperationResponse struct {
+	Operation    string                  `json:"operation"`
+	TxHash       string                  `json:"tx_hash,omitempty"`
+	Confirmation *ConfirmationResponse   `json:"confirmation,omitempty"`
+	Withdraw     *lottery.WithdrawReport `json:"withdraw,omitempty"`
+	Error        string                  `json:"error,omitempty"`
+}
+
+// ConfirmationResponse 交易監控器的確認結果
+type ConfirmationResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TransactionsResponse 後端發送的交易記錄，由新到舊排列
+type TransactionsResponse struct {
+	Transactions []store.Transaction `json:"transactions"`
+}
+
+// SetNFTContractRequest 設定 NFT 合約的請求內容
+type SetNFTContractRequest struct {
+	NFTContract string `json:"nft_contract"` // 空值使用 NFT_CONTRACT_ADDRESS
+}
+
+// adminKey 已解析的管理 API 金鑰，只保存金鑰的雜湊值
+type adminKey struct {
+	role string
+	hash [sha256.Size]byte
+}
+
+// registerAdminRoutes 註冊需要驗證的管理 API 路由，未設定金鑰時不註冊
+func (s *Server) registerAdminRoutes(mux *http.ServeMux) {
+	if len(s.adminKeys) == 0 {
+		return
+	}
+
+	mux.HandleFunc("POST /api/admin/draw", s.requireRole(config.AdminRoleOperator, s.handleDrawWinner))
+	mux.HandleFunc("POST /api/admin/rounds", s.requireRole(config.AdminRoleOperator, s.handleStartNewRound))
+	mux.HandleFunc("POST /api/admin/withdraw", s.requireRole(config.AdminRoleAdmin, s.handleWithdraw))
+	mux.HandleFunc("POST /api/admin/nft/withdraw", s.requireRole(config.AdminRoleAdmin, s.handleWithdrawNFT))
+	mux.HandleFunc("POST /api/admin/nft-contract", s.requireRole(config.AdminRoleAdmin, s.handleSetNFTContract))
+	mux.HandleFunc("GET /api/admin/transactions", s.requireRole(config.AdminRoleOperator, s.handleTransactions))
+	mux.HandleFunc("GET /api/admin/transactions/{hash}", s.requireRole(config.AdminRoleOperator, s.handleTransaction))
+}
+
+// loadAdminKeys 解析設定中的管理 API 金鑰
+func (s *Server) loadAdminKeys() {
+	keys, err := config.ParseAdminAPIKeys(s.config.AdminAPIKeys)
+	if err != nil {
+		s.logger.Error("ADMIN_API_KEYS 格式無效，管理 API 已停用", "error", err)
+		return
+	}
+
+	if len(keys) == 0 {
+		s.logger.Info("未設定 ADMIN_API_KEYS，管理 API 已停用")
+		return
+	}
+
+	s.adminKeys = make([]adminKey, 0, len(keys))
+	for _, key := range keys {
+		s.adminKeys = append(s.adminKeys, adminKey{role: key.Role, hash: sha256.Sum256([]byte(key.Key))})
+	}
+	s.logger.Info("管理 API 已啟用", "keys", len(s.adminKeys))
+}
+
+// authenticate 驗證請求中的 X-API-Key 或 Bearer token，返回對應的角色
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+	}
+	if token == "" {
+		return "", false
+	}
+
+	// 比較固定長度的雜湊值並走訪所有金鑰，避免以回應時間推測金鑰
+	hash := sha256.Sum256([]byte(token))
+	role := ""
+	for _, key := range s.adminKeys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash[:]) == 1 {
+			role = key.role
+		}
+	}
+	return role, role != ""
+}
+
+// roleAllows 角色是否具備所需權限，admin 可執行所有 operator 操作
+func roleAllows(role, required string) bool {
+	return role == config.AdminRoleAdmin || role == required
+}
+
+// requireRole 要求請求通過驗證且具備指定角色
+func (s *Server) requireRole(required string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			s.writeError(w, http.StatusUnauthorized, "缺少或無效的 API 金鑰")
+			return
+		}
+
+		if !roleAllows(role, required) {
+			s.logger.Warn("管理 API 權限不足", "role", role, "required", required, "path", r.URL.Path)
+			s.writeError(w, http.StatusForbidden, "權限不足")
+			return
+		}
+
+		s.logger.Info("管理 API 請求", "role", role, "path", r.URL.Path, "remote", r.RemoteAddr)
+		next(w, r)
+	}
+}
+
+// handleDrawWinner 發送抽獎交易
+func (s *Server) handleDrawWinner(w http.ResponseWriter, r *http.Request) {
+	s.runOperation(w, lottery.OperationDrawWinner, s.service.ExecuteDrawWinner)
+}
+
+// handleStartNewRound 發送開始新輪次交易
+func (s *Server) handleStartNewRound(w http.ResponseWriter, r *http.Request) {
+	s.runOperation(w, lottery.OperationStartNewRound, s.service.ExecuteStartNewRound)
+}
+
+// handleWithdraw 提取抽獎合約餘額
+func (s *Server) handleWithdraw(w http.ResponseWriter, r *http.Request) {
+	s.runOperation(w, lottery.OperationWithdraw, s.service.ExecuteWithdraw)
+}
+
+// handleWithdrawNFT 提取 NFT 合約餘額
+func (s *Server) handleWithdrawNFT(w http.ResponseWriter, r *http.Request) {
+	s.runOperation(w, lottery.OperationWithdrawNFT, s.service.ExecuteWithdrawNFT)
+}
+
+// handleSetNFTContract 發送設定 NFT 合約交易
+func (s *Server) handleSetNFTContract(w http.ResponseWriter, r *http.Request) {
+	var req SetNFTContractRequest
+	body := http.MaxBytesReader(w, r.Body, maxAdminRequestBody)
+	if err := json.NewDecoder(body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, http.StatusBadRequest, "無效的請求內容")
+		return
+	}
+
+	nftContract := req.NFTContract
+	if nftContract == "" {
+		nftContract = s.config.NFTContractAddress
+	}
+	if _, err := address.Parse(nftContract); err != nil {
+		s.writeError(w, http.StatusBadRequest, "無效的 NFT 合約地址")
+		return
+	}
+
+	s.runOperation(w, lottery.OperationSetNFTContract, func() (*lottery.OperationResult, error) {
+		return s.service.ExecuteSetNFTContract(nftContract)
+	})
+}
+
+// handleTransactions 返回交易記錄，可用 status 篩選確認狀態
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", store.TxStatusPending, store.TxStatusSuccess, store.TxStatusFailed, store.TxStatusTimeout:
+	default:
+		s.writeError(w, http.StatusBadRequest, "status 必須是 pending、success、failed 或 timeout")
+		return
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil || limit < 0 || limit > maxTransactionPageSize {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit 必須介於 1 到 %d", maxTransactionPageSize))
+		return
+	}
+	if limit == 0 {
+		limit = defaultTransactionPageSize
+	}
+
+	txs, err := s.service.ListTransactions(status, limit)
+	if err != nil {
+		s.logger.Error("讀取交易記錄失敗", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "讀取交易記錄失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, TransactionsResponse{Transactions: txs})
+}
+
+// handleTransaction 返回單筆交易記錄
+func (s *Server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	tx, err := s.service.GetTransaction(r.PathValue("hash"))
+	if errors.Is(err, store.ErrNotFound) {
+		s.writeError(w, http.StatusNotFound, "交易記錄不存在")
+		return
+	}
+	if err != nil {
+		s.logger.Error("讀取交易記錄失敗", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "讀取交易記錄失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, tx)
+}
+
+// runOperation 執行 owner 操作並返回交易 hash 與確認結果
+//
+// 確認可能需要數分鐘，因此取消此回應的寫入逾時。
+func (s *Server) runOperation(w http.ResponseWriter, operation string, execute func() (*lottery.OperationResult, error)) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.logger.Warn("無法取消寫入逾時", "error", err)
+	}
+
+	result, err := execute()
+
+	resp := OperationResponse{Operation: operation}
+	if result != nil {
+		resp.TxHash = result.TxHash
+		resp.Withdraw = result.Withdraw
+		if c := result.Confirmation; c != nil {
+			resp.Confirmation = &ConfirmationResponse{Status: c.Status}
+			if c.Error != nil {
+				resp.Confirmation.Error = c.Error.Error()
+			}
+		}
+	}
+
+	if err == nil {
+		s.writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	resp.Error = err.Error()
+	s.logger.Error("管理操作失敗", "operation", operation, "tx_hash", resp.TxHash, "error", err)
+	s.writeJSON(w, operationErrorStatus(result, err), resp)
+}
+
+// operationErrorStatus 依錯誤類型決定 HTTP 狀態碼
+func operationErrorStatus(result *lottery.OperationResult, err error) int {
+	switch {
+	case errors.Is(err, lottery.ErrOperationInProgress), errors.Is(err, lottery.ErrInvalidState):
+		return http.StatusConflict
+	case errors.Is(err, lottery.ErrLowWalletBalance):
+		return http.StatusServiceUnavailable
+	case result != nil && result.Confirmation != nil && result.Confirmation.Status == "pending":
+		// 交易已發送但在逾時前未確認
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
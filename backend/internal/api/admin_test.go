@@ -0,0 +1,265 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/internal/transaction"
+)
+
+const (
+	testAdminKey    = "admin-key-0123456789"
+	testOperatorKey = "operator-key-0123456789"
+)
+
+// createAdminTestServer 創建啟用管理 API 的測試伺服器
+func createAdminTestServer(t *testing.T, handle getMethodHandler) *Server {
+	t.Helper()
+
+	return createTestServerWithConfig(t, handle, func(cfg *config.Config) {
+		cfg.AdminAPIKeys = "admin:" + testAdminKey + ",operator:" + testOperatorKey
+		cfg.NFTContractAddress = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+	})
+}
+
+// doAdminRequest 以指定的標頭發送管理 API 請求
+func doAdminRequest(t *testing.T, s *Server, path string, header http.Header, body string, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("解析回應失敗: %v (body: %s)", err, rec.Body.String())
+		}
+	}
+	return rec
+}
+
+func apiKeyHeader(key string) http.Header {
+	return http.Header{"X-Api-Key": {key}}
+}
+
+func bearerHeader(key string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + key}}
+}
+
+func TestAdminAPIDisabledWithoutKeys(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+	rec := doAdminRequest(t, s, "/api/admin/draw", apiKeyHeader(testAdminKey), "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("未設定金鑰時 status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	// 合約未活躍，抽獎與提取會在發送交易前失敗，便於只測試驗證
+	s := createAdminTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method == "getContractInfo" {
+			return contractInfoResult(ton.LotteryContractInfo{LotteryActive: false, CurrentRound: 1, ParticipantCount: 0})
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		status int
+	}{
+		{"missing key", "/api/admin/draw", nil, http.StatusUnauthorized},
+		{"invalid key", "/api/admin/draw", apiKeyHeader("wrong-key-0123456789"), http.StatusUnauthorized},
+		{"non-bearer authorization", "/api/admin/draw", http.Header{"Authorization": {"Basic " + testAdminKey}}, http.StatusUnauthorized},
+		{"operator may draw", "/api/admin/draw", apiKeyHeader(testOperatorKey), http.StatusConflict},
+		{"bearer token accepted", "/api/admin/draw", bearerHeader(testAdminKey), http.StatusConflict},
+		{"operator may not withdraw", "/api/admin/withdraw", bearerHeader(testOperatorKey), http.StatusForbidden},
+		{"operator may not set nft", "/api/admin/nft-contract", apiKeyHeader(testOperatorKey), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAdminRequest(t, s, tt.path, tt.header, "", nil)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 回應應包含 WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestAdminOperationPreconditionFailure(t *testing.T) {
+	s := createAdminTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method == "getContractInfo" {
+			return contractInfoResult(ton.LotteryContractInfo{LotteryActive: true, CurrentRound: 1, ParticipantCount: 0})
+		}
+		return nil
+	})
+
+	var resp OperationResponse
+	rec := doAdminRequest(t, s, "/api/admin/draw", apiKeyHeader(testOperatorKey), "", &resp)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+	if resp.Operation != lottery.OperationDrawWinner || resp.TxHash != "" || resp.Error == "" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestAdminSetNFTContractValidation(t *testing.T) {
+	s := createAdminTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+	for name, body := range map[string]string{
+		"invalid json":    `{"nft_contract":`,
+		"invalid address": `{"nft_contract": "not-an-address"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := doAdminRequest(t, s, "/api/admin/nft-contract", apiKeyHeader(testAdminKey), body, &ErrorResponse{})
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestAdminDrawWinner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("交易監控每 10 秒查詢一次，short 模式略過")
+	}
+
+	s := createAdminTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(ton.LotteryContractInfo{LotteryActive: true, CurrentRound: 1, ParticipantCount: 5})
+		case "getWinner":
+			return getMethodResult(ton.EncodeLotteryResult(&ton.LotteryResult{Winner: testWinnerAddress, NFTId: 1042, Timestamp: 1700000000}))
+		}
+		return nil
+	})
+
+	var resp OperationResponse
+	rec := doAdminRequest(t, s, "/api/admin/draw", bearerHeader(testOperatorKey), "", &resp)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if resp.Operation != lottery.OperationDrawWinner || resp.TxHash != "0xabc123" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Confirmation == nil || resp.Confirmation.Status != "success" {
+		t.Errorf("confirmation = %+v, want success", resp.Confirmation)
+	}
+}
+
+func TestAdminTransactions(t *testing.T) {
+	storeFile := filepath.Join(t.TempDir(), "lottery.json")
+	repo, err := store.Open(storeFile)
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	sent := time.Unix(1700000000, 0)
+	for i, tx := range []store.Transaction{
+		{Hash: "hash-draw", Operation: lottery.OperationDrawWinner, Status: store.TxStatusSuccess},
+		{Hash: "hash-round", Operation: lottery.OperationStartNewRound, Status: store.TxStatusPending},
+	} {
+		tx.SentAt = sent.Add(time.Duration(i) * time.Minute)
+		if err := repo.SaveTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := createTestServerWithConfig(t, func(method string, stack tvm.Args) json.RawMessage { return nil }, func(cfg *config.Config) {
+		cfg.AdminAPIKeys = "admin:" + testAdminKey + ",operator:" + testOperatorKey
+		cfg.StoreFile = storeFile
+	})
+
+	get := func(path string, header http.Header, out interface{}) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if out != nil && rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatalf("解析回應失敗: %v (body: %s)", err, rec.Body.String())
+			}
+		}
+		return rec.Code
+	}
+
+	if code := get("/api/admin/transactions", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("未驗證 status = %d, want 401", code)
+	}
+
+	var resp TransactionsResponse
+	if code := get("/api/admin/transactions", apiKeyHeader(testOperatorKey), &resp); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(resp.Transactions) != 2 || resp.Transactions[0].Hash != "hash-round" {
+		t.Errorf("Expected newest transaction first, got %+v", resp.Transactions)
+	}
+
+	resp = TransactionsResponse{}
+	if code := get("/api/admin/transactions?status=pending", apiKeyHeader(testOperatorKey), &resp); code != http.StatusOK || len(resp.Transactions) != 1 {
+		t.Errorf("Expected 1 pending transaction, got %d %+v", code, resp)
+	}
+	if code := get("/api/admin/transactions?status=unknown", apiKeyHeader(testOperatorKey), nil); code != http.StatusBadRequest {
+		t.Errorf("無效 status 的 status = %d, want 400", code)
+	}
+
+	var tx store.Transaction
+	if code := get("/api/admin/transactions/hash-draw", apiKeyHeader(testOperatorKey), &tx); code != http.StatusOK || tx.Status != store.TxStatusSuccess {
+		t.Errorf("Unexpected transaction: %d %+v", code, tx)
+	}
+	if code := get("/api/admin/transactions/missing", apiKeyHeader(testOperatorKey), nil); code != http.StatusNotFound {
+		t.Errorf("不存在的交易 status = %d, want 404", code)
+	}
+}
+
+func TestOperationErrorStatus(t *testing.T) {
+	failed := &lottery.OperationResult{TxHash: "0x1", Confirmation: &transaction.Result{Status: "failed"}}
+	pending := &lottery.OperationResult{TxHash: "0x1", Confirmation: &transaction.Result{Status: "pending"}}
+
+	tests := []struct {
+		name   string
+		result *lottery.OperationResult
+		err    error
+		status int
+	}{
+		{"operation in progress", nil, lottery.ErrOperationInProgress, http.StatusConflict},
+		{"invalid state", nil, lottery.ErrInvalidState, http.StatusConflict},
+		{"low wallet balance", nil, lottery.ErrLowWalletBalance, http.StatusServiceUnavailable},
+		{"send failure", nil, errors.New("send failed"), http.StatusBadGateway},
+		{"transaction failed", failed, errors.New("failed"), http.StatusBadGateway},
+		{"confirmation timeout", pending, errors.New("timeout"), http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := operationErrorStatus(tt.result, tt.err); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
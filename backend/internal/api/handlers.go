@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+)
+
+// ParticipantsResponse 當前輪次的參與者列表
+//
+// 部分索引查詢失敗時仍返回其餘參與者，Complete 為 false 並在 FailedIndexes 列出失敗的索引。
+type ParticipantsResponse struct {
+	Round            int                          `json:"round"`
+	ParticipantCount int                          `json:"participant_count"`
+	Participants     []lottery.IndexedParticipant `json:"participants"`
+	Complete         bool                         `json:"complete"`
+	FailedIndexes    []int                        `json:"failed_indexes,omitempty"`
+}
+
+// WinnerResponse 指定輪次的中獎記錄
+type WinnerResponse struct {
+	Round int `json:"round"`
+	ton.LotteryResult
+}
+
+// RoundHistoryResponse 由新到舊排列的一頁開獎結果，以 next_cursor 查詢下一頁
+type RoundHistoryResponse struct {
+	CurrentRound int                   `json:"current_round"`
+	Rounds       []lottery.RoundResult `json:"rounds"`
+	NextCursor   int                   `json:"next_cursor,omitempty"`
+}
+
+// RoundParticipantsResponse 指定輪次已記錄的參與者
+type RoundParticipantsResponse struct {
+	Round        int                          `json:"round"`
+	Participants []lottery.IndexedParticipant `json:"participants"`
+}
+
+// RoundParticipantStatusResponse 指定地址在某輪次的參與與中獎狀態
+//
+// Participant 為資料檔中記錄的參與資訊，沒有記錄時為 null。
+type RoundParticipantStatusResponse struct {
+	Round       int                         `json:"round"`
+	Address     string                      `json:"address"`
+	Participant *lottery.IndexedParticipant `json:"participant"`
+	Winner      bool                        `json:"winner"`
+}
+
+// BalanceResponse 合約餘額
+type BalanceResponse struct {
+	Address    string  `json:"address"`
+	Balance    int64   `json:"balance"`     // nanoTON
+	BalanceTON float64 `json:"balance_ton"` // TON
+}
+
+// registerRoutes 註冊唯讀 API 路由
+func (s *Server) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/status", allowCORS(s.handleStatus))
+	mux.HandleFunc("GET /api/contract", allowCORS(s.handleContractInfo))
+	mux.HandleFunc("GET /api/contract/balance", allowCORS(s.handleContractBalance))
+	mux.HandleFunc("GET /api/participants", allowCORS(s.handleParticipants))
+	mux.HandleFunc("GET /api/rounds", allowCORS(s.handleRoundHistory))
+	mux.HandleFunc("GET /api/rounds/{round}/winner", allowCORS(s.handleWinner))
+	mux.HandleFunc("GET /api/rounds/{round}/participants", allowCORS(s.handleRoundParticipants))
+	mux.HandleFunc("GET /api/rounds/{round}/participants/{address}", allowCORS(s.handleRoundParticipantStatus))
+}
+
+// handleStatus 返回服務狀態
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.service.GetStatus())
+}
+
+// handleContractInfo 返回抽獎合約狀態
+func (s *Server) handleContractInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := s.service.GetContractInfo()
+	if err != nil {
+		s.logger.Error("查詢合約狀態失敗", "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢合約狀態失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, info)
+}
+
+// handleContractBalance 返回抽獎合約餘額
+func (s *Server) handleContractBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := s.service.GetContractBalance()
+	if err != nil {
+		s.logger.Error("查詢合約餘額失敗", "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢合約餘額失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, BalanceResponse{
+		Address:    s.config.LotteryContractAddress,
+		Balance:    balance,
+		BalanceTON: float64(balance) / 1e9,
+	})
+}
+
+// handleParticipants 返回當前輪次的所有參與者
+func (s *Server) handleParticipants(w http.ResponseWriter, r *http.Request) {
+	list, err := s.service.ListParticipants()
+	if errors.Is(err, lottery.ErrParticipantsChanged) {
+		s.writeError(w, http.StatusServiceUnavailable, "參與者列表正在變化，請稍後再試")
+		return
+	}
+	if err != nil {
+		s.logger.Error("查詢參與者列表失敗", "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢合約狀態失敗")
+		return
+	}
+
+	// 所有索引都失敗時部分結果沒有意義
+	if list.ParticipantCount > 0 && len(list.Failed) == list.ParticipantCount {
+		s.logger.Error("查詢參與者資訊失敗", "round", list.Round, "error", list.Failed[0])
+		s.writeError(w, http.StatusBadGateway, "查詢參與者資訊失敗")
+		return
+	}
+
+	resp := ParticipantsResponse{
+		Round:            list.Round,
+		ParticipantCount: list.ParticipantCount,
+		Participants:     list.Participants,
+		Complete:         list.Complete(),
+	}
+	for _, failed := range list.Failed {
+		resp.FailedIndexes = append(resp.FailedIndexes, failed.Index)
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleWinner 返回指定輪次的中獎記錄
+func (s *Server) handleWinner(w http.ResponseWriter, r *http.Request) {
+	round, err := strconv.Atoi(r.PathValue("round"))
+	if err != nil || round < 1 {
+		s.writeError(w, http.StatusBadRequest, "輪次必須為正整數")
+		return
+	}
+
+	winner, err := s.service.GetWinner(round)
+	if err != nil {
+		s.logger.Error("查詢中獎記錄失敗", "round", round, "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢中獎記錄失敗")
+		return
+	}
+
+	// 該輪次尚未開獎時合約返回 null
+	if winner == nil {
+		s.writeError(w, http.StatusNotFound, "該輪次尚無中獎記錄")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, WinnerResponse{Round: round, LotteryResult: *winner})
+}
+
+// handleRoundParticipants 返回資料檔中記錄的指定輪次參與者
+func (s *Server) handleRoundParticipants(w http.ResponseWriter, r *http.Request) {
+	round, err := strconv.Atoi(r.PathValue("round"))
+	if err != nil || round < 1 {
+		s.writeError(w, http.StatusBadRequest, "輪次必須為正整數")
+		return
+	}
+
+	participants, err := s.service.GetRoundParticipants(round)
+	if err != nil {
+		s.logger.Error("讀取參與者記錄失敗", "round", round, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "讀取參與者記錄失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RoundParticipantsResponse{Round: round, Participants: participants})
+}
+
+// handleRoundParticipantStatus 返回指定地址在某輪次是否參與及是否中獎，地址可為任一格式
+func (s *Server) handleRoundParticipantStatus(w http.ResponseWriter, r *http.Request) {
+	round, err := strconv.Atoi(r.PathValue("round"))
+	if err != nil || round < 1 {
+		s.writeError(w, http.StatusBadRequest, "輪次必須為正整數")
+		return
+	}
+	addr := r.PathValue("address")
+	if _, err := address.Parse(addr); err != nil {
+		s.writeError(w, http.StatusBadRequest, "地址格式無效")
+		return
+	}
+
+	participant, err := s.service.FindRoundParticipant(round, addr)
+	if err != nil {
+		s.logger.Error("讀取參與者記錄失敗", "round", round, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "讀取參與者記錄失敗")
+		return
+	}
+
+	winner, err := s.service.IsWinner(round, addr)
+	if err != nil {
+		s.logger.Error("查詢中獎記錄失敗", "round", round, "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢中獎記錄失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RoundParticipantStatusResponse{
+		Round:       round,
+		Address:     addr,
+		Participant: participant,
+		Winner:      winner,
+	})
+}
+
+// handleRoundHistory 返回分頁的開獎歷史
+func (s *Server) handleRoundHistory(w http.ResponseWriter, r *http.Request) {
+	cursor, err := queryInt(r, "cursor")
+	if err != nil || cursor < 0 {
+		s.writeError(w, http.StatusBadRequest, "cursor 必須為非負整數")
+		return
+	}
+	limit, err := queryInt(r, "limit")
+	if err != nil || limit < 0 || limit > lottery.MaxHistoryPageSize {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit 必須介於 1 到 %d", lottery.MaxHistoryPageSize))
+		return
+	}
+
+	history, err := s.service.GetRoundHistory(cursor, limit)
+	if err != nil {
+		s.logger.Error("查詢開獎歷史失敗", "cursor", cursor, "limit", limit, "error", err)
+		s.writeError(w, http.StatusBadGateway, "查詢開獎歷史失敗")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RoundHistoryResponse{
+		CurrentRound: history.CurrentRound,
+		Rounds:       history.Rounds,
+		NextCursor:   history.NextCursor,
+	})
+}
+
+// queryInt 讀取整數查詢參數，未提供時返回 0
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+const testWinnerAddress = "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n"
+
+var testContractInfo = ton.LotteryContractInfo{
+	EntryFee:         100000000,
+	MaxParticipants:  10,
+	CurrentRound:     2,
+	LotteryActive:    true,
+	ParticipantCount: 3,
+}
+
+// testUserAddress 返回第 i 位測試參與者的地址
+func testUserAddress(i int) string {
+	addr, err := address.NewAddress(0, bytes.Repeat([]byte{byte(i + 1)}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return addr.String()
+}
+
+// stackIndex 取出 get 方法的第一個整數參數
+func stackIndex(stack tvm.Args) int {
+	if len(stack) == 0 {
+		return -1
+	}
+	n, err := stack[0].Int64()
+	if err != nil {
+		return -1
+	}
+	return int(n)
+}
+
+func TestHandleStatus(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+	var status map[string]interface{}
+	rec := doRequest(t, s, http.MethodGet, "/api/status", &status)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("唯讀端點應允許跨來源請求")
+	}
+	if status["wallet_address"] != s.service.GetWalletAddress() {
+		t.Errorf("wallet_address = %v, want %s", status["wallet_address"], s.service.GetWalletAddress())
+	}
+	if _, ok := status["running"]; !ok {
+		t.Error("狀態應包含 running")
+	}
+}
+
+func TestHandleContractInfo(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method == "getContractInfo" {
+			return contractInfoResult(testContractInfo)
+		}
+		return nil
+	})
+
+	var info struct {
+		CurrentRound     int  `json:"current_round"`
+		ParticipantCount int  `json:"participant_count"`
+		LotteryActive    bool `json:"lottery_active"`
+	}
+	rec := doRequest(t, s, http.MethodGet, "/api/contract", &info)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if info.CurrentRound != 2 || info.ParticipantCount != 3 || !info.LotteryActive {
+		t.Errorf("unexpected contract info: %+v", info)
+	}
+}
+
+func TestHandleContractInfoUpstreamError(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+	var resp ErrorResponse
+	rec := doRequest(t, s, http.MethodGet, "/api/contract", &resp)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", rec.Code)
+	}
+	if resp.Error == "" {
+		t.Error("錯誤回應應包含 error")
+	}
+}
+
+func TestHandleContractBalance(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method == "getBalance" {
+			return getMethodResult(tvm.Stack{tvm.Int(2500000000)}, nil)
+		}
+		return nil
+	})
+
+	var balance BalanceResponse
+	rec := doRequest(t, s, http.MethodGet, "/api/contract/balance", &balance)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if balance.Balance != 2500000000 || balance.BalanceTON != 2.5 {
+		t.Errorf("unexpected balance: %+v", balance)
+	}
+	if balance.Address != testLotteryAddress {
+		t.Errorf("address = %s, want %s", balance.Address, testLotteryAddress)
+	}
+}
+
+func TestHandleParticipants(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(testContractInfo)
+		case "getParticipant":
+			i := stackIndex(stack)
+			return getMethodResult(ton.EncodeParticipant(&ton.Participant{
+				Address:   testUserAddress(i),
+				Amount:    100000000,
+				Timestamp: int64(1700000000 + i),
+			}))
+		}
+		return nil
+	})
+
+	var resp ParticipantsResponse
+	rec := doRequest(t, s, http.MethodGet, "/api/participants", &resp)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if resp.Round != 2 || resp.ParticipantCount != 3 {
+		t.Errorf("round = %d, count = %d, want 2, 3", resp.Round, resp.ParticipantCount)
+	}
+	if !resp.Complete || len(resp.FailedIndexes) != 0 {
+		t.Errorf("complete = %v, failed_indexes = %v", resp.Complete, resp.FailedIndexes)
+	}
+	if len(resp.Participants) != 3 {
+		t.Fatalf("len(participants) = %d, want 3", len(resp.Participants))
+	}
+	for i, p := range resp.Participants {
+		if p.Index != i || p.Address != testUserAddress(i) || p.Timestamp != int64(1700000000+i) {
+			t.Errorf("participants[%d] = %+v", i, p)
+		}
+	}
+}
+
+func TestHandleRoundParticipants(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(testContractInfo)
+		case "getParticipant":
+			i := stackIndex(stack)
+			return getMethodResult(ton.EncodeParticipant(&ton.Participant{Address: testUserAddress(i), Amount: 100000000}))
+		}
+		return nil
+	})
+
+	// 查詢當前參與者後，該輪次的參與者會被記錄
+	if rec := doRequest(t, s, http.MethodGet, "/api/participants", nil); rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/participants status = %d, want 200", rec.Code)
+	}
+
+	var resp RoundParticipantsResponse
+	if rec := doRequest(t, s, http.MethodGet, "/api/rounds/2/participants", &resp); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if resp.Round != 2 || len(resp.Participants) != 3 || resp.Participants[2].Address != testUserAddress(2) {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	// 沒有記錄的輪次返回空列表
+	resp = RoundParticipantsResponse{}
+	if rec := doRequest(t, s, http.MethodGet, "/api/rounds/1/participants", &resp); rec.Code != http.StatusOK || resp.Participants == nil || len(resp.Participants) != 0 {
+		t.Errorf("Expected empty participants for round 1, got %d %+v", rec.Code, resp)
+	}
+
+	if rec := doRequest(t, s, http.MethodGet, "/api/rounds/0/participants", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleRoundParticipantStatus(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(testContractInfo)
+		case "getParticipant":
+			i := stackIndex(stack)
+			return getMethodResult(ton.EncodeParticipant(&ton.Participant{Address: testUserAddress(i), Amount: 100000000}))
+		case "getWinner":
+			if stackIndex(stack) == 2 {
+				return getMethodResult(ton.EncodeLotteryResult(&ton.LotteryResult{Winner: testUserAddress(1), NFTId: 2003, Timestamp: 1700000000}))
+			}
+			return getMethodResult(ton.EncodeLotteryResult(nil))
+		}
+		return nil
+	})
+
+	if rec := doRequest(t, s, http.MethodGet, "/api/participants", nil); rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/participants status = %d, want 200", rec.Code)
+	}
+
+	// 以原始格式查詢記錄中的使用者友好地址
+	raw := address.MustParse(testUserAddress(1)).StringRaw()
+	var resp RoundParticipantStatusResponse
+	if rec := doRequest(t, s, http.MethodGet, "/api/rounds/2/participants/"+raw, &resp); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if resp.Round != 2 || resp.Address != raw || resp.Participant == nil || resp.Participant.Index != 1 || !resp.Winner {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	// 沒有參與記錄也沒有中獎
+	resp = RoundParticipantStatusResponse{}
+	if rec := doRequest(t, s, http.MethodGet, "/api/rounds/2/participants/"+testWinnerAddress, &resp); rec.Code != http.StatusOK || resp.Participant != nil || resp.Winner {
+		t.Errorf("Expected no participation, got %d %+v", rec.Code, resp)
+	}
+
+	for _, path := range []string{"/api/rounds/0/participants/" + raw, "/api/rounds/2/participants/not-an-address"} {
+		if rec := doRequest(t, s, http.MethodGet, path, &ErrorResponse{}); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, rec.Code)
+		}
+	}
+}
+
+func TestHandleParticipantsPartial(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(testContractInfo)
+		case "getParticipant":
+			switch i := stackIndex(stack); i {
+			case 0:
+				return getMethodResult(ton.EncodeParticipant(nil))
+			case 1:
+				return nil
+			default:
+				return getMethodResult(ton.EncodeParticipant(&ton.Participant{Address: testUserAddress(i), Amount: 100000000}))
+			}
+		}
+		return nil
+	})
+
+	var resp ParticipantsResponse
+	rec := doRequest(t, s, http.MethodGet, "/api/participants", &resp)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if resp.Complete || len(resp.FailedIndexes) != 1 || resp.FailedIndexes[0] != 1 {
+		t.Errorf("complete = %v, failed_indexes = %v, want false, [1]", resp.Complete, resp.FailedIndexes)
+	}
+	if len(resp.Participants) != 1 || resp.Participants[0].Index != 2 {
+		t.Errorf("participants = %+v, want only index 2", resp.Participants)
+	}
+}
+
+func TestHandleParticipantsUpstreamError(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method == "getContractInfo" {
+			return contractInfoResult(testContractInfo)
+		}
+		return nil
+	})
+
+	rec := doRequest(t, s, http.MethodGet, "/api/participants", &ErrorResponse{})
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", rec.Code)
+	}
+}
+
+func TestHandleWinner(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		if method != "getWinner" {
+			return nil
+		}
+		if stackIndex(stack) == 1 {
+			return getMethodResult(ton.EncodeLotteryResult(&ton.LotteryResult{Winner: testWinnerAddress, NFTId: 1042, Timestamp: 1700000000}))
+		}
+		return getMethodResult(ton.EncodeLotteryResult(nil))
+	})
+
+	t.Run("existing round", func(t *testing.T) {
+		var winner WinnerResponse
+		rec := doRequest(t, s, http.MethodGet, "/api/rounds/1/winner", &winner)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if winner.Round != 1 || winner.Winner != testWinnerAddress || winner.NFTId != 1042 {
+			t.Errorf("unexpected winner: %+v", winner)
+		}
+	})
+
+	t.Run("round without result", func(t *testing.T) {
+		rec := doRequest(t, s, http.MethodGet, "/api/rounds/5/winner", &ErrorResponse{})
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	for _, round := range []string{"0", "-1", "abc"} {
+		t.Run("invalid round "+round, func(t *testing.T) {
+			rec := doRequest(t, s, http.MethodGet, "/api/rounds/"+round+"/winner", &ErrorResponse{})
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleRoundHistory(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(ton.LotteryContractInfo{CurrentRound: 4})
+		case "getWinner":
+			// 第 2 輪沒有開獎，第 4 輪（當前輪次）尚未開獎
+			if round := stackIndex(stack); round == 1 || round == 3 {
+				return getMethodResult(ton.EncodeLotteryResult(&ton.LotteryResult{Winner: testWinnerAddress, NFTId: int64(round * 1000), Timestamp: 1700000000}))
+			}
+			return getMethodResult(ton.EncodeLotteryResult(nil))
+		}
+		return nil
+	})
+
+	var page RoundHistoryResponse
+	rec := doRequest(t, s, http.MethodGet, "/api/rounds?limit=1", &page)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if page.CurrentRound != 4 || len(page.Rounds) != 1 || page.Rounds[0].Round != 3 || page.NextCursor != 3 {
+		t.Fatalf("first page = %+v, want round 3 with next_cursor 3", page)
+	}
+	if page.Rounds[0].Winner != testWinnerAddress || page.Rounds[0].NFTId != 3000 {
+		t.Errorf("rounds[0] = %+v", page.Rounds[0])
+	}
+
+	page = RoundHistoryResponse{}
+	rec = doRequest(t, s, http.MethodGet, "/api/rounds?limit=1&cursor=3", &page)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if len(page.Rounds) != 1 || page.Rounds[0].Round != 1 || page.NextCursor != 0 {
+		t.Errorf("second page = %+v, want round 1 without next_cursor", page)
+	}
+
+	for _, query := range []string{"cursor=-1", "cursor=abc", "limit=101", "limit=-5"} {
+		rec := doRequest(t, s, http.MethodGet, "/api/rounds?"+query, &ErrorResponse{})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"ton-cat-lottery-backend/internal/lottery"
+)
+
+// registerHealthRoutes 註冊 Kubernetes 探針使用的存活與就緒端點
+func (s *Server) registerHealthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /ready", s.handleReady)
+}
+
+// handleHealth 存活檢查：服務與自動抽獎迴圈是否存活
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeHealthReport(w, s.service.Liveness())
+}
+
+// handleReady 就緒檢查：TON API、錢包與錢包餘額
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.writeHealthReport(w, s.service.Readiness(r.Context()))
+}
+
+// writeHealthReport 通過時返回 200，否則返回 503
+func (s *Server) writeHealthReport(w http.ResponseWriter, report *lottery.HealthReport) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+		s.logger.Warn("健康檢查未通過", "checks", report.Checks)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeJSON(w, status, report)
+}
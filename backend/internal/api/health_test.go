@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+func TestHandleHealth(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		return contractInfoResult(testContractInfo)
+	})
+
+	var report lottery.HealthReport
+	rec := doRequest(t, s, http.MethodGet, "/health", &report)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("啟動前 status = %d, want 503", rec.Code)
+	}
+	if report.Status != lottery.CheckStatusFail || len(report.Checks) == 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	if err := s.service.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer s.service.Stop()
+
+	rec = doRequest(t, s, http.MethodGet, "/health", &report)
+	if rec.Code != http.StatusOK {
+		t.Errorf("啟動後 status = %d, want 200 (%+v)", rec.Code, report.Checks)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("健康檢查回應不應被快取")
+	}
+}
+
+func TestHandleReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+			return contractInfoResult(testContractInfo)
+		})
+
+		var report lottery.HealthReport
+		rec := doRequest(t, s, http.MethodGet, "/ready", &report)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%+v)", rec.Code, report.Checks)
+		}
+
+		names := map[string]string{}
+		for _, check := range report.Checks {
+			names[check.Name] = check.Status
+		}
+		for _, name := range []string{"ton_api", "wallet", "wallet_balance"} {
+			if _, ok := names[name]; !ok {
+				t.Errorf("就緒檢查應包含 %s", name)
+			}
+		}
+	})
+
+	t.Run("ton api unavailable", func(t *testing.T) {
+		s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+		var report lottery.HealthReport
+		rec := doRequest(t, s, http.MethodGet, "/ready", &report)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", rec.Code)
+		}
+		if report.Status != lottery.CheckStatusFail {
+			t.Errorf("report status = %s, want fail", report.Status)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"ton-cat-lottery-backend/pkg/metrics"
+)
+
+// metricsRefreshTimeout 抓取指標時查詢鏈上狀態的逾時，逾時後仍輸出上次的數值
+const metricsRefreshTimeout = 5 * time.Second
+
+// registerMetricsRoutes 註冊 Prometheus 抓取的指標端點
+func (s *Server) registerMetricsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+}
+
+// handleMetrics 更新鏈上狀態的 gauge 後，以 Prometheus 文字格式輸出所有指標
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), metricsRefreshTimeout)
+	s.service.RefreshMetrics(ctx)
+	cancel()
+
+	w.Header().Set("Cache-Control", "no-store")
+	metrics.Default.Handler().ServeHTTP(w, r)
+}
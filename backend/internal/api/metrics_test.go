@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		switch method {
+		case "getContractInfo":
+			return contractInfoResult(ton.LotteryContractInfo{CurrentRound: 7, ParticipantCount: 3, LotteryActive: true})
+		case "getBalance":
+			return getMethodResult(tvm.Stack{tvm.Int(2500000000)}, nil)
+		}
+		return nil
+	})
+
+	rec := doRequest(t, s, http.MethodGet, "/metrics", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"lottery_current_round 7\n",
+		"lottery_participant_count 3\n",
+		"lottery_contract_balance_nanoton 2.5e+09\n",
+		"lottery_wallet_balance_nanoton 0\n",
+		`ton_api_request_duration_seconds_count{method="runGetMethod",status="200",endpoint="`,
+		"# TYPE ton_api_endpoint_up gauge\n",
+		"# TYPE lottery_draw_attempts_total counter\n",
+		"# TYPE tx_confirmation_wait_seconds histogram\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// HTTP 伺服器的逾時設定
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 60 * time.Second
+	idleTimeout       = 120 * time.Second
+)
+
+// Server 對外提供抽獎服務的 HTTP API
+type Server struct {
+	config     *config.Config
+	logger     *logger.Logger
+	service    *lottery.Service
+	httpServer *http.Server
+	listener   net.Listener
+
+	// closing 在 Shutdown 開始時關閉，通知 SSE 與 WebSocket 串流結束
+	closing chan struct{}
+
+	// adminKeys 管理 API 金鑰，為空時停用管理 API
+	adminKeys []adminKey
+}
+
+// ErrorResponse API 錯誤回應
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer 創建新的 HTTP API 伺服器
+func NewServer(cfg *config.Config, log *logger.Logger, service *lottery.Service) *Server {
+	s := &Server{
+		config:  cfg,
+		logger:  log.WithGroup("api"),
+		service: service,
+		closing: make(chan struct{}),
+	}
+	s.loadAdminKeys()
+
+	s.httpServer = &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	// Shutdown 不會等待已接管的連線，且串流不會自行結束，因此需主動通知
+	s.httpServer.RegisterOnShutdown(func() { close(s.closing) })
+
+	return s
+}
+
+// Handler 返回包含所有路由的 HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+	s.registerHealthRoutes(mux)
+	s.registerAdminRoutes(mux)
+	s.registerStreamRoutes(mux)
+	s.registerMetricsRoutes(mux)
+	return s.logRequests(mux)
+}
+
+// Start 開始監聽設定的埠號，並在背景處理請求
+//
+// 監聽失敗（例如埠號已被佔用）會直接返回錯誤。
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("監聽 %s 失敗: %w", s.httpServer.Addr, err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP 伺服器異常停止", "error", err)
+		}
+	}()
+
+	s.logger.Info("🌐 HTTP API 伺服器已啟動", "addr", listener.Addr().String())
+	return nil
+}
+
+// Addr 返回實際監聽的位址，尚未啟動時返回設定的位址
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.httpServer.Addr
+}
+
+// Shutdown 停止接受新連線，並等待進行中的請求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("關閉 HTTP 伺服器失敗: %w", err)
+	}
+
+	s.logger.Info("HTTP API 伺服器已關閉")
+	return nil
+}
+
+// logRequests 記錄每個請求的方法、路徑、狀態碼與耗時
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		s.logger.Debug("HTTP 請求",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start).String(),
+		)
+	})
+}
+
+// allowCORS 允許瀏覽器跨來源讀取唯讀端點
+func allowCORS(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		next(w, r)
+	}
+}
+
+// statusRecorder 記錄回應狀態碼
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap 供 http.ResponseController 存取底層的 ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// writeJSON 以 JSON 格式寫入回應
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("寫入 JSON 回應失敗", "error", err)
+	}
+}
+
+// writeError 以 JSON 格式寫入錯誤回應
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, ErrorResponse{Error: message})
+}
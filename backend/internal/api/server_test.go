@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/lottery"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+const testLotteryAddress = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+
+// testOwnerAddress 測試用的合約 owner
+const testOwnerAddress = "EQAREREREREREREREREREREREREREREREREREREREREREeYT"
+
+func createTestConfig(endpoint string) *config.Config {
+	return &config.Config{
+		Environment:            "test",
+		LogLevel:               "debug",
+		Port:                   "0",
+		TONAPIEndpoint:         endpoint,
+		TONNetwork:             "testnet",
+		LotteryContractAddress: testLotteryAddress,
+		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		DrawInterval:           time.Minute,
+		MaxParticipants:        10,
+		MinParticipants:        2,
+		EntryFeeTON:            0.1,
+		RetryCount:             3,
+		RetryDelay:             100 * time.Millisecond,
+	}
+}
+
+// getMethodHandler 依 get 方法名稱返回結果，nil 表示方法失敗
+type getMethodHandler func(method string, stack tvm.Args) json.RawMessage
+
+// createMockTONServer 創建模擬的 toncenter API
+func createMockTONServer(t *testing.T, handle getMethodHandler) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := ton.APIResponse{Ok: true, Result: json.RawMessage(`{}`)}
+
+		switch {
+		case strings.Contains(r.URL.Path, "sendBoc"):
+			response.Result = json.RawMessage(`{"hash": "0xabc123"}`)
+
+		case strings.Contains(r.URL.Path, "getTransactions"):
+			response.Result = json.RawMessage(`[{"hash": "0xabc123", "success": true}]`)
+
+		case strings.Contains(r.URL.Path, "runGetMethod"):
+			var req struct {
+				Method string   `json:"method"`
+				Stack  tvm.Args `json:"stack"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("解析 runGetMethod 請求失敗: %v", err)
+			}
+
+			if req.Method == "seqno" {
+				response.Result = getMethodResult(tvm.Stack{tvm.Int(1)}, nil)
+			} else if result := handle(req.Method, req.Stack); result != nil {
+				response.Result = result
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+				response = ton.APIResponse{Ok: false, Error: "method failed", Code: 500}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// getMethodResult 以 toncenter runGetMethod 的格式返回堆疊
+func getMethodResult(stack tvm.Stack, err error) json.RawMessage {
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := json.Marshal(map[string]interface{}{"gas_used": 500, "exit_code": 0, "stack": stack})
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// contractInfoResult 返回 getContractInfo 的結果
+func contractInfoResult(info ton.LotteryContractInfo) json.RawMessage {
+	// 合約的 owner 不可為 null，未指定時使用測試用的 owner
+	if info.Owner == "" {
+		info.Owner = testOwnerAddress
+	}
+	return getMethodResult(ton.EncodeLotteryContractInfo(&info))
+}
+
+// createTestServer 創建連接到模擬 toncenter 的 API 伺服器
+func createTestServer(t *testing.T, handle getMethodHandler) *Server {
+	t.Helper()
+	return createTestServerWithConfig(t, handle, func(*config.Config) {})
+}
+
+// createTestServerWithConfig 創建 API 伺服器，並在建立服務前調整設定
+func createTestServerWithConfig(t *testing.T, handle getMethodHandler, configure func(*config.Config)) *Server {
+	t.Helper()
+
+	tonServer := createMockTONServer(t, handle)
+	cfg := createTestConfig(tonServer.URL + "/")
+	configure(cfg)
+	log := logger.New("error")
+
+	service, err := lottery.NewService(cfg, log)
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+
+	return NewServer(cfg, log, service)
+}
+
+// doRequest 對 API handler 發送請求並解析 JSON 回應
+func doRequest(t *testing.T, s *Server, method, path string, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+	if out != nil {
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Fatalf("Content-Type = %q, want application/json", ct)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("解析回應失敗: %v (body: %s)", err, rec.Body.String())
+		}
+	}
+
+	return rec
+}
+
+func TestServerStartShutdown(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage {
+		return contractInfoResult(ton.LotteryContractInfo{CurrentRound: 1})
+	})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/status", s.Addr()))
+	if err != nil {
+		t.Fatalf("請求 /api/status 失敗: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	if _, err := http.Get(fmt.Sprintf("http://%s/api/status", s.Addr())); err == nil {
+		t.Error("關閉後仍可連線")
+	}
+}
+
+func TestServerStartPortInUse(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer s.Shutdown(context.Background())
+
+	// 以相同埠號啟動第二個伺服器
+	_, port, err := net.SplitHostPort(s.Addr())
+	if err != nil {
+		t.Fatalf("解析監聽位址失敗: %v", err)
+	}
+	cfg := *s.config
+	cfg.Port = port
+	other := NewServer(&cfg, logger.New("error"), s.service)
+
+	if err := other.Start(); err == nil {
+		other.Shutdown(context.Background())
+		t.Error("埠號被佔用時應返回錯誤")
+	}
+}
+
+func TestNotFoundAndMethodNotAllowed(t *testing.T) {
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+
+	if rec := doRequest(t, s, http.MethodGet, "/api/unknown", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("未知路徑 status = %d, want 404", rec.Code)
+	}
+
+	if rec := doRequest(t, s, http.MethodPost, "/api/status", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /api/status status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ton-cat-lottery-backend/internal/stream"
+)
+
+const (
+	// sseHeartbeatInterval SSE 註解心跳的間隔，避免代理伺服器關閉閒置連線
+	sseHeartbeatInterval = 15 * time.Second
+
+	// sseRetry 建議瀏覽器斷線後重新連線的等待時間 (毫秒)
+	sseRetry = 3000
+
+	// wsPingInterval WebSocket ping 的間隔，客戶端需在 wsReadTimeout 內回應 pong 或發送其他 frame
+	wsPingInterval = 30 * time.Second
+	wsReadTimeout  = 2 * wsPingInterval
+)
+
+// registerStreamRoutes 註冊即時事件端點
+func (s *Server) registerStreamRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/events", allowCORS(s.handleEventStream))
+	mux.HandleFunc("GET /api/events/ws", s.handleEventWebSocket)
+}
+
+// lastEventID 讀取續傳位置：瀏覽器重新連線時帶上的 Last-Event-ID 標頭，或 last_event_id 查詢參數
+func lastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// handleEventStream 以 Server-Sent Events 推送即時事件
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	lastID, err := lastEventID(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "last_event_id 必須為非負整數")
+		return
+	}
+
+	// 串流連線會持續很久，取消伺服器的寫入逾時
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.logger.Warn("無法取消寫入逾時", "error", err)
+	}
+
+	sub := s.service.Events().Subscribe(lastID)
+	defer sub.Close()
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry); err != nil {
+		return
+	}
+	for _, event := range sub.Backlog {
+		if err := writeSSE(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				// 處理過慢被中斷，瀏覽器會帶著 Last-Event-ID 重新連線
+				return
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE 寫入一個 SSE 事件，data 為完整的事件 JSON
+func writeSSE(w io.Writer, event stream.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// handleEventWebSocket 以 WebSocket 推送即時事件，每個文字訊息為一個事件 JSON
+//
+// 瀏覽器的 WebSocket 無法設定標頭，續傳位置以 last_event_id 查詢參數指定。
+func (s *Server) handleEventWebSocket(w http.ResponseWriter, r *http.Request) {
+	lastID, err := lastEventID(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "last_event_id 必須為非負整數")
+		return
+	}
+
+	conn, err := upgradeWebSocket(w, r)
+	if errors.Is(err, errNotWebSocket) {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Warn("WebSocket 握手失敗", "error", err)
+		return
+	}
+
+	sub := s.service.Events().Subscribe(lastID)
+	defer sub.Close()
+
+	readDone := make(chan struct{})
+	go s.readWebSocket(conn, readDone)
+
+	for _, event := range sub.Backlog {
+		if err := conn.writeJSON(event); err != nil {
+			conn.conn.Close()
+			return
+		}
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-readDone:
+			return
+		case <-s.closing:
+			conn.close(wsCloseGoingAway, "伺服器關閉")
+			return
+		case <-ping.C:
+			if err := conn.writeFrame(wsOpPing, nil); err != nil {
+				conn.conn.Close()
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				conn.close(wsCloseTryAgainLater, "請以 last_event_id 重新連線")
+				return
+			}
+			if err := conn.writeJSON(event); err != nil {
+				conn.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readWebSocket 處理客戶端的控制 frame，連線關閉或逾時後關閉 done
+//
+// 串流是單向的，客戶端的資料 frame 會被忽略。
+func (s *Server) readWebSocket(conn *wsConn, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		opcode, payload, err := conn.readFrame(time.Now().Add(wsReadTimeout))
+		if err != nil {
+			var closeErr *wsCloseError
+			if errors.As(err, &closeErr) {
+				conn.close(closeErr.code, closeErr.reason)
+			} else {
+				conn.conn.Close()
+			}
+			return
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := conn.writeFrame(wsOpPong, payload); err != nil {
+				conn.conn.Close()
+				return
+			}
+		case wsOpClose:
+			conn.close(wsCloseNormal, "")
+			return
+		case wsOpPong, wsOpText, wsOpBinary, wsOpContinuation:
+		default:
+			conn.close(wsCloseProtocolError, "未知的 opcode")
+			return
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/internal/stream"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// sseEvent 解析後的 SSE 事件
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSE 讀取下一個帶有 data 的 SSE 事件，略過 retry 與註解
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("讀取 SSE 失敗: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if e.data != "" {
+				return e
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+func createStreamTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+
+	s := createTestServer(t, func(method string, stack tvm.Args) json.RawMessage { return nil })
+	httpServer := httptest.NewServer(s.Handler())
+	t.Cleanup(httpServer.Close)
+	return s, httpServer
+}
+
+func TestEventStreamSSE(t *testing.T) {
+	s, httpServer := createStreamTestServer(t)
+	events := s.service.Events()
+
+	first, _ := events.Publish(stream.EventNewRoundStarted, stream.NewRoundStarted{Round: 2, TxHash: "hash-1"})
+	second, _ := events.Publish(stream.EventTxConfirmed, stream.TxConfirmed{Hash: "hash-1", Status: "success"})
+
+	req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/api/events", nil)
+	req.Header.Set("Last-Event-ID", fmt.Sprint(first.ID))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("連線失敗: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("SSE 端點應允許跨來源請求")
+	}
+	r := bufio.NewReader(resp.Body)
+
+	// 補送 Last-Event-ID 之後的事件
+	e := readSSE(t, r)
+	if e.id != fmt.Sprint(second.ID) || e.event != stream.EventTxConfirmed {
+		t.Errorf("Expected backlog event %d, got %+v", second.ID, e)
+	}
+	var event stream.Event
+	if err := json.Unmarshal([]byte(e.data), &event); err != nil || event.ID != second.ID || event.Type != stream.EventTxConfirmed {
+		t.Errorf("Unexpected event data: %s (%v)", e.data, err)
+	}
+
+	// 即時事件
+	third, _ := events.Publish(stream.EventRoundFull, map[string]int{"round": 2})
+	if e := readSSE(t, r); e.id != fmt.Sprint(third.ID) || e.event != stream.EventRoundFull {
+		t.Errorf("Expected live event %d, got %+v", third.ID, e)
+	}
+}
+
+func TestEventStreamSSEReset(t *testing.T) {
+	s, httpServer := createStreamTestServer(t)
+	latest, _ := s.service.Events().Publish(stream.EventTxConfirmed, stream.TxConfirmed{Hash: "hash-1"})
+
+	resp, err := http.Get(httpServer.URL + "/api/events?last_event_id=1")
+	if err != nil {
+		t.Fatalf("連線失敗: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if e := readSSE(t, bufio.NewReader(resp.Body)); e.event != stream.EventReset || e.id != fmt.Sprint(latest.ID) {
+		t.Errorf("Expected reset at %d, got %+v", latest.ID, e)
+	}
+}
+
+func TestEventStreamInvalidLastEventID(t *testing.T) {
+	s, _ := createStreamTestServer(t)
+
+	for _, path := range []string{"/api/events?last_event_id=abc", "/api/events/ws?last_event_id=-1"} {
+		if rec := doRequest(t, s, http.MethodGet, path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", path, rec.Code)
+		}
+	}
+
+	// 不是 WebSocket 升級請求
+	if rec := doRequest(t, s, http.MethodGet, "/api/events/ws", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestWebsocketAccept(t *testing.T) {
+	// RFC 6455 第 1.3 節的範例
+	if got := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("websocketAccept() = %s", got)
+	}
+}
+
+// wsTestClient 測試用的最小 WebSocket 客戶端
+type wsTestClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, httpServer *httptest.Server, path string) *wsTestClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(httpServer.URL, "http://"))
+	if err != nil {
+		t.Fatalf("連線失敗: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", path, key)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatalf("讀取握手回應失敗: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		t.Fatalf("握手失敗: %d %v", resp.StatusCode, resp.Header)
+	}
+	return &wsTestClient{conn: conn, r: r}
+}
+
+// writeFrame 發送遮罩過的客戶端 frame
+func (c *wsTestClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("寫入 frame 失敗: %v", err)
+	}
+}
+
+// readFrame 讀取伺服器 frame（未遮罩）
+func (c *wsTestClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		t.Fatalf("讀取 frame 失敗: %v", err)
+	}
+	if header[0]&0x80 == 0 || header[1]&0x80 != 0 {
+		t.Fatalf("伺服器 frame 應為 FIN 且未遮罩: %08b %08b", header[0], header[1])
+	}
+
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatalf("讀取 frame 內容失敗: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestEventStreamWebSocket(t *testing.T) {
+	s, httpServer := createStreamTestServer(t)
+	events := s.service.Events()
+
+	first, _ := events.Publish(stream.EventNewRoundStarted, stream.NewRoundStarted{Round: 2, TxHash: "hash-1"})
+	second, _ := events.Publish(stream.EventTxConfirmed, stream.TxConfirmed{Hash: "hash-1", Status: "success"})
+
+	client := dialWebSocket(t, httpServer, fmt.Sprintf("/api/events/ws?last_event_id=%d", first.ID))
+
+	opcode, payload := client.readFrame(t)
+	var event stream.Event
+	if err := json.Unmarshal(payload, &event); opcode != wsOpText || err != nil || event.ID != second.ID {
+		t.Fatalf("Expected backlog event %d, got opcode %d %s (%v)", second.ID, opcode, payload, err)
+	}
+
+	client.writeFrame(t, wsOpPing, []byte("hi"))
+	if opcode, payload := client.readFrame(t); opcode != wsOpPong || string(payload) != "hi" {
+		t.Errorf("Expected pong, got opcode %d %q", opcode, payload)
+	}
+
+	third, _ := events.Publish(stream.EventWinnerDrawn, map[string]int{"round": 2})
+	opcode, payload = client.readFrame(t)
+	if err := json.Unmarshal(payload, &event); opcode != wsOpText || err != nil || event.ID != third.ID || event.Type != stream.EventWinnerDrawn {
+		t.Errorf("Expected live event %d, got opcode %d %s (%v)", third.ID, opcode, payload, err)
+	}
+
+	client.writeFrame(t, wsOpClose, binary.BigEndian.AppendUint16(nil, wsCloseNormal))
+	if opcode, payload := client.readFrame(t); opcode != wsOpClose || binary.BigEndian.Uint16(payload) != wsCloseNormal {
+		t.Errorf("Expected close frame, got opcode %d %v", opcode, payload)
+	}
+}
+
+func TestEventStreamWebSocketUnmaskedFrame(t *testing.T) {
+	_, httpServer := createStreamTestServer(t)
+	client := dialWebSocket(t, httpServer, "/api/events/ws")
+
+	// 客戶端 frame 未遮罩屬於協定錯誤
+	client.conn.Write([]byte{0x80 | wsOpText, 0x01, 'x'})
+	if opcode, payload := client.readFrame(t); opcode != wsOpClose || binary.BigEndian.Uint16(payload) != wsCloseProtocolError {
+		t.Errorf("Expected protocol error close, got opcode %d %v", opcode, payload)
+	}
+}
+
+func TestShutdownClosesEventStream(t *testing.T) {
+	s, _ := createStreamTestServer(t)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/events", s.Addr()))
+	if err != nil {
+		t.Fatalf("連線失敗: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 開啟中的串流不應讓 Shutdown 等到逾時
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown() took %v with an open stream", elapsed)
+	}
+
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("Expected stream to end cleanly, got %v", err)
+	}
+}
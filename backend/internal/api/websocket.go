@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID RFC 6455 計算 Sec-WebSocket-Accept 使用的固定字串
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket frame opcode
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// WebSocket 關閉代碼
+const (
+	wsCloseNormal        = 1000
+	wsCloseGoingAway     = 1001
+	wsCloseProtocolError = 1002
+	wsCloseTooBig        = 1009
+	wsCloseTryAgainLater = 1013
+)
+
+const (
+	// wsMaxClientFrame 客戶端 frame 的大小上限，事件串流只需要接收控制 frame
+	wsMaxClientFrame = 4 << 10
+
+	// wsWriteTimeout 單一 frame 的寫入逾時
+	wsWriteTimeout = 10 * time.Second
+)
+
+// errNotWebSocket 請求不是有效的 WebSocket 升級請求
+var errNotWebSocket = errors.New("不是有效的 WebSocket 升級請求")
+
+// wsConn 已完成握手的伺服器端 WebSocket 連線，只實作事件推送需要的部分：
+// 發送未分段的文字與控制 frame，讀取遮罩過的客戶端 frame
+type wsConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu sync.Mutex // 序列化寫入
+}
+
+// upgradeWebSocket 驗證升級請求並完成 RFC 6455 握手
+//
+// 返回 errNotWebSocket 時尚未接管連線，呼叫端仍可寫入 HTTP 錯誤回應。
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, errNotWebSocket
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, fmt.Errorf("%w: 只支援版本 13", errNotWebSocket)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, fmt.Errorf("%w: 無效的 Sec-WebSocket-Key", errNotWebSocket)
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("接管連線失敗: %w", err)
+	}
+
+	// 清除 http.Server 設定的讀寫逾時，之後由串流自行管理
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("重設連線逾時失敗: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("寫入握手回應失敗: %w", err)
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("寫入握手回應失敗: %w", err)
+	}
+
+	return &wsConn{conn: conn, r: rw.Reader}, nil
+}
+
+// websocketAccept 計算 Sec-WebSocket-Accept
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContainsToken 標頭是否包含指定的逗號分隔 token（不分大小寫）
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrame 寫入單一未分段、未遮罩的 frame
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode // FIN
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return fmt.Errorf("寫入 WebSocket frame 失敗: %w", err)
+	}
+	return nil
+}
+
+// writeJSON 以文字 frame 發送 JSON
+func (c *wsConn) writeJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("編碼 WebSocket 訊息失敗: %w", err)
+	}
+	return c.writeFrame(wsOpText, data)
+}
+
+// readFrame 讀取一個客戶端 frame 並解除遮罩
+//
+// 客戶端 frame 必須遮罩，超過 wsMaxClientFrame 時返回錯誤。
+func (c *wsConn) readFrame(deadline time.Time) (opcode byte, payload []byte, err error) {
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return 0, nil, err
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		return opcode, nil, &wsCloseError{code: wsCloseProtocolError, reason: "客戶端 frame 必須遮罩"}
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxClientFrame {
+		return opcode, nil, &wsCloseError{code: wsCloseTooBig, reason: "訊息過大"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+// close 發送關閉 frame 後關閉連線
+func (c *wsConn) close(code uint16, reason string) {
+	payload := binary.BigEndian.AppendUint16(nil, code)
+	payload = append(payload, reason...)
+	c.writeFrame(wsOpClose, payload)
+	c.conn.Close()
+}
+
+// wsCloseError 需要以指定代碼關閉連線的協定錯誤
+type wsCloseError struct {
+	code   uint16
+	reason string
+}
+
+func (e *wsCloseError) Error() string {
+	return fmt.Sprintf("WebSocket 協定錯誤 (%d): %s", e.code, e.reason)
+}
@@ -0,0 +1,620 @@
+package emulator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/internal/wallet"
+)
+
+// 交易狀態，與 ton.TxStatusProvider 的返回值相同
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Chain 記憶體中的 TON 鏈，以 Go 實作 CatLottery 與 CatNFT 合約及錢包合約的行為
+//
+// Chain 實作 ton.Chain，可取代 toncenter 客戶端在沒有網路的情況下執行抽獎服務。
+// 外部訊息需由已註冊的錢包簽名，內部訊息依 TON 的規則傳遞：合約執行失敗時狀態不變，
+// 可退回的訊息會將金額退回發送者。gas 與轉發手續費不計算，帳戶餘額只隨訊息金額變動。
+type Chain struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	lt       uint64
+	accounts map[string]*account
+
+	// messages 外部訊息雜湊對應的執行結果
+	messages map[string]string
+}
+
+// account 鏈上帳戶
+type account struct {
+	address  *address.Address
+	balance  int64
+	contract contract // nil 表示帳戶尚未部署
+	txs      []ton.Transaction
+}
+
+// contract 合約的訊息處理與 get 方法
+type contract interface {
+	// receive 處理內部訊息，返回 *ContractError 時合約狀態不變
+	receive(ctx *execContext, msg *message) error
+	// get 執行 get 方法，balance 為帳戶目前的餘額；失敗時返回 *ton.GetMethodError
+	get(method string, args tvm.Args, balance int64) (tvm.Stack, error)
+	// clone 複製合約狀態，用於執行失敗時還原
+	clone() contract
+}
+
+// message 內部訊息
+type message struct {
+	src     *address.Address
+	dest    *address.Address
+	value   int64
+	bounce  bool
+	bounced bool
+	fwdFee  int64
+	body    *cell.Cell
+}
+
+// execContext 合約執行期間的上下文，合約以 send 與 emit 產生出站訊息
+type execContext struct {
+	self    *address.Address
+	balance int64 // 包含入站訊息金額
+	now     int64
+	sends   []*message
+	events  []*cell.Cell
+}
+
+// send 發送內部訊息
+func (c *execContext) send(msg *message) {
+	msg.src = c.self
+	c.sends = append(c.sends, msg)
+}
+
+// emit 發送事件（外部出站訊息）
+func (c *execContext) emit(payload ton.EventPayload) error {
+	body, err := ton.EncodeEvent(payload)
+	if err != nil {
+		return err
+	}
+	c.events = append(c.events, body)
+	return nil
+}
+
+// New 創建空的模擬鏈
+func New() *Chain {
+	return &Chain{
+		now:      time.Now,
+		accounts: make(map[string]*account),
+		messages: make(map[string]string),
+	}
+}
+
+// SetClock 設定合約 now() 使用的時鐘，用於產生可重現的抽獎結果
+func (c *Chain) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Fund 增加地址的餘額 (nanoTON)，地址不存在時創建尚未部署的帳戶
+func (c *Chain) Fund(addr string, amount int64) error {
+	a, err := address.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("無效的地址: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.getAccount(a).balance += amount
+	return nil
+}
+
+// AddWallet 註冊錢包合約
+//
+// 錢包在第一則外部訊息（附帶 StateInit、seqno 0）被接受後部署，之前 seqno 為 0。
+func (c *Chain) AddWallet(addr string, version wallet.Version, walletID uint32, publicKey ed25519.PublicKey) error {
+	if _, err := wallet.ParseVersion(string(version)); err != nil {
+		return err
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("公鑰長度無效: %d bytes", len(publicKey))
+	}
+
+	a, err := address.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("無效的錢包地址: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	acc := c.getAccount(a)
+	if acc.contract != nil {
+		return fmt.Errorf("地址 %s 已部署合約", addr)
+	}
+	acc.contract = &walletContract{version: version, walletID: walletID, publicKey: publicKey}
+	return nil
+}
+
+// DeployLottery 部署 CatLottery 合約，與合約的 init(owner, entryFee, maxParticipants) 相同
+func (c *Chain) DeployLottery(addr, owner string, entryFee int64, maxParticipants int) error {
+	ownerAddr, err := address.Parse(owner)
+	if err != nil {
+		return fmt.Errorf("無效的 owner 地址: %w", err)
+	}
+	return c.deploy(addr, newLotteryContract(ownerAddr, entryFee, maxParticipants))
+}
+
+// DeployNFT 部署 CatNFT 合約，與合約的 init(owner) 相同
+//
+// 抽獎合約以 MintTo 鑄造 NFT，因此 owner 需為抽獎合約地址才能鑄造成功。
+func (c *Chain) DeployNFT(addr, owner string) error {
+	ownerAddr, err := address.Parse(owner)
+	if err != nil {
+		return fmt.Errorf("無效的 owner 地址: %w", err)
+	}
+	return c.deploy(addr, newNFTContract(ownerAddr))
+}
+
+func (c *Chain) deploy(addr string, code contract) error {
+	a, err := address.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("無效的合約地址: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	acc := c.getAccount(a)
+	if acc.contract != nil {
+		return fmt.Errorf("地址 %s 已部署合約", addr)
+	}
+	acc.contract = code
+	return nil
+}
+
+// SendInternal 從 from 發送可退回的內部訊息，返回目的合約的執行結果
+//
+// from 的餘額需足以支付 value；合約執行失敗時返回 *ContractError，金額會退回 from。
+func (c *Chain) SendInternal(from, to string, value int64, body *cell.Cell) error {
+	src, err := address.Parse(from)
+	if err != nil {
+		return fmt.Errorf("無效的發送地址: %w", err)
+	}
+	dest, err := address.Parse(to)
+	if err != nil {
+		return fmt.Errorf("無效的目的地址: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sender := c.getAccount(src)
+	if sender.balance < value {
+		return fmt.Errorf("餘額不足: %d < %d nanoTON", sender.balance, value)
+	}
+	sender.balance -= value
+
+	return c.deliver(&message{src: src, dest: dest, value: value, bounce: true, body: body})
+}
+
+// SendComment 從 from 發送文字評論訊息，例如抽獎合約的 "join" 或 "withdraw"
+func (c *Chain) SendComment(from, to string, value int64, comment string) error {
+	body, err := commentCell(comment)
+	if err != nil {
+		return err
+	}
+	return c.SendInternal(from, to, value, body)
+}
+
+// Join 以 participant 的名義發送 "join" 參加抽獎
+func (c *Chain) Join(lottery, participant string, value int64) error {
+	return c.SendComment(participant, lottery, value, "join")
+}
+
+// === ton.Chain ===
+
+var _ ton.Chain = (*Chain)(nil)
+
+// GetLotteryContractInfo 執行 CatLottery 的 getContractInfo
+func (c *Chain) GetLotteryContractInfo(ctx context.Context, contractAddress string) (*ton.LotteryContractInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lottery, err := c.lottery(contractAddress, "getContractInfo")
+	if err != nil {
+		return nil, err
+	}
+	return lottery.contractInfo(), nil
+}
+
+// GetParticipant 執行 CatLottery 的 getParticipant
+func (c *Chain) GetParticipant(ctx context.Context, contractAddress string, index int) (*ton.Participant, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lottery, err := c.lottery(contractAddress, "getParticipant")
+	if err != nil {
+		return nil, err
+	}
+	return lottery.participant(index), nil
+}
+
+// GetWinner 執行 CatLottery 的 getWinner
+func (c *Chain) GetWinner(ctx context.Context, contractAddress string, round int) (*ton.LotteryResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lottery, err := c.lottery(contractAddress, "getWinner")
+	if err != nil {
+		return nil, err
+	}
+	return lottery.winner(round), nil
+}
+
+// GetContractBalance 執行 CatLottery 的 getBalance
+func (c *Chain) GetContractBalance(ctx context.Context, contractAddress string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.lottery(contractAddress, "getBalance"); err != nil {
+		return 0, err
+	}
+	acc, _ := c.findAccount(contractAddress)
+	return acc.balance, nil
+}
+
+// GetAddressBalance 返回地址的餘額，不存在的地址返回 0
+func (c *Chain) GetAddressBalance(ctx context.Context, addr string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.findAccount(addr)
+	if err != nil || acc == nil {
+		return 0, err
+	}
+	return acc.balance, nil
+}
+
+// GetContractInfo 返回帳戶的餘額與狀態，格式與 toncenter getAddressInformation 相同
+//
+// 已部署的帳戶狀態為 active，不存在或尚未部署的帳戶（包括尚未發送第一則外部訊息的錢包）為 uninitialized。
+func (c *Chain) GetContractInfo(ctx context.Context, addr string) (*ton.ContractInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.findAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ton.ContractInfo{Address: addr, Balance: "0", State: "uninitialized"}
+	if acc == nil {
+		return info, nil
+	}
+	info.Balance = strconv.FormatInt(acc.balance, 10)
+	if w, ok := acc.contract.(*walletContract); acc.contract != nil && (!ok || w.deployed) {
+		info.State = "active"
+	}
+	return info, nil
+}
+
+// GetNFTContractInfo 執行 CatNFT 的 getContractInfo
+func (c *Chain) GetNFTContractInfo(ctx context.Context, contractAddress string) (*ton.NFTContractInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.deployed(contractAddress, "getContractInfo")
+	if err != nil {
+		return nil, err
+	}
+	nft, ok := acc.contract.(*nftContract)
+	if !ok {
+		return nil, &ton.GetMethodError{Method: "getContractInfo", ExitCode: exitCodeNoGetMethod}
+	}
+	return nft.contractInfo(), nil
+}
+
+// GetWalletSeqno 執行錢包的 seqno，尚未部署的錢包返回 0
+func (c *Chain) GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.findAccount(walletAddress)
+	if err != nil {
+		return 0, err
+	}
+	if acc == nil || acc.contract == nil {
+		return 0, nil
+	}
+	w, ok := acc.contract.(*walletContract)
+	if !ok {
+		return 0, &ton.GetMethodError{Method: "seqno", ExitCode: exitCodeNoGetMethod}
+	}
+	return w.seqno, nil
+}
+
+// SendTransaction 處理錢包的外部訊息，返回外部訊息的雜湊 (base64)
+//
+// 錢包拒絕的訊息返回 *ton.MessageRejectedError，exit code 與官方錢包合約相同。
+// 訊息被接受後立即執行，GetTransactionStatus 以錢包發出的內部訊息在目的合約的執行結果判斷成功或失敗。
+func (c *Chain) SendTransaction(ctx context.Context, boc []byte) (string, error) {
+	ext, err := cell.FromBOC(boc)
+	if err != nil {
+		return "", fmt.Errorf("解析 BOC 失敗: %w", err)
+	}
+	hash := base64.StdEncoding.EncodeToString(ext.Hash())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, err := c.applyExternal(ext)
+	if err != nil {
+		return "", fmt.Errorf("發送交易失敗: %w", err)
+	}
+	c.messages[hash] = status
+	return hash, nil
+}
+
+// CheckTransaction 檢查錢包是否接受外部訊息但不執行，返回與 SendTransaction 相同的雜湊
+//
+// 節點廣播外部訊息前先以目前的狀態檢查錢包是否接受，被拒絕時立即返回錯誤；
+// 之後仍需以 SendTransaction 執行，期間狀態改變（例如 seqno 已被使用）時訊息會在執行時被拒絕。
+func (c *Chain) CheckTransaction(ctx context.Context, boc []byte) (string, error) {
+	ext, err := cell.FromBOC(boc)
+	if err != nil {
+		return "", fmt.Errorf("解析 BOC 失敗: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.acceptExternal(ext, c.now().Unix()); err != nil {
+		return "", fmt.Errorf("發送交易失敗: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(ext.Hash()), nil
+}
+
+// GetTransactionStatus 返回外部訊息的執行結果，未知的雜湊返回 pending
+func (c *Chain) GetTransactionStatus(ctx context.Context, hash string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if status, ok := c.messages[hash]; ok {
+		return status, nil
+	}
+	return StatusPending, nil
+}
+
+// GetTransactions 查詢帳戶的交易，參數與返回值與 ton.Client.GetTransactions 相同
+func (c *Chain) GetTransactions(ctx context.Context, addr string, limit int, from ton.TransactionID, toLT uint64) ([]ton.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.findAccount(addr)
+	if err != nil || acc == nil {
+		return []ton.Transaction{}, err
+	}
+
+	txs := []ton.Transaction{}
+	started := from.IsZero()
+	for i := len(acc.txs) - 1; i >= 0; i-- {
+		tx := acc.txs[i]
+		if !started {
+			if tx.ID.LT != from.LT {
+				continue
+			}
+			started = true
+		}
+		if toLT > 0 && tx.ID.LT <= toLT {
+			break
+		}
+		if limit > 0 && len(txs) >= limit {
+			break
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// === 帳戶與訊息傳遞 ===
+
+// getAccount 返回帳戶，不存在時創建尚未部署的帳戶
+func (c *Chain) getAccount(addr *address.Address) *account {
+	key := addr.StringRaw()
+	acc, ok := c.accounts[key]
+	if !ok {
+		a, _ := address.NewAddress(addr.Workchain(), addr.Data())
+		acc = &account{address: a}
+		c.accounts[key] = acc
+	}
+	return acc
+}
+
+// findAccount 返回地址的帳戶，不存在時返回 nil
+func (c *Chain) findAccount(addr string) (*account, error) {
+	a, err := address.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("無效的地址: %w", err)
+	}
+	return c.accounts[a.StringRaw()], nil
+}
+
+// deployed 返回已部署的帳戶，尚未部署時返回與節點相同的 get 方法錯誤
+func (c *Chain) deployed(addr, method string) (*account, error) {
+	acc, err := c.findAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil || acc.contract == nil {
+		return nil, &ton.GetMethodError{Method: method, ExitCode: ton.ExitCodeUninitialized}
+	}
+	return acc, nil
+}
+
+// lottery 返回地址上的抽獎合約
+func (c *Chain) lottery(addr, method string) (*lotteryContract, error) {
+	acc, err := c.deployed(addr, method)
+	if err != nil {
+		return nil, err
+	}
+	lottery, ok := acc.contract.(*lotteryContract)
+	if !ok {
+		return nil, &ton.GetMethodError{Method: method, ExitCode: exitCodeNoGetMethod}
+	}
+	return lottery, nil
+}
+
+// deliver 傳遞內部訊息及其產生的所有訊息，返回第一則訊息在目的合約的執行結果
+func (c *Chain) deliver(msg *message) error {
+	queue := []*message{msg}
+	var result error
+	for i := 0; len(queue) > 0; i++ {
+		next := queue[0]
+		queue = queue[1:]
+
+		out, err := c.process(next)
+		if i == 0 {
+			result = err
+		}
+		queue = append(queue, out...)
+	}
+	return result
+}
+
+// process 在目的帳戶執行一則內部訊息，返回出站的內部訊息
+func (c *Chain) process(msg *message) ([]*message, error) {
+	acc := c.getAccount(msg.dest)
+	now := c.now().Unix()
+	tx := c.newTransaction(acc, now)
+	tx.InMsg = c.messageOf(msg)
+
+	// 信用階段：訊息金額先計入餘額
+	acc.balance += msg.value
+
+	var err error
+	var out []*message
+	var events []ton.Message
+	switch {
+	case msg.bounced:
+		// Tact 合約沒有 bounced 處理函式時忽略退回的訊息，只保留金額
+
+	case acc.contract == nil:
+		if msg.bounce {
+			err = &ContractError{Address: acc.address.String(), ExitCode: exitCodeUninitialized, Message: "帳戶尚未部署"}
+		}
+
+	default:
+		saved := acc.contract.clone()
+		ctx := &execContext{self: acc.address, balance: acc.balance, now: now}
+		err = acc.contract.receive(ctx, msg)
+		if err == nil {
+			err = c.applyActions(acc, ctx)
+		}
+		if err != nil {
+			acc.contract = saved
+		} else {
+			out = ctx.sends
+			for _, event := range ctx.events {
+				events = append(events, c.eventOf(acc.address, event))
+			}
+		}
+	}
+
+	// 執行失敗時退回可退回的訊息
+	if err != nil && msg.bounce {
+		acc.balance -= msg.value
+		out = []*message{{src: acc.address, dest: msg.src, value: msg.value, bounced: true, body: bounceBody(msg.body)}}
+	}
+
+	for _, m := range out {
+		tx.OutMsgs = append(tx.OutMsgs, *c.messageOf(m))
+	}
+	tx.OutMsgs = append(tx.OutMsgs, events...)
+	acc.txs = append(acc.txs, tx)
+	return out, err
+}
+
+// applyActions 執行合約的發送動作，餘額不足時整筆交易失敗
+func (c *Chain) applyActions(acc *account, ctx *execContext) error {
+	var total int64
+	for _, m := range ctx.sends {
+		total += m.value
+	}
+	if total > acc.balance {
+		return &ContractError{Address: acc.address.String(), ExitCode: exitCodeNotEnoughBalance, Message: "餘額不足以發送訊息"}
+	}
+	acc.balance -= total
+	return nil
+}
+
+// newTransaction 為帳戶創建新的交易
+func (c *Chain) newTransaction(acc *account, now int64) ton.Transaction {
+	c.lt += 1000
+	sum := sha256.New()
+	sum.Write([]byte(acc.address.StringRaw()))
+	binary.Write(sum, binary.BigEndian, c.lt)
+
+	return ton.Transaction{
+		ID:      ton.TransactionID{LT: c.lt, Hash: base64.StdEncoding.EncodeToString(sum.Sum(nil))},
+		Utime:   now,
+		Fee:     "0",
+		OutMsgs: []ton.Message{},
+	}
+}
+
+// messageOf 將內部訊息轉換為 toncenter 的格式
+func (c *Chain) messageOf(msg *message) *ton.Message {
+	m := &ton.Message{
+		Destination: msg.dest.String(),
+		Value:       strconv.FormatInt(msg.value, 10),
+		CreatedLT:   strconv.FormatUint(c.lt, 10),
+		MsgData:     ton.MessageData{Type: "msg.dataRaw"},
+	}
+	if msg.src != nil {
+		m.Source = msg.src.String()
+	}
+	if msg.body != nil {
+		m.MsgData.Body = msg.body.ToBOCBase64()
+	}
+	return m
+}
+
+// eventOf 將事件轉換為外部出站訊息
+func (c *Chain) eventOf(src *address.Address, body *cell.Cell) ton.Message {
+	return ton.Message{
+		Source:    src.String(),
+		Value:     "0",
+		CreatedLT: strconv.FormatUint(c.lt, 10),
+		MsgData:   ton.MessageData{Type: "msg.dataRaw", Body: body.ToBOCBase64()},
+	}
+}
+
+// bounceBody 退回訊息的內容：0xffffffff 加上原訊息內容的前 256 位元
+func bounceBody(body *cell.Cell) *cell.Cell {
+	b := cell.BeginCell().StoreUInt(0xffffffff, 32)
+	if body != nil {
+		s := body.BeginParse()
+		n := s.BitsLeft()
+		if n > 256 {
+			n = 256
+		}
+		if data, err := s.LoadSlice(n); err == nil {
+			b.StoreSlice(data, n)
+		}
+	}
+	c, _ := b.EndCell()
+	return c
+}
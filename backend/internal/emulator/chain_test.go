@@ -0,0 +1,283 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/wallet"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+const (
+	testPrivateKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	oneTON         = int64(1000000000)
+)
+
+var (
+	testLottery = testAddress(1)
+	testNFT     = testAddress(2)
+	testAlice   = testAddress(3)
+	testBob     = testAddress(4)
+)
+
+// testAddress 以固定的位元組產生測試地址
+func testAddress(b byte) string {
+	addr, _ := address.NewAddress(0, bytes.Repeat([]byte{b}, 32))
+	return addr.String()
+}
+
+// newTestWallet 創建指定版本的錢包並註冊到模擬鏈
+func newTestWallet(t *testing.T, chain *Chain, version wallet.Version) *wallet.Manager {
+	t.Helper()
+	cfg := &config.Config{TONNetwork: "testnet", WalletPrivateKey: testPrivateKey, WalletVersion: string(version)}
+	w, err := wallet.NewManager(cfg, logger.New("error"))
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	if err := chain.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID(), w.GetPublicKey()); err != nil {
+		t.Fatalf("AddWallet() failed: %v", err)
+	}
+	if err := chain.Fund(w.GetAddress(), 10*oneTON); err != nil {
+		t.Fatalf("Fund() failed: %v", err)
+	}
+	return w
+}
+
+// newTestChain 創建部署了抽獎與 NFT 合約的模擬鏈，owner 為 v4r2 錢包
+func newTestChain(t *testing.T, maxParticipants int) (*Chain, *wallet.Manager) {
+	t.Helper()
+	chain := New()
+	chain.SetClock(func() time.Time { return time.Unix(1700000000, 0) })
+
+	owner := newTestWallet(t, chain, wallet.VersionV4R2)
+	if err := chain.DeployLottery(testLottery, owner.GetAddress(), oneTON/10, maxParticipants); err != nil {
+		t.Fatalf("DeployLottery() failed: %v", err)
+	}
+	if err := chain.DeployNFT(testNFT, testLottery); err != nil {
+		t.Fatalf("DeployNFT() failed: %v", err)
+	}
+	for _, addr := range []string{testAlice, testBob} {
+		if err := chain.Fund(addr, 10*oneTON); err != nil {
+			t.Fatalf("Fund() failed: %v", err)
+		}
+	}
+	return chain, owner
+}
+
+// sendOwner 以 owner 錢包目前的 seqno 發送外部訊息，返回交易狀態
+func sendOwner(t *testing.T, chain *Chain, owner *wallet.Manager, build func(seqno uint32) ([]byte, error)) string {
+	t.Helper()
+	ctx := context.Background()
+	seqno, err := chain.GetWalletSeqno(ctx, owner.GetAddress())
+	if err != nil {
+		t.Fatalf("GetWalletSeqno() failed: %v", err)
+	}
+	boc, err := build(seqno)
+	if err != nil {
+		t.Fatalf("build transaction failed: %v", err)
+	}
+	hash, err := chain.SendTransaction(ctx, boc)
+	if err != nil {
+		t.Fatalf("SendTransaction() failed: %v", err)
+	}
+	status, err := chain.GetTransactionStatus(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetTransactionStatus() failed: %v", err)
+	}
+	return status
+}
+
+func TestGetMethodsOnMissingAccount(t *testing.T) {
+	chain := New()
+	ctx := context.Background()
+
+	if _, err := chain.GetLotteryContractInfo(ctx, testLottery); !ton.IsUninitialized(err) {
+		t.Errorf("Expected uninitialized error, got %v", err)
+	}
+	if _, err := chain.GetNFTContractInfo(ctx, testNFT); !ton.IsUninitialized(err) {
+		t.Errorf("Expected uninitialized error, got %v", err)
+	}
+	if seqno, err := chain.GetWalletSeqno(ctx, testAlice); err != nil || seqno != 0 {
+		t.Errorf("Expected seqno 0 for missing wallet, got %d, %v", seqno, err)
+	}
+	if balance, err := chain.GetAddressBalance(ctx, testAlice); err != nil || balance != 0 {
+		t.Errorf("Expected balance 0 for missing account, got %d, %v", balance, err)
+	}
+	if status, _ := chain.GetTransactionStatus(ctx, "unknown"); status != StatusPending {
+		t.Errorf("Expected pending for unknown hash, got %s", status)
+	}
+
+	// 在錯誤的合約上執行 get 方法
+	if err := chain.DeployNFT(testNFT, testAlice); err != nil {
+		t.Fatal(err)
+	}
+	var getErr *ton.GetMethodError
+	if _, err := chain.GetLotteryContractInfo(ctx, testNFT); !errors.As(err, &getErr) || getErr.ExitCode != exitCodeNoGetMethod {
+		t.Errorf("Expected exit code %d, got %v", exitCodeNoGetMethod, err)
+	}
+	if err := chain.DeployLottery(testNFT, testAlice, 1, 1); err == nil {
+		t.Error("Expected deploying twice to fail")
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	chain, _ := newTestChain(t, 10)
+	ctx := context.Background()
+
+	for _, addr := range []string{testAlice, testBob} {
+		if err := chain.Join(testLottery, addr, oneTON/10); err != nil {
+			t.Fatalf("Join() failed: %v", err)
+		}
+	}
+
+	txs, err := chain.GetTransactions(ctx, testLottery, 10, ton.TransactionID{}, 0)
+	if err != nil {
+		t.Fatalf("GetTransactions() failed: %v", err)
+	}
+	if len(txs) != 2 || txs[0].ID.LT <= txs[1].ID.LT {
+		t.Fatalf("Expected 2 transactions newest first, got %+v", txs)
+	}
+	if txs[0].InMsg.Source != testBob || txs[0].InMsg.Value != "100000000" {
+		t.Errorf("Unexpected in_msg: %+v", txs[0].InMsg)
+	}
+
+	// 事件為外部出站訊息，可由 ton.DecodeEvent 解析
+	if len(txs[0].OutMsgs) != 1 || !txs[0].OutMsgs[0].IsExternalOut() {
+		t.Fatalf("Expected one event, got %+v", txs[0].OutMsgs)
+	}
+	body, err := txs[0].OutMsgs[0].BodyCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := ton.DecodeEvent(body)
+	if err != nil {
+		t.Fatalf("DecodeEvent() failed: %v", err)
+	}
+	joined, ok := event.(*ton.ParticipantJoined)
+	if !ok || joined.Participant != testBob || joined.ParticipantIndex != 1 || joined.Round != 1 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	// 分頁：from 包含在內，toLT 不包含
+	page, _ := chain.GetTransactions(ctx, testLottery, 10, txs[1].ID, 0)
+	if len(page) != 1 || page[0].ID != txs[1].ID {
+		t.Errorf("Expected page starting at older transaction, got %+v", page)
+	}
+	newer, _ := chain.GetTransactions(ctx, testLottery, 10, ton.TransactionID{}, txs[1].ID.LT)
+	if len(newer) != 1 || newer[0].ID != txs[0].ID {
+		t.Errorf("Expected only transactions after toLT, got %+v", newer)
+	}
+	limited, _ := chain.GetTransactions(ctx, testLottery, 1, ton.TransactionID{}, 0)
+	if len(limited) != 1 {
+		t.Errorf("Expected limit 1, got %d", len(limited))
+	}
+}
+
+func TestSendInternalBounce(t *testing.T) {
+	chain, _ := newTestChain(t, 10)
+	ctx := context.Background()
+
+	// 合約失敗時退回金額
+	err := chain.Join(testLottery, testAlice, oneTON/20)
+	if !IsRequireFailed(err, "Insufficient entry fee") {
+		t.Fatalf("Expected insufficient entry fee, got %v", err)
+	}
+	if balance, _ := chain.GetAddressBalance(ctx, testAlice); balance != 10*oneTON {
+		t.Errorf("Expected value to bounce back, balance %d", balance)
+	}
+	if balance, _ := chain.GetAddressBalance(ctx, testLottery); balance != 0 {
+		t.Errorf("Expected lottery balance 0, got %d", balance)
+	}
+
+	// 發送到尚未部署的帳戶同樣退回
+	var contractErr *ContractError
+	err = chain.SendInternal(testAlice, testAddress(9), oneTON, nil)
+	if !errors.As(err, &contractErr) || contractErr.ExitCode != exitCodeUninitialized {
+		t.Errorf("Expected uninitialized bounce, got %v", err)
+	}
+	if balance, _ := chain.GetAddressBalance(ctx, testAlice); balance != 10*oneTON {
+		t.Errorf("Expected value to bounce back, balance %d", balance)
+	}
+
+	if err := chain.SendInternal(testAlice, testLottery, 100*oneTON, nil); err == nil {
+		t.Error("Expected insufficient sender balance to fail")
+	}
+}
+
+func TestCheckTransaction(t *testing.T) {
+	chain, owner := newTestChain(t, 10)
+	ctx := context.Background()
+	startNewRound := func(seqno uint32) []byte {
+		boc, err := owner.CreateStartNewRoundTransaction(testLottery, seqno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return boc
+	}
+
+	// 檢查不改變狀態，返回的雜湊與 SendTransaction 相同
+	boc := startNewRound(0)
+	hash, err := chain.CheckTransaction(ctx, boc)
+	if err != nil {
+		t.Fatalf("CheckTransaction() failed: %v", err)
+	}
+	if seqno, _ := chain.GetWalletSeqno(ctx, owner.GetAddress()); seqno != 0 {
+		t.Errorf("Expected seqno to stay 0, got %d", seqno)
+	}
+	if status, _ := chain.GetTransactionStatus(ctx, hash); status != StatusPending {
+		t.Errorf("Expected checked message to be pending, got %s", status)
+	}
+
+	sent, err := chain.SendTransaction(ctx, boc)
+	if err != nil || sent != hash {
+		t.Fatalf("Expected SendTransaction to return %s, got %s, %v", hash, sent, err)
+	}
+
+	// 已使用的 seqno 不再被接受
+	var rejected *ton.MessageRejectedError
+	if _, err := chain.CheckTransaction(ctx, boc); !errors.As(err, &rejected) || rejected.ExitCode != exitCodesV4.seqno {
+		t.Errorf("Expected seqno mismatch, got %v", err)
+	}
+	if _, err := chain.CheckTransaction(ctx, startNewRound(1)); err != nil {
+		t.Errorf("Expected next seqno to be accepted, got %v", err)
+	}
+}
+
+func TestGetContractInfo(t *testing.T) {
+	chain, owner := newTestChain(t, 10)
+	ctx := context.Background()
+
+	tests := []struct {
+		addr    string
+		balance string
+		state   string
+	}{
+		{testAddress(9), "0", "uninitialized"},
+		{testAlice, "10000000000", "uninitialized"},
+		{owner.GetAddress(), "10000000000", "uninitialized"},
+		{testLottery, "0", "active"},
+	}
+	for _, tt := range tests {
+		info, err := chain.GetContractInfo(ctx, tt.addr)
+		if err != nil {
+			t.Fatalf("GetContractInfo(%s) failed: %v", tt.addr, err)
+		}
+		if info.Balance != tt.balance || info.State != tt.state {
+			t.Errorf("GetContractInfo(%s) = %+v, want balance %s state %s", tt.addr, info, tt.balance, tt.state)
+		}
+	}
+
+	// 錢包在第一則外部訊息被接受後部署
+	sendOwner(t, chain, owner, func(seqno uint32) ([]byte, error) {
+		return owner.CreateStartNewRoundTransaction(testLottery, seqno)
+	})
+	if info, _ := chain.GetContractInfo(ctx, owner.GetAddress()); info.State != "active" {
+		t.Errorf("Expected deployed wallet to be active, got %s", info.State)
+	}
+}
@@ -0,0 +1,53 @@
+package emulator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TVM 與 Tact 的 exit code
+const (
+	// exitCodeStackUnderflow get 方法的參數不足
+	exitCodeStackUnderflow = 2
+	// exitCodeTypeCheck get 方法的參數類型錯誤
+	exitCodeTypeCheck = 7
+	// exitCodeNoGetMethod 合約沒有指定的 get 方法
+	exitCodeNoGetMethod = 11
+	// exitCodeNotEnoughBalance 動作階段餘額不足以發送訊息
+	exitCodeNotEnoughBalance = 37
+	// exitCodeInvalidMessage Tact 合約沒有處理該訊息的 receive
+	exitCodeInvalidMessage = 130
+	// exitCodeUninitialized 可退回的訊息發送到尚未部署的帳戶
+	exitCodeUninitialized = -13
+)
+
+// ContractError 合約執行失敗
+//
+// Tact require 失敗時 Message 為合約中的錯誤訊息，例如 "Insufficient entry fee"；
+// require 的 exit code 由 Tact 編譯器分配，模擬器不重現，此時 ExitCode 為 0。
+type ContractError struct {
+	Address  string // 合約地址
+	ExitCode int    // TVM exit code
+	Message  string // require 的錯誤訊息
+}
+
+func (e *ContractError) Error() string {
+	if e.ExitCode != 0 {
+		return fmt.Sprintf("合約 %s 執行失敗 (exit code %d): %s", e.Address, e.ExitCode, e.Message)
+	}
+	return fmt.Sprintf("合約 %s 執行失敗: %s", e.Address, e.Message)
+}
+
+// IsRequireFailed 錯誤是否為合約 require 以指定訊息失敗
+func IsRequireFailed(err error, message string) bool {
+	var contractErr *ContractError
+	return errors.As(err, &contractErr) && contractErr.ExitCode == 0 && contractErr.Message == message
+}
+
+// require 與 Tact 的 require 相同，條件不成立時以 message 終止執行
+func require(ctx *execContext, ok bool, message string) error {
+	if ok {
+		return nil
+	}
+	return &ContractError{Address: ctx.self.String(), Message: message}
+}
@@ -0,0 +1,43 @@
+package emulator
+
+import (
+	"context"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// RunGetMethod 執行合約的 get 方法，返回與 toncenter runGetMethod 相同格式的堆疊
+//
+// 支援 CatLottery 與 CatNFT 的所有 get 方法，以及錢包的 seqno 與 get_public_key。
+// 失敗時返回 *ton.GetMethodError：帳戶尚未部署為 -13，方法不存在為 11，參數不足為 2，參數類型錯誤為 7。
+func (c *Chain) RunGetMethod(ctx context.Context, addr, method string, args tvm.Args) (tvm.Stack, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, err := c.deployed(addr, method)
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := acc.contract.(*walletContract); ok && !w.deployed {
+		return nil, &ton.GetMethodError{Method: method, ExitCode: ton.ExitCodeUninitialized}
+	}
+	return acc.contract.get(method, args, acc.balance)
+}
+
+// noGetMethod 合約沒有指定的 get 方法
+func noGetMethod(method string) error {
+	return &ton.GetMethodError{Method: method, ExitCode: exitCodeNoGetMethod}
+}
+
+// intArg 讀取 get 方法唯一的整數參數
+func intArg(method string, args tvm.Args) (int64, error) {
+	if len(args) == 0 {
+		return 0, &ton.GetMethodError{Method: method, ExitCode: exitCodeStackUnderflow}
+	}
+	v, err := args[len(args)-1].Int64()
+	if err != nil {
+		return 0, &ton.GetMethodError{Method: method, ExitCode: exitCodeTypeCheck}
+	}
+	return v, nil
+}
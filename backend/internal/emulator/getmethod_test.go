@@ -0,0 +1,135 @@
+package emulator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// runGetMethod 執行 get 方法並返回堆疊的讀取器
+func runGetMethod(t *testing.T, chain *Chain, addr, method string, args tvm.Args) *tvm.Reader {
+	t.Helper()
+	stack, err := chain.RunGetMethod(context.Background(), addr, method, args)
+	if err != nil {
+		t.Fatalf("RunGetMethod(%s) failed: %v", method, err)
+	}
+	return tvm.NewReader(stack)
+}
+
+// assertExitCode 確認 get 方法以指定的 exit code 失敗
+func assertExitCode(t *testing.T, chain *Chain, addr, method string, args tvm.Args, exitCode int) {
+	t.Helper()
+	_, err := chain.RunGetMethod(context.Background(), addr, method, args)
+	var getErr *ton.GetMethodError
+	if !errors.As(err, &getErr) || getErr.ExitCode != exitCode {
+		t.Errorf("Expected %s to fail with exit code %d, got %v", method, exitCode, err)
+	}
+}
+
+func TestRunGetMethodLottery(t *testing.T) {
+	chain, owner := newTestChain(t, 10)
+	setNFTContract(t, chain, owner)
+	for _, addr := range []string{testAlice, testBob} {
+		if err := chain.Join(testLottery, addr, oneTON/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info := runGetMethod(t, chain, testLottery, "getContractInfo", nil).Tuple()
+	if !address.Equal(info.Address().String(), owner.GetAddress()) || info.Int64() != oneTON/10 || info.Int() != 10 ||
+		info.Int() != 1 || !info.Bool() || info.Int() != 2 || info.Address().String() != testNFT {
+		t.Errorf("Unexpected getContractInfo stack: %v", info.Err())
+	}
+
+	participant := runGetMethod(t, chain, testLottery, "getParticipant", tvm.Args{tvm.Int(1)}).OptionalTuple()
+	if participant == nil || participant.Address().String() != testBob || participant.Int64() != oneTON/10 {
+		t.Errorf("Unexpected participant 1")
+	}
+	if r := runGetMethod(t, chain, testLottery, "getParticipant", tvm.Args{tvm.Int(5)}); r.OptionalTuple() != nil || r.Err() != nil {
+		t.Errorf("Expected null for missing participant, got %v", r.Err())
+	}
+
+	status := sendOwner(t, chain, owner, func(seqno uint32) ([]byte, error) {
+		return owner.CreateDrawWinnerTransaction(testLottery, seqno)
+	})
+	if status != StatusSuccess {
+		t.Fatalf("drawWinner failed: %s", status)
+	}
+
+	winner := runGetMethod(t, chain, testLottery, "getWinner", tvm.Args{tvm.Int(1)}).OptionalTuple()
+	if winner == nil || winner.Address().String() != testAlice || winner.Int64() != 1002 {
+		t.Errorf("Unexpected winner of round 1")
+	}
+
+	balance, _ := chain.GetContractBalance(context.Background(), testLottery)
+	if got := runGetMethod(t, chain, testLottery, "getBalance", nil).Int64(); got != balance {
+		t.Errorf("Expected getBalance %d, got %d", balance, got)
+	}
+
+	// 參數不足、參數類型錯誤與不存在的方法
+	assertExitCode(t, chain, testLottery, "getWinner", nil, exitCodeStackUnderflow)
+	empty, _ := cell.BeginCell().EndCell()
+	assertExitCode(t, chain, testLottery, "getWinner", tvm.Args{tvm.Cell(empty)}, exitCodeTypeCheck)
+	assertExitCode(t, chain, testLottery, "getNftOwner", tvm.Args{tvm.Int(1)}, exitCodeNoGetMethod)
+}
+
+func TestRunGetMethodNFT(t *testing.T) {
+	chain := New()
+	chain.Fund(testAlice, oneTON)
+	if err := chain.DeployNFT(testNFT, testAlice); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, mustComment(t, "mint")); err != nil {
+		t.Fatal(err)
+	}
+
+	info := runGetMethod(t, chain, testNFT, "getContractInfo", nil).Tuple()
+	if info.Address().String() != testAlice || info.Int64() != 2 || info.Int64() != 1 {
+		t.Errorf("Unexpected getContractInfo stack: %v", info.Err())
+	}
+
+	if owner := runGetMethod(t, chain, testNFT, "getNftOwner", tvm.Args{tvm.Int(1)}).Address(); !address.Equal(owner.String(), testAlice) {
+		t.Errorf("Expected NFT 1 to belong to alice, got %v", owner)
+	}
+	if owner := runGetMethod(t, chain, testNFT, "getNftOwner", tvm.Args{tvm.Int(2)}).Address(); owner != nil {
+		t.Errorf("Expected null owner for missing NFT, got %s", owner)
+	}
+	if !runGetMethod(t, chain, testNFT, "nftExists", tvm.Args{tvm.Int(1)}).Bool() {
+		t.Error("Expected NFT 1 to exist")
+	}
+	if runGetMethod(t, chain, testNFT, "nftExists", tvm.Args{tvm.Int(2)}).Bool() {
+		t.Error("Expected NFT 2 not to exist")
+	}
+
+	// 貓咪資訊依 nftId % 4 決定，不存在的 NFT 返回空白
+	cat := runGetMethod(t, chain, testNFT, "getCatInfo", tvm.Args{tvm.Int(1)}).Tuple()
+	if cat.Text() != "Siamese Princess" || cat.Text() != "Rare" || cat.Text() != "Siamese" {
+		t.Errorf("Unexpected cat info: %v", cat.Err())
+	}
+	if name := runGetMethod(t, chain, testNFT, "getCatInfo", tvm.Args{tvm.Int(2)}).Tuple().Text(); name != "" {
+		t.Errorf("Expected empty cat info, got %q", name)
+	}
+}
+
+func TestRunGetMethodWallet(t *testing.T) {
+	chain, owner := newTestChain(t, 10)
+
+	// 錢包在第一則外部訊息被接受前尚未部署
+	assertExitCode(t, chain, owner.GetAddress(), "seqno", nil, ton.ExitCodeUninitialized)
+	assertExitCode(t, chain, testAddress(9), "seqno", nil, ton.ExitCodeUninitialized)
+
+	setNFTContract(t, chain, owner)
+	if seqno := runGetMethod(t, chain, owner.GetAddress(), "seqno", nil).Int64(); seqno != 1 {
+		t.Errorf("Expected seqno 1, got %d", seqno)
+	}
+	key := runGetMethod(t, chain, owner.GetAddress(), "get_public_key", nil).BigInt()
+	if key == nil || string(key.FillBytes(make([]byte, 32))) != string(owner.GetPublicKey()) {
+		t.Errorf("Unexpected public key %v", key)
+	}
+	assertExitCode(t, chain, owner.GetAddress(), "getContractInfo", nil, exitCodeNoGetMethod)
+}
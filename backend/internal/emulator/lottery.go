@@ -0,0 +1,337 @@
+package emulator
+
+import (
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+const (
+	// mintValue drawWinner 發送 MintTo 時附帶的金額 (0.05 TON)
+	mintValue int64 = 50000000
+	// withdrawReserve withdraw 保留在合約中的金額 (0.1 TON)
+	withdrawReserve int64 = 100000000
+)
+
+// CatNFT 訊息的 op code
+var (
+	opMintTo      = ton.TactOpcode("MintTo{to:address}")
+	opTransferNFT = ton.TactOpcode("TransferNFT{nftId:int257,newOwner:address}")
+)
+
+// lotteryParticipant 合約中的 Participant
+type lotteryParticipant struct {
+	address   *address.Address
+	amount    int64
+	timestamp int64
+}
+
+// lotteryResult 合約中的 LotteryResult
+type lotteryResult struct {
+	winner    *address.Address
+	nftID     int64
+	timestamp int64
+}
+
+// lotteryContract CatLottery 合約的狀態與訊息處理，與 contracts/CatLottery.tact 一致
+type lotteryContract struct {
+	owner            *address.Address
+	entryFee         int64
+	maxParticipants  int
+	lotteryActive    bool
+	currentRound     int
+	participantCount int
+	participants     map[int]lotteryParticipant
+	nftContract      *address.Address
+	winners          map[int]lotteryResult
+}
+
+func newLotteryContract(owner *address.Address, entryFee int64, maxParticipants int) *lotteryContract {
+	return &lotteryContract{
+		owner:           owner,
+		entryFee:        entryFee,
+		maxParticipants: maxParticipants,
+		lotteryActive:   true,
+		currentRound:    1,
+		participants:    make(map[int]lotteryParticipant),
+		winners:         make(map[int]lotteryResult),
+	}
+}
+
+func (l *lotteryContract) clone() contract {
+	c := *l
+	c.participants = make(map[int]lotteryParticipant, len(l.participants))
+	for k, v := range l.participants {
+		c.participants[k] = v
+	}
+	c.winners = make(map[int]lotteryResult, len(l.winners))
+	for k, v := range l.winners {
+		c.winners[k] = v
+	}
+	return &c
+}
+
+func (l *lotteryContract) receive(ctx *execContext, msg *message) error {
+	op, s, err := parseBody(msg.body)
+	if err != nil {
+		return invalidMessage(ctx)
+	}
+
+	switch op {
+	case ton.OpComment:
+		text, err := s.LoadStringSnake()
+		if err != nil {
+			return invalidMessage(ctx)
+		}
+		switch text {
+		case "join":
+			return l.join(ctx, msg)
+		case "drawWinner":
+			return l.drawWinner(ctx, msg)
+		case "startNewRound":
+			return l.startNewRound(ctx, msg)
+		case "withdraw":
+			return l.withdraw(ctx, msg)
+		}
+
+	case ton.OpSetNFTContract:
+		nftContract, err := s.LoadAddress()
+		if err != nil || nftContract == nil {
+			return invalidMessage(ctx)
+		}
+		if err := require(ctx, msg.src.Equals(l.owner), "Only owner can set NFT contract"); err != nil {
+			return err
+		}
+		l.nftContract = nftContract
+		return nil
+	}
+
+	return invalidMessage(ctx)
+}
+
+// join 處理 receive("join")
+func (l *lotteryContract) join(ctx *execContext, msg *message) error {
+	if err := require(ctx, l.lotteryActive, "Lottery is not active"); err != nil {
+		return err
+	}
+	if err := require(ctx, msg.value >= l.entryFee, "Insufficient entry fee"); err != nil {
+		return err
+	}
+	if err := require(ctx, l.participantCount < l.maxParticipants, "Maximum participants reached"); err != nil {
+		return err
+	}
+	for i := 0; i < l.participantCount; i++ {
+		if p, ok := l.participants[i]; ok {
+			if err := require(ctx, !p.address.Equals(msg.src), "Already participated in this round"); err != nil {
+				return err
+			}
+		}
+	}
+
+	l.participants[l.participantCount] = lotteryParticipant{address: msg.src, amount: msg.value, timestamp: ctx.now}
+	l.participantCount++
+
+	if err := ctx.emit(&ton.ParticipantJoined{
+		Participant:      msg.src.String(),
+		Amount:           msg.value,
+		ParticipantIndex: l.participantCount - 1,
+		Round:            l.currentRound,
+	}); err != nil {
+		return err
+	}
+
+	// 達到最大參與人數時自動停止接受新參與者
+	if l.participantCount >= l.maxParticipants {
+		l.lotteryActive = false
+		return ctx.emit(&ton.LotteryFull{Round: l.currentRound})
+	}
+	return nil
+}
+
+// drawWinner 處理 receive("drawWinner")
+//
+// 隨機數與合約相同：now() + 入站訊息的轉發手續費 + 參與人數，因此可透過 SetClock 重現結果。
+func (l *lotteryContract) drawWinner(ctx *execContext, msg *message) error {
+	if err := require(ctx, msg.src.Equals(l.owner), "Only owner can draw winner"); err != nil {
+		return err
+	}
+	if err := require(ctx, l.participantCount > 0, "No participants in current round"); err != nil {
+		return err
+	}
+
+	seed := abs(ctx.now + msg.fwdFee + int64(l.participantCount))
+	winner, ok := l.participants[int(seed%int64(l.participantCount))]
+	if err := require(ctx, ok, "Failed to get winner"); err != nil {
+		return err
+	}
+
+	nftID := int64(l.currentRound)*1000 + seed%100
+	l.winners[l.currentRound] = lotteryResult{winner: winner.address, nftID: nftID, timestamp: ctx.now}
+
+	// sendNFT
+	if err := require(ctx, l.nftContract != nil, "NFT contract not set"); err != nil {
+		return err
+	}
+	body, err := cell.BeginCell().
+		StoreUInt(uint64(opMintTo), 32).
+		StoreAddress(winner.address).
+		EndCell()
+	if err != nil {
+		return err
+	}
+	ctx.send(&message{dest: l.nftContract, value: mintValue, bounce: true, body: body})
+
+	if err := ctx.emit(&ton.NFTSent{
+		Recipient:   winner.address.String(),
+		NFTId:       nftID,
+		NFTContract: l.nftContract.String(),
+		Timestamp:   ctx.now,
+	}); err != nil {
+		return err
+	}
+
+	if err := ctx.emit(&ton.WinnerDrawn{
+		Winner:           winner.address.String(),
+		NFTId:            nftID,
+		Round:            l.currentRound,
+		ParticipantCount: l.participantCount,
+	}); err != nil {
+		return err
+	}
+
+	// 重置抽獎狀態，清空本輪參與者
+	l.lotteryActive = false
+	for i := 0; i < l.participantCount; i++ {
+		delete(l.participants, i)
+	}
+	l.participantCount = 0
+	return nil
+}
+
+// startNewRound 處理 receive("startNewRound")
+func (l *lotteryContract) startNewRound(ctx *execContext, msg *message) error {
+	if err := require(ctx, msg.src.Equals(l.owner), "Only owner can start new round"); err != nil {
+		return err
+	}
+	if err := require(ctx, !l.lotteryActive, "Current lottery is still active"); err != nil {
+		return err
+	}
+
+	l.lotteryActive = true
+	l.participantCount = 0
+	l.currentRound++
+	return nil
+}
+
+// withdraw 處理 receive("withdraw")
+func (l *lotteryContract) withdraw(ctx *execContext, msg *message) error {
+	if err := require(ctx, msg.src.Equals(l.owner), "Only owner can withdraw"); err != nil {
+		return err
+	}
+	if err := require(ctx, !l.lotteryActive, "Cannot withdraw during active lottery"); err != nil {
+		return err
+	}
+
+	withdrawTo(ctx, msg, l.owner)
+	return nil
+}
+
+func (l *lotteryContract) contractInfo() *ton.LotteryContractInfo {
+	info := &ton.LotteryContractInfo{
+		Owner:            l.owner.String(),
+		EntryFee:         l.entryFee,
+		MaxParticipants:  l.maxParticipants,
+		CurrentRound:     l.currentRound,
+		LotteryActive:    l.lotteryActive,
+		ParticipantCount: l.participantCount,
+	}
+	if l.nftContract != nil {
+		info.NFTContract = l.nftContract.String()
+	}
+	return info
+}
+
+func (l *lotteryContract) get(method string, args tvm.Args, balance int64) (tvm.Stack, error) {
+	switch method {
+	case "getContractInfo":
+		return ton.EncodeLotteryContractInfo(l.contractInfo())
+	case "getParticipant":
+		index, err := intArg(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return ton.EncodeParticipant(l.participant(int(index)))
+	case "getWinner":
+		round, err := intArg(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return ton.EncodeLotteryResult(l.winner(int(round)))
+	case "getBalance":
+		return tvm.Stack{tvm.Int(balance)}, nil
+	}
+	return nil, noGetMethod(method)
+}
+
+// participant 與合約的 getParticipant 相同直接讀取 map，不檢查索引是否小於 participantCount
+func (l *lotteryContract) participant(index int) *ton.Participant {
+	p, ok := l.participants[index]
+	if !ok {
+		return nil
+	}
+	return &ton.Participant{Address: p.address.String(), Amount: p.amount, Timestamp: p.timestamp}
+}
+
+func (l *lotteryContract) winner(round int) *ton.LotteryResult {
+	r, ok := l.winners[round]
+	if !ok {
+		return nil
+	}
+	return &ton.LotteryResult{Winner: r.winner.String(), NFTId: r.nftID, Timestamp: r.timestamp}
+}
+
+// withdrawTo 與合約的 withdraw 相同：保留 0.1 TON，以 SendRemainingValue 將其餘金額發送給 owner
+//
+// SendRemainingValue 會再加上入站訊息剩餘的金額；模擬器不計算 gas，因此為入站訊息的完整金額。
+func withdrawTo(ctx *execContext, msg *message, owner *address.Address) {
+	balance := ctx.balance - withdrawReserve
+	if balance > 0 {
+		ctx.send(&message{dest: owner, value: balance + msg.value, bounce: true})
+	}
+}
+
+// parseBody 讀取訊息內容的 op code，空的內容返回錯誤
+func parseBody(body *cell.Cell) (uint32, *cell.Slice, error) {
+	if body == nil {
+		return 0, nil, fmt.Errorf("訊息沒有內容")
+	}
+	s := body.BeginParse()
+	op, err := s.LoadUInt(32)
+	if err != nil {
+		return 0, nil, err
+	}
+	return uint32(op), s, nil
+}
+
+// invalidMessage 合約沒有處理該訊息的 receive
+func invalidMessage(ctx *execContext) error {
+	return &ContractError{Address: ctx.self.String(), ExitCode: exitCodeInvalidMessage, Message: "無法處理的訊息"}
+}
+
+// commentCell 構建文字評論訊息 (op = 0)
+func commentCell(comment string) (*cell.Cell, error) {
+	return cell.BeginCell().
+		StoreUInt(uint64(ton.OpComment), 32).
+		StoreStringSnake(comment).
+		EndCell()
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,356 @@
+package emulator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/wallet"
+)
+
+// lotteryInfo 讀取抽獎合約狀態
+func lotteryInfo(t *testing.T, chain *Chain) *ton.LotteryContractInfo {
+	t.Helper()
+	info, err := chain.GetLotteryContractInfo(context.Background(), testLottery)
+	if err != nil {
+		t.Fatalf("GetLotteryContractInfo() failed: %v", err)
+	}
+	return info
+}
+
+// lastEvents 解碼抽獎合約最新一筆交易的事件
+func lastEvents(t *testing.T, chain *Chain) []ton.EventPayload {
+	t.Helper()
+	txs, err := chain.GetTransactions(context.Background(), testLottery, 1, ton.TransactionID{}, 0)
+	if err != nil || len(txs) != 1 {
+		t.Fatalf("GetTransactions() failed: %v", err)
+	}
+
+	var events []ton.EventPayload
+	for _, msg := range txs[0].OutMsgs {
+		if !msg.IsExternalOut() {
+			continue
+		}
+		body, err := msg.BodyCell()
+		if err != nil {
+			t.Fatal(err)
+		}
+		event, err := ton.DecodeEvent(body)
+		if err != nil {
+			t.Fatalf("DecodeEvent() failed: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// mustComment 構建文字評論訊息
+func mustComment(t *testing.T, comment string) *cell.Cell {
+	t.Helper()
+	body, err := commentCell(comment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// setNFTContract 以 owner 錢包將抽獎合約的 NFT 合約設為 testNFT
+func setNFTContract(t *testing.T, chain *Chain, owner *wallet.Manager) {
+	t.Helper()
+	status := sendOwner(t, chain, owner, func(seqno uint32) ([]byte, error) {
+		return owner.CreateSetNFTContractTransaction(testLottery, testNFT, seqno)
+	})
+	if status != StatusSuccess {
+		t.Fatalf("Expected setNFTContract to succeed, got %s", status)
+	}
+}
+
+func TestLotteryInit(t *testing.T) {
+	chain, owner := newTestChain(t, 3)
+
+	info := lotteryInfo(t, chain)
+	if !address.Equal(info.Owner, owner.GetAddress()) {
+		t.Errorf("Unexpected owner %s", info.Owner)
+	}
+	if !info.LotteryActive || info.CurrentRound != 1 || info.ParticipantCount != 0 ||
+		info.EntryFee != oneTON/10 || info.MaxParticipants != 3 || info.NFTContract != "" {
+		t.Errorf("Unexpected initial state: %+v", info)
+	}
+}
+
+func TestLotteryJoin(t *testing.T) {
+	chain, _ := newTestChain(t, 2)
+	ctx := context.Background()
+
+	if err := chain.Join(testLottery, testAlice, oneTON/20); !IsRequireFailed(err, "Insufficient entry fee") {
+		t.Errorf("Expected insufficient entry fee, got %v", err)
+	}
+
+	if err := chain.Join(testLottery, testAlice, oneTON/5); err != nil {
+		t.Fatalf("Join() failed: %v", err)
+	}
+	if err := chain.Join(testLottery, testAlice, oneTON/10); !IsRequireFailed(err, "Already participated in this round") {
+		t.Errorf("Expected duplicate join to fail, got %v", err)
+	}
+
+	participant, err := chain.GetParticipant(ctx, testLottery, 0)
+	if err != nil {
+		t.Fatalf("GetParticipant() failed: %v", err)
+	}
+	if participant == nil || participant.Address != testAlice || participant.Amount != oneTON/5 || participant.Timestamp != 1700000000 {
+		t.Errorf("Unexpected participant: %+v", participant)
+	}
+	if p, _ := chain.GetParticipant(ctx, testLottery, 1); p != nil {
+		t.Errorf("Expected no participant at index 1, got %+v", p)
+	}
+
+	// 第二位參與者使抽獎滿員並自動關閉
+	if err := chain.Join(testLottery, testBob, oneTON/10); err != nil {
+		t.Fatalf("Join() failed: %v", err)
+	}
+	events := lastEvents(t, chain)
+	if len(events) != 2 {
+		t.Fatalf("Expected ParticipantJoined and LotteryFull, got %+v", events)
+	}
+	if full, ok := events[1].(*ton.LotteryFull); !ok || full.Round != 1 {
+		t.Errorf("Expected LotteryFull for round 1, got %+v", events[1])
+	}
+
+	info := lotteryInfo(t, chain)
+	if info.LotteryActive || info.ParticipantCount != 2 {
+		t.Errorf("Expected full lottery to be inactive, got %+v", info)
+	}
+	if balance, _ := chain.GetContractBalance(ctx, testLottery); balance != oneTON*3/10 {
+		t.Errorf("Expected entry fees to stay in contract, got %d", balance)
+	}
+
+	// 關閉後拒絕參加（先檢查 lotteryActive）
+	chain.Fund(testAddress(5), oneTON)
+	if err := chain.Join(testLottery, testAddress(5), oneTON/10); !IsRequireFailed(err, "Lottery is not active") {
+		t.Errorf("Expected inactive lottery to reject join, got %v", err)
+	}
+}
+
+func TestLotteryMaximumParticipants(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+
+	// maxParticipants 為 0 時抽獎仍為活躍，但不接受任何參與者
+	if err := chain.Join(testLottery, testAlice, oneTON/10); !IsRequireFailed(err, "Maximum participants reached") {
+		t.Errorf("Expected maximum participants reached, got %v", err)
+	}
+}
+
+func TestLotteryDrawWinner(t *testing.T) {
+	chain, owner := newTestChain(t, 10)
+	ctx := context.Background()
+
+	if err := chain.SendInternal(testAlice, testLottery, oneTON/20, mustComment(t, "drawWinner")); !IsRequireFailed(err, "Only owner can draw winner") {
+		t.Errorf("Expected non-owner draw to fail, got %v", err)
+	}
+
+	drawWinner := func(seqno uint32) ([]byte, error) {
+		return owner.CreateDrawWinnerTransaction(testLottery, seqno)
+	}
+	if status := sendOwner(t, chain, owner, drawWinner); status != StatusFailed {
+		t.Errorf("Expected draw without participants to fail, got %s", status)
+	}
+
+	for _, addr := range []string{testAlice, testBob} {
+		if err := chain.Join(testLottery, addr, oneTON/10); err != nil {
+			t.Fatalf("Join() failed: %v", err)
+		}
+	}
+
+	// 未設定 NFT 合約時整筆交易失敗，狀態不變
+	if status := sendOwner(t, chain, owner, drawWinner); status != StatusFailed {
+		t.Errorf("Expected draw without NFT contract to fail, got %s", status)
+	}
+	if winner, _ := chain.GetWinner(ctx, testLottery, 1); winner != nil {
+		t.Errorf("Expected no winner after failed draw, got %+v", winner)
+	}
+	if info := lotteryInfo(t, chain); !info.LotteryActive || info.ParticipantCount != 2 {
+		t.Errorf("Expected state to be unchanged, got %+v", info)
+	}
+
+	setNFTContract(t, chain, owner)
+	if status := sendOwner(t, chain, owner, drawWinner); status != StatusSuccess {
+		t.Fatalf("Expected draw to succeed, got %s", status)
+	}
+
+	// seed = now + fwdFee + participantCount = 1700000002，中獎索引 0，nftId = 1*1000 + 2
+	winner, err := chain.GetWinner(ctx, testLottery, 1)
+	if err != nil {
+		t.Fatalf("GetWinner() failed: %v", err)
+	}
+	if winner == nil || winner.Winner != testAlice || winner.NFTId != 1002 || winner.Timestamp != 1700000000 {
+		t.Errorf("Unexpected winner: %+v", winner)
+	}
+
+	events := lastEvents(t, chain)
+	if len(events) != 2 {
+		t.Fatalf("Expected NFTSent and WinnerDrawn, got %+v", events)
+	}
+	if sent, ok := events[0].(*ton.NFTSent); !ok || sent.Recipient != testAlice || sent.NFTId != 1002 || sent.NFTContract != testNFT {
+		t.Errorf("Unexpected NFTSent: %+v", events[0])
+	}
+	if drawn, ok := events[1].(*ton.WinnerDrawn); !ok || drawn.Winner != testAlice || drawn.Round != 1 || drawn.ParticipantCount != 2 {
+		t.Errorf("Unexpected WinnerDrawn: %+v", events[1])
+	}
+
+	info := lotteryInfo(t, chain)
+	if info.LotteryActive || info.ParticipantCount != 0 || info.CurrentRound != 1 {
+		t.Errorf("Expected lottery to be reset, got %+v", info)
+	}
+	if p, _ := chain.GetParticipant(ctx, testLottery, 0); p != nil {
+		t.Errorf("Expected participants to be cleared, got %+v", p)
+	}
+
+	// NFT 合約收到 MintTo 並鑄造第一個 NFT
+	nft, err := chain.GetNFTContractInfo(ctx, testNFT)
+	if err != nil {
+		t.Fatalf("GetNFTContractInfo() failed: %v", err)
+	}
+	if nft.NFTSupply != 1 || nft.NextNFTId != 2 {
+		t.Errorf("Expected one minted NFT, got %+v", nft)
+	}
+	if balance, _ := chain.GetAddressBalance(ctx, testNFT); balance != mintValue {
+		t.Errorf("Expected NFT contract to receive mint value, got %d", balance)
+	}
+	// 入場費 0.2 + setNFTContract 與抽獎訊息各 0.05 - MintTo 0.05
+	if balance, _ := chain.GetContractBalance(ctx, testLottery); balance != oneTON/4 {
+		t.Errorf("Unexpected lottery balance %d", balance)
+	}
+}
+
+func TestLotteryMintBounce(t *testing.T) {
+	chain := New()
+	owner := newTestWallet(t, chain, wallet.VersionV4R2)
+	if err := chain.DeployLottery(testLottery, owner.GetAddress(), oneTON/10, 10); err != nil {
+		t.Fatal(err)
+	}
+	// NFT 合約的 owner 不是抽獎合約，MintTo 失敗並退回
+	if err := chain.DeployNFT(testNFT, owner.GetAddress()); err != nil {
+		t.Fatal(err)
+	}
+	chain.Fund(testAlice, oneTON)
+	if err := chain.Join(testLottery, testAlice, oneTON/10); err != nil {
+		t.Fatal(err)
+	}
+	setNFTContract(t, chain, owner)
+
+	status := sendOwner(t, chain, owner, func(seqno uint32) ([]byte, error) {
+		return owner.CreateDrawWinnerTransaction(testLottery, seqno)
+	})
+	if status != StatusSuccess {
+		t.Fatalf("Expected draw to succeed even if minting fails, got %s", status)
+	}
+
+	ctx := context.Background()
+	if winner, _ := chain.GetWinner(ctx, testLottery, 1); winner == nil || winner.Winner != testAlice {
+		t.Errorf("Expected winner to be recorded, got %+v", winner)
+	}
+	if nft, _ := chain.GetNFTContractInfo(ctx, testNFT); nft.NFTSupply != 0 {
+		t.Errorf("Expected no NFT to be minted, got %+v", nft)
+	}
+	// 入場費 0.1 + setNFTContract 與抽獎訊息各 0.05，MintTo 的 0.05 被退回
+	if balance, _ := chain.GetContractBalance(ctx, testLottery); balance != oneTON/5 {
+		t.Errorf("Expected mint value to bounce back, balance %d", balance)
+	}
+}
+
+func TestLotteryStartNewRound(t *testing.T) {
+	chain, owner := newTestChain(t, 1)
+	startNewRound := func(seqno uint32) ([]byte, error) {
+		return owner.CreateStartNewRoundTransaction(testLottery, seqno)
+	}
+
+	if err := chain.SendInternal(testAlice, testLottery, oneTON/20, mustComment(t, "startNewRound")); !IsRequireFailed(err, "Only owner can start new round") {
+		t.Errorf("Expected non-owner to fail, got %v", err)
+	}
+	if status := sendOwner(t, chain, owner, startNewRound); status != StatusFailed {
+		t.Errorf("Expected start during active lottery to fail, got %s", status)
+	}
+
+	// 滿員自動關閉後可以直接開始新輪次，未抽獎的參與者仍保留在 map 中
+	if err := chain.Join(testLottery, testAlice, oneTON/10); err != nil {
+		t.Fatal(err)
+	}
+	if status := sendOwner(t, chain, owner, startNewRound); status != StatusSuccess {
+		t.Fatalf("Expected start new round to succeed, got %s", status)
+	}
+
+	info := lotteryInfo(t, chain)
+	if !info.LotteryActive || info.CurrentRound != 2 || info.ParticipantCount != 0 {
+		t.Errorf("Unexpected state after new round: %+v", info)
+	}
+	if p, _ := chain.GetParticipant(context.Background(), testLottery, 0); p == nil || p.Address != testAlice {
+		t.Errorf("Expected stale participant to remain readable, got %+v", p)
+	}
+
+	// 新輪次可以再次參加
+	if err := chain.Join(testLottery, testAlice, oneTON/10); err != nil {
+		t.Errorf("Expected join in new round to succeed, got %v", err)
+	}
+}
+
+func TestLotteryWithdraw(t *testing.T) {
+	chain, owner := newTestChain(t, 1)
+	ctx := context.Background()
+	withdraw := func(seqno uint32) ([]byte, error) {
+		return owner.CreateWithdrawTransaction(testLottery, seqno)
+	}
+
+	if err := chain.SendInternal(testAlice, testLottery, oneTON/20, mustComment(t, "withdraw")); !IsRequireFailed(err, "Only owner can withdraw") {
+		t.Errorf("Expected non-owner to fail, got %v", err)
+	}
+	if status := sendOwner(t, chain, owner, withdraw); status != StatusFailed {
+		t.Errorf("Expected withdraw during active lottery to fail, got %s", status)
+	}
+
+	if err := chain.Join(testLottery, testAlice, oneTON); err != nil {
+		t.Fatal(err)
+	}
+	walletBefore, _ := chain.GetAddressBalance(ctx, owner.GetAddress())
+	if status := sendOwner(t, chain, owner, withdraw); status != StatusSuccess {
+		t.Fatalf("Expected withdraw to succeed, got %s", status)
+	}
+
+	// 合約餘額 1 + 0.05，發送 (1.05 - 0.1) + 0.05，剩餘 0.05
+	if balance, _ := chain.GetContractBalance(ctx, testLottery); balance != mintValue {
+		t.Errorf("Unexpected lottery balance after withdraw: %d", balance)
+	}
+	walletAfter, _ := chain.GetAddressBalance(ctx, owner.GetAddress())
+	if got := walletAfter - walletBefore; got != oneTON-mintValue {
+		t.Errorf("Expected wallet to gain %d, got %d", oneTON-mintValue, got)
+	}
+}
+
+func TestLotterySetNFTContract(t *testing.T) {
+	chain, owner := newTestChain(t, 1)
+
+	body, err := cell.BeginCell().
+		StoreUInt(uint64(ton.OpSetNFTContract), 32).
+		StoreAddress(address.MustParse(testNFT)).
+		EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendInternal(testAlice, testLottery, oneTON/20, body); !IsRequireFailed(err, "Only owner can set NFT contract") {
+		t.Errorf("Expected non-owner to fail, got %v", err)
+	}
+
+	setNFTContract(t, chain, owner)
+	if info := lotteryInfo(t, chain); info.NFTContract != testNFT {
+		t.Errorf("Expected NFT contract %s, got %s", testNFT, info.NFTContract)
+	}
+
+	// 未知的文字訊息
+	var contractErr *ContractError
+	err = chain.SendInternal(testAlice, testLottery, oneTON/20, mustComment(t, "hello"))
+	if !errors.As(err, &contractErr) || contractErr.ExitCode != exitCodeInvalidMessage {
+		t.Errorf("Expected invalid message, got %v", err)
+	}
+}
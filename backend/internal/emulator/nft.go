@@ -0,0 +1,198 @@
+package emulator
+
+import (
+	"math/big"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// CatNFT 的事件
+var (
+	opNFTMinted      = ton.TactOpcode("NFTMinted{nftId:int257,owner:address,timestamp:int257}")
+	opNFTTransferred = ton.TactOpcode("NFTTransferred{nftId:int257,from:address,to:address,timestamp:int257}")
+)
+
+// catInfos getCatInfo 依 nftId % 4 返回的貓咪資訊
+var catInfos = [4]ton.CatInfo{
+	{Name: "Orange Tabby", Rarity: "Common", CatType: "Tabby"},
+	{Name: "Siamese Princess", Rarity: "Rare", CatType: "Siamese"},
+	{Name: "Maine Coon King", Rarity: "Epic", CatType: "Maine Coon"},
+	{Name: "Cosmic Cat", Rarity: "Legendary", CatType: "Cosmic"},
+}
+
+// nftContract CatNFT 合約的狀態與訊息處理，與 contracts/CatNFT.tact 一致
+type nftContract struct {
+	owner     *address.Address
+	nextNftID int64
+	nftSupply int64
+	nftOwners map[int64]*address.Address
+}
+
+func newNFTContract(owner *address.Address) *nftContract {
+	return &nftContract{
+		owner:     owner,
+		nextNftID: 1,
+		nftOwners: make(map[int64]*address.Address),
+	}
+}
+
+func (n *nftContract) clone() contract {
+	c := *n
+	c.nftOwners = make(map[int64]*address.Address, len(n.nftOwners))
+	for k, v := range n.nftOwners {
+		c.nftOwners[k] = v
+	}
+	return &c
+}
+
+func (n *nftContract) receive(ctx *execContext, msg *message) error {
+	op, s, err := parseBody(msg.body)
+	if err != nil {
+		return invalidMessage(ctx)
+	}
+
+	switch op {
+	case ton.OpComment:
+		text, err := s.LoadStringSnake()
+		if err != nil {
+			return invalidMessage(ctx)
+		}
+		switch text {
+		case "mint":
+			if err := require(ctx, msg.src.Equals(n.owner), "Only owner can mint"); err != nil {
+				return err
+			}
+			return n.mint(ctx, msg.src)
+		case "withdraw":
+			if err := require(ctx, msg.src.Equals(n.owner), "Only owner can withdraw"); err != nil {
+				return err
+			}
+			withdrawTo(ctx, msg, n.owner)
+			return nil
+		}
+
+	case opMintTo:
+		to, err := s.LoadAddress()
+		if err != nil || to == nil {
+			return invalidMessage(ctx)
+		}
+		if err := require(ctx, msg.src.Equals(n.owner), "Only owner can mint"); err != nil {
+			return err
+		}
+		return n.mint(ctx, to)
+
+	case opTransferNFT:
+		nftID, err := s.LoadBigInt(257)
+		if err != nil || !nftID.IsInt64() {
+			return invalidMessage(ctx)
+		}
+		newOwner, err := s.LoadAddress()
+		if err != nil || newOwner == nil {
+			return invalidMessage(ctx)
+		}
+		return n.transfer(ctx, msg, nftID.Int64(), newOwner)
+	}
+
+	return invalidMessage(ctx)
+}
+
+// mint 鑄造下一個 NFT 給 to
+func (n *nftContract) mint(ctx *execContext, to *address.Address) error {
+	nftID := n.nextNftID
+	n.nftOwners[nftID] = to
+	n.nextNftID++
+	n.nftSupply++
+
+	event, err := cell.BeginCell().
+		StoreUInt(uint64(opNFTMinted), 32).
+		StoreBigInt(big.NewInt(nftID), 257).
+		StoreAddress(to).
+		StoreBigInt(big.NewInt(ctx.now), 257).
+		EndCell()
+	if err != nil {
+		return err
+	}
+	ctx.events = append(ctx.events, event)
+	return nil
+}
+
+// transfer 處理 receive(msg: TransferNFT)
+func (n *nftContract) transfer(ctx *execContext, msg *message, nftID int64, newOwner *address.Address) error {
+	current, ok := n.nftOwners[nftID]
+	if err := require(ctx, ok, "NFT does not exist"); err != nil {
+		return err
+	}
+	if err := require(ctx, msg.src.Equals(current), "Only NFT owner can transfer"); err != nil {
+		return err
+	}
+
+	n.nftOwners[nftID] = newOwner
+
+	// 欄位超過一個 Cell，timestamp 存放在 ref 中
+	next, err := cell.BeginCell().StoreBigInt(big.NewInt(ctx.now), 257).EndCell()
+	if err != nil {
+		return err
+	}
+	event, err := cell.BeginCell().
+		StoreUInt(uint64(opNFTTransferred), 32).
+		StoreBigInt(big.NewInt(nftID), 257).
+		StoreAddress(current).
+		StoreAddress(newOwner).
+		StoreRef(next).
+		EndCell()
+	if err != nil {
+		return err
+	}
+	ctx.events = append(ctx.events, event)
+	return nil
+}
+
+func (n *nftContract) contractInfo() *ton.NFTContractInfo {
+	return &ton.NFTContractInfo{
+		Owner:     n.owner.String(),
+		NextNFTId: n.nextNftID,
+		NFTSupply: n.nftSupply,
+	}
+}
+
+func (n *nftContract) get(method string, args tvm.Args, balance int64) (tvm.Stack, error) {
+	switch method {
+	case "getContractInfo":
+		return ton.EncodeNFTContractInfo(n.contractInfo())
+	case "getNftOwner":
+		nftID, err := intArg(method, args)
+		if err != nil {
+			return nil, err
+		}
+		if owner, ok := n.nftOwners[nftID]; ok {
+			return tvm.Stack{tvm.Address(owner)}, nil
+		}
+		return tvm.Stack{tvm.Null()}, nil
+	case "nftExists":
+		nftID, err := intArg(method, args)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := n.nftOwners[nftID]
+		return tvm.Stack{tvm.Bool(ok)}, nil
+	case "getCatInfo":
+		nftID, err := intArg(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return ton.EncodeCatInfo(n.catInfo(nftID)), nil
+	}
+	return nil, noGetMethod(method)
+}
+
+// catInfo 與合約的 getCatInfo 相同，不存在的 NFT 返回空白資訊
+func (n *nftContract) catInfo(nftID int64) *ton.CatInfo {
+	if _, ok := n.nftOwners[nftID]; !ok {
+		return &ton.CatInfo{}
+	}
+	info := catInfos[abs(nftID%4)]
+	return &info
+}
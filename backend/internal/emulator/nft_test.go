@@ -0,0 +1,115 @@
+package emulator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// nftState 讀取 NFT 合約的狀態
+func nftState(t *testing.T, chain *Chain) *nftContract {
+	t.Helper()
+	acc, err := chain.findAccount(testNFT)
+	if err != nil || acc == nil {
+		t.Fatalf("NFT contract not found: %v", err)
+	}
+	return acc.contract.(*nftContract)
+}
+
+func transferBody(t *testing.T, nftID int64, newOwner string) *cell.Cell {
+	t.Helper()
+	body, err := cell.BeginCell().
+		StoreUInt(uint64(opTransferNFT), 32).
+		StoreBigInt(big.NewInt(nftID), 257).
+		StoreAddress(address.MustParse(newOwner)).
+		EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestNFTMint(t *testing.T) {
+	chain := New()
+	chain.Fund(testAlice, oneTON)
+	chain.Fund(testBob, oneTON)
+	if err := chain.DeployNFT(testNFT, testAlice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.SendInternal(testBob, testNFT, oneTON/20, mustComment(t, "mint")); !IsRequireFailed(err, "Only owner can mint") {
+		t.Errorf("Expected non-owner mint to fail, got %v", err)
+	}
+
+	// "mint" 鑄造給 owner，MintTo 鑄造給指定地址
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, mustComment(t, "mint")); err != nil {
+		t.Fatalf("mint failed: %v", err)
+	}
+	mintTo, _ := cell.BeginCell().StoreUInt(uint64(opMintTo), 32).StoreAddress(address.MustParse(testBob)).EndCell()
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, mintTo); err != nil {
+		t.Fatalf("MintTo failed: %v", err)
+	}
+
+	info, err := chain.GetNFTContractInfo(context.Background(), testNFT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.NextNFTId != 3 || info.NFTSupply != 2 || info.Owner != testAlice {
+		t.Errorf("Unexpected NFT contract info: %+v", info)
+	}
+
+	state := nftState(t, chain)
+	if state.nftOwners[1].String() != testAlice || state.nftOwners[2].String() != testBob {
+		t.Errorf("Unexpected NFT owners: %v", state.nftOwners)
+	}
+}
+
+func TestNFTTransfer(t *testing.T) {
+	chain := New()
+	chain.Fund(testAlice, oneTON)
+	chain.Fund(testBob, oneTON)
+	if err := chain.DeployNFT(testNFT, testAlice); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, mustComment(t, "mint")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, transferBody(t, 7, testBob)); !IsRequireFailed(err, "NFT does not exist") {
+		t.Errorf("Expected transfer of missing NFT to fail, got %v", err)
+	}
+	if err := chain.SendInternal(testBob, testNFT, oneTON/20, transferBody(t, 1, testBob)); !IsRequireFailed(err, "Only NFT owner can transfer") {
+		t.Errorf("Expected non-owner transfer to fail, got %v", err)
+	}
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, transferBody(t, 1, testBob)); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	if owner := nftState(t, chain).nftOwners[1].String(); owner != testBob {
+		t.Errorf("Expected NFT 1 to belong to bob, got %s", owner)
+	}
+}
+
+func TestNFTWithdraw(t *testing.T) {
+	chain := New()
+	chain.Fund(testAlice, oneTON)
+	chain.Fund(testBob, oneTON)
+	if err := chain.DeployNFT(testNFT, testAlice); err != nil {
+		t.Fatal(err)
+	}
+	chain.Fund(testNFT, oneTON)
+
+	if err := chain.SendInternal(testBob, testNFT, oneTON/20, mustComment(t, "withdraw")); !IsRequireFailed(err, "Only owner can withdraw") {
+		t.Errorf("Expected non-owner withdraw to fail, got %v", err)
+	}
+
+	// 發送到尚未部署的 owner 帳戶時被退回，合約餘額不變
+	if err := chain.SendInternal(testAlice, testNFT, oneTON/20, mustComment(t, "withdraw")); err != nil {
+		t.Fatalf("withdraw failed: %v", err)
+	}
+	if balance, _ := chain.GetAddressBalance(context.Background(), testNFT); balance != oneTON+oneTON/20 {
+		t.Errorf("Expected payout to bounce back, NFT balance %d", balance)
+	}
+}
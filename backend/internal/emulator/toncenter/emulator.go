@@ -0,0 +1,86 @@
+package toncenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// 以下端點不屬於 toncenter API，用於在本地開發時操作模擬鏈，例如為地址加值或以其他地址參加抽獎。
+// 回應同樣使用 toncenter 的 {ok, result, error} 格式，且不受延遲與故障注入影響。
+
+// fundRequest POST /emulator/fund 的請求內容
+type fundRequest struct {
+	Address string `json:"address"`
+	Amount  int64  `json:"amount"` // nanoTON
+}
+
+// sendRequest POST /emulator/send 的請求內容
+type sendRequest struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Amount  int64  `json:"amount"`  // nanoTON
+	Comment string `json:"comment"` // 文字評論，例如 "join"
+}
+
+// balanceResult 地址操作後的餘額
+type balanceResult struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+}
+
+// registerEmulatorRoutes 註冊操作模擬鏈的端點
+func (s *Server) registerEmulatorRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /emulator/fund", s.handleFund)
+	mux.HandleFunc("POST /emulator/send", s.handleSend)
+}
+
+// handleFund 增加地址的餘額
+func (s *Server) handleFund(w http.ResponseWriter, r *http.Request) {
+	var req fundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的請求內容: %v", err))
+		return
+	}
+	if req.Amount <= 0 {
+		s.writeError(w, http.StatusBadRequest, "amount 必須大於 0")
+		return
+	}
+	if err := s.chain.Fund(req.Address, req.Amount); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.logger.Info("地址已加值", "address", req.Address, "amount", req.Amount)
+	s.writeBalance(w, r, req.Address)
+}
+
+// handleSend 從 from 發送文字評論的內部訊息，目的合約執行失敗時返回錯誤
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的請求內容: %v", err))
+		return
+	}
+	if req.Amount < 0 {
+		s.writeError(w, http.StatusBadRequest, "amount 不能為負數")
+		return
+	}
+	if err := s.chain.SendComment(req.From, req.To, req.Amount, req.Comment); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.logger.Info("內部訊息已執行", "from", req.From, "to", req.To, "amount", req.Amount, "comment", req.Comment)
+	s.writeBalance(w, r, req.From)
+}
+
+// writeBalance 返回地址目前的餘額
+func (s *Server) writeBalance(w http.ResponseWriter, r *http.Request, addr string) {
+	balance, err := s.chain.GetAddressBalance(r.Context(), addr)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeResult(w, balanceResult{Address: addr, Balance: fmt.Sprint(balance)})
+}
@@ -0,0 +1,77 @@
+package toncenter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+// postEmulator 呼叫操作模擬鏈的端點
+func postEmulator(t *testing.T, node *testNode, path string, body string) (int, ton.APIResponse) {
+	t.Helper()
+	resp, err := http.Post(node.url+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp ton.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, apiResp
+}
+
+func TestEmulatorFund(t *testing.T) {
+	// 操作模擬鏈的端點不受故障注入影響
+	node := newTestNode(t, Options{ErrorRate: 1})
+	carol := testAddress(5)
+
+	status, resp := postEmulator(t, node, "/emulator/fund", `{"address": "`+carol+`", "amount": 2000000000}`)
+	if status != http.StatusOK || !resp.Ok {
+		t.Fatalf("Expected fund to succeed, got %d %+v", status, resp)
+	}
+	var result balanceResult
+	json.Unmarshal(resp.Result, &result)
+	if result.Balance != "2000000000" {
+		t.Errorf("Expected balance 2000000000, got %s", result.Balance)
+	}
+
+	for _, body := range []string{`{"address": "` + carol + `", "amount": 0}`, `{"address": "invalid", "amount": 1}`, `not json`} {
+		if status, resp := postEmulator(t, node, "/emulator/fund", body); status != http.StatusBadRequest || resp.Ok {
+			t.Errorf("Expected %s to fail, got %d %+v", body, status, resp)
+		}
+	}
+}
+
+func TestEmulatorSend(t *testing.T) {
+	node := newTestNode(t, Options{})
+	ctx := context.Background()
+
+	status, resp := postEmulator(t, node, "/emulator/send",
+		`{"from": "`+testAlice+`", "to": "`+testLottery+`", "amount": 100000000, "comment": "join"}`)
+	if status != http.StatusOK || !resp.Ok {
+		t.Fatalf("Expected join to succeed, got %d %+v", status, resp)
+	}
+	if info, _ := node.client.GetLotteryContractInfo(ctx, testLottery); info.ParticipantCount != 1 {
+		t.Errorf("Expected 1 participant, got %+v", info)
+	}
+
+	// 合約 require 失敗時返回錯誤訊息，金額退回
+	status, resp = postEmulator(t, node, "/emulator/send",
+		`{"from": "`+testBob+`", "to": "`+testLottery+`", "amount": 1, "comment": "join"}`)
+	if status != http.StatusBadRequest || !strings.Contains(resp.Error, "Insufficient entry fee") {
+		t.Errorf("Expected insufficient entry fee, got %d %+v", status, resp)
+	}
+	if balance, _ := node.client.GetAddressBalance(ctx, testBob); balance != 10*oneTON {
+		t.Errorf("Expected value to bounce back, balance %d", balance)
+	}
+
+	if status, _ := postEmulator(t, node, "/emulator/send", `{"from": "`+testBob+`", "to": "`+testLottery+`", "amount": -1}`); status != http.StatusBadRequest {
+		t.Errorf("Expected negative amount to fail, got %d", status)
+	}
+}
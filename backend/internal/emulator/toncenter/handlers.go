@@ -0,0 +1,232 @@
+package toncenter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// defaultTransactionLimit toncenter getTransactions 未指定 limit 時返回的筆數
+const defaultTransactionLimit = 10
+
+// apiMethod toncenter API 方法的 HTTP 方法與處理函式
+type apiMethod struct {
+	httpMethod string
+	handle     http.HandlerFunc
+}
+
+// apiMethods 支援的 toncenter API 方法，GET 的參數以 query string 傳遞，POST 以 JSON 傳遞
+func (s *Server) apiMethods() map[string]apiMethod {
+	return map[string]apiMethod{
+		"getAddressInformation": {http.MethodGet, s.handleGetAddressInformation},
+		"getAddressBalance":     {http.MethodGet, s.handleGetAddressBalance},
+		"getTransactions":       {http.MethodGet, s.handleGetTransactions},
+		"runGetMethod":          {http.MethodPost, s.handleRunGetMethod},
+		"sendBoc":               {http.MethodPost, s.handleSendBoc(false)},
+		"sendBocReturnHash":     {http.MethodPost, s.handleSendBoc(true)},
+	}
+}
+
+// handleGetAddressInformation 返回地址的餘額與狀態
+func (s *Server) handleGetAddressInformation(w http.ResponseWriter, r *http.Request) {
+	info, err := s.chain.GetContractInfo(r.Context(), r.URL.Query().Get("address"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeResult(w, info)
+}
+
+// handleGetAddressBalance 以字串返回地址的餘額 (nanoTON)
+func (s *Server) handleGetAddressBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := s.chain.GetAddressBalance(r.Context(), r.URL.Query().Get("address"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeResult(w, strconv.FormatInt(balance, 10))
+}
+
+// messageStatus 以外部訊息雜湊查詢的交易，success 為錢包發出的訊息在目的合約的執行結果
+type messageStatus struct {
+	Hash    string `json:"hash"`
+	Success bool   `json:"success"`
+}
+
+// handleGetTransactions 查詢帳戶的交易；只帶 hash 時查詢外部訊息的執行結果，尚未上鏈時返回空陣列
+func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	addr := query.Get("address")
+
+	if addr == "" {
+		hash := query.Get("hash")
+		if hash == "" {
+			s.writeError(w, http.StatusBadRequest, "address 或 hash 不能為空")
+			return
+		}
+		status, err := s.chain.GetTransactionStatus(r.Context(), hash)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if status == emulator.StatusPending {
+			s.writeResult(w, []messageStatus{})
+			return
+		}
+		s.writeResult(w, []messageStatus{{Hash: hash, Success: status == emulator.StatusSuccess}})
+		return
+	}
+
+	limit := defaultTransactionLimit
+	var from ton.TransactionID
+	var toLT uint64
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的 limit: %s", v))
+			return
+		}
+	}
+	if v := query.Get("lt"); v != "" {
+		if from.LT, err = strconv.ParseUint(v, 10, 64); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的 lt: %s", v))
+			return
+		}
+		from.Hash = query.Get("hash")
+	}
+	if v := query.Get("to_lt"); v != "" {
+		if toLT, err = strconv.ParseUint(v, 10, 64); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的 to_lt: %s", v))
+			return
+		}
+	}
+
+	txs, err := s.chain.GetTransactions(r.Context(), addr, limit, from, toLT)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeResult(w, txs)
+}
+
+// runGetMethodRequest runGetMethod 的請求內容
+type runGetMethodRequest struct {
+	Address string   `json:"address"`
+	Method  string   `json:"method"`
+	Stack   tvm.Args `json:"stack"`
+}
+
+// getMethodResult runGetMethod 的回應，模擬鏈不計算 gas
+type getMethodResult struct {
+	GasUsed  int64     `json:"gas_used"`
+	ExitCode int       `json:"exit_code"`
+	Stack    tvm.Stack `json:"stack"`
+}
+
+// handleRunGetMethod 執行合約的 get 方法，失敗的 exit code 與節點相同以成功的回應返回
+func (s *Server) handleRunGetMethod(w http.ResponseWriter, r *http.Request) {
+	var req runGetMethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的請求內容: %v", err))
+		return
+	}
+
+	if hook := s.options.GetMethodHook; hook != nil {
+		stack, err := hook(req.Method, req.Stack)
+		if err != nil {
+			s.logger.Debug("注入 get 方法故障", "method", req.Method, "error", err)
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if stack != nil {
+			s.writeResult(w, getMethodResult{Stack: stack})
+			return
+		}
+	}
+
+	stack, err := s.chain.RunGetMethod(r.Context(), req.Address, req.Method, req.Stack)
+	var getErr *ton.GetMethodError
+	switch {
+	case errors.As(err, &getErr):
+		s.writeResult(w, getMethodResult{ExitCode: getErr.ExitCode, Stack: tvm.Stack{}})
+	case err != nil:
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.writeResult(w, getMethodResult{Stack: stack})
+	}
+}
+
+// sendBocRequest sendBoc 的請求內容，boc 以 base64 編碼
+type sendBocRequest struct {
+	BOC string `json:"boc"`
+}
+
+// handleSendBoc 發送外部訊息，returnHash 為 true 時返回訊息雜湊（sendBocReturnHash）
+//
+// 錢包拒絕的訊息以 HTTP 500 返回與 liteserver 相同格式的錯誤，包含 exitcode。
+func (s *Server) handleSendBoc(returnHash bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req sendBocRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的請求內容: %v", err))
+			return
+		}
+		boc, err := base64.StdEncoding.DecodeString(req.BOC)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("無效的 BOC: %v", err))
+			return
+		}
+
+		hash, err := s.sendBoc(r.Context(), boc)
+		var rejected *ton.MessageRejectedError
+		switch {
+		case errors.As(err, &rejected):
+			s.logger.Info("外部訊息被拒絕", "exit_code", rejected.ExitCode)
+			s.writeError(w, http.StatusInternalServerError,
+				"LITE_SERVER_UNKNOWN: cannot apply external message to current state : "+rejected.Reason)
+			return
+		case err != nil:
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if returnHash {
+			s.writeResult(w, map[string]string{"@type": "raw.extMessageInfo", "hash": hash})
+			return
+		}
+		s.writeResult(w, map[string]string{"@type": "ok"})
+	}
+}
+
+// sendBoc 檢查錢包是否接受外部訊息，並在設定的延遲後上鏈執行
+//
+// 與節點相同，被拒絕的訊息立即返回錯誤；延遲期間其他訊息改變了錢包狀態時，上鏈時被拒絕的訊息只記錄日誌，
+// 交易狀態保持 pending。
+func (s *Server) sendBoc(ctx context.Context, boc []byte) (string, error) {
+	if s.options.Latency <= 0 {
+		return s.chain.SendTransaction(ctx, boc)
+	}
+
+	hash, err := s.chain.CheckTransaction(ctx, boc)
+	if err != nil {
+		return "", err
+	}
+
+	s.pending.Add(1)
+	time.AfterFunc(s.options.Latency, func() {
+		defer s.pending.Done()
+		if _, err := s.chain.SendTransaction(context.Background(), boc); err != nil {
+			s.logger.Warn("外部訊息上鏈時被拒絕", "hash", hash, "error", err)
+		}
+	})
+	return hash, nil
+}
@@ -0,0 +1,284 @@
+package toncenter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/internal/wallet"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+const (
+	testPrivateKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	oneTON         = int64(1000000000)
+)
+
+var (
+	testLottery = testAddress(1)
+	testNFT     = testAddress(2)
+	testAlice   = testAddress(3)
+	testBob     = testAddress(4)
+)
+
+// testAddress 以固定的位元組產生測試地址
+func testAddress(b byte) string {
+	addr, _ := address.NewAddress(0, bytes.Repeat([]byte{b}, 32))
+	return addr.String()
+}
+
+// testNode 模擬 toncenter 與連線到它的 ton.Client
+type testNode struct {
+	server *Server
+	chain  *emulator.Chain
+	client *ton.Client
+	owner  *wallet.Manager
+	url    string
+}
+
+// newTestNode 啟動部署了抽獎與 NFT 合約的模擬 toncenter，owner 為 v4r2 錢包
+func newTestNode(t *testing.T, options Options) *testNode {
+	t.Helper()
+	log := logger.New("error")
+
+	chain := emulator.New()
+	chain.SetClock(func() time.Time { return time.Unix(1700000000, 0) })
+
+	cfg := &config.Config{TONNetwork: "testnet", WalletPrivateKey: testPrivateKey, WalletVersion: "v4r2"}
+	owner, err := wallet.NewManager(cfg, log)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	if err := chain.AddWallet(owner.GetAddress(), owner.GetVersion(), owner.GetWalletID(), owner.GetPublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{owner.GetAddress(), testAlice, testBob} {
+		if err := chain.Fund(addr, 10*oneTON); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.DeployLottery(testLottery, owner.GetAddress(), oneTON/10, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.DeployNFT(testNFT, testLottery); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(chain, options, log)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	clientCfg := &config.Config{TONAPIEndpoint: httpServer.URL + "/api/v2/", LogLevel: "error"}
+	return &testNode{
+		server: server,
+		chain:  chain,
+		client: ton.NewClient(clientCfg, log),
+		owner:  owner,
+		url:    httpServer.URL,
+	}
+}
+
+// send 以 owner 錢包目前的 seqno 經由 HTTP 發送交易
+func (n *testNode) send(t *testing.T, build func(seqno uint32) ([]byte, error)) string {
+	t.Helper()
+	ctx := context.Background()
+	seqno, err := n.client.GetWalletSeqno(ctx, n.owner.GetAddress())
+	if err != nil {
+		t.Fatalf("GetWalletSeqno() failed: %v", err)
+	}
+	boc, err := build(seqno)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := n.client.SendTransaction(ctx, boc)
+	if err != nil {
+		t.Fatalf("SendTransaction() failed: %v", err)
+	}
+	return hash
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	node := newTestNode(t, Options{})
+	ctx := context.Background()
+	client := node.client
+
+	// 錢包尚未部署時 seqno 為 0，狀態為 uninitialized
+	if seqno, err := client.GetWalletSeqno(ctx, node.owner.GetAddress()); err != nil || seqno != 0 {
+		t.Fatalf("Expected seqno 0, got %d, %v", seqno, err)
+	}
+	if info, err := client.GetContractInfo(ctx, node.owner.GetAddress()); err != nil || info.State != "uninitialized" {
+		t.Errorf("Expected uninitialized wallet, got %+v, %v", info, err)
+	}
+
+	hash := node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateSetNFTContractTransaction(testLottery, testNFT, seqno)
+	})
+	if status, err := client.GetTransactionStatus(ctx, hash); err != nil || status != "success" {
+		t.Fatalf("Expected success, got %s, %v", status, err)
+	}
+	if seqno, _ := client.GetWalletSeqno(ctx, node.owner.GetAddress()); seqno != 1 {
+		t.Errorf("Expected seqno 1, got %d", seqno)
+	}
+
+	for _, addr := range []string{testAlice, testBob} {
+		if err := node.chain.Join(testLottery, addr, oneTON/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := client.GetLotteryContractInfo(ctx, testLottery)
+	if err != nil {
+		t.Fatalf("GetLotteryContractInfo() failed: %v", err)
+	}
+	if info.ParticipantCount != 2 || !info.LotteryActive || !address.Equal(info.NFTContract, testNFT) {
+		t.Errorf("Unexpected contract info: %+v", info)
+	}
+	if p, err := client.GetParticipant(ctx, testLottery, 1); err != nil || p == nil || !p.HasAddress(testBob) {
+		t.Errorf("Unexpected participant: %+v, %v", p, err)
+	}
+
+	// 合約執行失敗的交易狀態為 failed
+	hash = node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateStartNewRoundTransaction(testLottery, seqno)
+	})
+	if status, _ := client.GetTransactionStatus(ctx, hash); status != "failed" {
+		t.Errorf("Expected startNewRound to fail while active, got %s", status)
+	}
+
+	node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateDrawWinnerTransaction(testLottery, seqno)
+	})
+	winner, err := client.GetWinner(ctx, testLottery, 1)
+	if err != nil || !winner.IsWinner(testAlice) || winner.NFTId != 1002 {
+		t.Errorf("Unexpected winner: %+v, %v", winner, err)
+	}
+	if owner, err := client.GetNFTOwner(ctx, testNFT, 1); err != nil || !address.Equal(owner, testAlice) {
+		t.Errorf("Expected NFT 1 to belong to alice, got %s, %v", owner, err)
+	}
+	if cat, err := client.GetCatInfo(ctx, testNFT, 1); err != nil || cat.Rarity != "Rare" {
+		t.Errorf("Unexpected cat info: %+v, %v", cat, err)
+	}
+
+	balance, err := client.GetContractBalance(ctx, testLottery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := client.GetAddressBalance(ctx, testLottery); got != balance {
+		t.Errorf("Expected address balance %d, got %d", balance, got)
+	}
+
+	// 事件可由索引器以 getTransactions 讀取
+	txs, err := client.GetTransactions(ctx, testLottery, 10, ton.TransactionID{}, 0)
+	if err != nil {
+		t.Fatalf("GetTransactions() failed: %v", err)
+	}
+	var events []ton.EventPayload
+	for _, tx := range txs {
+		for _, msg := range tx.OutMsgs {
+			if !msg.IsExternalOut() {
+				continue
+			}
+			body, _ := msg.BodyCell()
+			event, err := ton.DecodeEvent(body)
+			if err != nil {
+				t.Fatalf("DecodeEvent() failed: %v", err)
+			}
+			events = append(events, event)
+		}
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 2 joins, NFTSent and WinnerDrawn, got %d events", len(events))
+	}
+	// 同一筆交易中的事件依 emit 的順序排列
+	if _, ok := events[1].(*ton.WinnerDrawn); !ok {
+		t.Errorf("Expected WinnerDrawn after NFTSent, got %T", events[1])
+	}
+
+	page, err := client.GetTransactions(ctx, testLottery, 10, txs[1].ID, txs[len(txs)-1].ID.LT)
+	if err != nil || len(page) != len(txs)-2 || page[0].ID != txs[1].ID {
+		t.Errorf("Unexpected page: %d transactions, %v", len(page), err)
+	}
+}
+
+func TestSendBocRejected(t *testing.T) {
+	node := newTestNode(t, Options{})
+	ctx := context.Background()
+
+	boc, err := node.owner.CreateDrawWinnerTransaction(testLottery, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 尚未部署的錢包只接受 seqno 0
+	_, err = node.client.SendTransaction(ctx, boc)
+	if !ton.IsMessageRejected(err) {
+		t.Fatalf("Expected rejected message, got %v", err)
+	}
+
+	node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateSetNFTContractTransaction(testLottery, testNFT, seqno)
+	})
+	_, err = node.client.SendTransaction(ctx, boc)
+	if !ton.IsSeqnoMismatch(err) {
+		t.Errorf("Expected seqno mismatch, got %v", err)
+	}
+	if ton.IsRetryable(err) {
+		t.Error("Expected rejected message not to be retryable")
+	}
+}
+
+func TestGetMethodErrors(t *testing.T) {
+	node := newTestNode(t, Options{})
+	ctx := context.Background()
+
+	if _, err := node.client.GetLotteryContractInfo(ctx, testAddress(9)); !ton.IsUninitialized(err) {
+		t.Errorf("Expected uninitialized, got %v", err)
+	}
+	if _, err := node.client.RunGetMethod(ctx, testLottery, "getCatInfo", tvm.Args{tvm.Int(1)}); err == nil {
+		t.Error("Expected missing get method to fail")
+	}
+	if _, err := node.client.GetLotteryContractInfo(ctx, "invalid"); err == nil || ton.IsRetryable(err) {
+		t.Errorf("Expected permanent error for invalid address, got %v", err)
+	}
+}
+
+func TestGetTransactionsParams(t *testing.T) {
+	node := newTestNode(t, Options{})
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"", http.StatusBadRequest},
+		{"?address=" + testLottery + "&limit=0", http.StatusBadRequest},
+		{"?address=" + testLottery + "&lt=abc", http.StatusBadRequest},
+		{"?address=" + testLottery + "&to_lt=-1", http.StatusBadRequest},
+		{"?address=" + testLottery, http.StatusOK},
+		{"?hash=unknown", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(node.url + "/api/v2/getTransactions" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body ton.APIResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status || body.Ok != (tt.status == http.StatusOK) {
+			t.Errorf("getTransactions%s: status %d ok %v, want %d", tt.query, resp.StatusCode, body.Ok, tt.status)
+		}
+		if tt.status == http.StatusOK && string(body.Result) != "[]\n" && string(body.Result) != "[]" {
+			t.Errorf("getTransactions%s: expected empty result, got %s", tt.query, body.Result)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package toncenter 以記憶體模擬鏈實作 toncenter HTTP API 的子集，供本地開發與測試使用
+//
+// Server 接受 ton.Client 發出的請求：runGetMethod、sendBoc、sendBocReturnHash、getTransactions、
+// getAddressInformation 與 getAddressBalance。路徑前綴不限，TON_API_ENDPOINT 設為
+// http://<host>/api/v2/ 即可。外部訊息為錢包簽名的真實 BOC，由 emulator.Chain 驗證並執行。
+package toncenter
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// Options 模擬節點的延遲與故障注入設定，零值表示立即回應且不注入故障
+type Options struct {
+	// Latency 外部訊息被接受到上鏈執行之間的延遲，期間 seqno 不變且交易狀態為 pending
+	Latency time.Duration
+	// ResponseDelay 每個 API 請求回應前的延遲
+	ResponseDelay time.Duration
+	// ErrorRate 以 HTTP 500 回應的 API 請求比例 (0 ~ 1)
+	ErrorRate float64
+	// RateLimitRate 以 HTTP 429 回應的 API 請求比例 (0 ~ 1)
+	RateLimitRate float64
+	// RetryAfter 429 回應的 Retry-After，0 表示不帶此標頭
+	RetryAfter time.Duration
+	// Seed 故障注入的亂數種子，0 表示以目前時間為種子
+	Seed int64
+	// GetMethodHook 在執行 runGetMethod 前以方法名稱與參數呼叫，用於注入單一 get 方法的故障，
+	// 例如讓特定索引的 getParticipant 失敗。返回錯誤時以 HTTP 500 回應；返回非 nil 的堆疊時
+	// 以其取代合約的結果。hook 會被並發呼叫，也可以在查詢之間改變模擬鏈的狀態
+	GetMethodHook func(method string, args tvm.Args) (tvm.Stack, error)
+}
+
+// Server 以 emulator.Chain 回應 toncenter API 的 HTTP handler
+type Server struct {
+	chain   *emulator.Chain
+	options Options
+	logger  *logger.Logger
+	mux     *http.ServeMux
+	methods map[string]apiMethod
+
+	// randMu 保護 rand，故障注入在多個請求間共用亂數來源
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	// pending 延遲上鏈中的外部訊息
+	pending sync.WaitGroup
+}
+
+// NewServer 創建以 chain 為狀態的模擬 toncenter
+func NewServer(chain *emulator.Chain, options Options, log *logger.Logger) *Server {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	s := &Server{
+		chain:   chain,
+		options: options,
+		logger:  log.WithGroup("toncenter"),
+		mux:     http.NewServeMux(),
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+	s.methods = s.apiMethods()
+	s.registerEmulatorRoutes(s.mux)
+	s.mux.HandleFunc("/", s.handleAPI)
+	return s
+}
+
+// Chain 返回模擬節點使用的模擬鏈
+func (s *Server) Chain() *emulator.Chain {
+	return s.chain
+}
+
+// ServeHTTP 實作 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Wait 等待所有延遲中的外部訊息上鏈
+func (s *Server) Wait() {
+	s.pending.Wait()
+}
+
+// handleAPI 依路徑的最後一段分派 toncenter API 方法
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	method := path.Base(r.URL.Path)
+	handler, ok := s.methods[method]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if r.Method != handler.httpMethod {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if s.options.ResponseDelay > 0 {
+		select {
+		case <-time.After(s.options.ResponseDelay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if s.injectFailure(w) {
+		s.logger.Debug("注入故障", "method", method)
+		return
+	}
+
+	handler.handle(w, r)
+}
+
+// injectFailure 依設定的比例以 500 或 429 回應，返回是否已回應
+func (s *Server) injectFailure(w http.ResponseWriter) bool {
+	s.randMu.Lock()
+	p := s.rand.Float64()
+	s.randMu.Unlock()
+
+	switch {
+	case p < s.options.RateLimitRate:
+		if s.options.RetryAfter > 0 {
+			seconds := int((s.options.RetryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		s.writeError(w, http.StatusTooManyRequests, "Ratelimit exceed")
+		return true
+	case p < s.options.RateLimitRate+s.options.ErrorRate:
+		s.writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return true
+	}
+	return false
+}
+
+// writeResult 以 toncenter 的格式寫入成功的回應
+func (s *Server) writeResult(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, ton.APIResponse{Ok: true, Result: data})
+}
+
+// writeError 以 toncenter 的格式寫入錯誤回應，code 與 HTTP 狀態碼相同
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, ton.APIResponse{Ok: false, Error: message, Code: status})
+}
+
+// writeJSON 以 JSON 格式寫入回應
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("寫入 JSON 回應失敗", "error", err)
+	}
+}
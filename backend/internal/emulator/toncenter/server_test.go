@@ -0,0 +1,189 @@
+package toncenter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+func TestLatency(t *testing.T) {
+	node := newTestNode(t, Options{Latency: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	hash := node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateSetNFTContractTransaction(testLottery, testNFT, seqno)
+	})
+
+	// 上鏈前交易狀態為 pending，seqno 不變
+	if status, _ := node.client.GetTransactionStatus(ctx, hash); status != "pending" {
+		t.Errorf("Expected pending before inclusion, got %s", status)
+	}
+	if seqno, _ := node.client.GetWalletSeqno(ctx, node.owner.GetAddress()); seqno != 0 {
+		t.Errorf("Expected seqno 0 before inclusion, got %d", seqno)
+	}
+
+	// 被拒絕的訊息仍立即返回錯誤
+	boc, _ := node.owner.CreateDrawWinnerTransaction(testLottery, 5)
+	if _, err := node.client.SendTransaction(ctx, boc); !ton.IsMessageRejected(err) {
+		t.Errorf("Expected immediate rejection, got %v", err)
+	}
+
+	node.server.Wait()
+	if status, _ := node.client.GetTransactionStatus(ctx, hash); status != "success" {
+		t.Errorf("Expected success after inclusion, got %s", status)
+	}
+	if seqno, _ := node.client.GetWalletSeqno(ctx, node.owner.GetAddress()); seqno != 1 {
+		t.Errorf("Expected seqno 1 after inclusion, got %d", seqno)
+	}
+
+	// 同一個 seqno 的兩則訊息都通過檢查，但只有第一則上鏈，另一則保持 pending
+	first := node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateStartNewRoundTransaction(testLottery, seqno)
+	})
+	second := node.send(t, func(seqno uint32) ([]byte, error) {
+		return node.owner.CreateWithdrawTransaction(testLottery, seqno)
+	})
+	node.server.Wait()
+
+	statuses := map[string]int{}
+	for _, hash := range []string{first, second} {
+		status, _ := node.client.GetTransactionStatus(ctx, hash)
+		statuses[status]++
+	}
+	if statuses["pending"] != 1 {
+		t.Errorf("Expected exactly one message to stay pending, got %v", statuses)
+	}
+	if seqno, _ := node.client.GetWalletSeqno(ctx, node.owner.GetAddress()); seqno != 2 {
+		t.Errorf("Expected seqno 2, got %d", seqno)
+	}
+}
+
+func TestFailureInjection(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    Options
+		status     int
+		retryAfter string
+	}{
+		{"error", Options{ErrorRate: 1}, http.StatusInternalServerError, ""},
+		{"rate limit", Options{RateLimitRate: 1, RetryAfter: 1500 * time.Millisecond}, http.StatusTooManyRequests, "2"},
+		{"none", Options{}, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newTestNode(t, tt.options)
+
+			resp, err := http.Get(node.url + "/api/v2/getAddressBalance?address=" + testAlice)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if got := resp.Header.Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.retryAfter, got)
+			}
+
+			var body ton.APIResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Ok != (tt.status == http.StatusOK) || (!body.Ok && body.Code != tt.status) {
+				t.Errorf("Unexpected response: %+v", body)
+			}
+
+			// 注入的故障對客戶端而言是可重試的錯誤
+			_, err = node.client.GetAddressBalance(context.Background(), testAlice)
+			if (err != nil) != (tt.status != http.StatusOK) || (err != nil && !ton.IsRetryable(err)) {
+				t.Errorf("Unexpected client error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFailureInjectionRate(t *testing.T) {
+	node := newTestNode(t, Options{ErrorRate: 0.5, Seed: 1})
+
+	failures := 0
+	for i := 0; i < 200; i++ {
+		if _, err := node.client.GetAddressBalance(context.Background(), testAlice); err != nil {
+			failures++
+		}
+	}
+	if failures < 60 || failures > 140 {
+		t.Errorf("Expected about half of the requests to fail, got %d/200", failures)
+	}
+}
+
+func TestGetMethodHook(t *testing.T) {
+	node := newTestNode(t, Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+		if method != "getParticipant" {
+			return nil, nil
+		}
+		switch tvm.NewReader(args).Int() {
+		case 1:
+			return ton.EncodeParticipant(nil)
+		case 2:
+			return nil, errors.New("injected failure")
+		}
+		return nil, nil
+	}})
+	ctx := context.Background()
+
+	for _, addr := range []string{testAlice, testBob} {
+		if err := node.chain.Join(testLottery, addr, oneTON/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 未攔截的請求由模擬鏈回應
+	if p, err := node.client.GetParticipant(ctx, testLottery, 0); err != nil || p == nil || !address.Equal(p.Address, testAlice) {
+		t.Errorf("Unexpected participant 0: %+v, %v", p, err)
+	}
+	if info, err := node.client.GetLotteryContractInfo(ctx, testLottery); err != nil || info.ParticipantCount != 2 {
+		t.Errorf("Unexpected contract info: %+v, %v", info, err)
+	}
+
+	// hook 返回的堆疊取代合約的結果
+	if p, err := node.client.GetParticipant(ctx, testLottery, 1); err != nil || p != nil {
+		t.Errorf("Expected null participant from the hook, got %+v, %v", p, err)
+	}
+
+	// hook 返回錯誤時以可重試的 500 回應
+	if _, err := node.client.GetParticipant(ctx, testLottery, 2); err == nil || !ton.IsRetryable(err) {
+		t.Errorf("Expected retryable injected failure, got %v", err)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	node := newTestNode(t, Options{})
+
+	resp, err := http.Get(node.url + "/api/v2/getMasterchainInfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(node.url+"/api/v2/getAddressBalance", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+}
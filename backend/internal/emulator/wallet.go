@@ -0,0 +1,498 @@
+package emulator
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/internal/wallet"
+)
+
+// 錢包合約拒絕外部訊息的 exit code
+type walletExitCodes struct {
+	seqno, walletID, signature, expired int
+}
+
+var (
+	// v3r2 以 35 同時代表簽名錯誤與過期
+	exitCodesV3 = walletExitCodes{seqno: 33, walletID: 34, signature: 35, expired: 35}
+	exitCodesV4 = walletExitCodes{seqno: 33, walletID: 34, signature: 35, expired: 36}
+	exitCodesV5 = walletExitCodes{seqno: 133, walletID: 134, signature: 135, expired: 136}
+)
+
+const (
+	opV5SignedExternal uint32 = 0x7369676e
+	opV5ActionSendMsg  uint32 = 0x0ec3c86d
+
+	// sendModeIgnoreErrors 忽略動作階段的錯誤（餘額不足時不發送）
+	sendModeIgnoreErrors uint8 = 2
+)
+
+// walletContract 錢包合約，驗證外部訊息的簽名、wallet id、seqno 與有效期限
+type walletContract struct {
+	version   wallet.Version
+	walletID  uint32
+	publicKey ed25519.PublicKey
+	seqno     uint32
+	deployed  bool
+}
+
+func (w *walletContract) clone() contract {
+	c := *w
+	return &c
+}
+
+// receive 錢包接受所有內部訊息
+func (w *walletContract) receive(ctx *execContext, msg *message) error {
+	if !w.deployed {
+		return &ContractError{Address: ctx.self.String(), ExitCode: exitCodeUninitialized, Message: "帳戶尚未部署"}
+	}
+	return nil
+}
+
+func (w *walletContract) get(method string, args tvm.Args, balance int64) (tvm.Stack, error) {
+	switch method {
+	case "seqno":
+		return tvm.Stack{tvm.Int(int64(w.seqno))}, nil
+	case "get_public_key":
+		return tvm.Stack{tvm.BigInt(new(big.Int).SetBytes(w.publicKey))}, nil
+	}
+	return nil, noGetMethod(method)
+}
+
+// signedRequest 已驗證簽名的外部訊息內容
+type signedRequest struct {
+	walletID   uint32
+	validUntil uint32
+	seqno      uint32
+	actions    []walletAction
+}
+
+// walletAction 錢包要發送的內部訊息
+type walletAction struct {
+	mode uint8
+	msg  *cell.Cell
+}
+
+// externalRequest 已被錢包接受的外部訊息
+type externalRequest struct {
+	account *account
+	wallet  *walletContract
+	body    *cell.Cell
+	req     *signedRequest
+}
+
+// acceptExternal 檢查錢包是否接受外部訊息，不改變狀態；錢包拒絕時返回 *ton.MessageRejectedError
+func (c *Chain) acceptExternal(ext *cell.Cell, now int64) (*externalRequest, error) {
+	dest, stateInit, body, err := parseExternalMessage(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := c.accounts[dest.StringRaw()]
+	if acc == nil || acc.contract == nil {
+		return nil, &ton.MessageRejectedError{Reason: "inbound external message rejected: 帳戶尚未部署"}
+	}
+	w, ok := acc.contract.(*walletContract)
+	if !ok {
+		return nil, &ton.MessageRejectedError{Reason: "inbound external message rejected: 帳戶不是錢包合約"}
+	}
+	if !w.deployed && !stateInit {
+		return nil, &ton.MessageRejectedError{Reason: "inbound external message rejected: 帳戶尚未部署且沒有 StateInit"}
+	}
+
+	req, err := w.verify(body, now)
+	if err != nil {
+		return nil, err
+	}
+	return &externalRequest{account: acc, wallet: w, body: body, req: req}, nil
+}
+
+// applyExternal 處理錢包的外部訊息，返回交易狀態；錢包拒絕時返回 *ton.MessageRejectedError
+func (c *Chain) applyExternal(ext *cell.Cell) (string, error) {
+	now := c.now().Unix()
+	accepted, err := c.acceptExternal(ext, now)
+	if err != nil {
+		return "", err
+	}
+	acc, w, body, req := accepted.account, accepted.wallet, accepted.body, accepted.req
+
+	w.seqno++
+	w.deployed = true
+
+	tx := c.newTransaction(acc, now)
+	tx.InMsg = &ton.Message{
+		Destination: acc.address.String(),
+		Value:       "0",
+		CreatedLT:   "0",
+		MsgData:     ton.MessageData{Type: "msg.dataRaw", Body: body.ToBOCBase64()},
+	}
+
+	// 動作階段：錢包已接受訊息，即使無法發送 seqno 仍會遞增
+	var out []*message
+	balance := acc.balance
+	for _, action := range req.actions {
+		msg, err := parseInternalMessage(action.msg)
+		if err != nil || msg.value > balance {
+			if action.mode&sendModeIgnoreErrors != 0 {
+				continue
+			}
+			// 沒有 IgnoreErrors 時整個動作階段失敗，不發送任何訊息
+			out = nil
+			break
+		}
+		msg.src = acc.address
+		balance -= msg.value
+		out = append(out, msg)
+	}
+
+	for _, msg := range out {
+		acc.balance -= msg.value
+		tx.OutMsgs = append(tx.OutMsgs, *c.messageOf(msg))
+	}
+	acc.txs = append(acc.txs, tx)
+
+	if len(out) == 0 {
+		return StatusFailed, nil
+	}
+
+	status := StatusSuccess
+	for _, msg := range out {
+		if err := c.deliver(msg); err != nil {
+			status = StatusFailed
+		}
+	}
+	return status, nil
+}
+
+// verify 依錢包版本解析簽名內容，並以與官方錢包合約相同的順序檢查
+func (w *walletContract) verify(body *cell.Cell, now int64) (*signedRequest, error) {
+	var codes walletExitCodes
+	var signing *cell.Cell
+	var signature []byte
+	var err error
+
+	switch w.version {
+	case wallet.VersionV3R2, wallet.VersionV4R2:
+		codes = exitCodesV4
+		if w.version == wallet.VersionV3R2 {
+			codes = exitCodesV3
+		}
+		s := body.BeginParse()
+		if signature, err = s.LoadBytes(ed25519.SignatureSize); err == nil {
+			signing, err = s.ToCell()
+		}
+
+	case wallet.VersionV5R1:
+		codes = exitCodesV5
+		signing, signature, err = splitTrailingSignature(body)
+
+	default:
+		err = fmt.Errorf("不支援的錢包版本: %s", w.version)
+	}
+	if err != nil {
+		return nil, rejected(0, fmt.Sprintf("無法解析外部訊息: %v", err))
+	}
+
+	req, err := parseSigningMessage(w.version, signing)
+	if err != nil {
+		return nil, rejected(0, fmt.Sprintf("無法解析外部訊息: %v", err))
+	}
+
+	validSignature := ed25519.Verify(w.publicKey, signing.Hash(), signature)
+	expired := int64(req.validUntil) <= now
+
+	if w.version == wallet.VersionV5R1 {
+		// v5r1 先驗證簽名，再檢查 seqno、wallet id 與有效期限
+		if !validSignature {
+			return nil, rejected(codes.signature, "簽名無效")
+		}
+		if req.seqno != w.seqno {
+			return nil, rejected(codes.seqno, fmt.Sprintf("seqno 不符: %d != %d", req.seqno, w.seqno))
+		}
+		if req.walletID != w.walletID {
+			return nil, rejected(codes.walletID, "wallet id 不符")
+		}
+		if expired {
+			return nil, rejected(codes.expired, "訊息已過期")
+		}
+		return req, nil
+	}
+
+	if expired {
+		return nil, rejected(codes.expired, "訊息已過期")
+	}
+	if req.seqno != w.seqno {
+		return nil, rejected(codes.seqno, fmt.Sprintf("seqno 不符: %d != %d", req.seqno, w.seqno))
+	}
+	if req.walletID != w.walletID {
+		return nil, rejected(codes.walletID, "subwallet id 不符")
+	}
+	if !validSignature {
+		return nil, rejected(codes.signature, "簽名無效")
+	}
+	return req, nil
+}
+
+// rejected 以節點的錯誤格式返回外部訊息被拒絕
+func rejected(exitCode int, reason string) error {
+	return &ton.MessageRejectedError{
+		ExitCode: exitCode,
+		Reason:   fmt.Sprintf("external message was not accepted: exitcode=%d, %s", exitCode, reason),
+	}
+}
+
+// parseExternalMessage 解析 ext_in_msg_info，返回目的錢包、是否附帶 StateInit 與訊息內容
+func parseExternalMessage(ext *cell.Cell) (*address.Address, bool, *cell.Cell, error) {
+	s := ext.BeginParse()
+	fail := func(err error) (*address.Address, bool, *cell.Cell, error) {
+		return nil, false, nil, fmt.Errorf("無效的外部訊息: %w", err)
+	}
+
+	tag, err := s.LoadUInt(2)
+	if err != nil {
+		return fail(err)
+	}
+	if tag != 0b10 {
+		return fail(fmt.Errorf("不是 ext_in_msg_info"))
+	}
+	if _, err := s.LoadAddress(); err != nil {
+		return fail(err)
+	}
+	dest, err := s.LoadAddress()
+	if err != nil {
+		return fail(err)
+	}
+	if dest == nil {
+		return fail(fmt.Errorf("缺少目的地址"))
+	}
+	if _, err := s.LoadCoins(); err != nil {
+		return fail(err)
+	}
+
+	hasInit, err := s.LoadBool()
+	if err != nil {
+		return fail(err)
+	}
+	if hasInit {
+		inRef, err := s.LoadBool()
+		if err != nil {
+			return fail(err)
+		}
+		if !inRef {
+			return fail(fmt.Errorf("不支援內嵌的 StateInit"))
+		}
+		if _, err := s.LoadRef(); err != nil {
+			return fail(err)
+		}
+	}
+
+	body, err := loadEitherRef(s)
+	if err != nil {
+		return fail(err)
+	}
+	return dest, hasInit, body, nil
+}
+
+// parseInternalMessage 解析錢包發出的 MessageRelaxed
+func parseInternalMessage(c *cell.Cell) (*message, error) {
+	s := c.BeginParse()
+
+	tag, err := s.LoadUInt(1)
+	if err != nil {
+		return nil, err
+	}
+	if tag != 0 {
+		return nil, fmt.Errorf("不是 int_msg_info")
+	}
+
+	msg := &message{}
+	if _, err := s.LoadBool(); err != nil { // ihr_disabled
+		return nil, err
+	}
+	if msg.bounce, err = s.LoadBool(); err != nil {
+		return nil, err
+	}
+	if msg.bounced, err = s.LoadBool(); err != nil {
+		return nil, err
+	}
+	if _, err := s.LoadAddress(); err != nil { // src 由錢包填入
+		return nil, err
+	}
+	if msg.dest, err = s.LoadAddress(); err != nil {
+		return nil, err
+	}
+	if msg.dest == nil {
+		return nil, fmt.Errorf("缺少目的地址")
+	}
+	value, err := s.LoadCoins()
+	if err != nil {
+		return nil, err
+	}
+	msg.value = int64(value)
+	if extra, err := s.LoadBool(); err != nil || extra {
+		return nil, fmt.Errorf("不支援額外貨幣")
+	}
+	if _, err := s.LoadCoins(); err != nil { // ihr_fee
+		return nil, err
+	}
+	fwdFee, err := s.LoadCoins()
+	if err != nil {
+		return nil, err
+	}
+	msg.fwdFee = int64(fwdFee)
+	if _, err := s.LoadUInt(64); err != nil { // created_lt
+		return nil, err
+	}
+	if _, err := s.LoadUInt(32); err != nil { // created_at
+		return nil, err
+	}
+	if hasInit, err := s.LoadBool(); err != nil || hasInit {
+		return nil, fmt.Errorf("不支援附帶 StateInit 的內部訊息")
+	}
+
+	if msg.body, err = loadEitherRef(s); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// loadEitherRef 讀取 Either X ^X，內嵌的內容轉換為 Cell
+func loadEitherRef(s *cell.Slice) (*cell.Cell, error) {
+	inRef, err := s.LoadBool()
+	if err != nil {
+		return nil, err
+	}
+	if inRef {
+		return s.LoadRef()
+	}
+	return s.ToCell()
+}
+
+// parseSigningMessage 解析各版本錢包的簽名內容
+func parseSigningMessage(version wallet.Version, signing *cell.Cell) (*signedRequest, error) {
+	s := signing.BeginParse()
+	req := &signedRequest{}
+
+	if version == wallet.VersionV5R1 {
+		op, err := s.LoadUInt(32)
+		if err != nil {
+			return nil, err
+		}
+		if uint32(op) != opV5SignedExternal {
+			return nil, fmt.Errorf("未知的 v5 操作: %#x", op)
+		}
+	}
+
+	fields := []*uint32{&req.walletID, &req.validUntil, &req.seqno}
+	for _, field := range fields {
+		v, err := s.LoadUInt(32)
+		if err != nil {
+			return nil, err
+		}
+		*field = uint32(v)
+	}
+
+	switch version {
+	case wallet.VersionV3R2, wallet.VersionV4R2:
+		if version == wallet.VersionV4R2 {
+			op, err := s.LoadUInt(8)
+			if err != nil {
+				return nil, err
+			}
+			if op != 0 {
+				return nil, fmt.Errorf("不支援的 v4 操作: %d", op)
+			}
+		}
+		for s.RefsLeft() > 0 {
+			mode, err := s.LoadUInt(8)
+			if err != nil {
+				return nil, err
+			}
+			msg, err := s.LoadRef()
+			if err != nil {
+				return nil, err
+			}
+			req.actions = append(req.actions, walletAction{mode: uint8(mode), msg: msg})
+		}
+
+	case wallet.VersionV5R1:
+		actions, err := s.LoadMaybeRef()
+		if err != nil {
+			return nil, err
+		}
+		if extended, err := s.LoadBool(); err != nil || extended {
+			return nil, fmt.Errorf("不支援擴充動作")
+		}
+		if req.actions, err = parseOutList(actions); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// parseOutList 解析 v5 的 OutList，依發送順序返回 action_send_msg
+func parseOutList(list *cell.Cell) ([]walletAction, error) {
+	var actions []walletAction
+	for list != nil && list.RefsNum() > 0 {
+		s := list.BeginParse()
+		prev, err := s.LoadRef()
+		if err != nil {
+			return nil, err
+		}
+		op, err := s.LoadUInt(32)
+		if err != nil {
+			return nil, err
+		}
+		if uint32(op) != opV5ActionSendMsg {
+			return nil, fmt.Errorf("不支援的 v5 動作: %#x", op)
+		}
+		mode, err := s.LoadUInt(8)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := s.LoadRef()
+		if err != nil {
+			return nil, err
+		}
+		actions = append([]walletAction{{mode: uint8(mode), msg: msg}}, actions...)
+		list = prev
+	}
+	return actions, nil
+}
+
+// splitTrailingSignature 分離 v5 訊息最後 512 位元的簽名與簽名內容
+func splitTrailingSignature(body *cell.Cell) (*cell.Cell, []byte, error) {
+	s := body.BeginParse()
+	n := s.BitsLeft() - ed25519.SignatureSize*8
+	if n < 0 {
+		return nil, nil, fmt.Errorf("訊息長度不足以包含簽名")
+	}
+
+	data, err := s.LoadSlice(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := s.LoadBytes(ed25519.SignatureSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := cell.BeginCell().StoreSlice(data, n)
+	for i := 0; i < body.RefsNum(); i++ {
+		ref, err := body.Ref(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		b.StoreRef(ref)
+	}
+	signing, err := b.EndCell()
+	if err != nil {
+		return nil, nil, err
+	}
+	return signing, signature, nil
+}
@@ -0,0 +1,130 @@
+package emulator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/wallet"
+)
+
+func TestWalletExternalMessages(t *testing.T) {
+	versions := map[wallet.Version]walletExitCodes{
+		wallet.VersionV3R2: exitCodesV3,
+		wallet.VersionV4R2: exitCodesV4,
+		wallet.VersionV5R1: exitCodesV5,
+	}
+
+	for version, codes := range versions {
+		t.Run(string(version), func(t *testing.T) {
+			ctx := context.Background()
+			chain := New()
+			w := newTestWallet(t, chain, version)
+			if err := chain.DeployLottery(testLottery, w.GetAddress(), oneTON/10, 10); err != nil {
+				t.Fatal(err)
+			}
+			startNewRound := func(seqno uint32) ([]byte, error) {
+				return w.CreateStartNewRoundTransaction(testLottery, seqno)
+			}
+
+			// 尚未部署時只接受附帶 StateInit 的 seqno 0
+			boc, _ := startNewRound(1)
+			assertRejected(t, chain, boc, 0)
+
+			for seqno := uint32(0); seqno < 2; seqno++ {
+				if got, _ := chain.GetWalletSeqno(ctx, w.GetAddress()); got != seqno {
+					t.Fatalf("Expected seqno %d, got %d", seqno, got)
+				}
+				// 抽獎仍在進行中，startNewRound 在合約中失敗，但錢包已接受訊息
+				if status := sendOwner(t, chain, w, startNewRound); status != StatusFailed {
+					t.Errorf("Expected contract to reject startNewRound, got %s", status)
+				}
+			}
+
+			boc, _ = startNewRound(5)
+			assertRejected(t, chain, boc, codes.seqno)
+
+			// 訊息過期
+			chain.SetClock(func() time.Time { return time.Now().Add(time.Hour) })
+			boc, _ = startNewRound(2)
+			assertRejected(t, chain, boc, codes.expired)
+
+			if balance, _ := chain.GetAddressBalance(ctx, w.GetAddress()); balance != 10*oneTON {
+				t.Errorf("Expected bounced values to be returned, balance %d", balance)
+			}
+		})
+	}
+}
+
+func TestWalletVerification(t *testing.T) {
+	chain := New()
+	w := newTestWallet(t, New(), wallet.VersionV4R2)
+	boc, err := w.CreateDrawWinnerTransaction(testLottery, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未註冊的錢包
+	assertRejected(t, chain, boc, 0)
+
+	// wallet id 不符
+	if err := chain.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID()+1, w.GetPublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	assertRejected(t, chain, boc, exitCodesV4.walletID)
+
+	// 公鑰不符
+	other := New()
+	otherKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	if err := other.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID(), otherKey); err != nil {
+		t.Fatal(err)
+	}
+	assertRejected(t, other, boc, exitCodesV4.signature)
+
+	if err := chain.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID(), w.GetPublicKey()); err == nil {
+		t.Error("Expected registering a wallet twice to fail")
+	}
+}
+
+func TestWalletInsufficientBalance(t *testing.T) {
+	chain := New()
+	w := newTestWallet(t, New(), wallet.VersionV4R2)
+	if err := chain.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID(), w.GetPublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	chain.Fund(w.GetAddress(), mintValue-1)
+	chain.DeployLottery(testLottery, w.GetAddress(), oneTON/10, 10)
+
+	// 外部訊息仍被接受並消耗 seqno，但 IgnoreErrors 使內部訊息不被發送
+	status := sendOwner(t, chain, w, func(seqno uint32) ([]byte, error) {
+		return w.CreateDrawWinnerTransaction(testLottery, seqno)
+	})
+	if status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", status)
+	}
+	if seqno, _ := chain.GetWalletSeqno(context.Background(), w.GetAddress()); seqno != 1 {
+		t.Errorf("Expected seqno to advance, got %d", seqno)
+	}
+
+	txs, _ := chain.GetTransactions(context.Background(), testLottery, 10, ton.TransactionID{}, 0)
+	if len(txs) != 0 {
+		t.Errorf("Expected no message to reach the lottery, got %d transactions", len(txs))
+	}
+}
+
+// assertRejected 確認外部訊息被錢包以指定的 exit code 拒絕
+func assertRejected(t *testing.T, chain *Chain, boc []byte, exitCode int) {
+	t.Helper()
+	_, err := chain.SendTransaction(context.Background(), boc)
+
+	var rejected *ton.MessageRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("Expected message to be rejected, got %v", err)
+	}
+	if rejected.ExitCode != exitCode {
+		t.Errorf("Expected exit code %d, got %d (%s)", exitCode, rejected.ExitCode, rejected.Reason)
+	}
+}
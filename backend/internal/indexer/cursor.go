@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+// CursorStore 保存最後一筆已處理的交易，重啟後從該位置繼續索引
+type CursorStore interface {
+	// Load 讀取游標，尚未保存過時返回空的 TransactionID
+	Load() (ton.TransactionID, error)
+	// Save 保存游標，返回前必須已寫入持久儲存
+	Save(cursor ton.TransactionID) error
+}
+
+// FileCursorStore 以 JSON 檔案保存游標
+type FileCursorStore struct {
+	path string
+}
+
+// NewFileCursorStore 創建保存在 path 的游標
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+// Load 讀取游標檔案，檔案不存在時返回空的 TransactionID
+func (s *FileCursorStore) Load() (ton.TransactionID, error) {
+	var cursor ton.TransactionID
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	}
+	if err != nil {
+		return cursor, fmt.Errorf("讀取索引游標失敗: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("解析索引游標失敗: %w", err)
+	}
+	return cursor, nil
+}
+
+// Save 先寫入暫存檔並同步到磁碟後再改名，避免中途當機留下不完整的檔案
+func (s *FileCursorStore) Save(cursor ton.TransactionID) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return fmt.Errorf("編碼索引游標失敗: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("建立索引游標目錄失敗: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("建立索引游標暫存檔失敗: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("寫入索引游標失敗: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步索引游標失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("寫入索引游標失敗: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("保存索引游標失敗: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+func TestFileCursorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "cursor.json")
+	store := NewFileCursorStore(path)
+
+	cursor, err := store.Load()
+	if err != nil || !cursor.IsZero() {
+		t.Fatalf("Expected empty cursor for a missing file, got %+v (%v)", cursor, err)
+	}
+
+	want := ton.TransactionID{LT: 47000000000003, Hash: "k9vjb3Z5SfQ0kqGrOcDqXNOm+Uv0F1mTk1mWJXbbnTQ="}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// 重新開啟，模擬服務重啟
+	got, err := NewFileCursorStore(path).Load()
+	if err != nil || got != want {
+		t.Errorf("Expected %+v, got %+v (%v)", want, got, err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the cursor file, found %d entries", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Expected corrupted cursor file to fail")
+	}
+}
@@ -0,0 +1,280 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// defaultPageSize 每次 getTransactions 查詢的交易數量
+const defaultPageSize = 50
+
+// Event 從交易的外部出站訊息解碼出的合約事件
+type Event struct {
+	// ID 交易邏輯時間與出站訊息索引，依鏈上順序遞增且唯一
+	ID     string           `json:"id"`
+	Type   ton.EventType    `json:"type"`
+	TxLT   uint64           `json:"tx_lt,string"`
+	TxHash string           `json:"tx_hash"`
+	Time   int64            `json:"time"` // 交易時間 (Unix 秒)
+	Data   ton.EventPayload `json:"data"`
+}
+
+// EventID 組合事件 ID
+func EventID(lt uint64, msgIndex int) string {
+	return fmt.Sprintf("%d:%d", lt, msgIndex)
+}
+
+// Handler 處理一批依鏈上順序排列的事件
+//
+// 返回錯誤時游標不會前進，下次輪詢會重新送出同一批事件，因此 Handler 需以 Event.ID 去重。
+type Handler func(ctx context.Context, events []Event) error
+
+// TransactionSource 查詢帳戶交易，由新到舊排列；參數意義與 ton.Client.GetTransactions 相同
+type TransactionSource interface {
+	GetTransactions(ctx context.Context, addr string, limit int, from ton.TransactionID, toLT uint64) ([]ton.Transaction, error)
+}
+
+// Indexer 輪詢抽獎合約的交易並解碼事件
+type Indexer struct {
+	config   *config.Config
+	logger   *logger.Logger
+	client   TransactionSource
+	store    CursorStore
+	handler  Handler
+	interval time.Duration
+	pageSize int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu 保護 cursor，並確保同一時間只有一個輪詢
+	mu     sync.Mutex
+	cursor ton.TransactionID
+}
+
+// NewIndexer 創建事件索引器，handler 為 nil 時只記錄日誌
+func NewIndexer(cfg *config.Config, log *logger.Logger, client TransactionSource, store CursorStore, handler Handler) *Indexer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Indexer{
+		config:   cfg,
+		logger:   log.WithGroup("indexer"),
+		client:   client,
+		store:    store,
+		handler:  handler,
+		interval: cfg.IndexerPollInterval,
+		pageSize: defaultPageSize,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start 載入游標並開始輪詢
+func (ix *Indexer) Start() error {
+	cursor, err := ix.store.Load()
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	ix.cursor = cursor
+	ix.mu.Unlock()
+
+	ix.logger.Info("📇 事件索引器啟動", "contract", ix.config.LotteryContractAddress, "lt", cursor.LT, "interval", ix.interval)
+
+	ix.wg.Add(1)
+	go ix.loop()
+	return nil
+}
+
+// Stop 停止輪詢並等待進行中的輪詢結束
+func (ix *Indexer) Stop() {
+	ix.cancel()
+	ix.wg.Wait()
+	ix.logger.Info("事件索引器已停止")
+}
+
+// Cursor 最後一筆已處理的交易
+func (ix *Indexer) Cursor() ton.TransactionID {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.cursor
+}
+
+func (ix *Indexer) loop() {
+	defer ix.wg.Done()
+
+	ticker := time.NewTicker(ix.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ix.Poll(ix.ctx); err != nil && !errors.Is(err, context.Canceled) {
+			ix.logger.Warn("索引合約事件失敗", "error", err)
+		}
+
+		select {
+		case <-ix.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll 處理游標之後的所有新交易，返回送出的事件數量
+//
+// toncenter 由新到舊返回交易，因此先往回翻頁直到游標，只記錄每頁最新一筆交易；
+// 之後由最舊的一頁開始逐頁解碼並交給 Handler，每頁成功後即保存游標。
+// 回補大量歷史時記憶體只保存一頁，中途失敗或重啟也只需重新處理未完成的那一頁。
+func (ix *Indexer) Poll(ctx context.Context) (int, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	heads, oldest, err := ix.scanNewPages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for i := len(heads) - 1; i >= 0; i-- {
+		page := oldest
+		if i < len(heads)-1 {
+			// 從該頁最新一筆交易重新查詢，較舊的交易已處理，由游標過濾
+			if page, _, err = ix.fetchPage(ctx, heads[i], ix.cursor); err != nil {
+				return total, err
+			}
+		}
+
+		n, err := ix.processPage(ctx, page)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// scanNewPages 往回翻頁直到游標，返回每頁最新一筆交易（由新到舊）與最舊一頁的交易
+func (ix *Indexer) scanNewPages(ctx context.Context) ([]ton.TransactionID, []ton.Transaction, error) {
+	var (
+		heads []ton.TransactionID
+		page  []ton.Transaction
+		from  ton.TransactionID
+	)
+
+	for {
+		next, more, err := ix.fetchPage(ctx, from, ix.cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// 從指定交易開始查詢時，第一筆就是上一頁的最後一筆
+		if !from.IsZero() && len(next) > 0 && next[0].ID == from {
+			next = next[1:]
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		heads = append(heads, next[0].ID)
+		page = next
+		if !more {
+			break
+		}
+		from = next[len(next)-1].ID
+	}
+	return heads, page, nil
+}
+
+// fetchPage 查詢 from（包含，零值表示最新）之前的一頁交易，由新到舊排列，只返回 cursor 之後的交易；
+// more 表示可能還有更舊且在 cursor 之後的交易
+func (ix *Indexer) fetchPage(ctx context.Context, from, cursor ton.TransactionID) ([]ton.Transaction, bool, error) {
+	page, err := ix.client.GetTransactions(ctx, ix.config.LotteryContractAddress, ix.pageSize, from, cursor.LT)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i, tx := range page {
+		if !cursor.IsZero() && tx.ID.LT <= cursor.LT {
+			return page[:i], false, nil
+		}
+	}
+	return page, len(page) >= ix.pageSize, nil
+}
+
+// processPage 依鏈上順序解碼一頁交易（由新到舊）的事件，交給 Handler 後保存游標
+func (ix *Indexer) processPage(ctx context.Context, page []ton.Transaction) (int, error) {
+	if len(page) == 0 {
+		return 0, nil
+	}
+
+	var events []Event
+	for i := len(page) - 1; i >= 0; i-- {
+		events = append(events, ix.decodeTransaction(page[i])...)
+	}
+
+	if len(events) > 0 {
+		for _, event := range events {
+			ix.logger.Info("合約事件", "id", event.ID, "type", event.Type, "tx", event.TxHash)
+		}
+		if ix.handler != nil {
+			if err := ix.handler(ctx, events); err != nil {
+				return 0, fmt.Errorf("處理合約事件失敗: %w", err)
+			}
+		}
+	}
+
+	cursor := page[0].ID
+	if err := ix.store.Save(cursor); err != nil {
+		return 0, err
+	}
+	ix.cursor = cursor
+
+	ix.logger.Debug("索引進度更新", "transactions", len(page), "events", len(events), "lt", cursor.LT)
+	return len(events), nil
+}
+
+// decodeTransaction 解碼交易中由抽獎合約發出的事件，無法解碼的訊息會被略過
+func (ix *Indexer) decodeTransaction(tx ton.Transaction) []Event {
+	var events []Event
+
+	for i, msg := range tx.OutMsgs {
+		if !msg.IsExternalOut() || !address.Equal(msg.Source, ix.config.LotteryContractAddress) {
+			continue
+		}
+
+		body, err := msg.BodyCell()
+		if err != nil || body == nil {
+			ix.logger.Warn("無法讀取事件內容", "tx", tx.ID.Hash, "index", i, "error", err)
+			continue
+		}
+
+		payload, err := ton.DecodeEvent(body)
+		if errors.Is(err, ton.ErrUnknownEvent) {
+			ix.logger.Debug("略過未知的外部訊息", "tx", tx.ID.Hash, "index", i, "error", err)
+			continue
+		}
+		if err != nil {
+			ix.logger.Warn("解碼合約事件失敗", "tx", tx.ID.Hash, "index", i, "error", err)
+			continue
+		}
+
+		events = append(events, Event{
+			ID:     EventID(tx.ID.LT, i),
+			Type:   payload.EventType(),
+			TxLT:   tx.ID.LT,
+			TxHash: tx.ID.Hash,
+			Time:   tx.Utime,
+			Data:   payload,
+		})
+	}
+
+	return events
+}
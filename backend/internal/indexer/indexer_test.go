@@ -0,0 +1,302 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+const (
+	testLotteryAddress = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	testNFTAddress     = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+	testUserAddress    = "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n"
+	testOwnerAddress   = "EQAREREREREREREREREREREREREREREREREREREREREREeYT"
+
+	testEntryFee = int64(100000000)
+)
+
+// testAddress 以固定的位元組產生測試地址
+func testAddress(b byte) string {
+	addr, err := address.NewAddress(0, bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return addr.String()
+}
+
+// newTestChain 創建部署了抽獎與 NFT 合約的模擬鏈，合約交易即為索引的來源
+func newTestChain(t *testing.T, maxParticipants int) *emulator.Chain {
+	t.Helper()
+
+	chain := emulator.New()
+	chain.SetClock(func() time.Time { return time.Unix(1700000000, 0) })
+	if err := chain.Fund(testOwnerAddress, 10*testEntryFee); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.DeployLottery(testLotteryAddress, testOwnerAddress, testEntryFee, maxParticipants); err != nil {
+		t.Fatalf("DeployLottery() failed: %v", err)
+	}
+	if err := chain.DeployNFT(testNFTAddress, testLotteryAddress); err != nil {
+		t.Fatalf("DeployNFT() failed: %v", err)
+	}
+	return chain
+}
+
+// join 為 participant 注資並參加當前輪次，產生 ParticipantJoined（滿額時另有 LotteryFull）
+func join(t *testing.T, chain *emulator.Chain, participant string) ton.TransactionID {
+	t.Helper()
+
+	if err := chain.Fund(participant, testEntryFee); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Join(testLotteryAddress, participant, testEntryFee); err != nil {
+		t.Fatalf("Join(%s) failed: %v", participant, err)
+	}
+	return latestTransaction(t, chain)
+}
+
+// sendOwner 以 owner 的名義向抽獎合約發送訊息，body 為 nil 時發送 comment
+func sendOwner(t *testing.T, chain *emulator.Chain, comment string, body *cell.Cell) ton.TransactionID {
+	t.Helper()
+
+	var err error
+	if body != nil {
+		err = chain.SendInternal(testOwnerAddress, testLotteryAddress, testEntryFee/2, body)
+	} else {
+		err = chain.SendComment(testOwnerAddress, testLotteryAddress, testEntryFee/2, comment)
+	}
+	if err != nil {
+		t.Fatalf("%s failed: %v", comment, err)
+	}
+	return latestTransaction(t, chain)
+}
+
+// setNFTContract 設定抽獎合約的 NFT 合約，產生一筆沒有事件的交易
+func setNFTContract(t *testing.T, chain *emulator.Chain) ton.TransactionID {
+	t.Helper()
+
+	body, err := cell.BeginCell().
+		StoreUInt(uint64(ton.OpSetNFTContract), 32).
+		StoreAddress(address.MustParse(testNFTAddress)).
+		EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sendOwner(t, chain, "SetNFTContract", body)
+}
+
+// latestTransaction 返回抽獎合約最新的交易
+func latestTransaction(t *testing.T, chain *emulator.Chain) ton.TransactionID {
+	t.Helper()
+
+	txs, err := chain.GetTransactions(context.Background(), testLotteryAddress, 1, ton.TransactionID{}, 0)
+	if err != nil || len(txs) == 0 {
+		t.Fatalf("GetTransactions() = %v, %v", txs, err)
+	}
+	return txs[0].ID
+}
+
+// collector 記錄 Handler 收到的事件，fail 為 true 時返回錯誤
+type collector struct {
+	events []Event
+	fail   bool
+}
+
+func (c *collector) handle(ctx context.Context, events []Event) error {
+	if c.fail {
+		return errors.New("handler failed")
+	}
+	c.events = append(c.events, events...)
+	return nil
+}
+
+func newTestIndexer(t *testing.T, chain *emulator.Chain, store CursorStore, handler Handler) *Indexer {
+	t.Helper()
+
+	cfg := &config.Config{
+		LogLevel:               "debug",
+		LotteryContractAddress: testLotteryAddress,
+		IndexerPollInterval:    time.Hour,
+	}
+
+	ix := NewIndexer(cfg, logger.New(cfg.LogLevel), chain, store, handler)
+	ix.pageSize = 2
+	return ix
+}
+
+func TestIndexerPoll(t *testing.T) {
+	chain := newTestChain(t, 2)
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+	c := &collector{}
+	ix := newTestIndexer(t, chain, store, c.handle)
+
+	other := testAddress(0x22)
+	setNFTContract(t, chain) // 沒有事件的交易
+	join(t, chain, testUserAddress)
+	full := join(t, chain, other)
+	sendOwner(t, chain, "drawWinner", nil)
+	last := sendOwner(t, chain, "startNewRound", nil)
+
+	n, err := ix.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() failed: %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("Expected 5 events, got %d", n)
+	}
+
+	// 依鏈上順序，跨越多個分頁
+	wantTypes := []ton.EventType{ton.EventParticipantJoined, ton.EventParticipantJoined, ton.EventLotteryFull, ton.EventNFTSent, ton.EventWinnerDrawn}
+	for i, event := range c.events {
+		if event.Type != wantTypes[i] {
+			t.Errorf("events[%d].Type = %s, want %s", i, event.Type, wantTypes[i])
+		}
+	}
+	if e := c.events[2]; e.ID != EventID(full.LT, 1) || e.TxLT != full.LT || e.TxHash != full.Hash || e.Time != 1700000000 {
+		t.Errorf("Unexpected event metadata: %+v", e)
+	}
+	if joined, ok := c.events[1].Data.(*ton.ParticipantJoined); !ok || joined.ParticipantIndex != 1 || !address.Equal(joined.Participant, other) {
+		t.Errorf("Unexpected ParticipantJoined: %+v", c.events[1].Data)
+	}
+	if drawn, ok := c.events[4].Data.(*ton.WinnerDrawn); !ok || drawn.NFTId != 1002 || drawn.ParticipantCount != 2 {
+		t.Errorf("Unexpected WinnerDrawn: %+v", c.events[4].Data)
+	}
+
+	// 游標保存為最新的交易
+	if ix.Cursor() != last {
+		t.Errorf("Expected cursor %+v, got %+v", last, ix.Cursor())
+	}
+	if saved, err := store.Load(); err != nil || saved != last {
+		t.Errorf("Expected saved cursor %+v, got %+v (%v)", last, saved, err)
+	}
+
+	// 沒有新交易時不送出事件
+	if n, err := ix.Poll(context.Background()); err != nil || n != 0 {
+		t.Errorf("Expected no new events, got %d (%v)", n, err)
+	}
+
+	joined := join(t, chain, testUserAddress)
+	if n, err := ix.Poll(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Expected 1 new event, got %d (%v)", n, err)
+	}
+	if len(c.events) != 6 || c.events[5].ID != EventID(joined.LT, 0) {
+		t.Errorf("Expected only the new event, got %d events ending with %+v", len(c.events), c.events[len(c.events)-1])
+	}
+}
+
+func TestIndexerResumeFromCursor(t *testing.T) {
+	chain := newTestChain(t, 1)
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+
+	// 每輪一位參與者即滿額
+	join(t, chain, testUserAddress)
+	sendOwner(t, chain, "startNewRound", nil)
+	cursor := join(t, chain, testUserAddress)
+	sendOwner(t, chain, "startNewRound", nil)
+	join(t, chain, testUserAddress)
+	if err := store.Save(cursor); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	delivered := make(chan []Event, 1)
+	ix := newTestIndexer(t, chain, store, func(ctx context.Context, events []Event) error {
+		delivered <- events
+		return nil
+	})
+	if err := ix.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer ix.Stop()
+
+	select {
+	case events := <-delivered:
+		if len(events) != 2 || events[1].Data.(*ton.LotteryFull).Round != 3 {
+			t.Errorf("Expected only round 3 after the saved cursor, got %+v", events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the first poll to deliver events")
+	}
+}
+
+func TestIndexerHandlerError(t *testing.T) {
+	chain := newTestChain(t, 2)
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+	c := &collector{fail: true}
+	ix := newTestIndexer(t, chain, store, c.handle)
+
+	join(t, chain, testUserAddress)
+
+	if _, err := ix.Poll(context.Background()); err == nil {
+		t.Fatal("Expected Poll() to fail when the handler fails")
+	}
+	if !ix.Cursor().IsZero() {
+		t.Errorf("Expected cursor not to advance, got %+v", ix.Cursor())
+	}
+
+	// 處理成功後重新送出同一批事件
+	c.fail = false
+	if n, err := ix.Poll(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Expected the event to be redelivered, got %d (%v)", n, err)
+	}
+}
+
+func TestIndexerBackfillByPage(t *testing.T) {
+	chain := newTestChain(t, 10)
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+
+	// 每筆交易一個 ParticipantJoined，索引即加入順序
+	var ids []ton.TransactionID
+	for i := 0; i < 5; i++ {
+		ids = append(ids, join(t, chain, testAddress(byte(0x40+i))))
+	}
+
+	// 第三頁處理失敗：之前的頁已保存游標，之後不會再送出
+	var batches [][]Event
+	failAt := 3
+	ix := newTestIndexer(t, chain, store, func(ctx context.Context, events []Event) error {
+		if len(batches)+1 == failAt {
+			return errors.New("handler failed")
+		}
+		batches = append(batches, events)
+		return nil
+	})
+
+	n, err := ix.Poll(context.Background())
+	if err == nil {
+		t.Fatal("Expected Poll() to fail when the handler fails")
+	}
+	if n != 2 || len(batches) != 2 {
+		t.Fatalf("Expected 2 events in 2 pages before the failure, got %d in %d pages", n, len(batches))
+	}
+	for i, batch := range batches {
+		if len(batch) != 1 || batch[0].Data.(*ton.ParticipantJoined).ParticipantIndex != i {
+			t.Errorf("Expected page %d to contain participant %d oldest first, got %+v", i, i, batch)
+		}
+	}
+	if saved, err := store.Load(); err != nil || saved != ids[1] {
+		t.Errorf("Expected saved cursor %+v after the second page, got %+v (%v)", ids[1], saved, err)
+	}
+
+	// 之後從保存的游標繼續，不重送已處理的頁
+	failAt = 0
+	n, err = ix.Poll(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("Expected the remaining 3 events, got %d (%v)", n, err)
+	}
+	if index := batches[2][0].Data.(*ton.ParticipantJoined).ParticipantIndex; index != 2 {
+		t.Errorf("Expected backfill to resume at participant 2, got %d", index)
+	}
+	if ix.Cursor() != ids[4] {
+		t.Errorf("Expected cursor %+v, got %+v", ids[4], ix.Cursor())
+	}
+}
@@ -0,0 +1,309 @@
+package lottery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/indexer"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/wallet"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// newEmulatedChain 創建部署了 cfg 中抽獎與 NFT 合約的模擬鏈
+//
+// 抽獎合約的 owner 為服務的錢包，NFT 合約的 owner 為抽獎合約；時鐘固定以便重現抽獎結果。
+func newEmulatedChain(t *testing.T, cfg *config.Config) *emulator.Chain {
+	t.Helper()
+	chain := emulator.New()
+	chain.SetClock(func() time.Time { return time.Unix(1700000000, 0) })
+
+	w, err := wallet.NewManager(cfg, logger.New("error"))
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	if err := chain.AddWallet(w.GetAddress(), w.GetVersion(), w.GetWalletID(), w.GetPublicKey()); err != nil {
+		t.Fatalf("AddWallet() failed: %v", err)
+	}
+	if err := chain.Fund(w.GetAddress(), 10*tonToNano(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.DeployLottery(cfg.LotteryContractAddress, w.GetAddress(), tonToNano(cfg.EntryFeeTON), cfg.MaxParticipants); err != nil {
+		t.Fatalf("DeployLottery() failed: %v", err)
+	}
+	if err := chain.DeployNFT(cfg.NFTContractAddress, cfg.LotteryContractAddress); err != nil {
+		t.Fatalf("DeployNFT() failed: %v", err)
+	}
+	return chain
+}
+
+// testAddress 以固定的位元組產生測試地址
+func testAddress(b byte) string {
+	addr, err := address.NewAddress(0, bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return addr.String()
+}
+
+// testParticipant 返回第 i 位測試參與者的地址
+func testParticipant(i int) string {
+	return testAddress(byte(0x40 + i))
+}
+
+// joinParticipant 為第 i 位測試參與者注資並參加當前輪次，不依賴 *testing.T 以便在 toncenter 的 hook 中呼叫
+func joinParticipant(chain *emulator.Chain, cfg *config.Config, i int) error {
+	if err := chain.Fund(testParticipant(i), tonToNano(1)); err != nil {
+		return err
+	}
+	return chain.Join(cfg.LotteryContractAddress, testParticipant(i), tonToNano(cfg.EntryFeeTON))
+}
+
+// joinParticipants 第 0 到 n-1 位測試參與者依序參加當前輪次
+func joinParticipants(t *testing.T, chain *emulator.Chain, cfg *config.Config, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := joinParticipant(chain, cfg, i); err != nil {
+			t.Fatalf("Join(%s) failed: %v", testParticipant(i), err)
+		}
+	}
+}
+
+// sendOwnerComment 以服務錢包的名義直接向抽獎合約發送 comment（例如 drawWinner）
+//
+// 訊息不經過服務與交易監控，只用於準備鏈上狀態。
+func sendOwnerComment(t *testing.T, chain *emulator.Chain, service *Service, comment string) {
+	t.Helper()
+	if err := chain.SendComment(service.GetWalletAddress(), service.config.LotteryContractAddress, tonToNano(0.05), comment); err != nil {
+		t.Fatalf("%s failed: %v", comment, err)
+	}
+}
+
+// newEmulatedService 創建以記憶體模擬鏈執行的抽獎服務
+func newEmulatedService(t *testing.T) (*Service, *emulator.Chain) {
+	t.Helper()
+	cfg := createTestConfig()
+	chain := newEmulatedChain(t, cfg)
+
+	service, err := NewServiceWithChain(cfg, logger.New("error"), chain)
+	if err != nil {
+		t.Fatalf("NewServiceWithChain() failed: %v", err)
+	}
+	service.txMonitor.SetPollInterval(time.Millisecond)
+	t.Cleanup(service.Stop)
+	return service, chain
+}
+
+func TestEmulatedLotteryRound(t *testing.T) {
+	service, chain := newEmulatedService(t)
+	ctx := context.Background()
+	entryFee := tonToNano(service.config.EntryFeeTON)
+
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+	}
+
+	// 參與人數不足時不發送抽獎交易
+	if _, err := service.ExecuteDrawWinner(); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState without participants, got %v", err)
+	}
+
+	for _, addr := range []string{testWinnerAddress, testOwnerAddress} {
+		chain.Fund(addr, tonToNano(1))
+		if err := chain.Join(testLotteryAddress, addr, entryFee); err != nil {
+			t.Fatalf("Join(%s) failed: %v", addr, err)
+		}
+	}
+
+	list, err := service.ListParticipants()
+	if err != nil {
+		t.Fatalf("ListParticipants() failed: %v", err)
+	}
+	if !list.Complete() || len(list.Participants) != 2 || !address.Equal(list.Participants[0].Address, testWinnerAddress) {
+		t.Fatalf("Unexpected participants: %+v", list)
+	}
+
+	result, err := service.ExecuteDrawWinner()
+	if err != nil {
+		t.Fatalf("ExecuteDrawWinner() failed: %v", err)
+	}
+	if result.Confirmation == nil || result.Confirmation.Status != "success" {
+		t.Errorf("Expected confirmed draw, got %+v", result.Confirmation)
+	}
+
+	// seed = 1700000000 + 0 + 2，中獎者為第一位參與者，nftId = 1*1000 + 2
+	winner, err := service.GetWinner(1)
+	if err != nil {
+		t.Fatalf("GetWinner() failed: %v", err)
+	}
+	if winner == nil || !address.Equal(winner.Winner, testWinnerAddress) || winner.NFTId != 1002 {
+		t.Errorf("Unexpected winner: %+v", winner)
+	}
+	if isWinner, err := service.IsWinner(1, testWinnerAddress); err != nil || !isWinner {
+		t.Errorf("Expected IsWinner to be true, got %v, %v", isWinner, err)
+	}
+
+	info, err := service.GetContractInfo()
+	if err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if info.LotteryActive || info.ParticipantCount != 0 || info.CurrentRound != 1 {
+		t.Errorf("Expected lottery to close after draw, got %+v", info)
+	}
+	if nft, _ := chain.GetNFTContractInfo(ctx, testNFTAddress); nft.NFTSupply != 1 {
+		t.Errorf("Expected NFT to be minted, got %+v", nft)
+	}
+
+	// 抽獎後提取：合約保留 0.1 TON，SendRemainingValue 再加上 withdraw 訊息的金額
+	withdraw, err := service.ExecuteWithdraw()
+	if err != nil {
+		t.Fatalf("ExecuteWithdraw() failed: %v", err)
+	}
+	if report := withdraw.Withdraw; report.ContractAfter == nil || *report.ContractAfter != ContractReserve-50000000 {
+		t.Errorf("Unexpected withdraw report: %+v", report)
+	}
+
+	if _, err := service.ExecuteStartNewRound(); err != nil {
+		t.Fatalf("ExecuteStartNewRound() failed: %v", err)
+	}
+	info, _ = service.GetContractInfo()
+	if !info.LotteryActive || info.CurrentRound != 2 {
+		t.Errorf("Expected round 2 to be active, got %+v", info)
+	}
+
+	// 每個 owner 操作消耗一個 seqno，且全部確認成功
+	if seqno, _ := chain.GetWalletSeqno(ctx, service.GetWalletAddress()); seqno != 4 {
+		t.Errorf("Expected wallet seqno 4, got %d", seqno)
+	}
+	txs, err := service.ListTransactions("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 4 {
+		t.Fatalf("Expected 4 recorded transactions, got %d", len(txs))
+	}
+	for _, tx := range txs {
+		if tx.Status != store.TxStatusSuccess {
+			t.Errorf("Expected %s to succeed, got %s", tx.Operation, tx.Status)
+		}
+	}
+}
+
+func TestEmulatedOperationFailure(t *testing.T) {
+	service, chain := newEmulatedService(t)
+
+	// 未設定 NFT 合約時 drawWinner 在合約中失敗，交易記錄為 failed，狀態不變
+	for _, addr := range []string{testWinnerAddress, testOwnerAddress} {
+		chain.Fund(addr, tonToNano(1))
+		if err := chain.Join(testLotteryAddress, addr, tonToNano(service.config.EntryFeeTON)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := service.ExecuteDrawWinner()
+	if err == nil {
+		t.Fatal("Expected draw without NFT contract to fail")
+	}
+	if result == nil || result.Confirmation == nil || result.Confirmation.Status != "failed" {
+		t.Fatalf("Expected failed confirmation, got %+v", result)
+	}
+	if tx, err := service.GetTransaction(result.TxHash); err != nil || tx.Status != store.TxStatusFailed {
+		t.Errorf("Expected failed transaction record, got %+v, %v", tx, err)
+	}
+
+	if winner, _ := service.GetWinner(1); winner != nil {
+		t.Errorf("Expected no winner, got %+v", winner)
+	}
+	info, _ := service.GetContractInfo()
+	if !info.LotteryActive || info.ParticipantCount != 2 {
+		t.Errorf("Expected state to be unchanged, got %+v", info)
+	}
+
+	// 失敗後本地 seqno 失效，下一筆交易從鏈上重新同步
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+	}
+	if _, err := service.ExecuteDrawWinner(); err != nil {
+		t.Fatalf("ExecuteDrawWinner() failed: %v", err)
+	}
+}
+
+// stoppingChain 在服務停止（ctx 取消）後才返回合約資訊的模擬鏈
+type stoppingChain struct {
+	*emulator.Chain
+	queried chan struct{}
+}
+
+func (c *stoppingChain) GetLotteryContractInfo(ctx context.Context, contractAddress string) (*ton.LotteryContractInfo, error) {
+	close(c.queried)
+	<-ctx.Done()
+	return c.Chain.GetLotteryContractInfo(context.Background(), contractAddress)
+}
+
+func TestEmulatedStopDuringOwnerCheck(t *testing.T) {
+	cfg := createTestConfig()
+	chain := &stoppingChain{Chain: newEmulatedChain(t, cfg), queried: make(chan struct{})}
+
+	service, err := NewServiceWithChain(cfg, logger.New("error"), chain)
+	if err != nil {
+		t.Fatalf("NewServiceWithChain() failed: %v", err)
+	}
+	if err := service.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	<-chain.queried
+
+	// owner 檢查在 Stop 持有鎖等待 goroutine 結束時才完成，不得因此死鎖
+	stopped := make(chan struct{})
+	go func() {
+		service.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() deadlocked while the owner check was completing")
+	}
+	if verified, ok := service.GetStatus()["owner_verified"]; !ok || verified != true {
+		t.Errorf("Expected owner_verified=true, got %v", verified)
+	}
+}
+
+func TestEmulatedIndexer(t *testing.T) {
+	service, chain := newEmulatedService(t)
+
+	// 索引器與服務共用同一個鏈上介面
+	source := service.TransactionSource()
+	if source == nil {
+		t.Fatal("Expected the emulated chain to support transaction queries")
+	}
+
+	for _, addr := range []string{testWinnerAddress, testOwnerAddress} {
+		chain.Fund(addr, tonToNano(1))
+		if err := chain.Join(testLotteryAddress, addr, tonToNano(service.config.EntryFeeTON)); err != nil {
+			t.Fatalf("Join(%s) failed: %v", addr, err)
+		}
+	}
+
+	var events []indexer.Event
+	store := indexer.NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+	ix := indexer.NewIndexer(service.config, logger.New("error"), source, store, func(ctx context.Context, batch []indexer.Event) error {
+		events = append(events, batch...)
+		return service.HandleChainEvents(ctx, batch)
+	})
+	if n, err := ix.Poll(context.Background()); err != nil || n != 2 {
+		t.Fatalf("Expected 2 events, got %d (%v)", n, err)
+	}
+	if joined, ok := events[1].Data.(*ton.ParticipantJoined); !ok || !address.Equal(joined.Participant, testOwnerAddress) {
+		t.Errorf("Unexpected second event: %+v", events[1].Data)
+	}
+}
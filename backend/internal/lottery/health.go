@@ -0,0 +1,237 @@
+package lottery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 健康檢查相關設定
+const (
+	// maxLoopHeartbeatInterval 自動抽獎迴圈閒置時更新心跳的最長間隔
+	maxLoopHeartbeatInterval = 15 * time.Second
+
+	// defaultLoopStallTimeout 心跳停止超過此時間即視為迴圈卡住，
+	// 需涵蓋一次完整抽獎（發送交易 + 最長 5 分鐘的確認等待）
+	defaultLoopStallTimeout = 10 * time.Minute
+
+	// readinessCheckTimeout 就緒檢查中每項鏈上查詢的逾時
+	readinessCheckTimeout = 5 * time.Second
+)
+
+// 健康檢查狀態
+const (
+	CheckStatusOK      = "ok"
+	CheckStatusFail    = "fail"
+	CheckStatusSkipped = "skipped"
+)
+
+// CheckResult 單項檢查結果
+type CheckResult struct {
+	Name     string                 `json:"name"`
+	Status   string                 `json:"status"`
+	Message  string                 `json:"message,omitempty"`
+	Duration string                 `json:"duration,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport 存活或就緒檢查的結果
+type HealthReport struct {
+	Status    string        `json:"status"`
+	Timestamp time.Time     `json:"timestamp"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Healthy 所有檢查是否都通過（略過的檢查不影響結果）
+func (r *HealthReport) Healthy() bool {
+	return r.Status == CheckStatusOK
+}
+
+// newHealthReport 依各項檢查結果彙總整體狀態
+func newHealthReport(checks []CheckResult) *HealthReport {
+	status := CheckStatusOK
+	for _, check := range checks {
+		if check.Status == CheckStatusFail {
+			status = CheckStatusFail
+			break
+		}
+	}
+
+	return &HealthReport{
+		Status:    status,
+		Timestamp: time.Now().UTC(),
+		Checks:    checks,
+	}
+}
+
+// loopHeartbeatInterval 自動抽獎迴圈的心跳間隔
+func (s *Service) loopHeartbeatInterval() time.Duration {
+	if s.config.DrawInterval > 0 && s.config.DrawInterval < maxLoopHeartbeatInterval {
+		return s.config.DrawInterval
+	}
+	return maxLoopHeartbeatInterval
+}
+
+// beat 記錄自動抽獎迴圈的心跳
+func (s *Service) beat() {
+	s.loopBeat.Store(time.Now().UnixNano())
+}
+
+// Liveness 檢查服務與自動抽獎迴圈是否存活
+//
+// 自動抽獎迴圈在閒置時定期更新心跳，執行抽獎檢查期間則不會；
+// 心跳停止超過門檻代表迴圈已卡在某次抽獎中，需要重啟。
+func (s *Service) Liveness() *HealthReport {
+	s.mu.RLock()
+	running := s.running
+	s.mu.RUnlock()
+
+	service := CheckResult{Name: "service", Status: CheckStatusOK}
+	if !running {
+		service.Status = CheckStatusFail
+		service.Message = "抽獎服務未運行"
+	}
+
+	return newHealthReport([]CheckResult{service, s.checkAutoDrawLoop(time.Now())})
+}
+
+// checkAutoDrawLoop 檢查自動抽獎迴圈的心跳
+func (s *Service) checkAutoDrawLoop(now time.Time) CheckResult {
+	result := CheckResult{Name: "auto_draw_loop", Status: CheckStatusOK}
+
+	if !s.config.AutoDraw {
+		result.Status = CheckStatusSkipped
+		result.Message = "未啟用自動抽獎"
+		return result
+	}
+
+	if !s.loopAlive.Load() {
+		result.Status = CheckStatusFail
+		result.Message = "自動抽獎迴圈未運行"
+		return result
+	}
+
+	lastBeat := time.Unix(0, s.loopBeat.Load())
+	sinceBeat := now.Sub(lastBeat)
+	result.Details = map[string]interface{}{
+		"last_heartbeat":   lastBeat.UTC().Format(time.RFC3339),
+		"stall_timeout":    s.loopStallTimeout.String(),
+		"draw_in_progress": false,
+	}
+
+	if started := s.drawStarted.Load(); started != 0 {
+		result.Details["draw_in_progress"] = true
+		result.Details["draw_started_at"] = time.Unix(0, started).UTC().Format(time.RFC3339)
+	}
+
+	if sinceBeat > s.loopStallTimeout {
+		result.Status = CheckStatusFail
+		result.Message = fmt.Sprintf("自動抽獎迴圈已 %s 沒有心跳", sinceBeat.Round(time.Second))
+	}
+
+	return result
+}
+
+// Readiness 檢查服務是否可以處理請求
+//
+// 同時檢查 TON API 是否能回應 getContractInfo、錢包是否已載入，
+// 以及錢包餘額是否高於發送交易所需的門檻。
+func (s *Service) Readiness(ctx context.Context) *HealthReport {
+	checks := []func(context.Context) CheckResult{
+		s.checkTONAPI,
+		s.checkWallet,
+		s.checkWalletGas,
+	}
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func(context.Context) CheckResult) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			results[i] = check(checkCtx)
+			results[i].Duration = time.Since(start).Round(time.Millisecond).String()
+		}(i, check)
+	}
+	wg.Wait()
+
+	return newHealthReport(results)
+}
+
+// checkTONAPI 檢查 TON API 是否能回應抽獎合約的 getContractInfo
+func (s *Service) checkTONAPI(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "ton_api", Status: CheckStatusOK}
+
+	info, err := s.chain.GetLotteryContractInfo(ctx, s.config.LotteryContractAddress)
+	if err != nil {
+		result.Status = CheckStatusFail
+		result.Message = err.Error()
+		return result
+	}
+	observeContractInfo(info)
+
+	result.Details = map[string]interface{}{
+		"current_round":     info.CurrentRound,
+		"participant_count": info.ParticipantCount,
+	}
+	return result
+}
+
+// checkWallet 檢查錢包是否已載入
+func (s *Service) checkWallet(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "wallet", Status: CheckStatusOK}
+
+	if s.wallet == nil || s.wallet.GetAddress() == "" {
+		result.Status = CheckStatusFail
+		result.Message = "錢包未載入"
+		return result
+	}
+
+	result.Details = map[string]interface{}{
+		"address": s.wallet.GetAddress(),
+		"version": string(s.wallet.GetVersion()),
+	}
+	return result
+}
+
+// checkWalletGas 檢查錢包餘額是否足以支付交易手續費，門檻為 0 時略過
+func (s *Service) checkWalletGas(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "wallet_balance", Status: CheckStatusOK}
+
+	threshold := tonToNano(s.config.MinWalletBalanceTON)
+	if threshold <= 0 {
+		result.Status = CheckStatusSkipped
+		result.Message = "未設定錢包餘額門檻"
+		return result
+	}
+
+	if s.wallet == nil {
+		result.Status = CheckStatusFail
+		result.Message = "錢包未載入"
+		return result
+	}
+
+	balance, err := s.wallet.GetBalance(ctx)
+	if err != nil {
+		result.Status = CheckStatusFail
+		result.Message = err.Error()
+		return result
+	}
+	walletBalanceGauge.Set(float64(balance))
+
+	result.Details = map[string]interface{}{
+		"balance":   balance,
+		"threshold": threshold,
+	}
+	if balance < threshold {
+		result.Status = CheckStatusFail
+		result.Message = fmt.Sprintf("錢包餘額低於門檻: %d < %d nanoTON", balance, threshold)
+	}
+	return result
+}
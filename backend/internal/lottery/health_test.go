@@ -0,0 +1,149 @@
+package lottery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// findCheck 依名稱取出檢查結果
+func findCheck(t *testing.T, report *HealthReport, name string) CheckResult {
+	t.Helper()
+
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("找不到檢查項目 %s: %+v", name, report.Checks)
+	return CheckResult{}
+}
+
+func TestLiveness(t *testing.T) {
+	t.Run("service not running", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.AutoDraw = false
+		service, err := NewService(cfg, logger.New(cfg.LogLevel))
+		if err != nil {
+			t.Fatalf("NewService() failed: %v", err)
+		}
+
+		report := service.Liveness()
+		if report.Healthy() {
+			t.Error("Expected liveness to fail before Start()")
+		}
+		if check := findCheck(t, report, "auto_draw_loop"); check.Status != CheckStatusSkipped {
+			t.Errorf("Expected auto_draw_loop to be skipped, got %s", check.Status)
+		}
+	})
+
+	t.Run("auto draw loop alive", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.DrawInterval = time.Hour
+		service, err := NewService(cfg, logger.New(cfg.LogLevel))
+		if err != nil {
+			t.Fatalf("NewService() failed: %v", err)
+		}
+
+		if err := service.Start(); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+
+		report := service.Liveness()
+		if !report.Healthy() {
+			t.Errorf("Expected liveness to pass, got %+v", report.Checks)
+		}
+
+		service.Stop()
+
+		report = service.Liveness()
+		if report.Healthy() {
+			t.Error("Expected liveness to fail after Stop()")
+		}
+		if check := findCheck(t, report, "auto_draw_loop"); check.Status != CheckStatusFail {
+			t.Errorf("Expected auto_draw_loop to fail after the loop exits, got %s", check.Status)
+		}
+	})
+
+	t.Run("wedged draw", func(t *testing.T) {
+		cfg := createTestConfig()
+		service, err := NewService(cfg, logger.New(cfg.LogLevel))
+		if err != nil {
+			t.Fatalf("NewService() failed: %v", err)
+		}
+
+		// 模擬迴圈卡在一次抽獎檢查中
+		started := time.Now().Add(-time.Hour)
+		service.loopAlive.Store(true)
+		service.loopBeat.Store(started.UnixNano())
+		service.drawStarted.Store(started.UnixNano())
+
+		check := service.checkAutoDrawLoop(time.Now())
+		if check.Status != CheckStatusFail {
+			t.Errorf("Expected wedged loop to fail, got %s", check.Status)
+		}
+		if check.Details["draw_in_progress"] != true {
+			t.Errorf("Expected draw_in_progress=true, got %v", check.Details["draw_in_progress"])
+		}
+
+		// 抽獎進行中但未超過門檻時仍視為存活
+		check = service.checkAutoDrawLoop(started.Add(service.loopStallTimeout / 2))
+		if check.Status != CheckStatusOK {
+			t.Errorf("Expected in-progress draw within the timeout to pass, got %s: %s", check.Status, check.Message)
+		}
+	})
+}
+
+func TestReadiness(t *testing.T) {
+	// 錢包在模擬鏈上有 10 TON
+	tests := []struct {
+		name       string
+		contractOK bool
+		threshold  float64
+		healthy    bool
+		failed     string
+	}{
+		{"all checks pass", true, 0.2, true, ""},
+		{"ton api failure", false, 0.2, false, "ton_api"},
+		{"balance below gas floor", true, 20, false, "wallet_balance"},
+		{"balance check disabled", true, 0, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.MinWalletBalanceTON = tt.threshold
+			newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+				if method == "getContractInfo" && !tt.contractOK {
+					return nil, errors.New("internal error")
+				}
+				return nil, nil
+			}})
+			service := newToncenterService(t, cfg)
+
+			report := service.Readiness(context.Background())
+			if report.Healthy() != tt.healthy {
+				t.Errorf("Expected healthy=%v, got %+v", tt.healthy, report.Checks)
+			}
+			if len(report.Checks) != 3 {
+				t.Errorf("Expected 3 checks, got %d", len(report.Checks))
+			}
+
+			if findCheck(t, report, "wallet").Status != CheckStatusOK {
+				t.Error("Expected wallet check to pass")
+			}
+			if tt.failed != "" {
+				if check := findCheck(t, report, tt.failed); check.Status != CheckStatusFail || check.Message == "" {
+					t.Errorf("Expected %s to fail with a message, got %+v", tt.failed, check)
+				}
+			}
+			if tt.threshold == 0 && findCheck(t, report, "wallet_balance").Status != CheckStatusSkipped {
+				t.Error("Expected wallet_balance to be skipped when the threshold is 0")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package lottery
+
+import (
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+// 歷史查詢每頁的輪次數量
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+// RoundResult 單一輪次的開獎結果
+type RoundResult struct {
+	Round int `json:"round"`
+	ton.LotteryResult
+}
+
+// RoundHistory 由新到舊排列的一頁開獎結果
+//
+// NextCursor 傳回 GetRoundHistory 可取得下一頁（更早的輪次），0 表示已經沒有更早的輪次。
+type RoundHistory struct {
+	CurrentRound int
+	Rounds       []RoundResult
+	NextCursor   int
+}
+
+// GetRoundHistory 查詢開獎歷史，由 cursor 之前的輪次開始往回查詢，沒有結果的輪次會被略過
+//
+// cursor 為 0 時從當前輪次開始；limit 為 0 時使用 DefaultHistoryPageSize，最大為 MaxHistoryPageSize。
+// 每批以有限的並發查詢尚缺的輪次數量，已保存結果的輪次從資料檔讀取。
+func (s *Service) GetRoundHistory(cursor, limit int) (*RoundHistory, error) {
+	if cursor < 0 {
+		return nil, fmt.Errorf("無效的 cursor: %d", cursor)
+	}
+	if limit <= 0 {
+		limit = DefaultHistoryPageSize
+	}
+	limit = min(limit, MaxHistoryPageSize)
+
+	info, err := s.GetContractInfo()
+	if err != nil {
+		return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
+	}
+
+	round := info.CurrentRound
+	if cursor > 0 {
+		round = min(round, cursor-1)
+	}
+
+	history := &RoundHistory{
+		CurrentRound: info.CurrentRound,
+		Rounds:       make([]RoundResult, 0, limit),
+	}
+
+	for round >= 1 && len(history.Rounds) < limit {
+		batch := min(limit-len(history.Rounds), round)
+
+		results := make([]*ton.LotteryResult, batch)
+		errs := make([]error, batch)
+		forEachConcurrent(batch, func(i int) {
+			results[i], errs[i] = s.getRoundResult(round-i, info.CurrentRound)
+		})
+
+		for i := 0; i < batch; i++ {
+			if errs[i] != nil {
+				return nil, fmt.Errorf("查詢第 %d 輪開獎結果失敗: %w", round-i, errs[i])
+			}
+			if results[i] != nil {
+				history.Rounds = append(history.Rounds, RoundResult{Round: round - i, LotteryResult: *results[i]})
+			}
+		}
+		round -= batch
+	}
+
+	if round >= 1 {
+		history.NextCursor = round + 1
+	}
+	return history, nil
+}
+
+// getRoundResult 查詢輪次的開獎結果，currentRound 之前沒有結果的輪次記錄為已結束
+func (s *Service) getRoundResult(round, currentRound int) (*ton.LotteryResult, error) {
+	result, err := s.GetWinner(round)
+	if err == nil && result == nil && round < currentRound {
+		s.updateRound(round, func(r *store.Round) { r.Closed = true })
+	}
+	return result, err
+}
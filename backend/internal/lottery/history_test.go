@@ -0,0 +1,180 @@
+package lottery
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// winnerQueries 記錄每個輪次的 getWinner 查詢次數
+type winnerQueries struct {
+	mu     sync.Mutex
+	counts map[int]int
+}
+
+// options 返回計算 getWinner 查詢次數的模擬 toncenter 設定
+func (q *winnerQueries) options() toncenter.Options {
+	q.counts = map[int]int{}
+	return toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+		if method == "getWinner" {
+			q.mu.Lock()
+			q.counts[tvm.NewReader(args).Int()]++
+			q.mu.Unlock()
+		}
+		return nil, nil
+	}}
+}
+
+func (q *winnerQueries) count(round int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.counts[round]
+}
+
+// newHistoryConfig 每輪兩位參與者即滿額，未開獎的輪次也能開始新輪次
+func newHistoryConfig() *config.Config {
+	cfg := createTestConfig()
+	cfg.MaxParticipants = 2
+	return cfg
+}
+
+// playRounds 依序結束輪次，draws[i] 表示當前輪次是否開獎，每輪結束後開始新輪次
+func playRounds(t *testing.T, chain *emulator.Chain, service *Service, draws ...bool) {
+	t.Helper()
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+	}
+	for _, draw := range draws {
+		joinParticipants(t, chain, service.config, 2)
+		if draw {
+			sendOwnerComment(t, chain, service, "drawWinner")
+		}
+		sendOwnerComment(t, chain, service, "startNewRound")
+	}
+}
+
+// historyRounds 取出頁面中的輪次
+func historyRounds(history *RoundHistory) []int {
+	rounds := make([]int, len(history.Rounds))
+	for i, r := range history.Rounds {
+		rounds[i] = r.Round
+	}
+	return rounds
+}
+
+func TestGetRoundHistory(t *testing.T) {
+	var queries winnerQueries
+	cfg := newHistoryConfig()
+	chain := newFakeToncenter(t, cfg, queries.options())
+	service := newToncenterService(t, cfg)
+
+	// 第 3 輪沒有開獎，第 7 輪（當前輪次）尚未開獎
+	playRounds(t, chain, service, true, true, false, true, true, true)
+
+	pages := []struct {
+		cursor     int
+		rounds     []int
+		nextCursor int
+	}{
+		{0, []int{6, 5}, 5},
+		{5, []int{4, 2}, 2},
+		{2, []int{1}, 0},
+	}
+	for _, page := range pages {
+		history, err := service.GetRoundHistory(page.cursor, 2)
+		if err != nil {
+			t.Fatalf("GetRoundHistory(%d) failed: %v", page.cursor, err)
+		}
+		if got := historyRounds(history); !reflect.DeepEqual(got, page.rounds) || history.NextCursor != page.nextCursor {
+			t.Errorf("cursor %d: expected rounds %v and next cursor %d, got %v and %d",
+				page.cursor, page.rounds, page.nextCursor, got, history.NextCursor)
+		}
+		if history.CurrentRound != 7 {
+			t.Errorf("Expected current round 7, got %d", history.CurrentRound)
+		}
+	}
+
+	history, err := service.GetRoundHistory(0, 0)
+	if err != nil {
+		t.Fatalf("GetRoundHistory() failed: %v", err)
+	}
+	if got := historyRounds(history); !reflect.DeepEqual(got, []int{6, 5, 4, 2, 1}) || history.NextCursor != 0 {
+		t.Errorf("Expected all rounds in one page, got %v (next %d)", got, history.NextCursor)
+	}
+	// seed = 1700000000 + 0 + 2，中獎者為第一位參與者，nftId = 6*1000 + 2
+	if r := history.Rounds[0]; !address.Equal(r.Winner, testParticipant(0)) || r.NFTId != 6002 || r.Timestamp != 1700000000 {
+		t.Errorf("Unexpected round 6 result: %+v", r)
+	}
+
+	// 已結束的輪次只查詢一次，當前輪次每次都重新查詢
+	for round := 1; round <= 6; round++ {
+		if queries.count(round) != 1 {
+			t.Errorf("Expected round %d to be queried once, got %d", round, queries.count(round))
+		}
+	}
+	if queries.count(7) != 2 {
+		t.Errorf("Expected current round to be queried on each first page, got %d", queries.count(7))
+	}
+}
+
+func TestGetWinnerCache(t *testing.T) {
+	var queries winnerQueries
+	cfg := newHistoryConfig()
+	chain := newFakeToncenter(t, cfg, queries.options())
+	service := newToncenterService(t, cfg)
+	playRounds(t, chain, service, true)
+
+	for i := 0; i < 3; i++ {
+		if winner, err := service.GetWinner(1); err != nil || winner == nil || winner.NFTId != 1002 {
+			t.Fatalf("GetWinner(1) = %+v, %v", winner, err)
+		}
+		if winner, err := service.GetWinner(2); err != nil || winner != nil {
+			t.Fatalf("GetWinner(2) = %+v, %v", winner, err)
+		}
+	}
+
+	// 當前輪次開獎後應能查到結果
+	joinParticipants(t, chain, cfg, 2)
+	sendOwnerComment(t, chain, service, "drawWinner")
+	if winner, err := service.GetWinner(2); err != nil || winner == nil || winner.NFTId != 2002 {
+		t.Fatalf("GetWinner(2) after draw = %+v, %v", winner, err)
+	}
+
+	if queries.count(1) != 1 || queries.count(2) != 4 {
+		t.Errorf("Expected 1 query for round 1 and 4 for round 2, got %d and %d", queries.count(1), queries.count(2))
+	}
+}
+
+func TestRoundHistoryPersisted(t *testing.T) {
+	var queries winnerQueries
+	cfg := newHistoryConfig()
+	cfg.StoreFile = filepath.Join(t.TempDir(), "lottery.json")
+	chain := newFakeToncenter(t, cfg, queries.options())
+	service := newToncenterService(t, cfg)
+	playRounds(t, chain, service, true, false)
+
+	if _, err := service.GetRoundHistory(0, 0); err != nil {
+		t.Fatalf("GetRoundHistory() failed: %v", err)
+	}
+
+	// 重啟後已結束的輪次從資料檔讀取，只有當前輪次需要查詢合約
+	restarted := newToncenterService(t, cfg)
+	history, err := restarted.GetRoundHistory(0, 0)
+	if err != nil {
+		t.Fatalf("GetRoundHistory() after restart failed: %v", err)
+	}
+	if got := historyRounds(history); !reflect.DeepEqual(got, []int{1}) || history.Rounds[0].NFTId != 1002 {
+		t.Errorf("Expected round 1 from the store, got %+v", history.Rounds)
+	}
+
+	if queries.count(1) != 1 || queries.count(2) != 1 || queries.count(3) != 2 {
+		t.Errorf("Expected ended rounds to be queried once, got %d, %d and %d", queries.count(1), queries.count(2), queries.count(3))
+	}
+}
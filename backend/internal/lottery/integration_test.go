@@ -2,119 +2,54 @@ package lottery
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
-
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
 	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
-// TestLotteryFlow 測試完整的抽獎流程
-func TestLotteryFlow(t *testing.T) {
-	// 創建模擬的 TON API 服務器
-	callCount := 0
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-		var response ton.APIResponse
-
-		if strings.Contains(r.URL.Path, "runGetMethod") {
-			// 模擬合約狀態查詢
-			if callCount <= 2 {
-				// 初始狀態：抽獎活躍，參與者不足
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 5,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 1,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-			} else if callCount <= 4 {
-				// 中間狀態：達到最小參與者數量
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 5,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 5,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-			} else if strings.Contains(r.RequestURI, "getWinner") {
-				// 查詢中獎者
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"winner": "EQWinner123",
-						"nft_id": 42,
-						"timestamp": 1640995200
-					}`),
-				}
-			} else {
-				// 抽獎完成後：抽獎未活躍
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 5,
-						"current_round": 2,
-						"lottery_active": false,
-						"participant_count": 0,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-			}
-		} else if strings.Contains(r.URL.Path, "sendBoc") {
-			// 模擬交易發送
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"hash": "0x123456789abcdef"
-				}`),
-			}
-		} else if strings.Contains(r.URL.Path, "getTransactions") {
-			// 模擬交易狀態查詢
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`[{
-					"hash": "0x123456789abcdef",
-					"success": true
-				}]`),
-			}
-		} else {
-			// 默認回應
-			response = ton.APIResponse{
-				Ok:     true,
-				Result: json.RawMessage(`{}`),
-			}
-		}
+// newFakeToncenter 啟動以模擬鏈回應的 toncenter，並將 cfg.TONAPIEndpoint 指向它
+//
+// 服務經由 ton.Client 與 HTTP 存取鏈上狀態，交易為錢包簽名的真實 BOC。
+func newFakeToncenter(t *testing.T, cfg *config.Config, options toncenter.Options) *emulator.Chain {
+	t.Helper()
+	chain := newEmulatedChain(t, cfg)
+
+	server := httptest.NewServer(toncenter.NewServer(chain, options, logger.New("error")))
+	t.Cleanup(server.Close)
+	cfg.TONAPIEndpoint = server.URL + "/api/v2/"
+	return chain
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
+// newToncenterService 創建經由 cfg.TONAPIEndpoint 存取鏈上狀態的服務，通常先以 newFakeToncenter 啟動模擬節點
+//
+// 同一個 cfg 可以創建多個服務，例如模擬重啟後讀取同一個資料檔。
+func newToncenterService(t *testing.T, cfg *config.Config) *Service {
+	t.Helper()
+	service, err := NewService(cfg, logger.New("error"))
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	service.txMonitor.SetPollInterval(time.Millisecond)
+	t.Cleanup(service.Stop)
+	return service
+}
 
+// TestLotteryFlow 測試完整的抽獎流程
+func TestLotteryFlow(t *testing.T) {
 	// 創建測試配置
 	cfg := &config.Config{
 		Environment:            "test",
 		LogLevel:               "debug",
-		TONAPIEndpoint:         server.URL + "/",
 		TONNetwork:             "testnet",
-		LotteryContractAddress: "EQLotteryTest123",
-		NFTContractAddress:     "EQNFTTest456",
+		LotteryContractAddress: testLotteryAddress,
+		NFTContractAddress:     testNFTAddress,
 		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		DrawInterval:           100 * time.Millisecond,
 		MaxParticipants:        5,
@@ -125,6 +60,9 @@ func TestLotteryFlow(t *testing.T) {
 		RetryDelay:             50 * time.Millisecond,
 	}
 
+	// 模擬 toncenter，外部訊息延遲上鏈
+	chain := newFakeToncenter(t, cfg, toncenter.Options{Latency: 20 * time.Millisecond})
+
 	log := logger.New(cfg.LogLevel)
 
 	// 創建抽獎服務
@@ -132,6 +70,7 @@ func TestLotteryFlow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewService() failed: %v", err)
 	}
+	service.txMonitor.SetPollInterval(10 * time.Millisecond)
 
 	// 啟動服務（但不啟用自動抽獎）
 	err = service.Start()
@@ -167,28 +106,36 @@ func TestLotteryFlow(t *testing.T) {
 		t.Errorf("Expected error about insufficient participants, got: %v", err)
 	}
 
-	// 步驟3：模擬參與者達到條件後執行抽獎
+	// 步驟3：設定 NFT 合約並加入參與者後執行抽獎
 	t.Log("步驟3：參與者達到條件後執行抽獎")
-	// 注意：由於 callCount 會增加，模擬服務器會返回足夠的參與者數量
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+	}
+	for _, addr := range []string{testWinnerAddress, testOwnerAddress} {
+		chain.Fund(addr, tonToNano(1))
+		if err := chain.Join(testLotteryAddress, addr, tonToNano(cfg.EntryFeeTON)); err != nil {
+			t.Fatalf("Join(%s) failed: %v", addr, err)
+		}
+	}
 
 	err = service.SendDrawWinner()
 	if err != nil {
 		t.Fatalf("SendDrawWinner() failed when participants sufficient: %v", err)
 	}
 
-	// 步驟4：查詢中獎結果
+	// 步驟4：查詢中獎結果（seed = 1700000000 + 0 + 2，中獎者為第一位參與者）
 	t.Log("步驟4：查詢中獎結果")
 	winner, err := service.GetWinner(1)
 	if err != nil {
 		t.Fatalf("GetWinner() failed: %v", err)
 	}
 
-	if winner.Winner != "EQWinner123" {
-		t.Errorf("Expected winner='EQWinner123', got %s", winner.Winner)
+	if winner == nil || !address.Equal(winner.Winner, testWinnerAddress) {
+		t.Fatalf("Expected winner=%s, got %+v", testWinnerAddress, winner)
 	}
 
-	if winner.NFTId != 42 {
-		t.Errorf("Expected NFT ID=42, got %d", winner.NFTId)
+	if winner.NFTId != 1002 {
+		t.Errorf("Expected NFT ID=1002, got %d", winner.NFTId)
 	}
 
 	// 步驟5：開始新輪次
@@ -198,6 +145,14 @@ func TestLotteryFlow(t *testing.T) {
 		t.Fatalf("SendStartNewRound() failed: %v", err)
 	}
 
+	contractInfo, err = service.GetContractInfo()
+	if err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if !contractInfo.LotteryActive || contractInfo.CurrentRound != 2 || contractInfo.ParticipantCount != 0 {
+		t.Errorf("Expected round 2 to be active, got %+v", contractInfo)
+	}
+
 	// 步驟6：驗證服務狀態
 	t.Log("步驟6：驗證服務狀態")
 	status := service.GetStatus()
@@ -210,111 +165,63 @@ func TestLotteryFlow(t *testing.T) {
 		t.Error("Expected non-empty wallet address")
 	}
 
+	// 參加費用扣除 drawWinner 鑄造 NFT 的 0.05 TON，再加上 SetNFTContract 訊息帶入的金額
 	balance, err := service.GetContractBalance()
 	if err != nil {
 		t.Fatalf("GetContractBalance() failed: %v", err)
 	}
-	_ = balance // 餘額可能為任何值，只要不出錯即可
+	if expected, _ := chain.GetAddressBalance(context.Background(), testLotteryAddress); balance != expected || balance <= 0 {
+		t.Errorf("Expected contract balance %d, got %d", expected, balance)
+	}
 
 	t.Log("✅ 完整抽獎流程測試成功")
 }
 
 // TestAutoDrawFlow 測試自動抽獎流程
 func TestAutoDrawFlow(t *testing.T) {
-	drawExecuted := false
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var response ton.APIResponse
-
-		if strings.Contains(r.URL.Path, "runGetMethod") {
-			// 總是返回達到最大參與者數量的狀態
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"owner": "EQOwner123",
-					"entry_fee": 100000000,
-					"max_participants": 3,
-					"current_round": 1,
-					"lottery_active": true,
-					"participant_count": 3,
-					"nft_contract": "EQNFTTest456"
-				}`),
-			}
-		} else if strings.Contains(r.URL.Path, "sendBoc") {
-			// 標記抽獎已執行
-			drawExecuted = true
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"hash": "0x123456789abcdef"
-				}`),
-			}
-		} else if strings.Contains(r.URL.Path, "getTransactions") {
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`[{
-					"hash": "0x123456789abcdef",
-					"success": true
-				}]`),
-			}
-		} else if strings.Contains(r.RequestURI, "getWinner") {
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"winner": "EQAutoWinner123",
-					"nft_id": 99,
-					"timestamp": 1640995200
-				}`),
-			}
-		} else {
-			response = ton.APIResponse{
-				Ok:     true,
-				Result: json.RawMessage(`{}`),
-			}
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
-
 	cfg := &config.Config{
 		Environment:            "test",
 		LogLevel:               "debug",
-		TONAPIEndpoint:         server.URL + "/",
 		TONNetwork:             "testnet",
-		LotteryContractAddress: "EQLotteryTest123",
-		NFTContractAddress:     "EQNFTTest456",
+		LotteryContractAddress: testLotteryAddress,
+		NFTContractAddress:     testNFTAddress,
 		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		DrawInterval:           200 * time.Millisecond, // 短間隔用於測試
-		MaxParticipants:        3,
+		MaxParticipants:        10,
 		MinParticipants:        2,
 		EntryFeeTON:            0.1,
 		AutoDraw:               true, // 啟用自動抽獎
 		RetryCount:             3,
 		RetryDelay:             50 * time.Millisecond,
 	}
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
 
-	log := logger.New(cfg.LogLevel)
-
-	service, err := NewService(cfg, log)
-	if err != nil {
-		t.Fatalf("NewService() failed: %v", err)
+	// 服務的最大參與者數量低於合約，合約仍在進行中而服務認為已滿額
+	cfg.MaxParticipants = 3
+	service := newToncenterService(t, cfg)
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
 	}
+	joinParticipants(t, chain, cfg, 3)
 
 	// 啟動服務（自動抽獎將被啟用）
-	err = service.Start()
+	err := service.Start()
 	if err != nil {
 		t.Fatalf("Start() failed: %v", err)
 	}
 
-	// 等待足夠時間讓自動抽獎執行
-	time.Sleep(1 * time.Second)
+	// 等待自動抽獎執行
+	var winner *ton.LotteryResult
+	for deadline := time.Now().Add(5 * time.Second); winner == nil && time.Now().Before(deadline); {
+		time.Sleep(50 * time.Millisecond)
+		winner, _ = chain.GetWinner(context.Background(), testLotteryAddress, 1)
+	}
 
 	// 停止服務
 	service.Stop()
 
 	// 驗證自動抽獎是否執行
-	if !drawExecuted {
+	if winner == nil {
 		t.Error("Expected auto draw to be executed")
 	}
 
@@ -323,25 +230,12 @@ func TestAutoDrawFlow(t *testing.T) {
 
 // TestErrorHandling 測試錯誤處理
 func TestErrorHandling(t *testing.T) {
-	// 創建會返回錯誤的服務器
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := ton.APIResponse{
-			Ok:    false,
-			Error: "Network error",
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
-
 	cfg := &config.Config{
 		Environment:            "test",
 		LogLevel:               "debug",
-		TONAPIEndpoint:         server.URL + "/",
 		TONNetwork:             "testnet",
-		LotteryContractAddress: "EQLotteryTest123",
-		NFTContractAddress:     "EQNFTTest456",
+		LotteryContractAddress: testLotteryAddress,
+		NFTContractAddress:     testNFTAddress,
 		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		DrawInterval:           1 * time.Second,
 		MaxParticipants:        5,
@@ -352,6 +246,9 @@ func TestErrorHandling(t *testing.T) {
 		RetryDelay:             50 * time.Millisecond,
 	}
 
+	// 所有請求都返回錯誤的模擬 toncenter
+	newFakeToncenter(t, cfg, toncenter.Options{ErrorRate: 1})
+
 	log := logger.New(cfg.LogLevel)
 
 	service, err := NewService(cfg, log)
@@ -401,16 +298,12 @@ func TestErrorHandling(t *testing.T) {
 
 // TestConcurrentOperations 測試並發操作
 func TestConcurrentOperations(t *testing.T) {
-	server := createMockServer()
-	defer server.Close()
-
 	cfg := &config.Config{
 		Environment:            "test",
 		LogLevel:               "debug",
-		TONAPIEndpoint:         server.URL + "/",
 		TONNetwork:             "testnet",
-		LotteryContractAddress: "EQLotteryTest123",
-		NFTContractAddress:     "EQNFTTest456",
+		LotteryContractAddress: testLotteryAddress,
+		NFTContractAddress:     testNFTAddress,
 		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		DrawInterval:           1 * time.Second,
 		MaxParticipants:        5,
@@ -420,6 +313,7 @@ func TestConcurrentOperations(t *testing.T) {
 		RetryCount:             3,
 		RetryDelay:             50 * time.Millisecond,
 	}
+	newFakeToncenter(t, cfg, toncenter.Options{})
 
 	log := logger.New(cfg.LogLevel)
 
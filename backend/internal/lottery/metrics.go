@@ -0,0 +1,108 @@
+package lottery
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/pkg/metrics"
+)
+
+// metricsRefreshInterval /metrics 觸發重新查詢鏈上狀態的最短間隔，避免頻繁抓取時對 toncenter 造成負擔
+const metricsRefreshInterval = 15 * time.Second
+
+// 抽獎結果
+var (
+	drawAttempts = metrics.NewCounterVec(
+		"lottery_draw_attempts_total",
+		"Draw attempts, including those rejected before sending a transaction.",
+	)
+	drawSuccesses = metrics.NewCounterVec(
+		"lottery_draw_successes_total",
+		"Draw transactions confirmed on chain.",
+	)
+	// reason: invalid_state（合約狀態不允許抽獎）、low_balance（錢包餘額不足）、
+	// send（查詢狀態或發送交易失敗）、confirmation（交易已發送但未成功確認）
+	drawFailures = metrics.NewCounterVec(
+		"lottery_draw_failures_total",
+		"Failed draw attempts by reason.",
+		"reason",
+	)
+)
+
+// 最後一次查詢到的鏈上狀態
+var (
+	currentRoundGauge = metrics.NewGauge(
+		"lottery_current_round",
+		"Current lottery round.",
+	)
+	participantCountGauge = metrics.NewGauge(
+		"lottery_participant_count",
+		"Participants in the current round.",
+	)
+	contractBalanceGauge = metrics.NewGauge(
+		"lottery_contract_balance_nanoton",
+		"Lottery contract balance in nanoTON.",
+	)
+	walletBalanceGauge = metrics.NewGauge(
+		"lottery_wallet_balance_nanoton",
+		"Backend wallet balance in nanoTON.",
+	)
+)
+
+// recordDraw 記錄一次抽獎的結果，已有其他操作進行中時不算一次嘗試
+func recordDraw(result *OperationResult, err error) {
+	if errors.Is(err, ErrOperationInProgress) {
+		return
+	}
+
+	drawAttempts.Inc()
+	switch {
+	case err == nil:
+		drawSuccesses.Inc()
+	case errors.Is(err, ErrInvalidState):
+		drawFailures.Inc("invalid_state")
+	case errors.Is(err, ErrLowWalletBalance):
+		drawFailures.Inc("low_balance")
+	case result == nil:
+		drawFailures.Inc("send")
+	default:
+		drawFailures.Inc("confirmation")
+	}
+}
+
+// observeContractInfo 以查詢到的合約狀態更新 gauge
+func observeContractInfo(info *ton.LotteryContractInfo) {
+	currentRoundGauge.Set(float64(info.CurrentRound))
+	participantCountGauge.Set(float64(info.ParticipantCount))
+}
+
+// RefreshMetrics 重新查詢合約狀態、合約餘額與錢包餘額並更新 gauge
+//
+// 距離上次重新查詢未滿 metricsRefreshInterval 時不查詢；查詢失敗的項目保留上次的數值。
+func (s *Service) RefreshMetrics(ctx context.Context) {
+	now := time.Now()
+	last := s.metricsRefreshed.Load()
+	if now.Sub(time.Unix(0, last)) < metricsRefreshInterval || !s.metricsRefreshed.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	if info, err := s.chain.GetLotteryContractInfo(ctx, s.config.LotteryContractAddress); err == nil {
+		observeContractInfo(info)
+	} else {
+		s.logger.Warn("更新指標時查詢合約狀態失敗", "error", err)
+	}
+
+	if balance, err := s.chain.GetContractBalance(ctx, s.config.LotteryContractAddress); err == nil {
+		contractBalanceGauge.Set(float64(balance))
+	} else {
+		s.logger.Warn("更新指標時查詢合約餘額失敗", "error", err)
+	}
+
+	if balance, err := s.wallet.GetBalance(ctx); err == nil {
+		walletBalanceGauge.Set(float64(balance))
+	} else {
+		s.logger.Warn("更新指標時查詢錢包餘額失敗", "error", err)
+	}
+}
@@ -0,0 +1,94 @@
+package lottery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+func TestRecordDraw(t *testing.T) {
+	reasons := []string{"invalid_state", "low_balance", "send", "confirmation"}
+	attempts, successes := drawAttempts.Value(), drawSuccesses.Value()
+	failures := map[string]uint64{}
+	for _, reason := range reasons {
+		failures[reason] = drawFailures.Value(reason)
+	}
+
+	sent := &OperationResult{Operation: OperationDrawWinner, TxHash: "hash"}
+	recordDraw(sent, nil)
+	recordDraw(nil, ErrOperationInProgress) // 不算一次嘗試
+	recordDraw(nil, ErrInvalidState)
+	recordDraw(nil, ErrLowWalletBalance)
+	recordDraw(nil, errors.New("發送抽獎交易失敗"))
+	recordDraw(sent, errors.New("抽獎交易監控失敗"))
+
+	if got := drawAttempts.Value() - attempts; got != 5 {
+		t.Errorf("Expected 5 attempts, got %d", got)
+	}
+	if got := drawSuccesses.Value() - successes; got != 1 {
+		t.Errorf("Expected 1 success, got %d", got)
+	}
+	for _, reason := range reasons {
+		if got := drawFailures.Value(reason) - failures[reason]; got != 1 {
+			t.Errorf("Expected 1 failure with reason %s, got %d", reason, got)
+		}
+	}
+}
+
+func TestExecuteDrawWinnerMetrics(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.MinParticipants = 5 // 合約只有 3 位參與者
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	joinParticipants(t, chain, cfg, 3)
+	service := newToncenterService(t, cfg)
+
+	attempts, failures := drawAttempts.Value(), drawFailures.Value("invalid_state")
+	if _, err := service.ExecuteDrawWinner(); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("Expected ErrInvalidState, got %v", err)
+	}
+	if drawAttempts.Value()-attempts != 1 || drawFailures.Value("invalid_state")-failures != 1 {
+		t.Error("Expected the rejected draw to be counted as an invalid_state failure")
+	}
+
+	// 查詢合約狀態時同步更新 gauge
+	if round, ok := currentRoundGauge.Value(); !ok || round != 1 {
+		t.Errorf("lottery_current_round = %v (set=%v), want 1", round, ok)
+	}
+	if count, ok := participantCountGauge.Value(); !ok || count != 3 {
+		t.Errorf("lottery_participant_count = %v (set=%v), want 3", count, ok)
+	}
+}
+
+func TestRefreshMetrics(t *testing.T) {
+	cfg := createTestConfig()
+	fake := toncenter.NewServer(newEmulatedChain(t, cfg), toncenter.Options{}, logger.New("error"))
+
+	// 計算模擬 toncenter 收到的請求數
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	cfg.TONAPIEndpoint = server.URL + "/api/v2/"
+	service := newToncenterService(t, cfg)
+
+	service.RefreshMetrics(context.Background())
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected 3 requests (contract info, contract balance, wallet balance), got %d", got)
+	}
+	if balance, ok := walletBalanceGauge.Value(); !ok || balance != 10000000000 {
+		t.Errorf("lottery_wallet_balance_nanoton = %v (set=%v), want 10000000000", balance, ok)
+	}
+
+	// 間隔內不重複查詢
+	service.RefreshMetrics(context.Background())
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected refresh to be throttled, got %d requests", got)
+	}
+}
@@ -0,0 +1,194 @@
+package lottery
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+const (
+	// getMethodConcurrency 批次查詢（參與者、歷史輪次）時同時執行的最大 get 方法請求數，
+	// 避免觸發 toncenter 的速率限制
+	getMethodConcurrency = 4
+
+	// participantListAttempts 合約狀態在查詢期間改變時的最大嘗試次數
+	participantListAttempts = 3
+)
+
+// ErrParticipantsChanged 查詢參與者期間合約的輪次或參與人數持續改變
+var ErrParticipantsChanged = errors.New("查詢期間參與者列表持續變化")
+
+// IndexedParticipant 參與者與其在合約中的索引
+type IndexedParticipant struct {
+	Index int `json:"index"`
+	ton.Participant
+}
+
+// ParticipantError 單一索引的查詢錯誤
+type ParticipantError struct {
+	Index int
+	Err   error
+}
+
+func (e *ParticipantError) Error() string {
+	return fmt.Sprintf("查詢第 %d 位參與者失敗: %v", e.Index, e.Err)
+}
+
+func (e *ParticipantError) Unwrap() error {
+	return e.Err
+}
+
+// ParticipantList 當前輪次的參與者列表
+//
+// Participants 依索引排序，不包含合約返回 null 的索引（開獎後合約會留下空項目）。
+// 查詢失敗的索引記錄在 Failed，呼叫端可以選擇顯示部分結果或重試。
+type ParticipantList struct {
+	Round            int
+	ParticipantCount int
+	Participants     []IndexedParticipant
+	Failed           []*ParticipantError
+}
+
+// Complete 所有索引是否都查詢成功
+func (l *ParticipantList) Complete() bool {
+	return len(l.Failed) == 0
+}
+
+// ListParticipants 查詢當前輪次的所有參與者
+//
+// 先讀取 participantCount，再以有限的並發查詢每個索引。查詢完成後重新讀取合約狀態，
+// 輪次或參與人數改變時（有人加入或已開獎）重新查詢，確保列表對應同一個合約狀態。
+// 單一索引失敗不會中斷查詢，而是記錄在 ParticipantList.Failed。
+// 查詢到的參與者會寫入資料檔，開獎後合約清空參與者時仍可由 GetRoundParticipants 查詢。
+func (s *Service) ListParticipants() (*ParticipantList, error) {
+	info, err := s.GetContractInfo()
+	if err != nil {
+		return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		list := s.fetchParticipants(info)
+
+		after, err := s.GetContractInfo()
+		if err != nil {
+			return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
+		}
+		if after.CurrentRound == info.CurrentRound && after.ParticipantCount == info.ParticipantCount {
+			if !list.Complete() {
+				s.logger.Warn("部分參與者查詢失敗",
+					"round", list.Round,
+					"participant_count", list.ParticipantCount,
+					"failed", len(list.Failed),
+				)
+			}
+			s.saveParticipants(list)
+			return list, nil
+		}
+
+		if attempt >= participantListAttempts {
+			return nil, fmt.Errorf("%w: 已嘗試 %d 次", ErrParticipantsChanged, attempt)
+		}
+
+		s.logger.Debug("查詢期間合約狀態改變，重新查詢參與者",
+			"round", info.CurrentRound, "new_round", after.CurrentRound,
+			"participant_count", info.ParticipantCount, "new_participant_count", after.ParticipantCount,
+		)
+		info = after
+	}
+}
+
+// fetchParticipants 以有限的並發查詢 info.ParticipantCount 個索引
+func (s *Service) fetchParticipants(info *ton.LotteryContractInfo) *ParticipantList {
+	count := info.ParticipantCount
+	participants := make([]*ton.Participant, count)
+	errs := make([]error, count)
+
+	forEachConcurrent(count, func(i int) {
+		participants[i], errs[i] = s.GetParticipant(i)
+	})
+
+	list := &ParticipantList{
+		Round:            info.CurrentRound,
+		ParticipantCount: count,
+		Participants:     make([]IndexedParticipant, 0, count),
+	}
+	for i := 0; i < count; i++ {
+		switch {
+		case errs[i] != nil:
+			list.Failed = append(list.Failed, &ParticipantError{Index: i, Err: errs[i]})
+		case participants[i] != nil:
+			list.Participants = append(list.Participants, IndexedParticipant{Index: i, Participant: *participants[i]})
+		}
+	}
+	return list
+}
+
+// saveParticipants 將查詢到的參與者與參與人數寫入資料檔，寫入失敗只記錄錯誤
+func (s *Service) saveParticipants(list *ParticipantList) {
+	participants := make([]store.Participant, 0, len(list.Participants))
+	for _, p := range list.Participants {
+		participants = append(participants, store.Participant{Round: list.Round, Index: p.Index, Participant: p.Participant})
+	}
+	if len(participants) > 0 {
+		if err := s.store.SaveParticipants(list.Round, participants); err != nil {
+			s.logger.Error("保存參與者記錄失敗", "round", list.Round, "error", err)
+		}
+	}
+
+	// 開獎後合約的參與人數可能歸零，保留先前記錄的人數
+	s.updateRound(list.Round, func(r *store.Round) {
+		r.ParticipantCount = max(r.ParticipantCount, list.ParticipantCount)
+	})
+}
+
+// GetRoundParticipants 從資料檔查詢輪次的參與者，依索引排序
+//
+// 只包含服務曾經查詢到的參與者；合約在開獎後會清空參與者，已結束的輪次只能由此查詢。
+func (s *Service) GetRoundParticipants(round int) ([]IndexedParticipant, error) {
+	records, err := s.store.ListParticipants(round)
+	if err != nil {
+		return nil, fmt.Errorf("讀取第 %d 輪參與者記錄失敗: %w", round, err)
+	}
+
+	participants := make([]IndexedParticipant, 0, len(records))
+	for _, record := range records {
+		participants = append(participants, IndexedParticipant{Index: record.Index, Participant: record.Participant})
+	}
+	return participants, nil
+}
+
+// FindRoundParticipant 以地址查找資料檔中記錄的指定輪次參與者，沒有記錄時返回 nil
+//
+// 地址以帳戶比較，原始格式與可退回、不可退回的使用者友好格式都能找到同一位參與者。
+func (s *Service) FindRoundParticipant(round int, addr string) (*IndexedParticipant, error) {
+	participants, err := s.GetRoundParticipants(round)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range participants {
+		if participants[i].HasAddress(addr) {
+			return &participants[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// forEachConcurrent 對 0..n-1 執行 fn，最多同時執行 getMethodConcurrency 個，全部完成後返回
+func forEachConcurrent(n int, fn func(i int)) {
+	sem := make(chan struct{}, getMethodConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
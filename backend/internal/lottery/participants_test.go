@@ -0,0 +1,197 @@
+package lottery
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// participantIndex 返回 getParticipant 請求的索引，其他方法返回 -1
+func participantIndex(method string, args tvm.Args) int {
+	if method != "getParticipant" {
+		return -1
+	}
+	return tvm.NewReader(args).Int()
+}
+
+func TestListParticipants(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	cfg := createTestConfig()
+	chain := newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+		index := participantIndex(method, args)
+		if index < 0 {
+			return nil, nil
+		}
+
+		// 記錄同時進行中的 getParticipant 請求數
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if index == 4 {
+			return ton.EncodeParticipant(nil)
+		}
+		return nil, nil
+	}})
+	joinParticipants(t, chain, cfg, 10)
+	service := newToncenterService(t, cfg)
+
+	list, err := service.ListParticipants()
+	if err != nil {
+		t.Fatalf("ListParticipants() failed: %v", err)
+	}
+
+	if list.Round != 1 || list.ParticipantCount != 10 {
+		t.Errorf("Expected round 1 with 10 participants, got %d and %d", list.Round, list.ParticipantCount)
+	}
+	if !list.Complete() {
+		t.Errorf("Expected complete list, failed: %v", list.Failed)
+	}
+
+	// null 項目被略過，其餘依索引排序
+	if len(list.Participants) != 9 {
+		t.Fatalf("Expected 9 participants, got %d", len(list.Participants))
+	}
+	for i, p := range list.Participants {
+		index := i
+		if i >= 4 {
+			index++
+		}
+		if p.Index != index || !address.Equal(p.Address, testParticipant(index)) || p.Amount != tonToNano(cfg.EntryFeeTON) {
+			t.Errorf("participants[%d] = %+v", i, p)
+		}
+	}
+
+	if peak := maxInFlight.Load(); peak > getMethodConcurrency {
+		t.Errorf("Expected at most %d concurrent requests, got %d", getMethodConcurrency, peak)
+	}
+}
+
+func TestListParticipantsPartialFailure(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.RetryCount = 1
+	chain := newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+		if participantIndex(method, args) == 1 {
+			return nil, errors.New("method failed")
+		}
+		return nil, nil
+	}})
+	joinParticipants(t, chain, cfg, 3)
+	service := newToncenterService(t, cfg)
+
+	list, err := service.ListParticipants()
+	if err != nil {
+		t.Fatalf("ListParticipants() failed: %v", err)
+	}
+
+	if list.Complete() || len(list.Failed) != 1 || list.Failed[0].Index != 1 {
+		t.Fatalf("Expected index 1 to fail, got %v", list.Failed)
+	}
+	if len(list.Participants) != 2 || list.Participants[0].Index != 0 || list.Participants[1].Index != 2 {
+		t.Errorf("Unexpected participants: %+v", list.Participants)
+	}
+}
+
+func TestGetRoundParticipants(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.RetryCount = 1
+	chain := newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+		if participantIndex(method, args) == 2 {
+			return nil, errors.New("method failed")
+		}
+		return nil, nil
+	}})
+	joinParticipants(t, chain, cfg, 3)
+	service := newToncenterService(t, cfg)
+
+	if participants, err := service.GetRoundParticipants(1); err != nil || len(participants) != 0 {
+		t.Fatalf("Expected no recorded participants yet, got %+v (%v)", participants, err)
+	}
+
+	if _, err := service.ListParticipants(); err != nil {
+		t.Fatalf("ListParticipants() failed: %v", err)
+	}
+
+	// 查詢成功的參與者已記錄，失敗的索引不會留下記錄
+	participants, err := service.GetRoundParticipants(1)
+	if err != nil {
+		t.Fatalf("GetRoundParticipants() failed: %v", err)
+	}
+	if len(participants) != 2 || participants[0].Index != 0 || participants[1].Index != 1 || !address.Equal(participants[1].Address, testParticipant(1)) {
+		t.Errorf("Unexpected recorded participants: %+v", participants)
+	}
+
+	// 以原始格式查找，查詢失敗的索引沒有記錄
+	raw := address.MustParse(testParticipant(1)).StringRaw()
+	if p, err := service.FindRoundParticipant(1, raw); err != nil || p == nil || p.Index != 1 {
+		t.Errorf("FindRoundParticipant(1) = %+v, %v", p, err)
+	}
+	if p, err := service.FindRoundParticipant(1, testParticipant(2)); err != nil || p != nil {
+		t.Errorf("FindRoundParticipant(2) = %+v, %v", p, err)
+	}
+
+	round, err := service.store.GetRound(1)
+	if err != nil || round.ParticipantCount != 3 {
+		t.Errorf("Expected round 1 with 3 participants, got %+v (%v)", round, err)
+	}
+}
+
+func TestListParticipantsStateChanged(t *testing.T) {
+	t.Run("retry after new participant", func(t *testing.T) {
+		cfg := createTestConfig()
+		var chain *emulator.Chain
+		var joined sync.Once
+		chain = newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+			// 第一次查詢參與者期間有新的參與者加入
+			if participantIndex(method, args) >= 0 {
+				var err error
+				joined.Do(func() { err = joinParticipant(chain, cfg, 2) })
+				return nil, err
+			}
+			return nil, nil
+		}})
+		joinParticipants(t, chain, cfg, 2)
+		service := newToncenterService(t, cfg)
+
+		list, err := service.ListParticipants()
+		if err != nil {
+			t.Fatalf("ListParticipants() failed: %v", err)
+		}
+		if list.ParticipantCount != 3 || len(list.Participants) != 3 {
+			t.Errorf("Expected the list to be refetched with 3 participants, got %+v", list)
+		}
+	})
+
+	t.Run("keeps changing", func(t *testing.T) {
+		cfg := createTestConfig()
+		var chain *emulator.Chain
+		var joins atomic.Int32
+		chain = newFakeToncenter(t, cfg, toncenter.Options{GetMethodHook: func(method string, args tvm.Args) (tvm.Stack, error) {
+			// 每次讀取合約狀態前都有新的參與者加入
+			if method == "getContractInfo" {
+				return nil, joinParticipant(chain, cfg, int(joins.Add(1)))
+			}
+			return nil, nil
+		}})
+		joinParticipants(t, chain, cfg, 1)
+		service := newToncenterService(t, cfg)
+
+		if _, err := service.ListParticipants(); !errors.Is(err, ErrParticipantsChanged) {
+			t.Fatalf("Expected ErrParticipantsChanged, got %v", err)
+		}
+	})
+}
@@ -2,17 +2,54 @@ package lottery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/indexer"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/stream"
 	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
 	"ton-cat-lottery-backend/internal/transaction"
 	"ton-cat-lottery-backend/internal/wallet"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
+var (
+	// ErrLowWalletBalance 錢包餘額低於設定的門檻
+	ErrLowWalletBalance = errors.New("錢包餘額不足")
+
+	// ErrOperationInProgress 另一個 owner 操作（抽獎、新輪次等）正在執行
+	ErrOperationInProgress = errors.New("另一個管理操作正在執行中")
+
+	// ErrInvalidState 合約目前的狀態不允許執行此操作
+	ErrInvalidState = errors.New("合約狀態不允許此操作")
+)
+
+// owner 操作名稱
+const (
+	OperationDrawWinner     = "draw_winner"
+	OperationStartNewRound  = "start_new_round"
+	OperationWithdraw       = "withdraw"
+	OperationWithdrawNFT    = "withdraw_nft"
+	OperationSetNFTContract = "set_nft_contract"
+)
+
+// OperationResult owner 操作的交易 hash 與確認結果
+type OperationResult struct {
+	Operation    string
+	TxHash       string
+	Confirmation *transaction.Result
+
+	// Withdraw 提取操作的前後餘額，其他操作為 nil
+	Withdraw *WithdrawReport
+}
+
 // Service 抽獎服務
 type Service struct {
 	config  *config.Config
@@ -23,18 +60,41 @@ type Service struct {
 	running bool
 	mu      sync.RWMutex
 
+	// opMu 確保同一時間只執行一個 owner 操作，避免合約狀態檢查與交易交錯
+	opMu sync.Mutex
+
+	// ownerVerified 錢包是否為合約 owner，nil 表示尚未完成檢查
+	// （verifyOwner 在 wg 中執行，Stop 持有 mu 等待 wg，因此不共用 mu）
+	ownerVerified atomic.Pointer[bool]
+
+	// 自動抽獎迴圈的存活狀態，供存活檢查使用（Stop 持有 mu 等待迴圈結束，因此不共用 mu）
+	loopAlive        atomic.Bool
+	loopBeat         atomic.Int64 // 最後一次心跳 (UnixNano)
+	drawStarted      atomic.Int64 // 進行中的抽獎檢查開始時間 (UnixNano)，0 表示閒置
+	loopStallTimeout time.Duration
+
+	// metricsRefreshed 上次為 /metrics 重新查詢鏈上狀態的時間 (UnixNano)
+	metricsRefreshed atomic.Int64
+
+	// roundMu 序列化輪次記錄的讀取-修改-寫入
+	roundMu sync.Mutex
+
 	// 依賴項
-	tonClient *ton.Client
+	store     store.Repository
+	events    *stream.Broker
+	chain     ton.Chain
 	wallet    *wallet.Manager
 	txMonitor *transaction.Monitor
 }
 
-// NewService 創建新的抽獎服務
+// NewService 創建以 toncenter 存取鏈上狀態的抽獎服務
 func NewService(cfg *config.Config, log *logger.Logger) (*Service, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewServiceWithChain(cfg, log, ton.NewClient(cfg, log))
+}
 
-	// 初始化TON客戶端
-	tonClient := ton.NewClient(cfg, log)
+// NewServiceWithChain 創建以 chain 讀取鏈上狀態、發送與確認交易的抽獎服務
+func NewServiceWithChain(cfg *config.Config, log *logger.Logger, chain ton.Chain) (*Service, error) {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// 初始化錢包管理器
 	walletManager, err := wallet.NewManager(cfg, log)
@@ -43,17 +103,37 @@ func NewService(cfg *config.Config, log *logger.Logger) (*Service, error) {
 		return nil, fmt.Errorf("初始化錢包管理器失敗: %w", err)
 	}
 
-	// 初始化交易監控器
-	txMonitor := transaction.NewMonitor(cfg, log, tonClient)
+	// seqno 由錢包合約的 get 方法讀取，餘額由 getAddressInformation 查詢
+	walletManager.SetSeqnoReader(chain)
+	walletManager.SetBalanceReader(chain)
+
+	// 開啟資料檔，保存輪次、參與者與已發送的交易
+	repo, err := store.Open(cfg.StoreFile)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("開啟資料檔失敗: %w", err)
+	}
+
+	// 推送給前端的即時事件
+	events := stream.NewBroker(stream.DefaultBufferSize)
+
+	// 初始化交易監控器，確認結果寫入交易記錄並推送確認事件
+	txMonitor := transaction.NewMonitor(cfg, log, chain)
+	txMonitor.SetStore(repo)
+	txMonitor.SetEvents(events)
 
 	service := &Service{
 		config:    cfg,
 		logger:    log.WithGroup("lottery"),
 		ctx:       ctx,
 		cancel:    cancel,
-		tonClient: tonClient,
+		store:     repo,
+		events:    events,
+		chain:     chain,
 		wallet:    walletManager,
 		txMonitor: txMonitor,
+
+		loopStallTimeout: defaultLoopStallTimeout,
 	}
 
 	return service, nil
@@ -74,8 +154,21 @@ func (s *Service) Start() error {
 		"max_participants", s.config.MaxParticipants,
 	)
 
+	// 在背景確認錢包地址即為合約 owner，避免啟動被鏈上查詢阻塞
+	s.wg.Add(1)
+	go s.verifyOwner()
+
+	// 繼續追蹤重啟前尚未確認的交易
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.txMonitor.ResumePending(s.ctx, s.config.RetryCount)
+	}()
+
 	// 如果啟用自動抽獎，啟動定時器
 	if s.config.AutoDraw {
+		s.loopAlive.Store(true)
+		s.beat()
 		s.wg.Add(1)
 		go s.autoDrawLoop()
 	}
@@ -103,17 +196,60 @@ func (s *Service) Stop() {
 	// 等待所有 goroutine 結束
 	s.wg.Wait()
 
+	// 中斷即時事件的訂閱者
+	s.events.Close()
+
 	s.running = false
 	s.logger.Info("✅ 抽獎服務已停止")
 }
 
+// ownerCheckTimeout 啟動時 owner 檢查的查詢逾時
+const ownerCheckTimeout = 15 * time.Second
+
+// verifyOwner 檢查錢包地址是否與合約 owner 一致
+//
+// 不一致時所有 owner 專屬操作都會被合約拒絕，因此以錯誤等級記錄；
+// 查詢失敗則僅記錄警告，不影響服務啟動。
+func (s *Service) verifyOwner() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithTimeout(s.ctx, ownerCheckTimeout)
+	defer cancel()
+
+	info, err := s.chain.GetLotteryContractInfo(ctx, s.config.LotteryContractAddress)
+	if err != nil {
+		s.logger.Warn("無法確認合約 owner", "error", err)
+		return
+	}
+
+	walletAddress := s.wallet.GetAddress()
+	verified := address.Equal(info.Owner, walletAddress)
+
+	s.ownerVerified.Store(&verified)
+
+	if !verified {
+		s.logger.Error("❌ 錢包地址與合約 owner 不一致，owner 專屬操作將會失敗",
+			"wallet", walletAddress,
+			"wallet_version", s.wallet.GetVersion(),
+			"owner", info.Owner,
+		)
+		return
+	}
+
+	s.logger.Info("✅ 錢包地址與合約 owner 一致", "owner", info.Owner)
+}
+
 // autoDrawLoop 自動抽獎迴圈
 func (s *Service) autoDrawLoop() {
 	defer s.wg.Done()
+	defer s.loopAlive.Store(false)
 
 	ticker := time.NewTicker(s.config.DrawInterval)
 	defer ticker.Stop()
 
+	heartbeat := time.NewTicker(s.loopHeartbeatInterval())
+	defer heartbeat.Stop()
+
 	s.logger.Info("⏰ 自動抽獎計時器啟動", "interval", s.config.DrawInterval)
 
 	for {
@@ -121,11 +257,17 @@ func (s *Service) autoDrawLoop() {
 		case <-s.ctx.Done():
 			s.logger.Info("📝 自動抽獎迴圈已停止")
 			return
+		case <-heartbeat.C:
+			s.beat()
 		case <-ticker.C:
 			s.logger.Debug("⚡ 觸發自動抽獎檢查")
+			s.beat()
+			s.drawStarted.Store(time.Now().UnixNano())
 			if err := s.checkAndDraw(); err != nil {
 				s.logger.Error("自動抽獎失敗", "error", err)
 			}
+			s.drawStarted.Store(0)
+			s.beat()
 		}
 	}
 }
@@ -176,122 +318,352 @@ func (s *Service) DrawWinner() error {
 
 // SendDrawWinner 發送抽獎交易
 func (s *Service) SendDrawWinner() error {
+	_, err := s.ExecuteDrawWinner()
+	return err
+}
+
+// ExecuteDrawWinner 發送抽獎交易並等待確認，返回交易 hash 與確認結果
+//
+// 交易已發送但確認失敗時，會同時返回結果與錯誤。
+func (s *Service) ExecuteDrawWinner() (*OperationResult, error) {
+	result, err := s.executeDrawWinner()
+	recordDraw(result, err)
+	return result, err
+}
+
+func (s *Service) executeDrawWinner() (*OperationResult, error) {
+	if !s.opMu.TryLock() {
+		return nil, ErrOperationInProgress
+	}
+	defer s.opMu.Unlock()
+
 	s.logger.Info("🎲 發送抽獎交易...")
 
 	// 1. 檢查合約狀態
 	contractInfo, err := s.GetContractInfo()
 	if err != nil {
-		return fmt.Errorf("查詢合約狀態失敗: %w", err)
+		return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
 	}
 
 	if !contractInfo.LotteryActive {
-		return fmt.Errorf("抽獎未活躍")
+		return nil, fmt.Errorf("%w: 抽獎未活躍", ErrInvalidState)
 	}
 
 	if contractInfo.ParticipantCount < s.config.MinParticipants {
-		return fmt.Errorf("參與人數不足: %d < %d",
-			contractInfo.ParticipantCount, s.config.MinParticipants)
+		return nil, fmt.Errorf("%w: 參與人數不足: %d < %d",
+			ErrInvalidState, contractInfo.ParticipantCount, s.config.MinParticipants)
 	}
 
-	// 2. 創建抽獎交易
-	transaction, err := s.wallet.CreateDrawWinnerTransaction(s.config.LotteryContractAddress)
+	// 2. 創建並發送抽獎交易，監控交易結果
+	result, err := s.executeOwnerOperation(OperationDrawWinner, "抽獎", func(seqno uint32) ([]byte, error) {
+		return s.wallet.CreateDrawWinnerTransaction(s.config.LotteryContractAddress, seqno)
+	})
 	if err != nil {
-		return fmt.Errorf("創建抽獎交易失敗: %w", err)
+		return result, err
+	}
+
+	s.logger.Info("🎉 抽獎執行成功", "hash", result.TxHash, "round", contractInfo.CurrentRound)
+
+	// 查詢中獎結果
+	if winner, err := s.GetWinner(contractInfo.CurrentRound); err == nil && winner != nil {
+		s.logger.Info("🏆 中獎者",
+			"winner", winner.Winner,
+			"nft_id", winner.NFTId,
+			"round", contractInfo.CurrentRound)
+	}
+
+	return result, nil
+}
+
+// SendStartNewRound 開始新輪次
+func (s *Service) SendStartNewRound() error {
+	_, err := s.ExecuteStartNewRound()
+	return err
+}
+
+// ExecuteStartNewRound 發送開始新輪次交易並等待確認
+func (s *Service) ExecuteStartNewRound() (*OperationResult, error) {
+	if !s.opMu.TryLock() {
+		return nil, ErrOperationInProgress
 	}
+	defer s.opMu.Unlock()
+
+	s.logger.Info("🔄 開始新輪次...")
 
-	// 3. 發送交易
-	txHash, err := s.tonClient.SendTransaction(s.ctx, transaction)
+	// 1. 檢查合約狀態
+	contractInfo, err := s.GetContractInfo()
 	if err != nil {
-		return fmt.Errorf("發送抽獎交易失敗: %w", err)
+		return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
 	}
 
-	s.logger.Info("抽獎交易已發送", "hash", txHash)
+	if contractInfo.LotteryActive {
+		return nil, fmt.Errorf("%w: 當前抽獎仍在進行中，不能開始新輪次", ErrInvalidState)
+	}
 
-	// 4. 監控交易結果
-	result, err := s.txMonitor.WaitForConfirmationWithRetry(s.ctx, txHash, s.config.RetryCount)
+	// 2. 創建並發送開始新輪次交易，監控交易結果
+	result, err := s.executeOwnerOperation(OperationStartNewRound, "新輪次", func(seqno uint32) ([]byte, error) {
+		return s.wallet.CreateStartNewRoundTransaction(s.config.LotteryContractAddress, seqno)
+	})
 	if err != nil {
-		return fmt.Errorf("抽獎交易監控失敗: %w", err)
+		return result, err
 	}
 
-	if result.Status == "success" {
-		s.logger.Info("🎉 抽獎執行成功", "hash", txHash, "round", contractInfo.CurrentRound)
+	s.logger.Info("✅ 新輪次開始成功", "hash", result.TxHash, "new_round", contractInfo.CurrentRound+1)
+	s.publish(stream.EventNewRoundStarted, stream.NewRoundStarted{Round: contractInfo.CurrentRound + 1, TxHash: result.TxHash})
+	return result, nil
+}
 
-		// 查詢中獎結果
-		if winner, err := s.GetWinner(contractInfo.CurrentRound); err == nil {
-			s.logger.Info("🏆 中獎者",
-				"winner", winner.Winner,
-				"nft_id", winner.NFTId,
-				"round", contractInfo.CurrentRound)
-		}
+// ExecuteSetNFTContract 發送設定 NFT 合約地址交易並等待確認
+func (s *Service) ExecuteSetNFTContract(nftContract string) (*OperationResult, error) {
+	if _, err := address.Parse(nftContract); err != nil {
+		return nil, fmt.Errorf("無效的 NFT 合約地址: %w", err)
+	}
 
-		return nil
+	if !s.opMu.TryLock() {
+		return nil, ErrOperationInProgress
+	}
+	defer s.opMu.Unlock()
+
+	s.logger.Info("🖼️ 設定 NFT 合約...", "nft_contract", nftContract)
+
+	result, err := s.executeOwnerOperation(OperationSetNFTContract, "設定 NFT 合約", func(seqno uint32) ([]byte, error) {
+		return s.wallet.CreateSetNFTContractTransaction(s.config.LotteryContractAddress, nftContract, seqno)
+	})
+	if err != nil {
+		return result, err
 	}
 
-	return fmt.Errorf("抽獎交易失敗: %s", result.Status)
+	s.logger.Info("✅ NFT 合約設定成功", "hash", result.TxHash, "nft_contract", nftContract)
+	return result, nil
 }
 
-// SendStartNewRound 開始新輪次
-func (s *Service) SendStartNewRound() error {
-	s.logger.Info("🔄 開始新輪次...")
+// Events 返回即時事件廣播，供 HTTP API 的 SSE 與 WebSocket 端點訂閱
+func (s *Service) Events() *stream.Broker {
+	return s.events
+}
 
-	// 1. 檢查合約狀態
-	contractInfo, err := s.GetContractInfo()
-	if err != nil {
-		return fmt.Errorf("查詢合約狀態失敗: %w", err)
+// TransactionSource 返回服務的鏈上介面供事件索引器查詢交易，與服務共用同一個客戶端的頻率限制與端點狀態；
+// 鏈上介面不支援交易查詢時返回 nil
+func (s *Service) TransactionSource() indexer.TransactionSource {
+	source, _ := s.chain.(indexer.TransactionSource)
+	return source
+}
+
+// HandleChainEvents 將事件索引器解碼的合約事件推送給前端，作為 indexer.Handler 使用
+func (s *Service) HandleChainEvents(ctx context.Context, events []indexer.Event) error {
+	for _, event := range events {
+		var eventType string
+		switch event.Type {
+		case ton.EventParticipantJoined:
+			eventType = stream.EventParticipantJoined
+		case ton.EventLotteryFull:
+			eventType = stream.EventRoundFull
+		case ton.EventWinnerDrawn:
+			eventType = stream.EventWinnerDrawn
+		default:
+			continue
+		}
+		s.publish(eventType, event)
 	}
+	return nil
+}
 
-	if contractInfo.LotteryActive {
-		return fmt.Errorf("當前抽獎仍在進行中，不能開始新輪次")
+// publish 推送即時事件，失敗只記錄警告
+func (s *Service) publish(eventType string, data interface{}) {
+	if _, err := s.events.Publish(eventType, data); err != nil {
+		s.logger.Warn("推送即時事件失敗", "type", eventType, "error", err)
 	}
+}
 
-	// 2. 創建開始新輪次交易
-	transaction, err := s.wallet.CreateStartNewRoundTransaction(s.config.LotteryContractAddress)
+// executeOwnerOperation 檢查錢包餘額、發送 owner 操作交易並等待確認
+//
+// label 用於錯誤訊息，例如「發送抽獎交易失敗」。
+func (s *Service) executeOwnerOperation(operation, label string, build func(seqno uint32) ([]byte, error)) (*OperationResult, error) {
+	// 錢包餘額不足以支付 gas 時，交易必定失敗
+	if err := s.checkWalletBalance(); err != nil {
+		return nil, err
+	}
+
+	txHash, err := s.sendWalletMessage(build)
 	if err != nil {
-		return fmt.Errorf("創建新輪次交易失敗: %w", err)
+		return nil, fmt.Errorf("發送%s交易失敗: %w", label, err)
+	}
+
+	s.logger.Info(label+"交易已發送", "hash", txHash)
+	result := &OperationResult{Operation: operation, TxHash: txHash}
+
+	// 先記錄為 pending，確認結果由交易監控器更新
+	tx := store.Transaction{Hash: txHash, Operation: operation, Status: store.TxStatusPending}
+	if err := s.store.SaveTransaction(tx); err != nil {
+		s.logger.Error("保存交易記錄失敗", "hash", txHash, "operation", operation, "error", err)
 	}
 
-	// 3. 發送交易
-	txHash, err := s.tonClient.SendTransaction(s.ctx, transaction)
+	confirmation, err := s.waitForConfirmation(txHash)
+	result.Confirmation = confirmation
 	if err != nil {
-		return fmt.Errorf("發送新輪次交易失敗: %w", err)
+		return result, fmt.Errorf("%s交易監控失敗: %w", label, err)
 	}
 
-	s.logger.Info("新輪次交易已發送", "hash", txHash)
+	if confirmation.Status != "success" {
+		return result, fmt.Errorf("%s交易失敗: %s", label, confirmation.Status)
+	}
 
-	// 4. 監控交易結果
-	result, err := s.txMonitor.WaitForConfirmationWithRetry(s.ctx, txHash, s.config.RetryCount)
+	return result, nil
+}
+
+// sendWalletMessage 保留 seqno、創建並發送錢包外部訊息
+//
+// 發送成功才會消耗 seqno；失敗時釋放保留，下一筆交易前會從鏈上重新同步。
+func (s *Service) sendWalletMessage(build func(seqno uint32) ([]byte, error)) (string, error) {
+	reservation, err := s.wallet.ReserveSeqno(s.ctx)
 	if err != nil {
-		return fmt.Errorf("新輪次交易監控失敗: %w", err)
+		return "", fmt.Errorf("保留錢包 seqno 失敗: %w", err)
 	}
 
-	if result.Status == "success" {
-		s.logger.Info("✅ 新輪次開始成功", "hash", txHash, "new_round", contractInfo.CurrentRound+1)
-		return nil
+	boc, err := build(reservation.Seqno())
+	if err != nil {
+		reservation.Release(err)
+		return "", fmt.Errorf("創建交易失敗: %w", err)
+	}
+
+	txHash, err := s.chain.SendTransaction(s.ctx, boc)
+	if err != nil {
+		reservation.Release(err)
+		return "", err
 	}
 
-	return fmt.Errorf("新輪次交易失敗: %s", result.Status)
+	reservation.Commit()
+	return txHash, nil
+}
+
+// waitForConfirmation 等待交易確認，未成功時使本地 seqno 失效以便重新同步
+func (s *Service) waitForConfirmation(txHash string) (*transaction.Result, error) {
+	result, err := s.txMonitor.WaitForConfirmationWithRetry(s.ctx, txHash, s.config.RetryCount)
+	if err != nil || result.Status != "success" {
+		s.wallet.InvalidateSeqno()
+	}
+	return result, err
 }
 
 // === 查詢方法 ===
 
 // GetContractInfo 獲取合約狀態
 func (s *Service) GetContractInfo() (*ton.LotteryContractInfo, error) {
-	return s.tonClient.GetLotteryContractInfo(s.ctx, s.config.LotteryContractAddress)
+	info, err := s.chain.GetLotteryContractInfo(s.ctx, s.config.LotteryContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	observeContractInfo(info)
+	return info, nil
 }
 
 // GetParticipant 獲取參與者資訊
 func (s *Service) GetParticipant(index int) (*ton.Participant, error) {
-	return s.tonClient.GetParticipant(s.ctx, s.config.LotteryContractAddress, index)
+	return s.chain.GetParticipant(s.ctx, s.config.LotteryContractAddress, index)
 }
 
-// GetWinner 獲取中獎記錄
+// GetWinner 獲取中獎記錄，尚未開獎時返回 nil
+//
+// 開獎結果不會改變，已保存的結果與已結束且沒有開獎的輪次直接從資料檔讀取。
 func (s *Service) GetWinner(round int) (*ton.LotteryResult, error) {
-	return s.tonClient.GetWinner(s.ctx, s.config.LotteryContractAddress, round)
+	if record, err := s.store.GetRound(round); err == nil && (record.Result != nil || record.Closed) {
+		return record.Result, nil
+	}
+
+	result, err := s.chain.GetWinner(s.ctx, s.config.LotteryContractAddress, round)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		s.updateRound(round, func(r *store.Round) { r.Result = result })
+	}
+	return result, nil
+}
+
+// updateRound 以 update 修改輪次記錄後寫入，記錄不存在時建立新記錄
+//
+// 資料檔寫入失敗只記錄錯誤，查詢結果仍然有效。
+func (s *Service) updateRound(number int, update func(r *store.Round)) {
+	s.roundMu.Lock()
+	defer s.roundMu.Unlock()
+
+	round := store.Round{Number: number}
+	if existing, err := s.store.GetRound(number); err == nil {
+		round = *existing
+	}
+	update(&round)
+
+	if err := s.store.SaveRound(round); err != nil {
+		s.logger.Error("保存輪次記錄失敗", "round", number, "error", err)
+	}
+}
+
+// IsWinner 檢查指定地址是否為某輪次的中獎者
+func (s *Service) IsWinner(round int, addr string) (bool, error) {
+	winner, err := s.GetWinner(round)
+	if err != nil {
+		return false, err
+	}
+	return winner.IsWinner(addr), nil
+}
+
+// ListTransactions 由新到舊列出後端發送的交易記錄，status 為空時列出所有狀態
+func (s *Service) ListTransactions(status string, limit int) ([]store.Transaction, error) {
+	return s.store.ListTransactions(status, limit)
+}
+
+// GetTransaction 查詢後端發送的交易記錄，不存在時返回 store.ErrNotFound
+func (s *Service) GetTransaction(hash string) (*store.Transaction, error) {
+	return s.store.GetTransaction(hash)
 }
 
 // GetContractBalance 獲取合約餘額
 func (s *Service) GetContractBalance() (int64, error) {
-	return s.tonClient.GetContractBalance(s.ctx, s.config.LotteryContractAddress)
+	balance, err := s.chain.GetContractBalance(s.ctx, s.config.LotteryContractAddress)
+	if err != nil {
+		return 0, err
+	}
+	contractBalanceGauge.Set(float64(balance))
+	return balance, nil
+}
+
+// GetWalletBalance 獲取後端錢包餘額 (nanoTON)
+func (s *Service) GetWalletBalance() (int64, error) {
+	balance, err := s.wallet.GetBalance(s.ctx)
+	if err != nil {
+		return 0, err
+	}
+	walletBalanceGauge.Set(float64(balance))
+	return balance, nil
+}
+
+// checkWalletBalance 檢查錢包餘額是否高於設定的門檻，門檻為 0 時不檢查
+func (s *Service) checkWalletBalance() error {
+	threshold := tonToNano(s.config.MinWalletBalanceTON)
+	if threshold <= 0 {
+		return nil
+	}
+
+	balance, err := s.GetWalletBalance()
+	if err != nil {
+		return fmt.Errorf("無法確認錢包餘額: %w", err)
+	}
+
+	if balance < threshold {
+		s.logger.Error("❌ 錢包餘額不足，已停止發送抽獎交易",
+			"wallet", s.wallet.GetAddress(),
+			"balance", balance,
+			"threshold", threshold,
+		)
+		return fmt.Errorf("%w: %d < %d nanoTON", ErrLowWalletBalance, balance, threshold)
+	}
+
+	return nil
+}
+
+// tonToNano 將 TON 轉換為 nanoTON
+func tonToNano(amount float64) int64 {
+	return int64(math.Round(amount * 1e9))
 }
 
 // GetWalletAddress 獲取錢包地址
@@ -304,12 +676,21 @@ func (s *Service) GetStatus() map[string]interface{} {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return map[string]interface{}{
-		"running":          s.running,
-		"auto_draw":        s.config.AutoDraw,
-		"draw_interval":    s.config.DrawInterval.String(),
-		"max_participants": s.config.MaxParticipants,
-		"min_participants": s.config.MinParticipants,
-		"entry_fee_ton":    s.config.EntryFeeTON,
+	status := map[string]interface{}{
+		"running":                s.running,
+		"auto_draw":              s.config.AutoDraw,
+		"draw_interval":          s.config.DrawInterval.String(),
+		"max_participants":       s.config.MaxParticipants,
+		"min_participants":       s.config.MinParticipants,
+		"entry_fee_ton":          s.config.EntryFeeTON,
+		"min_wallet_balance_ton": s.config.MinWalletBalanceTON,
+		"wallet_address":         s.wallet.GetAddress(),
+		"wallet_version":         string(s.wallet.GetVersion()),
+	}
+
+	if verified := s.ownerVerified.Load(); verified != nil {
+		status["owner_verified"] = *verified
 	}
+
+	return status
 }
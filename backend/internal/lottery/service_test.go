@@ -1,26 +1,39 @@
 package lottery
 
 import (
+	"context"
 	"encoding/json"
-	"net/http"
-	"net/http/httptest"
+	"errors"
 	"strings"
 	"testing"
 	"time"
-
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+	"ton-cat-lottery-backend/internal/indexer"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/stream"
 	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
+// 測試用合約地址（合法的使用者友善格式）
+const (
+	testLotteryAddress = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	testNFTAddress     = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+	testWinnerAddress  = "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n"
+	testOwnerAddress   = "EQAREREREREREREREREREREREREREREREREREREREREREeYT"
+)
+
 func createTestConfig() *config.Config {
 	return &config.Config{
 		Environment:            "test",
 		LogLevel:               "debug",
 		TONAPIEndpoint:         "https://testnet.toncenter.com/api/v2/",
 		TONNetwork:             "testnet",
-		LotteryContractAddress: "EQLotteryTest123",
-		NFTContractAddress:     "EQNFTTest456",
+		LotteryContractAddress: testLotteryAddress,
+		NFTContractAddress:     testNFTAddress,
 		WalletPrivateKey:       "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		DrawInterval:           1 * time.Second, // 短間隔用於測試
 		MaxParticipants:        10,
@@ -32,54 +45,6 @@ func createTestConfig() *config.Config {
 	}
 }
 
-func createMockServer() *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var response ton.APIResponse
-
-		if strings.Contains(r.URL.Path, "runGetMethod") {
-			// Mock contract info response
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"owner": "EQOwner123",
-					"entry_fee": 100000000,
-					"max_participants": 10,
-					"current_round": 1,
-					"lottery_active": true,
-					"participant_count": 3,
-					"nft_contract": "EQNFTTest456"
-				}`),
-			}
-		} else if strings.Contains(r.URL.Path, "sendBoc") {
-			// Mock send transaction response
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`{
-					"hash": "0x123456789abcdef"
-				}`),
-			}
-		} else if strings.Contains(r.URL.Path, "getTransactions") {
-			// Mock transaction status response
-			response = ton.APIResponse{
-				Ok: true,
-				Result: json.RawMessage(`[{
-					"hash": "0x123456789abcdef",
-					"success": true
-				}]`),
-			}
-		} else {
-			// Default response
-			response = ton.APIResponse{
-				Ok:     true,
-				Result: json.RawMessage(`{}`),
-			}
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-}
-
 func TestNewService(t *testing.T) {
 	cfg := createTestConfig()
 	log := logger.New(cfg.LogLevel)
@@ -101,8 +66,8 @@ func TestNewService(t *testing.T) {
 		t.Error("Expected logger to be set")
 	}
 
-	if service.tonClient == nil {
-		t.Error("Expected tonClient to be set")
+	if service.chain == nil {
+		t.Error("Expected chain to be set")
 	}
 
 	if service.wallet == nil {
@@ -156,24 +121,16 @@ func TestServiceStartStop(t *testing.T) {
 }
 
 func TestServiceAutoDrawLoop(t *testing.T) {
-	server := createMockServer()
-	defer server.Close()
-
 	cfg := createTestConfig()
-	cfg.TONAPIEndpoint = server.URL + "/"
 	cfg.AutoDraw = true
 	cfg.DrawInterval = 200 * time.Millisecond // 短間隔
-	cfg.MinParticipants = 1                   // 降低最小參與人數以便觸發抽獎
-
-	log := logger.New(cfg.LogLevel)
-
-	service, err := NewService(cfg, log)
-	if err != nil {
-		t.Fatalf("NewService() failed: %v", err)
-	}
+	cfg.MinParticipants = 1                   // 降低最小參與人數以便觸發抽獎檢查
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	joinParticipants(t, chain, cfg, 3)
+	service := newToncenterService(t, cfg)
 
 	// 啟動服務
-	err = service.Start()
+	err := service.Start()
 	if err != nil {
 		t.Fatalf("Start() failed: %v", err)
 	}
@@ -191,25 +148,18 @@ func TestServiceAutoDrawLoop(t *testing.T) {
 }
 
 func TestGetContractInfo(t *testing.T) {
-	server := createMockServer()
-	defer server.Close()
-
 	cfg := createTestConfig()
-	cfg.TONAPIEndpoint = server.URL + "/"
-	log := logger.New(cfg.LogLevel)
-
-	service, err := NewService(cfg, log)
-	if err != nil {
-		t.Fatalf("NewService() failed: %v", err)
-	}
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	joinParticipants(t, chain, cfg, 3)
+	service := newToncenterService(t, cfg)
 
 	contractInfo, err := service.GetContractInfo()
 	if err != nil {
 		t.Fatalf("GetContractInfo() failed: %v", err)
 	}
 
-	if contractInfo.Owner != "EQOwner123" {
-		t.Errorf("Expected owner='EQOwner123', got %s", contractInfo.Owner)
+	if !address.Equal(contractInfo.Owner, service.GetWalletAddress()) {
+		t.Errorf("Expected owner=%s, got %s", service.GetWalletAddress(), contractInfo.Owner)
 	}
 
 	if contractInfo.CurrentRound != 1 {
@@ -225,58 +175,79 @@ func TestGetContractInfo(t *testing.T) {
 	}
 }
 
+// stubChain 只實作 GetLotteryContractInfo 與 GetWalletSeqno 的 ton.Chain，呼叫其他方法會 panic
+type stubChain struct {
+	ton.Chain
+	info  ton.LotteryContractInfo
+	calls int
+}
+
+func (c *stubChain) GetLotteryContractInfo(ctx context.Context, contractAddress string) (*ton.LotteryContractInfo, error) {
+	c.calls++
+	info := c.info
+	return &info, nil
+}
+
+func (c *stubChain) GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error) {
+	return 0, nil
+}
+
+func TestNewServiceWithChain(t *testing.T) {
+	cfg := createTestConfig()
+	chain := &stubChain{info: ton.LotteryContractInfo{Owner: testOwnerAddress, CurrentRound: 4, LotteryActive: true}}
+
+	service, err := NewServiceWithChain(cfg, logger.New("error"), chain)
+	if err != nil {
+		t.Fatalf("NewServiceWithChain() failed: %v", err)
+	}
+	if service.chain != chain {
+		t.Error("Expected the given chain to be used")
+	}
+
+	info, err := service.GetContractInfo()
+	if err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if info.CurrentRound != 4 || chain.calls != 1 {
+		t.Errorf("Expected contract info from the chain, got round %d after %d calls", info.CurrentRound, chain.calls)
+	}
+}
+
 func TestSendDrawWinner(t *testing.T) {
 	t.Run("successful draw", func(t *testing.T) {
-		server := createMockServer()
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
 		cfg.MinParticipants = 2 // 設定最小參與人數
-		log := logger.New(cfg.LogLevel)
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
-		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
+		if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+			t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
 		}
+		joinParticipants(t, chain, cfg, 3)
 
-		err = service.SendDrawWinner()
+		err := service.SendDrawWinner()
 		if err != nil {
 			t.Fatalf("SendDrawWinner() failed: %v", err)
 		}
+
+		// 交易記錄包含確認結果
+		txs, err := service.ListTransactions("", 1)
+		if err != nil || len(txs) != 1 {
+			t.Fatalf("ListTransactions() = %+v, %v", txs, err)
+		}
+		if tx := txs[0]; tx.Operation != OperationDrawWinner || tx.Status != store.TxStatusSuccess {
+			t.Errorf("Unexpected transaction record: %+v", tx)
+		}
 	})
 
 	t.Run("lottery not active", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response := ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": false,
-						"participant_count": 3,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
-			}
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
-		log := logger.New(cfg.LogLevel)
+		cfg.MaxParticipants = 3
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 3) // 達到上限後合約停止接受參與者
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
-		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
-		}
-
-		err = service.SendDrawWinner()
+		err := service.SendDrawWinner()
 		if err == nil {
 			t.Fatal("Expected SendDrawWinner() to fail when lottery not active")
 		}
@@ -287,37 +258,13 @@ func TestSendDrawWinner(t *testing.T) {
 	})
 
 	t.Run("insufficient participants", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response := ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 1,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
-			}
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
 		cfg.MinParticipants = 2
-		log := logger.New(cfg.LogLevel)
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 1)
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
-		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
-		}
-
-		err = service.SendDrawWinner()
+		err := service.SendDrawWinner()
 		if err == nil {
 			t.Fatal("Expected SendDrawWinner() to fail with insufficient participants")
 		}
@@ -330,96 +277,29 @@ func TestSendDrawWinner(t *testing.T) {
 
 func TestSendStartNewRound(t *testing.T) {
 	t.Run("successful start new round", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			var response ton.APIResponse
-
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				// 返回抽獎未活躍的狀態
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": false,
-						"participant_count": 0,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-			} else if strings.Contains(r.URL.Path, "sendBoc") {
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"hash": "0x123456789abcdef"
-					}`),
-				}
-			} else if strings.Contains(r.URL.Path, "getTransactions") {
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`[{
-						"hash": "0x123456789abcdef",
-						"success": true
-					}]`),
-				}
-			} else {
-				response = ton.APIResponse{
-					Ok:     true,
-					Result: json.RawMessage(`{}`),
-				}
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(response)
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
-		log := logger.New(cfg.LogLevel)
+		cfg.MaxParticipants = 3
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 3) // 抽獎未活躍的狀態
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
+		err := service.SendStartNewRound()
 		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
+			t.Fatalf("SendStartNewRound() failed: %v", err)
 		}
 
-		err = service.SendStartNewRound()
-		if err != nil {
-			t.Fatalf("SendStartNewRound() failed: %v", err)
+		if info, err := service.GetContractInfo(); err != nil || !info.LotteryActive || info.CurrentRound != 2 {
+			t.Errorf("Expected round 2 to be active, got %+v (%v)", info, err)
 		}
 	})
 
 	t.Run("lottery still active", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response := ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 3,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
-			}
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
-		log := logger.New(cfg.LogLevel)
-
-		service, err := NewService(cfg, log)
-		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
-		}
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 3)
+		service := newToncenterService(t, cfg)
 
-		err = service.SendStartNewRound()
+		err := service.SendStartNewRound()
 		if err == nil {
 			t.Fatal("Expected SendStartNewRound() to fail when lottery is still active")
 		}
@@ -432,136 +312,58 @@ func TestSendStartNewRound(t *testing.T) {
 
 func TestCheckAndDraw(t *testing.T) {
 	t.Run("should draw when max participants reached", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			var response ton.APIResponse
-
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 10,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-			} else if strings.Contains(r.URL.Path, "sendBoc") {
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"hash": "0x123456789abcdef"
-					}`),
-				}
-			} else if strings.Contains(r.URL.Path, "getTransactions") {
-				response = ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`[{
-						"hash": "0x123456789abcdef",
-						"success": true
-					}]`),
-				}
-			} else {
-				response = ton.APIResponse{
-					Ok:     true,
-					Result: json.RawMessage(`{}`),
-				}
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(response)
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
-		cfg.MaxParticipants = 10
 		cfg.MinParticipants = 2
-		log := logger.New(cfg.LogLevel)
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		cfg.MaxParticipants = 3 // 服務的上限低於合約，合約仍在進行中
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
-		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
+		if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+			t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
 		}
+		joinParticipants(t, chain, cfg, 3)
 
-		err = service.checkAndDraw()
+		err := service.checkAndDraw()
 		if err != nil {
 			t.Fatalf("checkAndDraw() failed: %v", err)
 		}
+
+		if winner, err := service.GetWinner(1); err != nil || winner == nil {
+			t.Errorf("Expected a winner to be drawn, got %+v (%v)", winner, err)
+		}
 	})
 
 	t.Run("should not draw when insufficient participants", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response := ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": true,
-						"participant_count": 1,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
-			}
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
 		cfg.MinParticipants = 2
-		log := logger.New(cfg.LogLevel)
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 1)
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
+		err := service.checkAndDraw()
 		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
+			t.Fatalf("checkAndDraw() should not fail when conditions not met: %v", err)
 		}
 
-		err = service.checkAndDraw()
-		if err != nil {
-			t.Fatalf("checkAndDraw() should not fail when conditions not met: %v", err)
+		if seqno := walletSeqno(t, chain, service); seqno != 0 {
+			t.Errorf("Expected no transaction to be sent, got seqno %d", seqno)
 		}
 	})
 
 	t.Run("should not draw when lottery inactive", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.Contains(r.URL.Path, "runGetMethod") {
-				response := ton.APIResponse{
-					Ok: true,
-					Result: json.RawMessage(`{
-						"owner": "EQOwner123",
-						"entry_fee": 100000000,
-						"max_participants": 10,
-						"current_round": 1,
-						"lottery_active": false,
-						"participant_count": 5,
-						"nft_contract": "EQNFTTest456"
-					}`),
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(response)
-			}
-		}))
-		defer server.Close()
-
 		cfg := createTestConfig()
-		cfg.TONAPIEndpoint = server.URL + "/"
-		log := logger.New(cfg.LogLevel)
+		cfg.MaxParticipants = 5
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 5) // 達到上限後合約停止接受參與者
+		service := newToncenterService(t, cfg)
 
-		service, err := NewService(cfg, log)
+		err := service.checkAndDraw()
 		if err != nil {
-			t.Fatalf("NewService() failed: %v", err)
+			t.Fatalf("checkAndDraw() should not fail when lottery inactive: %v", err)
 		}
 
-		err = service.checkAndDraw()
-		if err != nil {
-			t.Fatalf("checkAndDraw() should not fail when lottery inactive: %v", err)
+		if seqno := walletSeqno(t, chain, service); seqno != 0 {
+			t.Errorf("Expected no transaction to be sent, got seqno %d", seqno)
 		}
 	})
 }
@@ -616,3 +418,195 @@ func TestGetWalletAddress(t *testing.T) {
 		t.Errorf("Expected address to start with 'EQ', got %s", address)
 	}
 }
+
+func TestVerifyOwner(t *testing.T) {
+	cfg := createTestConfig()
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	service := newToncenterService(t, cfg)
+
+	// 另一個 owner 不同的抽獎合約
+	otherLottery := testAddress(0x30)
+	if err := chain.DeployLottery(otherLottery, testOwnerAddress, tonToNano(cfg.EntryFeeTON), cfg.MaxParticipants); err != nil {
+		t.Fatalf("DeployLottery() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		contract string
+		expected bool
+	}{
+		{"owner matches wallet", testLotteryAddress, true},
+		{"owner differs from wallet", otherLottery, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.LotteryContractAddress = tt.contract
+			service.ownerVerified.Store(nil)
+
+			service.wg.Add(1)
+			service.verifyOwner()
+
+			status := service.GetStatus()
+			if status["owner_verified"] != tt.expected {
+				t.Errorf("Expected owner_verified=%v, got %v", tt.expected, status["owner_verified"])
+			}
+		})
+	}
+
+	t.Run("query failure leaves owner unverified", func(t *testing.T) {
+		// 地址上沒有部署合約，查詢失敗
+		cfg.LotteryContractAddress = testWinnerAddress
+		service.ownerVerified.Store(nil)
+
+		service.wg.Add(1)
+		service.verifyOwner()
+
+		if _, ok := service.GetStatus()["owner_verified"]; ok {
+			t.Error("Expected owner_verified to be absent when the check fails")
+		}
+	})
+}
+
+// balanceErrorChain 查詢地址餘額一律失敗的模擬鏈
+type balanceErrorChain struct {
+	*emulator.Chain
+}
+
+func (c *balanceErrorChain) GetAddressBalance(ctx context.Context, addr string) (int64, error) {
+	return 0, errors.New("balance unavailable")
+}
+
+func TestWalletBalanceThreshold(t *testing.T) {
+	t.Run("balance below threshold blocks draw", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.MinWalletBalanceTON = 20 // 錢包只有 10 TON
+		chain := newFakeToncenter(t, cfg, toncenter.Options{})
+		joinParticipants(t, chain, cfg, 5)
+		service := newToncenterService(t, cfg)
+
+		balance, err := service.GetWalletBalance()
+		if err != nil {
+			t.Fatalf("GetWalletBalance() failed: %v", err)
+		}
+		if balance != 10000000000 {
+			t.Errorf("Expected balance=10000000000, got %d", balance)
+		}
+
+		err = service.SendDrawWinner()
+		if !errors.Is(err, ErrLowWalletBalance) {
+			t.Fatalf("Expected ErrLowWalletBalance, got %v", err)
+		}
+		if walletSeqno(t, chain, service) != 0 {
+			t.Error("Expected no transaction to be sent when balance is low")
+		}
+	})
+
+	t.Run("balance query failure blocks draw", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.MinWalletBalanceTON = 0.2
+		chain := newEmulatedChain(t, cfg)
+		joinParticipants(t, chain, cfg, 5)
+		service, err := NewServiceWithChain(cfg, logger.New("error"), &balanceErrorChain{Chain: chain})
+		if err != nil {
+			t.Fatalf("NewServiceWithChain() failed: %v", err)
+		}
+
+		if err := service.SendDrawWinner(); err == nil {
+			t.Fatal("Expected SendDrawWinner() to fail when balance is unknown")
+		}
+		if walletSeqno(t, chain, service) != 0 {
+			t.Error("Expected no transaction to be sent when balance is unknown")
+		}
+	})
+
+	t.Run("threshold zero disables check", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.MinWalletBalanceTON = 0
+		service, err := NewService(cfg, logger.New(cfg.LogLevel))
+		if err != nil {
+			t.Fatalf("NewService() failed: %v", err)
+		}
+
+		if err := service.checkWalletBalance(); err != nil {
+			t.Errorf("Expected balance check to be skipped, got %v", err)
+		}
+	})
+}
+
+func TestOwnerOperationGuards(t *testing.T) {
+	cfg := createTestConfig()
+	service, err := NewService(cfg, logger.New(cfg.LogLevel))
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+
+	t.Run("invalid nft contract address", func(t *testing.T) {
+		if _, err := service.ExecuteSetNFTContract("not-an-address"); err == nil {
+			t.Error("Expected invalid NFT contract address to be rejected")
+		}
+	})
+
+	t.Run("only one operation at a time", func(t *testing.T) {
+		service.opMu.Lock()
+		defer service.opMu.Unlock()
+
+		operations := map[string]func() (*OperationResult, error){
+			OperationDrawWinner:    service.ExecuteDrawWinner,
+			OperationStartNewRound: service.ExecuteStartNewRound,
+			OperationWithdraw:      service.ExecuteWithdraw,
+			OperationSetNFTContract: func() (*OperationResult, error) {
+				return service.ExecuteSetNFTContract(testNFTAddress)
+			},
+		}
+
+		for name, execute := range operations {
+			if _, err := execute(); !errors.Is(err, ErrOperationInProgress) {
+				t.Errorf("%s: expected ErrOperationInProgress, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestHandleChainEvents(t *testing.T) {
+	cfg := createTestConfig()
+	service, err := NewService(cfg, logger.New(cfg.LogLevel))
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	sub := service.Events().Subscribe(0)
+	defer sub.Close()
+
+	err = service.HandleChainEvents(context.Background(), []indexer.Event{
+		{ID: "1000:1", Type: ton.EventParticipantJoined, TxHash: "hash-1000", Data: &ton.ParticipantJoined{Participant: testWinnerAddress, Round: 1}},
+		{ID: "2000:1", Type: ton.EventLotteryFull, TxHash: "hash-2000", Data: &ton.LotteryFull{Round: 1}},
+		{ID: "3000:1", Type: ton.EventNFTSent, TxHash: "hash-3000", Data: &ton.NFTSent{Recipient: testWinnerAddress}},
+		{ID: "3000:2", Type: ton.EventWinnerDrawn, TxHash: "hash-3000", Data: &ton.WinnerDrawn{Winner: testWinnerAddress, NFTId: 1042, Round: 1}},
+	})
+	if err != nil {
+		t.Fatalf("HandleChainEvents() failed: %v", err)
+	}
+
+	// NFTSent 不推送，其餘依序轉換為前端事件類型
+	want := []string{stream.EventParticipantJoined, stream.EventRoundFull, stream.EventWinnerDrawn}
+	for _, eventType := range want {
+		event := <-sub.Events()
+		if event.Type != eventType {
+			t.Errorf("Expected %s, got %s", eventType, event.Type)
+		}
+		if eventType == stream.EventWinnerDrawn {
+			var data struct {
+				TxHash string          `json:"tx_hash"`
+				Data   ton.WinnerDrawn `json:"data"`
+			}
+			if err := json.Unmarshal(event.Data, &data); err != nil || data.TxHash != "hash-3000" || data.Data.NFTId != 1042 {
+				t.Errorf("Unexpected winner_drawn data: %s (%v)", event.Data, err)
+			}
+		}
+	}
+	select {
+	case event := <-sub.Events():
+		t.Errorf("Unexpected extra event: %+v", event)
+	default:
+	}
+}
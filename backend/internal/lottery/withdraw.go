@@ -0,0 +1,147 @@
+package lottery
+
+import (
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+)
+
+// ContractReserve CatLottery 與 CatNFT 在 withdraw 時保留的餘額 (0.1 TON, nanoTON)
+const ContractReserve int64 = 100000000
+
+// WithdrawReport 提取前後的合約與錢包餘額 (nanoTON)
+//
+// 合約餘額在提取交易確認後即會更新；錢包收到款項需等待合約的出站訊息在下一個區塊處理，
+// 因此 WalletAfter 可能尚未包含提取的金額。查詢失敗時 After 欄位為 nil。
+type WithdrawReport struct {
+	Contract       string `json:"contract"`
+	Reserve        int64  `json:"reserve"`
+	ContractBefore int64  `json:"contract_balance_before"`
+	ContractAfter  *int64 `json:"contract_balance_after,omitempty"`
+	WalletBefore   int64  `json:"wallet_balance_before"`
+	WalletAfter    *int64 `json:"wallet_balance_after,omitempty"`
+	Expected       int64  `json:"expected_amount"` // 提取前餘額扣除保留金額
+}
+
+// SendWithdraw 提取抽獎合約餘額
+func (s *Service) SendWithdraw() error {
+	_, err := s.ExecuteWithdraw()
+	return err
+}
+
+// SendWithdrawNFT 提取 NFT 合約餘額
+func (s *Service) SendWithdrawNFT() error {
+	_, err := s.ExecuteWithdrawNFT()
+	return err
+}
+
+// ExecuteWithdraw 提取抽獎合約餘額並等待確認，返回前後餘額
+//
+// 合約只允許在抽獎未活躍時提取；另外要求沒有等待開獎的參與者，
+// 避免提取後合約餘額不足以支付開獎時鑄造 NFT 的費用。
+func (s *Service) ExecuteWithdraw() (*OperationResult, error) {
+	if !s.opMu.TryLock() {
+		return nil, ErrOperationInProgress
+	}
+	defer s.opMu.Unlock()
+
+	s.logger.Info("💰 提取抽獎合約餘額...")
+
+	contractInfo, err := s.GetContractInfo()
+	if err != nil {
+		return nil, fmt.Errorf("查詢合約狀態失敗: %w", err)
+	}
+
+	if contractInfo.LotteryActive {
+		return nil, fmt.Errorf("%w: 抽獎進行中，不能提取餘額", ErrInvalidState)
+	}
+
+	if contractInfo.ParticipantCount > 0 {
+		return nil, fmt.Errorf("%w: 尚有 %d 位參與者等待開獎，不能提取餘額",
+			ErrInvalidState, contractInfo.ParticipantCount)
+	}
+
+	return s.executeWithdraw(OperationWithdraw, "提取餘額", s.config.LotteryContractAddress)
+}
+
+// ExecuteWithdrawNFT 提取 NFT 合約餘額並等待確認，返回前後餘額
+//
+// CatNFT 只允許其 owner 提取，因此先確認錢包即為 NFT 合約的 owner。
+func (s *Service) ExecuteWithdrawNFT() (*OperationResult, error) {
+	if !s.opMu.TryLock() {
+		return nil, ErrOperationInProgress
+	}
+	defer s.opMu.Unlock()
+
+	s.logger.Info("💰 提取 NFT 合約餘額...")
+
+	nftInfo, err := s.chain.GetNFTContractInfo(s.ctx, s.config.NFTContractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("查詢 NFT 合約狀態失敗: %w", err)
+	}
+
+	if !address.Equal(nftInfo.Owner, s.wallet.GetAddress()) {
+		return nil, fmt.Errorf("%w: 錢包不是 NFT 合約的 owner (%s)", ErrInvalidState, nftInfo.Owner)
+	}
+
+	return s.executeWithdraw(OperationWithdrawNFT, "提取 NFT 合約餘額", s.config.NFTContractAddress)
+}
+
+// executeWithdraw 檢查合約餘額、發送 withdraw 並記錄前後餘額
+func (s *Service) executeWithdraw(operation, label, contract string) (*OperationResult, error) {
+	contractBefore, err := s.chain.GetAddressBalance(s.ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("查詢合約餘額失敗: %w", err)
+	}
+
+	if contractBefore <= ContractReserve {
+		return nil, fmt.Errorf("%w: 合約餘額 %d 未超過保留金額 %d nanoTON",
+			ErrInvalidState, contractBefore, ContractReserve)
+	}
+
+	walletBefore, err := s.GetWalletBalance()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WithdrawReport{
+		Contract:       contract,
+		Reserve:        ContractReserve,
+		ContractBefore: contractBefore,
+		WalletBefore:   walletBefore,
+		Expected:       contractBefore - ContractReserve,
+	}
+
+	result, err := s.executeOwnerOperation(operation, label, func(seqno uint32) ([]byte, error) {
+		return s.wallet.CreateWithdrawTransaction(contract, seqno)
+	})
+	if result != nil {
+		result.Withdraw = report
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if balance, err := s.chain.GetAddressBalance(s.ctx, contract); err == nil {
+		report.ContractAfter = &balance
+	} else {
+		s.logger.Warn("查詢提取後的合約餘額失敗", "contract", contract, "error", err)
+	}
+
+	if balance, err := s.GetWalletBalance(); err == nil {
+		report.WalletAfter = &balance
+	} else {
+		s.logger.Warn("查詢提取後的錢包餘額失敗", "error", err)
+	}
+
+	s.logger.Info("✅ "+label+"成功",
+		"hash", result.TxHash,
+		"contract", contract,
+		"contract_before", report.ContractBefore,
+		"contract_after", report.ContractAfter,
+		"wallet_before", report.WalletBefore,
+		"wallet_after", report.WalletAfter,
+	)
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package lottery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/emulator"
+	"ton-cat-lottery-backend/internal/emulator/toncenter"
+)
+
+// walletSeqno 返回服務錢包在鏈上的 seqno，用於確認是否發送了交易
+func walletSeqno(t *testing.T, chain *emulator.Chain, service *Service) uint32 {
+	t.Helper()
+	seqno, err := chain.GetWalletSeqno(context.Background(), service.GetWalletAddress())
+	if err != nil {
+		t.Fatalf("GetWalletSeqno() failed: %v", err)
+	}
+	return seqno
+}
+
+func TestWithdrawPreChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, chain *emulator.Chain, service *Service)
+	}{
+		{"lottery active", func(t *testing.T, chain *emulator.Chain, service *Service) {}},
+		{"participants waiting for draw", func(t *testing.T, chain *emulator.Chain, service *Service) {
+			joinParticipants(t, chain, service.config, 2)
+		}},
+		{"balance within reserve", func(t *testing.T, chain *emulator.Chain, service *Service) {
+			if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+				t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+			}
+			joinParticipants(t, chain, service.config, 2)
+			sendOwnerComment(t, chain, service, "drawWinner")
+			sendOwnerComment(t, chain, service, "withdraw")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.MaxParticipants = 2
+			cfg.MinWalletBalanceTON = 0.2
+			chain := newFakeToncenter(t, cfg, toncenter.Options{})
+			service := newToncenterService(t, cfg)
+			tt.setup(t, chain, service)
+			seqno := walletSeqno(t, chain, service)
+
+			result, err := service.ExecuteWithdraw()
+			if !errors.Is(err, ErrInvalidState) {
+				t.Fatalf("Expected ErrInvalidState, got %v", err)
+			}
+			if result != nil {
+				t.Errorf("Expected no result, got %+v", result)
+			}
+			if walletSeqno(t, chain, service) != seqno {
+				t.Error("Expected no transaction to be sent")
+			}
+		})
+	}
+}
+
+func TestWithdrawNFTRequiresOwner(t *testing.T) {
+	// NFT 合約的 owner 為抽獎合約，錢包不能提取
+	cfg := createTestConfig()
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	service := newToncenterService(t, cfg)
+
+	if err := service.SendWithdrawNFT(); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("Expected ErrInvalidState, got %v", err)
+	}
+	if walletSeqno(t, chain, service) != 0 {
+		t.Error("Expected no transaction to be sent")
+	}
+}
+
+func TestExecuteWithdraw(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.MaxParticipants = 2
+	cfg.MinWalletBalanceTON = 0.2
+	chain := newFakeToncenter(t, cfg, toncenter.Options{})
+	service := newToncenterService(t, cfg)
+	ctx := context.Background()
+
+	if _, err := service.ExecuteSetNFTContract(testNFTAddress); err != nil {
+		t.Fatalf("ExecuteSetNFTContract() failed: %v", err)
+	}
+	joinParticipants(t, chain, cfg, 2)
+	sendOwnerComment(t, chain, service, "drawWinner")
+
+	contractBefore, _ := chain.GetAddressBalance(ctx, testLotteryAddress)
+	walletBefore, _ := chain.GetAddressBalance(ctx, service.GetWalletAddress())
+
+	result, err := service.ExecuteWithdraw()
+	if err != nil {
+		t.Fatalf("ExecuteWithdraw() failed: %v", err)
+	}
+
+	if result.Operation != OperationWithdraw || result.TxHash == "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Confirmation == nil || result.Confirmation.Status != "success" {
+		t.Errorf("Expected confirmed transaction, got %+v", result.Confirmation)
+	}
+
+	report := result.Withdraw
+	if report == nil {
+		t.Fatal("Expected withdraw report")
+	}
+	if report.Contract != testLotteryAddress || report.Reserve != ContractReserve {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.ContractBefore != contractBefore || report.Expected != contractBefore-ContractReserve {
+		t.Errorf("before = %d, expected = %d", report.ContractBefore, report.Expected)
+	}
+
+	contractAfter, _ := chain.GetAddressBalance(ctx, testLotteryAddress)
+	walletAfter, _ := chain.GetAddressBalance(ctx, service.GetWalletAddress())
+	if report.ContractAfter == nil || *report.ContractAfter != contractAfter || contractAfter > ContractReserve {
+		t.Errorf("contract after = %v, want %d within the reserve", report.ContractAfter, contractAfter)
+	}
+	if report.WalletBefore != walletBefore || report.WalletAfter == nil || *report.WalletAfter != walletAfter || walletAfter <= walletBefore {
+		t.Errorf("wallet before = %d, after = %v", report.WalletBefore, report.WalletAfter)
+	}
+}
@@ -0,0 +1,378 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileStore 將所有記錄保存在單一 JSON 檔案的 Repository
+//
+// 資料全部載入記憶體，每次寫入都以暫存檔加改名的方式重寫整個檔案，
+// 寫入失敗時還原記憶體中的變更。資料量為每輪數十筆記錄，不需要外部資料庫。
+type FileStore struct {
+	path          string
+	schemaVersion int
+	mu            sync.RWMutex
+
+	rounds       map[int]Round
+	participants map[int]map[int]Participant
+	transactions map[string]Transaction
+
+	now func() time.Time
+}
+
+// document 目前 schema 版本的資料檔結構
+type document struct {
+	SchemaVersion int           `json:"schema_version"`
+	Rounds        []Round       `json:"rounds"`
+	Participants  []Participant `json:"participants"`
+	Transactions  []Transaction `json:"transactions"`
+}
+
+// Open 開啟 path 的資料檔並套用尚未執行的 migration，檔案不存在時建立新的資料檔
+//
+// path 為空時只保存在記憶體中，重啟後資料會遺失。
+func Open(path string) (*FileStore, error) {
+	return open(path, migrations)
+}
+
+func open(path string, migrations []migration) (*FileStore, error) {
+	s := &FileStore{
+		path:         path,
+		rounds:       make(map[int]Round),
+		participants: make(map[int]map[int]Participant),
+		transactions: make(map[string]Transaction),
+		now:          time.Now,
+	}
+
+	raw := rawDocument{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("讀取資料檔失敗: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &raw); err != nil {
+				return nil, fmt.Errorf("解析資料檔失敗: %w", err)
+			}
+		}
+	}
+
+	from, to, err := migrate(raw, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("編碼資料檔失敗: %w", err)
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析資料檔失敗: %w", err)
+	}
+	s.load(&doc)
+	s.schemaVersion = to
+
+	// 升級後立即寫回，之後的寫入都使用新版本
+	if from != to {
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// load 將資料檔內容放入記憶體索引
+func (s *FileStore) load(doc *document) {
+	for _, round := range doc.Rounds {
+		s.rounds[round.Number] = round
+	}
+	for _, p := range doc.Participants {
+		if s.participants[p.Round] == nil {
+			s.participants[p.Round] = make(map[int]Participant)
+		}
+		s.participants[p.Round][p.Index] = p
+	}
+	for _, tx := range doc.Transactions {
+		s.transactions[tx.Hash] = tx
+	}
+}
+
+// SaveRound 新增或更新輪次記錄
+func (s *FileStore) SaveRound(round Round) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, existed := s.rounds[round.Number]
+	round = cloneRound(round)
+	round.UpdatedAt = s.now()
+	s.rounds[round.Number] = round
+
+	if err := s.persist(); err != nil {
+		if existed {
+			s.rounds[round.Number] = prev
+		} else {
+			delete(s.rounds, round.Number)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRound 查詢輪次記錄，不存在時返回 ErrNotFound
+func (s *FileStore) GetRound(number int) (*Round, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	round, ok := s.rounds[number]
+	if !ok {
+		return nil, fmt.Errorf("第 %d 輪: %w", number, ErrNotFound)
+	}
+	round = cloneRound(round)
+	return &round, nil
+}
+
+// ListRounds 由新到舊列出編號小於 before 的輪次，before 為 0 時從最新的輪次開始
+func (s *FileStore) ListRounds(before, limit int) ([]Round, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rounds := make([]Round, 0, len(s.rounds))
+	for number, round := range s.rounds {
+		if before > 0 && number >= before {
+			continue
+		}
+		rounds = append(rounds, cloneRound(round))
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].Number > rounds[j].Number })
+
+	if limit > 0 && len(rounds) > limit {
+		rounds = rounds[:limit]
+	}
+	return rounds, nil
+}
+
+// SaveParticipants 新增或更新輪次中的參與者
+func (s *FileStore) SaveParticipants(round int, participants []Participant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.participants[round]
+	next := make(map[int]Participant, len(prev)+len(participants))
+	for index, p := range prev {
+		next[index] = p
+	}
+	for _, p := range participants {
+		p.Round = round
+		next[p.Index] = p
+	}
+	s.participants[round] = next
+
+	if err := s.persist(); err != nil {
+		if prev == nil {
+			delete(s.participants, round)
+		} else {
+			s.participants[round] = prev
+		}
+		return err
+	}
+	return nil
+}
+
+// ListParticipants 依索引列出輪次中的參與者
+func (s *FileStore) ListParticipants(round int) ([]Participant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	participants := make([]Participant, 0, len(s.participants[round]))
+	for _, p := range s.participants[round] {
+		participants = append(participants, p)
+	}
+	sort.Slice(participants, func(i, j int) bool { return participants[i].Index < participants[j].Index })
+	return participants, nil
+}
+
+// SaveTransaction 新增或更新交易記錄，以 Hash 識別
+func (s *FileStore) SaveTransaction(tx Transaction) error {
+	if tx.Hash == "" {
+		return fmt.Errorf("交易 hash 不能為空")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, existed := s.transactions[tx.Hash]
+	tx.UpdatedAt = s.now()
+	if tx.SentAt.IsZero() {
+		tx.SentAt = tx.UpdatedAt
+	}
+	s.transactions[tx.Hash] = tx
+
+	if err := s.persist(); err != nil {
+		if existed {
+			s.transactions[tx.Hash] = prev
+		} else {
+			delete(s.transactions, tx.Hash)
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateTransactionStatus 更新交易狀態，交易不存在時返回 ErrNotFound
+func (s *FileStore) UpdateTransactionStatus(hash, status, errMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.transactions[hash]
+	if !ok {
+		return fmt.Errorf("交易 %s: %w", hash, ErrNotFound)
+	}
+
+	tx := prev
+	tx.Status = status
+	tx.Error = errMsg
+	tx.UpdatedAt = s.now()
+	s.transactions[hash] = tx
+
+	if err := s.persist(); err != nil {
+		s.transactions[hash] = prev
+		return err
+	}
+	return nil
+}
+
+// GetTransaction 查詢交易記錄，不存在時返回 ErrNotFound
+func (s *FileStore) GetTransaction(hash string) (*Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, ok := s.transactions[hash]
+	if !ok {
+		return nil, fmt.Errorf("交易 %s: %w", hash, ErrNotFound)
+	}
+	return &tx, nil
+}
+
+// ListTransactions 由新到舊列出交易，status 為空時列出所有狀態，limit 為 0 時不限制數量
+func (s *FileStore) ListTransactions(status string, limit int) ([]Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	txs := make([]Transaction, 0, len(s.transactions))
+	for _, tx := range s.transactions {
+		if status == "" || tx.Status == status {
+			txs = append(txs, tx)
+		}
+	}
+	sortTransactions(txs)
+
+	if limit > 0 && len(txs) > limit {
+		txs = txs[:limit]
+	}
+	return txs, nil
+}
+
+// persist 將目前的資料寫入檔案，呼叫端需持有寫入鎖
+//
+// 先寫入暫存檔並同步到磁碟後再改名，避免中途當機留下不完整的檔案。
+func (s *FileStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("編碼資料檔失敗: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("建立資料目錄失敗: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("建立資料暫存檔失敗: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("寫入資料檔失敗: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步資料檔失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("寫入資料檔失敗: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("保存資料檔失敗: %w", err)
+	}
+	return nil
+}
+
+// snapshot 以固定順序輸出所有記錄，讓資料檔內容穩定、方便比對
+func (s *FileStore) snapshot() *document {
+	doc := &document{
+		SchemaVersion: s.schemaVersion,
+		Rounds:        make([]Round, 0, len(s.rounds)),
+		Participants:  []Participant{},
+		Transactions:  make([]Transaction, 0, len(s.transactions)),
+	}
+
+	for _, round := range s.rounds {
+		doc.Rounds = append(doc.Rounds, round)
+	}
+	sort.Slice(doc.Rounds, func(i, j int) bool { return doc.Rounds[i].Number < doc.Rounds[j].Number })
+
+	for _, participants := range s.participants {
+		for _, p := range participants {
+			doc.Participants = append(doc.Participants, p)
+		}
+	}
+	sort.Slice(doc.Participants, func(i, j int) bool {
+		a, b := doc.Participants[i], doc.Participants[j]
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		return a.Index < b.Index
+	})
+
+	for _, tx := range s.transactions {
+		doc.Transactions = append(doc.Transactions, tx)
+	}
+	sortTransactions(doc.Transactions)
+
+	return doc
+}
+
+// sortTransactions 依發送時間由新到舊排序，時間相同時依 hash 排序
+func sortTransactions(txs []Transaction) {
+	sort.Slice(txs, func(i, j int) bool {
+		if !txs[i].SentAt.Equal(txs[j].SentAt) {
+			return txs[i].SentAt.After(txs[j].SentAt)
+		}
+		return txs[i].Hash < txs[j].Hash
+	})
+}
+
+// cloneRound 複製輪次記錄，避免呼叫端修改共用的開獎結果
+func cloneRound(round Round) Round {
+	if round.Result != nil {
+		result := *round.Result
+		round.Result = &result
+	}
+	return round
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+const testWinnerAddress = "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n"
+
+func openTestStore(t *testing.T, path string) *FileStore {
+	t.Helper()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	return s
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "lottery.json")
+	s := openTestStore(t, path)
+
+	result := &ton.LotteryResult{Winner: testWinnerAddress, NFTId: 3042, Timestamp: 1700000300}
+	if err := s.SaveRound(Round{Number: 3, ParticipantCount: 2, Result: result}); err != nil {
+		t.Fatalf("SaveRound() failed: %v", err)
+	}
+	participants := []Participant{
+		{Index: 1, Participant: ton.Participant{Address: testWinnerAddress, Amount: 100000000, Timestamp: 1700000201}},
+		{Index: 0, Participant: ton.Participant{Address: testWinnerAddress, Amount: 100000000, Timestamp: 1700000200}},
+	}
+	if err := s.SaveParticipants(3, participants); err != nil {
+		t.Fatalf("SaveParticipants() failed: %v", err)
+	}
+	if err := s.SaveTransaction(Transaction{Hash: "hash-1", Operation: "draw_winner", Status: TxStatusPending}); err != nil {
+		t.Fatalf("SaveTransaction() failed: %v", err)
+	}
+	if err := s.UpdateTransactionStatus("hash-1", TxStatusSuccess, ""); err != nil {
+		t.Fatalf("UpdateTransactionStatus() failed: %v", err)
+	}
+
+	// 重新開啟，模擬服務重啟
+	reopened := openTestStore(t, path)
+
+	round, err := reopened.GetRound(3)
+	if err != nil {
+		t.Fatalf("GetRound() failed: %v", err)
+	}
+	if round.ParticipantCount != 2 || round.Result == nil || *round.Result != *result || round.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected round: %+v", round)
+	}
+
+	got, err := reopened.ListParticipants(3)
+	if err != nil || len(got) != 2 || got[0].Index != 0 || got[1].Index != 1 || got[0].Round != 3 {
+		t.Errorf("Expected participants sorted by index, got %+v (%v)", got, err)
+	}
+
+	tx, err := reopened.GetTransaction("hash-1")
+	if err != nil || tx.Status != TxStatusSuccess || tx.Operation != "draw_winner" || tx.SentAt.IsZero() {
+		t.Errorf("Unexpected transaction: %+v (%v)", tx, err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the data file, found %d entries", len(entries))
+	}
+}
+
+func TestFileStoreRounds(t *testing.T) {
+	s := openTestStore(t, "")
+
+	for _, number := range []int{2, 5, 1, 4} {
+		if err := s.SaveRound(Round{Number: number, Closed: true}); err != nil {
+			t.Fatalf("SaveRound() failed: %v", err)
+		}
+	}
+
+	rounds, _ := s.ListRounds(0, 3)
+	if len(rounds) != 3 || rounds[0].Number != 5 || rounds[1].Number != 4 || rounds[2].Number != 2 {
+		t.Errorf("Expected rounds 5, 4, 2, got %+v", rounds)
+	}
+	rounds, _ = s.ListRounds(4, 0)
+	if len(rounds) != 2 || rounds[0].Number != 2 || rounds[1].Number != 1 {
+		t.Errorf("Expected rounds 2, 1 before 4, got %+v", rounds)
+	}
+
+	if _, err := s.GetRound(3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// 返回的記錄是複本
+	if err := s.SaveRound(Round{Number: 6, Result: &ton.LotteryResult{NFTId: 6042}}); err != nil {
+		t.Fatalf("SaveRound() failed: %v", err)
+	}
+	round, _ := s.GetRound(6)
+	round.Result.NFTId = 0
+	if again, _ := s.GetRound(6); again.Result.NFTId != 6042 {
+		t.Errorf("Expected stored result to be unchanged, got %d", again.Result.NFTId)
+	}
+}
+
+func TestFileStoreTransactions(t *testing.T) {
+	s := openTestStore(t, "")
+	base := time.Unix(1700000000, 0)
+
+	txs := []Transaction{
+		{Hash: "a", Operation: "draw_winner", Status: TxStatusSuccess, SentAt: base},
+		{Hash: "b", Operation: "start_new_round", Status: TxStatusPending, SentAt: base.Add(time.Minute)},
+		{Hash: "c", Operation: "draw_winner", Status: TxStatusPending, SentAt: base.Add(2 * time.Minute)},
+	}
+	for _, tx := range txs {
+		if err := s.SaveTransaction(tx); err != nil {
+			t.Fatalf("SaveTransaction() failed: %v", err)
+		}
+	}
+
+	all, _ := s.ListTransactions("", 0)
+	if len(all) != 3 || all[0].Hash != "c" || all[2].Hash != "a" {
+		t.Errorf("Expected newest first, got %+v", all)
+	}
+	pending, _ := s.ListTransactions(TxStatusPending, 1)
+	if len(pending) != 1 || pending[0].Hash != "c" {
+		t.Errorf("Expected newest pending transaction, got %+v", pending)
+	}
+
+	if err := s.UpdateTransactionStatus("c", TxStatusFailed, "交易執行失敗"); err != nil {
+		t.Fatalf("UpdateTransactionStatus() failed: %v", err)
+	}
+	if tx, _ := s.GetTransaction("c"); tx.Status != TxStatusFailed || tx.Error != "交易執行失敗" || !tx.SentAt.Equal(txs[2].SentAt) {
+		t.Errorf("Unexpected updated transaction: %+v", tx)
+	}
+
+	if err := s.UpdateTransactionStatus("missing", TxStatusSuccess, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := s.SaveTransaction(Transaction{}); err == nil {
+		t.Error("Expected empty hash to fail")
+	}
+}
+
+func TestFileStoreWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStore(t, filepath.Join(dir, "lottery.json"))
+
+	// 以同名檔案佔用資料目錄的位置，讓寫入失敗
+	s.path = filepath.Join(dir, "blocked", "lottery.json")
+	if err := os.WriteFile(filepath.Join(dir, "blocked"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SaveRound(Round{Number: 1}); err == nil {
+		t.Fatal("Expected SaveRound() to fail")
+	}
+	if _, err := s.GetRound(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected failed write to be rolled back, got %v", err)
+	}
+}
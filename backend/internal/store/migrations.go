@@ -0,0 +1,82 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// schemaVersionKey 資料檔中記錄 schema 版本的欄位
+const schemaVersionKey = "schema_version"
+
+// rawDocument 尚未解析為 Go 型別的資料檔內容，migration 以此操作欄位，
+// 不受目前版本的結構定義限制
+type rawDocument map[string]json.RawMessage
+
+// migration 將資料檔從前一個版本升級到 version
+type migration struct {
+	version     int
+	description string
+	apply       func(doc rawDocument) error
+}
+
+// migrations 依版本排序的 schema 變更，新增版本時只能附加在最後
+var migrations = []migration{
+	{
+		version:     1,
+		description: "建立輪次、參與者與交易集合",
+		apply: func(doc rawDocument) error {
+			for _, key := range []string{"rounds", "participants", "transactions"} {
+				if _, ok := doc[key]; !ok {
+					doc[key] = json.RawMessage(`[]`)
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// schemaVersion 讀取資料檔的 schema 版本，沒有記錄時為 0（空檔案）
+func (doc rawDocument) schemaVersion() (int, error) {
+	raw, ok := doc[schemaVersionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("無效的 schema 版本: %w", err)
+	}
+	return version, nil
+}
+
+// migrate 依序套用版本大於資料檔目前版本的 migration，返回套用前後的版本
+//
+// 資料檔版本比程式支援的版本新時返回錯誤，避免舊版程式覆寫新格式的資料。
+func migrate(doc rawDocument, migrations []migration) (from, to int, err error) {
+	from, err = doc.schemaVersion()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].version
+	}
+	if from > latest {
+		return from, from, fmt.Errorf("資料檔 schema 版本 %d 比支援的版本 %d 新", from, latest)
+	}
+
+	to = from
+	for _, m := range migrations {
+		if m.version <= to {
+			continue
+		}
+		if err := m.apply(doc); err != nil {
+			return from, to, fmt.Errorf("套用 migration %d (%s) 失敗: %w", m.version, m.description, err)
+		}
+
+		to = m.version
+		doc[schemaVersionKey] = json.RawMessage(fmt.Sprint(to))
+	}
+	return from, to, nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenCreatesLatestSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lottery.json")
+	openTestStore(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected data file to be created: %v", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if want := migrations[len(migrations)-1].version; doc.SchemaVersion != want {
+		t.Errorf("Expected schema version %d, got %d", want, doc.SchemaVersion)
+	}
+}
+
+func TestMigrateUpgradesOldFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lottery.json")
+	// 版本 1 的資料檔，交易狀態欄位在版本 2 改名
+	legacy := `{"schema_version": 1, "rounds": [], "participants": [], "transactions": [{"hash": "a", "operation": "draw_winner", "state": "success"}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := 0
+	testMigrations := append(append([]migration{}, migrations...), migration{
+		version:     2,
+		description: "交易 state 欄位改名為 status",
+		apply: func(doc rawDocument) error {
+			applied++
+			var txs []map[string]json.RawMessage
+			if err := json.Unmarshal(doc["transactions"], &txs); err != nil {
+				return err
+			}
+			for _, tx := range txs {
+				tx["status"] = tx["state"]
+				delete(tx, "state")
+			}
+			data, err := json.Marshal(txs)
+			doc["transactions"] = data
+			return err
+		},
+	})
+
+	s, err := open(path, testMigrations)
+	if err != nil {
+		t.Fatalf("open() failed: %v", err)
+	}
+	if tx, err := s.GetTransaction("a"); err != nil || tx.Status != TxStatusSuccess {
+		t.Errorf("Expected migrated status, got %+v (%v)", tx, err)
+	}
+
+	// 已套用的 migration 不會重複執行
+	if _, err := open(path, testMigrations); err != nil {
+		t.Fatalf("open() failed: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Expected migration to run once, ran %d times", applied)
+	}
+}
+
+func TestMigrateRejectsNewerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lottery.json")
+	if err := os.WriteFile(path, []byte(`{"schema_version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "99") {
+		t.Errorf("Expected newer schema to be rejected, got %v", err)
+	}
+
+	// 拒絕開啟時不覆寫檔案
+	if data, _ := os.ReadFile(path); string(data) != `{"schema_version": 99}` {
+		t.Errorf("Expected data file to be untouched, got %s", data)
+	}
+}
+
+func TestOpenCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lottery.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Error("Expected corrupted data file to fail")
+	}
+}
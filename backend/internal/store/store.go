@@ -0,0 +1,74 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+// ErrNotFound 查詢的記錄不存在
+var ErrNotFound = errors.New("記錄不存在")
+
+// 交易確認狀態，與交易監控器的狀態字串相同
+const (
+	TxStatusPending = "pending" // 已發送，等待確認
+	TxStatusSuccess = "success" // 已在鏈上成功執行
+	TxStatusFailed  = "failed"  // 已在鏈上執行失敗
+	TxStatusTimeout = "timeout" // 重試後仍未確認，不再自動追蹤
+)
+
+// Round 輪次記錄
+//
+// 合約每輪只能開獎一次，Result 寫入後不再改變。Closed 表示合約已進入下一輪，
+// 此時沒有 Result 即代表該輪沒有開獎。
+type Round struct {
+	Number           int                `json:"number"`
+	ParticipantCount int                `json:"participant_count"`
+	Result           *ton.LotteryResult `json:"result,omitempty"`
+	Closed           bool               `json:"closed"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// Participant 輪次中的參與者，以輪次與合約索引識別
+type Participant struct {
+	Round int `json:"round"`
+	Index int `json:"index"`
+	ton.Participant
+}
+
+// Transaction 後端錢包發送的 owner 操作交易
+type Transaction struct {
+	Hash      string    `json:"hash"`
+	Operation string    `json:"operation"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Repository 輪次、參與者、中獎記錄與已發送交易的持久化存取
+//
+// 返回的記錄都是複本，修改後需呼叫對應的 Save 方法才會寫入。
+type Repository interface {
+	// SaveRound 新增或更新輪次記錄
+	SaveRound(round Round) error
+	// GetRound 查詢輪次記錄，不存在時返回 ErrNotFound
+	GetRound(number int) (*Round, error)
+	// ListRounds 由新到舊列出編號小於 before 的輪次，before 為 0 時從最新的輪次開始
+	ListRounds(before, limit int) ([]Round, error)
+
+	// SaveParticipants 新增或更新輪次中的參與者
+	SaveParticipants(round int, participants []Participant) error
+	// ListParticipants 依索引列出輪次中的參與者
+	ListParticipants(round int) ([]Participant, error)
+
+	// SaveTransaction 新增或更新交易記錄，以 Hash 識別
+	SaveTransaction(tx Transaction) error
+	// UpdateTransactionStatus 更新交易狀態，交易不存在時返回 ErrNotFound
+	UpdateTransactionStatus(hash, status, errMsg string) error
+	// GetTransaction 查詢交易記錄，不存在時返回 ErrNotFound
+	GetTransaction(hash string) (*Transaction, error)
+	// ListTransactions 由新到舊列出交易，status 為空時列出所有狀態，limit 為 0 時不限制數量
+	ListTransactions(status string, limit int) ([]Transaction, error)
+}
@@ -0,0 +1,197 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 推送給前端的事件類型
+const (
+	EventParticipantJoined = "participant_joined"
+	EventRoundFull         = "round_full"
+	EventWinnerDrawn       = "winner_drawn"
+	EventNewRoundStarted   = "new_round_started"
+	EventTxConfirmed       = "tx_confirmed"
+
+	// EventReset 無法從 Last-Event-ID 續傳（事件已不在緩衝區或來自重啟前），客戶端應重新載入完整狀態
+	EventReset = "reset"
+)
+
+const (
+	// DefaultBufferSize 保留供續傳的最近事件數量
+	DefaultBufferSize = 1024
+
+	// subscriberBuffer 每個訂閱者的待送事件數量，塞滿時中斷該訂閱者，由客戶端以 Last-Event-ID 重新連線
+	subscriberBuffer = 64
+)
+
+// Event 推送的事件，ID 在同一次啟動中嚴格遞增
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewRoundStarted 開始新輪次交易確認成功
+type NewRoundStarted struct {
+	Round  int    `json:"round"`
+	TxHash string `json:"tx_hash"`
+}
+
+// TxConfirmed 後端發送的交易已在鏈上執行
+type TxConfirmed struct {
+	Hash      string `json:"hash"`
+	Operation string `json:"operation,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Broker 將事件廣播給所有訂閱者，並保留最近的事件供斷線續傳
+//
+// 事件 ID 由啟動時間（毫秒 × 1000）開始遞增，重啟後的 ID 必定大於重啟前的 ID，
+// 帶著重啟前 ID 的客戶端會收到 EventReset 而不是錯誤的續傳。
+type Broker struct {
+	mu     sync.Mutex
+	nextID uint64
+	size   int
+	buffer []Event
+	subs   map[*Subscription]struct{}
+	closed bool
+
+	now func() time.Time
+}
+
+// Subscription 單一客戶端的訂閱
+type Subscription struct {
+	// Backlog 訂閱時需要補送的事件（Last-Event-ID 之後的事件）；
+	// 無法續傳時只包含一個 EventReset，其 ID 為目前最新的事件 ID，客戶端可由此繼續
+	Backlog []Event
+	// Reset 無法從 Last-Event-ID 續傳
+	Reset bool
+
+	broker *Broker
+	ch     chan Event
+}
+
+// NewBroker 創建保留 size 個最近事件的 Broker，size 不大於 0 時使用 DefaultBufferSize
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	now := time.Now
+	return &Broker{
+		nextID: uint64(now().UnixMilli()) * 1000,
+		size:   size,
+		subs:   make(map[*Subscription]struct{}),
+		now:    now,
+	}
+}
+
+// Publish 發布事件給所有訂閱者，data 以 JSON 編碼
+//
+// 不會阻塞：待送事件已滿的訂閱者會被中斷。
+func (b *Broker) Publish(eventType string, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("編碼 %s 事件失敗: %w", eventType, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Event{}, fmt.Errorf("事件廣播已關閉")
+	}
+
+	event := Event{ID: b.nextID, Type: eventType, Time: b.now().UTC(), Data: raw}
+	b.nextID++
+
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > b.size {
+		b.buffer = append(b.buffer[:0], b.buffer[len(b.buffer)-b.size:]...)
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+	return event, nil
+}
+
+// Subscribe 訂閱之後發布的事件
+//
+// lastEventID 不為 0 時，Backlog 包含該 ID 之後仍在緩衝區的事件；
+// 該 ID 之後的事件已被丟棄或 ID 不屬於這次啟動時，Reset 為 true 且 Backlog 只包含 EventReset。
+func (b *Broker) Subscribe(lastEventID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{broker: b, ch: make(chan Event, subscriberBuffer)}
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+
+	if lastEventID != 0 {
+		sub.Backlog, sub.Reset = b.since(lastEventID)
+		if sub.Reset {
+			sub.Backlog = []Event{{ID: b.nextID - 1, Type: EventReset, Time: b.now().UTC(), Data: json.RawMessage(`{}`)}}
+		}
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// since 返回 lastEventID 之後的事件，無法續傳時返回 reset
+func (b *Broker) since(lastEventID uint64) (events []Event, reset bool) {
+	if lastEventID >= b.nextID {
+		return nil, true
+	}
+	if lastEventID+1 == b.nextID {
+		return nil, false
+	}
+	if len(b.buffer) == 0 || lastEventID+1 < b.buffer[0].ID {
+		return nil, true
+	}
+
+	start := int(lastEventID + 1 - b.buffer[0].ID)
+	return append([]Event(nil), b.buffer[start:]...), false
+}
+
+// drop 移除訂閱者並關閉其事件通道，呼叫端需持有鎖
+func (b *Broker) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Close 中斷所有訂閱者，之後的 Publish 會返回錯誤
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// Events 之後發布的事件，通道關閉表示訂閱已被中斷（處理過慢或 Broker 關閉）
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close 取消訂閱
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.drop(s)
+}
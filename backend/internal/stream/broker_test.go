@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// receive 從訂閱讀取一個事件
+func receive(t *testing.T, sub *Subscription) (Event, bool) {
+	t.Helper()
+
+	select {
+	case event, ok := <-sub.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+		return Event{}, false
+	}
+}
+
+func publish(t *testing.T, b *Broker, eventType string, data interface{}) Event {
+	t.Helper()
+
+	event, err := b.Publish(eventType, data)
+	if err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+	return event
+}
+
+func TestBrokerPublishSubscribe(t *testing.T) {
+	b := NewBroker(10)
+	sub := b.Subscribe(0)
+	defer sub.Close()
+
+	if len(sub.Backlog) != 0 || sub.Reset {
+		t.Errorf("Expected no backlog for a new subscriber, got %+v", sub.Backlog)
+	}
+
+	first := publish(t, b, EventNewRoundStarted, NewRoundStarted{Round: 2, TxHash: "hash-1"})
+	second := publish(t, b, EventTxConfirmed, TxConfirmed{Hash: "hash-1", Status: "success"})
+	if second.ID != first.ID+1 {
+		t.Errorf("Expected consecutive IDs, got %d and %d", first.ID, second.ID)
+	}
+
+	event, _ := receive(t, sub)
+	var data NewRoundStarted
+	if err := json.Unmarshal(event.Data, &data); err != nil || event.Type != EventNewRoundStarted || data.Round != 2 {
+		t.Errorf("Unexpected event: %+v (%v)", event, err)
+	}
+	if event, _ := receive(t, sub); event.ID != second.ID {
+		t.Errorf("Expected event %d, got %d", second.ID, event.ID)
+	}
+
+	if _, err := b.Publish(EventTxConfirmed, func() {}); err == nil {
+		t.Error("Expected unencodable data to fail")
+	}
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := NewBroker(3)
+	var events []Event
+	for i := 0; i < 5; i++ {
+		events = append(events, publish(t, b, EventTxConfirmed, TxConfirmed{Hash: "hash"}))
+	}
+
+	// 緩衝區保留最後 3 個事件
+	sub := b.Subscribe(events[2].ID)
+	if sub.Reset || len(sub.Backlog) != 2 || sub.Backlog[0].ID != events[3].ID || sub.Backlog[1].ID != events[4].ID {
+		t.Errorf("Expected events 3 and 4, got %+v", sub.Backlog)
+	}
+	sub.Close()
+
+	// 已是最新的事件
+	sub = b.Subscribe(events[4].ID)
+	if sub.Reset || len(sub.Backlog) != 0 {
+		t.Errorf("Expected no backlog, got %+v", sub.Backlog)
+	}
+	sub.Close()
+
+	// 已被丟棄、重啟前或不存在的 ID
+	for _, id := range []uint64{events[0].ID, 1, events[4].ID + 100} {
+		sub = b.Subscribe(id)
+		if !sub.Reset || len(sub.Backlog) != 1 || sub.Backlog[0].Type != EventReset || sub.Backlog[0].ID != events[4].ID {
+			t.Errorf("Subscribe(%d): expected reset at %d, got %+v", id, events[4].ID, sub.Backlog)
+		}
+		sub.Close()
+	}
+}
+
+func TestBrokerIDsIncreaseAcrossRestart(t *testing.T) {
+	before := publish(t, NewBroker(0), EventTxConfirmed, TxConfirmed{})
+	time.Sleep(2 * time.Millisecond)
+
+	restarted := NewBroker(0)
+	sub := restarted.Subscribe(before.ID)
+	defer sub.Close()
+	if !sub.Reset {
+		t.Error("Expected an ID from before the restart to reset")
+	}
+	if after := publish(t, restarted, EventTxConfirmed, TxConfirmed{}); after.ID <= before.ID {
+		t.Errorf("Expected IDs to increase across restarts, got %d after %d", after.ID, before.ID)
+	}
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(0)
+	slow := b.Subscribe(0)
+	fast := b.Subscribe(0)
+	defer fast.Close()
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		publish(t, b, EventTxConfirmed, TxConfirmed{})
+		<-fast.Events()
+	}
+
+	// 待送事件塞滿後通道被關閉
+	count := 0
+	for range slow.Events() {
+		count++
+	}
+	if count != subscriberBuffer {
+		t.Errorf("Expected %d buffered events before the drop, got %d", subscriberBuffer, count)
+	}
+	slow.Close()
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(0)
+	sub := b.Subscribe(0)
+	b.Close()
+
+	if _, ok := receive(t, sub); ok {
+		t.Error("Expected subscription to be closed")
+	}
+	if _, err := b.Publish(EventTxConfirmed, TxConfirmed{}); err == nil {
+		t.Error("Expected Publish() after Close() to fail")
+	}
+	if _, ok := <-b.Subscribe(0).Events(); ok {
+		t.Error("Expected new subscription after Close() to be closed")
+	}
+	sub.Close()
+}
@@ -0,0 +1,234 @@
+package address
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 使用者友善格式的標記位元組
+	tagBounceable    byte = 0x11
+	tagNonBounceable byte = 0x51
+	flagTestnetOnly  byte = 0x80
+
+	// friendlyLength 使用者友善格式解碼後的長度：tag(1) + workchain(1) + hash(32) + crc16(2)
+	friendlyLength = 36
+)
+
+// Address TON 標準地址 (addr_std)
+type Address struct {
+	workchain   int8
+	data        [32]byte
+	bounceable  bool
+	testnetOnly bool
+}
+
+// NewAddress 以 workchain 與 32 位元組帳戶 ID 創建地址（預設可退回、非測試網專用）
+func NewAddress(workchain int8, data []byte) (*Address, error) {
+	if len(data) != 32 {
+		return nil, fmt.Errorf("地址資料長度無效，預期 32 bytes，實際 %d bytes", len(data))
+	}
+
+	addr := &Address{
+		workchain:  workchain,
+		bounceable: true,
+	}
+	copy(addr.data[:], data)
+	return addr, nil
+}
+
+// Parse 解析地址，支援原始格式 (0:abcd…) 與使用者友善格式 (base64/base64url)
+func Parse(s string) (*Address, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		return ParseRaw(s)
+	}
+	return ParseFriendly(s)
+}
+
+// MustParse 解析地址，失敗時 panic（僅用於常數或測試）
+func MustParse(s string) *Address {
+	addr, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// ParseRaw 解析原始格式地址 (workchain:hex)
+func ParseRaw(s string) (*Address, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("無效的原始地址格式: %s", s)
+	}
+
+	workchain, err := strconv.ParseInt(parts[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 workchain: %s", parts[0])
+	}
+
+	data, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("無效的地址雜湊: %w", err)
+	}
+
+	return NewAddress(int8(workchain), data)
+}
+
+// ParseFriendly 解析使用者友善格式地址，並驗證 CRC16 校驗碼
+func ParseFriendly(s string) (*Address, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 48 {
+		return nil, fmt.Errorf("使用者友善地址長度無效，預期 48 字元，實際 %d 字元", len(s))
+	}
+
+	encoding := base64.URLEncoding
+	if strings.ContainsAny(s, "+/") {
+		encoding = base64.StdEncoding
+	}
+
+	raw, err := encoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 base64 地址: %w", err)
+	}
+	if len(raw) != friendlyLength {
+		return nil, fmt.Errorf("使用者友善地址長度無效: %d bytes", len(raw))
+	}
+
+	expected := binary.BigEndian.Uint16(raw[34:])
+	if actual := crc16(raw[:34]); actual != expected {
+		return nil, fmt.Errorf("地址校驗碼錯誤: 預期 %04x，實際 %04x", expected, actual)
+	}
+
+	tag := raw[0]
+	testnetOnly := tag&flagTestnetOnly != 0
+	tag &^= flagTestnetOnly
+
+	var bounceable bool
+	switch tag {
+	case tagBounceable:
+		bounceable = true
+	case tagNonBounceable:
+		bounceable = false
+	default:
+		return nil, fmt.Errorf("無效的地址標記: 0x%02x", raw[0])
+	}
+
+	addr, err := NewAddress(int8(raw[1]), raw[2:34])
+	if err != nil {
+		return nil, err
+	}
+	addr.bounceable = bounceable
+	addr.testnetOnly = testnetOnly
+	return addr, nil
+}
+
+// Workchain 獲取 workchain ID
+func (a *Address) Workchain() int8 {
+	return a.workchain
+}
+
+// Data 獲取 32 位元組帳戶 ID
+func (a *Address) Data() []byte {
+	out := make([]byte, len(a.data))
+	copy(out, a.data[:])
+	return out
+}
+
+// IsBounceable 是否為可退回地址
+func (a *Address) IsBounceable() bool {
+	return a.bounceable
+}
+
+// IsTestnetOnly 是否為測試網專用地址
+func (a *Address) IsTestnetOnly() bool {
+	return a.testnetOnly
+}
+
+// WithBounceable 返回設定了可退回旗標的地址副本
+func (a *Address) WithBounceable(bounceable bool) *Address {
+	clone := *a
+	clone.bounceable = bounceable
+	return &clone
+}
+
+// WithTestnetOnly 返回設定了測試網專用旗標的地址副本
+func (a *Address) WithTestnetOnly(testnetOnly bool) *Address {
+	clone := *a
+	clone.testnetOnly = testnetOnly
+	return &clone
+}
+
+// Equals 比較兩個地址是否指向同一個帳戶（忽略格式旗標）
+func (a *Address) Equals(other *Address) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+	return a.workchain == other.workchain && bytes.Equal(a.data[:], other.data[:])
+}
+
+// String 以使用者友善的 base64url 格式輸出地址
+func (a *Address) String() string {
+	return a.ToFriendly(true)
+}
+
+// StringRaw 以原始格式輸出地址 (workchain:hex)
+func (a *Address) StringRaw() string {
+	return fmt.Sprintf("%d:%s", a.workchain, hex.EncodeToString(a.data[:]))
+}
+
+// ToFriendly 以使用者友善格式輸出地址，urlSafe 決定使用 base64url 或標準 base64
+func (a *Address) ToFriendly(urlSafe bool) string {
+	raw := make([]byte, 0, friendlyLength)
+
+	tag := tagNonBounceable
+	if a.bounceable {
+		tag = tagBounceable
+	}
+	if a.testnetOnly {
+		tag |= flagTestnetOnly
+	}
+
+	raw = append(raw, tag, byte(a.workchain))
+	raw = append(raw, a.data[:]...)
+	raw = binary.BigEndian.AppendUint16(raw, crc16(raw))
+
+	if urlSafe {
+		return base64.URLEncoding.EncodeToString(raw)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// Equal 比較兩個地址字串是否指向同一個帳戶，任一方無法解析時返回 false
+func Equal(a, b string) bool {
+	addrA, err := Parse(a)
+	if err != nil {
+		return false
+	}
+	addrB, err := Parse(b)
+	if err != nil {
+		return false
+	}
+	return addrA.Equals(addrB)
+}
+
+// crc16 計算 CRC16-XMODEM 校驗碼
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
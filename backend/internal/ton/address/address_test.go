@@ -0,0 +1,159 @@
+package address
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	// TON Foundation 地址，用於驗證格式轉換
+	testFriendly = "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF"
+	testRaw      = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+)
+
+func TestParseFriendly(t *testing.T) {
+	addr, err := Parse(testFriendly)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if addr.Workchain() != 0 {
+		t.Errorf("Expected workchain=0, got %d", addr.Workchain())
+	}
+
+	if !addr.IsBounceable() {
+		t.Error("Expected EQ address to be bounceable")
+	}
+
+	if addr.IsTestnetOnly() {
+		t.Error("Expected EQ address not to be testnet only")
+	}
+
+	if addr.StringRaw() != testRaw {
+		t.Errorf("Expected raw=%s, got %s", testRaw, addr.StringRaw())
+	}
+
+	if addr.String() != testFriendly {
+		t.Errorf("Expected friendly=%s, got %s", testFriendly, addr.String())
+	}
+}
+
+func TestParseRaw(t *testing.T) {
+	addr, err := Parse(testRaw)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if addr.String() != testFriendly {
+		t.Errorf("Expected friendly=%s, got %s", testFriendly, addr.String())
+	}
+
+	masterchain, err := ParseRaw("-1:" + strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("ParseRaw() for masterchain failed: %v", err)
+	}
+	if masterchain.Workchain() != -1 {
+		t.Errorf("Expected workchain=-1, got %d", masterchain.Workchain())
+	}
+
+	reparsed, err := Parse(masterchain.String())
+	if err != nil {
+		t.Fatalf("Parse() of masterchain friendly address failed: %v", err)
+	}
+	if reparsed.Workchain() != -1 || !reparsed.Equals(masterchain) {
+		t.Error("Expected masterchain address round trip")
+	}
+}
+
+func TestFlags(t *testing.T) {
+	addr := MustParse(testFriendly)
+
+	nonBounceable := addr.WithBounceable(false)
+	if !strings.HasPrefix(nonBounceable.String(), "UQ") {
+		t.Errorf("Expected non-bounceable address to start with 'UQ', got %s", nonBounceable.String())
+	}
+
+	testnet := addr.WithTestnetOnly(true)
+	if !strings.HasPrefix(testnet.String(), "kQ") {
+		t.Errorf("Expected testnet bounceable address to start with 'kQ', got %s", testnet.String())
+	}
+
+	testnetNonBounceable := addr.WithBounceable(false).WithTestnetOnly(true)
+	if !strings.HasPrefix(testnetNonBounceable.String(), "0Q") {
+		t.Errorf("Expected testnet non-bounceable address to start with '0Q', got %s", testnetNonBounceable.String())
+	}
+
+	for _, variant := range []*Address{nonBounceable, testnet, testnetNonBounceable} {
+		parsed, err := Parse(variant.String())
+		if err != nil {
+			t.Fatalf("Parse(%s) failed: %v", variant.String(), err)
+		}
+		if parsed.IsBounceable() != variant.IsBounceable() || parsed.IsTestnetOnly() != variant.IsTestnetOnly() {
+			t.Errorf("Expected flags to survive round trip for %s", variant.String())
+		}
+		if !parsed.Equals(addr) {
+			t.Errorf("Expected %s to refer to the same account", variant.String())
+		}
+	}
+
+	// 原本的地址不應被修改
+	if !addr.IsBounceable() || addr.IsTestnetOnly() {
+		t.Error("Expected With* methods not to modify the original address")
+	}
+}
+
+func TestStandardBase64(t *testing.T) {
+	addr := MustParse(testFriendly)
+
+	std := addr.ToFriendly(false)
+	parsed, err := Parse(std)
+	if err != nil {
+		t.Fatalf("Parse(%s) failed: %v", std, err)
+	}
+	if !parsed.Equals(addr) {
+		t.Error("Expected standard base64 address to parse to the same account")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"fake short address", "EQTestLottery123"},
+		{"bad checksum", testFriendly[:47] + "A"},
+		{"raw bad hex", "0:zz"},
+		{"raw short hash", "0:abcd"},
+		{"raw bad workchain", "x:" + strings.Repeat("00", 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Expected Parse(%q) to fail", tt.input)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	nonBounceable := MustParse(testFriendly).WithBounceable(false).String()
+
+	if !Equal(testFriendly, testRaw) {
+		t.Error("Expected friendly and raw forms to be equal")
+	}
+
+	if !Equal(testFriendly, nonBounceable) {
+		t.Error("Expected bounceable and non-bounceable forms to be equal")
+	}
+
+	other := "0:" + strings.Repeat("00", 32)
+	if Equal(testFriendly, other) {
+		t.Error("Expected different accounts not to be equal")
+	}
+
+	if Equal("invalid", testFriendly) {
+		t.Error("Expected invalid address not to be equal to anything")
+	}
+}
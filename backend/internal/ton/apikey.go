@@ -0,0 +1,59 @@
+package ton
+
+import (
+	"sync/atomic"
+
+	"ton-cat-lottery-backend/pkg/metrics"
+)
+
+// keyRotations on_429 模式下因 429 切換 API key 的次數
+var keyRotations = metrics.NewCounterVec(
+	"ton_api_key_rotations_total",
+	"API key switches after rate limit responses.",
+	"endpoint",
+)
+
+// keyRing 端點的 API key 與輪替狀態
+//
+// toncenter 依 API key 計算請求頻率。round_robin 模式下每個請求依序使用下一個 key；
+// on_429 模式下使用同一個 key，直到收到 429 才切換到下一個。沒有 key 時 pick 返回空字串。
+type keyRing struct {
+	keys       []string
+	roundRobin bool
+	next       atomic.Uint64
+}
+
+// newKeyRing 創建 API key 輪替
+func newKeyRing(keys []string, roundRobin bool) *keyRing {
+	return &keyRing{keys: keys, roundRobin: roundRobin}
+}
+
+// len 返回 key 的數量
+func (r *keyRing) len() int {
+	return len(r.keys)
+}
+
+// pick 返回這次請求使用的 key 及其序號，序號用於 rotate
+func (r *keyRing) pick() (uint64, string) {
+	if len(r.keys) == 0 {
+		return 0, ""
+	}
+
+	var i uint64
+	if r.roundRobin {
+		i = r.next.Add(1) - 1
+	} else {
+		i = r.next.Load()
+	}
+	return i, r.keys[i%uint64(len(r.keys))]
+}
+
+// rotate 在序號 i 的 key 收到 429 後切換到下一個 key，返回是否由這次呼叫切換
+//
+// 同時收到多個 429 時只切換一次；round_robin 模式與只有一個 key 時不切換。
+func (r *keyRing) rotate(i uint64) bool {
+	if r.roundRobin || len(r.keys) < 2 {
+		return false
+	}
+	return r.next.CompareAndSwap(i, i+1)
+}
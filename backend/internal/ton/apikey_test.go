@@ -0,0 +1,162 @@
+package ton
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+func TestKeyRing(t *testing.T) {
+	if _, key := newKeyRing(nil, false).pick(); key != "" {
+		t.Errorf("Expected no key, got %q", key)
+	}
+
+	// round_robin 依序使用每個 key
+	rr := newKeyRing([]string{"a", "b", "c"}, true)
+	var got []string
+	for i := 0; i < 4; i++ {
+		_, key := rr.pick()
+		got = append(got, key)
+	}
+	if strings.Join(got, "") != "abca" {
+		t.Errorf("Expected round robin order abca, got %v", got)
+	}
+	if rr.rotate(0) {
+		t.Error("Expected round robin not to rotate on 429")
+	}
+
+	// on_429 使用同一個 key 直到收到 429，同時收到的 429 只切換一次
+	ring := newKeyRing([]string{"a", "b"}, false)
+	i, key := ring.pick()
+	if _, again := ring.pick(); key != "a" || again != "a" {
+		t.Errorf("Expected the same key until rate limited, got %s and %s", key, again)
+	}
+	if !ring.rotate(i) || ring.rotate(i) {
+		t.Error("Expected exactly one rotation for the same rate limited key")
+	}
+	if _, key := ring.pick(); key != "b" {
+		t.Errorf("Expected next key after 429, got %s", key)
+	}
+
+	if newKeyRing([]string{"a"}, false).rotate(0) {
+		t.Error("Expected a single key not to rotate")
+	}
+}
+
+// newKeyServer 創建記錄 X-API-Key 的 mock 端點，rateLimited 中的 key 回應 429
+func newKeyServer(t *testing.T, rateLimited map[string]bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if rateLimited[key] {
+			// 錯誤訊息包含 key，用於確認日誌遮蔽
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(APIResponse{Ok: false, Code: 429, Error: "Ratelimit exceed for key " + key})
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`{"balance": "1"}`)})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestRequestKeyRotation(t *testing.T) {
+	server, sent := newKeyServer(t, map[string]bool{"secret-key-1": true})
+
+	var buf bytes.Buffer
+	log := &logger.Logger{Logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	cfg := &config.Config{
+		TONAPIEndpoint:       server.URL + "/",
+		TONAPIKeys:           []string{"secret-key-1", "secret-key-2"},
+		TONAPIKeyRotation:    config.KeyRotationOn429,
+		TONAPIMaxRetries:     1,
+		TONAPIRetryBaseDelay: time.Millisecond,
+		TONAPIRetryMaxDelay:  time.Second,
+	}
+	client := NewClient(cfg, log)
+	before := keyRotations.Value(server.URL + "/")
+
+	// Retry-After 只針對第一個 key，切換後立即以第二個 key 重試
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetContractInfo(context.Background(), "EQTest123"); err != nil {
+			t.Fatalf("GetContractInfo() failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected rotation not to wait for Retry-After, took %v", elapsed)
+	}
+
+	if got := strings.Join(sent(), ","); got != "secret-key-1,secret-key-2,secret-key-2" {
+		t.Errorf("Unexpected keys sent: %s", got)
+	}
+	if got := keyRotations.Value(server.URL+"/") - before; got != 1 {
+		t.Errorf("Expected 1 key rotation, got %d", got)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Ratelimit exceed for key "+logger.Redacted) {
+		t.Errorf("Expected rate limit error to be logged, got: %s", output)
+	}
+	if strings.Contains(output, "secret-key") {
+		t.Errorf("Expected API keys to be redacted from logs, got: %s", output)
+	}
+}
+
+func TestRequestKeyRoundRobin(t *testing.T) {
+	server, sent := newKeyServer(t, nil)
+
+	cfg := &config.Config{
+		TONAPIEndpoints:   server.URL + "/",
+		TONAPIKeys:        []string{"key-a", "key-b"},
+		TONAPIKeyRotation: config.KeyRotationRoundRobin,
+	}
+	client := NewClient(cfg, logger.New("error"))
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetContractInfo(context.Background(), "EQTest123"); err != nil {
+			t.Fatalf("GetContractInfo() failed: %v", err)
+		}
+	}
+
+	if got := strings.Join(sent(), ","); got != "key-a,key-b,key-a" {
+		t.Errorf("Expected keys to alternate, got %s", got)
+	}
+}
+
+func TestEndpointKeyOverridesGlobalKeys(t *testing.T) {
+	server, sent := newKeyServer(t, nil)
+
+	cfg := &config.Config{
+		TONAPIEndpoints: server.URL + "/|endpoint-key",
+		TONAPIKeys:      []string{"global-key"},
+	}
+	if _, err := NewClient(cfg, logger.New("error")).GetContractInfo(context.Background(), "EQTest123"); err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if got := sent(); len(got) != 1 || got[0] != "endpoint-key" {
+		t.Errorf("Expected endpoint key to be used, got %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package cell
+
+import (
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+)
+
+// StoreAddress 寫入 MsgAddress：nil 寫入 addr_none$00，否則寫入 addr_std$10
+func (b *Builder) StoreAddress(addr *address.Address) *Builder {
+	if addr == nil {
+		return b.StoreUInt(0, 2)
+	}
+	return b.StoreUInt(0b10, 2).
+		StoreBool(false). // anycast: nothing
+		StoreInt(int64(addr.Workchain()), 8).
+		StoreBytes(addr.Data())
+}
+
+// LoadAddress 讀取 MsgAddress：addr_none 返回 nil，僅支援 addr_std
+func (s *Slice) LoadAddress() (*address.Address, error) {
+	tag, err := s.LoadUInt(2)
+	if err != nil {
+		return nil, fmt.Errorf("讀取地址類型失敗: %w", err)
+	}
+
+	switch tag {
+	case 0b00:
+		return nil, nil
+	case 0b10:
+	default:
+		return nil, fmt.Errorf("不支援的地址類型: %02b", tag)
+	}
+
+	anycast, err := s.LoadBool()
+	if err != nil {
+		return nil, err
+	}
+	if anycast {
+		return nil, fmt.Errorf("不支援 anycast 地址")
+	}
+
+	workchain, err := s.LoadInt(8)
+	if err != nil {
+		return nil, fmt.Errorf("讀取 workchain 失敗: %w", err)
+	}
+
+	data, err := s.LoadBytes(32)
+	if err != nil {
+		return nil, fmt.Errorf("讀取地址雜湊失敗: %w", err)
+	}
+
+	return address.NewAddress(int8(workchain), data)
+}
@@ -0,0 +1,383 @@
+package cell
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+)
+
+// bocMagic 標準 BOC 格式的魔術數字
+var bocMagic = []byte{0xb5, 0xee, 0x9c, 0x72}
+
+// crcTable CRC32C (Castagnoli) 查表
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ToBOC 以標準格式序列化 Cell（附帶 CRC32C 校驗）
+func (c *Cell) ToBOC() []byte {
+	return c.ToBOCWithFlags(true)
+}
+
+// ToBOCWithFlags 以標準格式序列化 Cell，可選擇是否附帶 CRC32C 校驗
+func (c *Cell) ToBOCWithFlags(withCRC bool) []byte {
+	cells := topologicalOrder(c)
+
+	index := make(map[string]int, len(cells))
+	for i, cl := range cells {
+		index[string(cl.hash)] = i
+	}
+
+	refSize := bytesFor(uint64(len(cells)))
+
+	var payload []byte
+	for _, cl := range cells {
+		d1, d2 := cl.descriptors()
+		payload = append(payload, d1, d2)
+		payload = append(payload, cl.paddedData()...)
+		for _, ref := range cl.refs {
+			payload = appendUint(payload, uint64(index[string(ref.hash)]), refSize)
+		}
+	}
+
+	offSize := bytesFor(uint64(len(payload)))
+
+	var flags byte
+	if withCRC {
+		flags |= 0x40
+	}
+	flags |= byte(refSize)
+
+	out := make([]byte, 0, 4+2+refSize*4+offSize+len(payload)+4)
+	out = append(out, bocMagic...)
+	out = append(out, flags, byte(offSize))
+	out = appendUint(out, uint64(len(cells)), refSize) // cells
+	out = appendUint(out, 1, refSize)                  // roots
+	out = appendUint(out, 0, refSize)                  // absent
+	out = appendUint(out, uint64(len(payload)), offSize)
+	out = appendUint(out, 0, refSize) // root index
+	out = append(out, payload...)
+
+	if withCRC {
+		out = binary.LittleEndian.AppendUint32(out, crc32.Checksum(out, crcTable))
+	}
+
+	return out
+}
+
+// ToBOCBase64 序列化 Cell 並以 base64 編碼
+func (c *Cell) ToBOCBase64() string {
+	return base64.StdEncoding.EncodeToString(c.ToBOC())
+}
+
+// FromBOC 從 BOC 反序列化，返回第一個根 Cell
+func FromBOC(data []byte) (*Cell, error) {
+	roots, err := FromBOCMultiRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	return roots[0], nil
+}
+
+// FromBOCBase64 從 base64 編碼的 BOC 反序列化
+func FromBOCBase64(s string) (*Cell, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 base64 BOC: %w", err)
+	}
+	return FromBOC(data)
+}
+
+// FromBOCHex 從十六進位編碼的 BOC 反序列化
+func FromBOCHex(s string) (*Cell, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("無效的十六進位 BOC: %w", err)
+	}
+	return FromBOC(data)
+}
+
+// FromBOCMultiRoot 從 BOC 反序列化，返回所有根 Cell
+func FromBOCMultiRoot(data []byte) ([]*Cell, error) {
+	r := &bocReader{data: data}
+
+	magic, err := r.read(4)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, bocMagic) {
+		return nil, fmt.Errorf("無效的 BOC 魔術數字: %x", magic)
+	}
+
+	header, err := r.read(2)
+	if err != nil {
+		return nil, err
+	}
+	hasIdx := header[0]&0x80 != 0
+	hasCRC := header[0]&0x40 != 0
+	refSize := int(header[0] & 0x07)
+	offSize := int(header[1])
+
+	if refSize < 1 || refSize > 4 {
+		return nil, fmt.Errorf("無效的 BOC 引用大小: %d", refSize)
+	}
+	if offSize < 1 || offSize > 8 {
+		return nil, fmt.Errorf("無效的 BOC 偏移大小: %d", offSize)
+	}
+
+	if hasCRC {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("BOC 長度不足")
+		}
+		body := data[:len(data)-4]
+		expected := binary.LittleEndian.Uint32(data[len(data)-4:])
+		if actual := crc32.Checksum(body, crcTable); actual != expected {
+			return nil, fmt.Errorf("BOC CRC32C 校驗失敗: 預期 %08x，實際 %08x", expected, actual)
+		}
+		r.data = body
+	}
+
+	cellsNum, err := r.readUint(refSize)
+	if err != nil {
+		return nil, err
+	}
+	rootsNum, err := r.readUint(refSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.readUint(refSize); err != nil { // absent
+		return nil, err
+	}
+	totalSize, err := r.readUint(offSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if rootsNum == 0 || rootsNum > cellsNum {
+		return nil, fmt.Errorf("無效的 BOC 根數量: %d（cells=%d）", rootsNum, cellsNum)
+	}
+	// 數量來自未驗證的標頭，配置記憶體前先確認資料足夠：每個 Cell 至少有 2 位元組的描述
+	remaining := uint64(r.remaining())
+	if cellsNum > remaining/2 {
+		return nil, fmt.Errorf("BOC Cell 數量超出資料長度: %d（剩餘 %d 位元組）", cellsNum, remaining)
+	}
+	if rootsNum*uint64(refSize) > remaining {
+		return nil, fmt.Errorf("BOC 根數量超出資料長度: %d（剩餘 %d 位元組）", rootsNum, remaining)
+	}
+
+	rootIndexes := make([]int, rootsNum)
+	for i := range rootIndexes {
+		idx, err := r.readUint(refSize)
+		if err != nil {
+			return nil, err
+		}
+		if idx >= cellsNum {
+			return nil, fmt.Errorf("BOC 根索引超出範圍: %d", idx)
+		}
+		rootIndexes[i] = int(idx)
+	}
+
+	if hasIdx {
+		if _, err := r.read(int(cellsNum) * offSize); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := r.read(int(totalSize))
+	if err != nil {
+		return nil, err
+	}
+
+	cells, err := parseCells(payload, int(cellsNum), refSize)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]*Cell, len(rootIndexes))
+	for i, idx := range rootIndexes {
+		roots[i] = cells[idx]
+	}
+	return roots, nil
+}
+
+// rawCell 反序列化過程中的暫存資料
+type rawCell struct {
+	data    []byte
+	bitsLen int
+	refs    []int
+}
+
+// parseCells 解析 BOC 中的 Cell 資料區段
+func parseCells(payload []byte, count, refSize int) ([]*Cell, error) {
+	r := &bocReader{data: payload}
+	if count > len(payload)/2 {
+		return nil, fmt.Errorf("BOC Cell 數量超出資料長度: %d（資料 %d 位元組）", count, len(payload))
+	}
+	raws := make([]rawCell, count)
+
+	for i := 0; i < count; i++ {
+		desc, err := r.read(2)
+		if err != nil {
+			return nil, fmt.Errorf("讀取 Cell %d 描述失敗: %w", i, err)
+		}
+		d1, d2 := desc[0], desc[1]
+
+		refsNum := int(d1 & 0x07)
+		if d1&0x08 != 0 {
+			return nil, fmt.Errorf("Cell %d 為特殊 Cell，目前不支援", i)
+		}
+		if refsNum > MaxRefs {
+			return nil, fmt.Errorf("Cell %d 引用數無效: %d", i, refsNum)
+		}
+		if d1&0x10 != 0 {
+			// 附帶雜湊與深度，直接略過
+			level := int(d1 >> 5)
+			if _, err := r.read((level + 1) * (32 + 2)); err != nil {
+				return nil, err
+			}
+		}
+
+		dataLen := int(d2+1) / 2
+		data, err := r.read(dataLen)
+		if err != nil {
+			return nil, fmt.Errorf("讀取 Cell %d 資料失敗: %w", i, err)
+		}
+
+		bitsLen := dataLen * 8
+		if d2%2 == 1 {
+			bitsLen, err = unpaddedBits(data)
+			if err != nil {
+				return nil, fmt.Errorf("Cell %d: %w", i, err)
+			}
+		}
+
+		refs := make([]int, refsNum)
+		for j := range refs {
+			idx, err := r.readUint(refSize)
+			if err != nil {
+				return nil, fmt.Errorf("讀取 Cell %d 引用失敗: %w", i, err)
+			}
+			if int(idx) <= i || int(idx) >= count {
+				return nil, fmt.Errorf("Cell %d 引用索引無效: %d", i, idx)
+			}
+			refs[j] = int(idx)
+		}
+
+		raws[i] = rawCell{data: data, bitsLen: bitsLen, refs: refs}
+	}
+
+	// 引用只會指向後面的 Cell，因此從尾端開始建立
+	cells := make([]*Cell, count)
+	for i := count - 1; i >= 0; i-- {
+		raw := raws[i]
+		c := &Cell{
+			data:    make([]byte, (raw.bitsLen+7)/8),
+			bitsLen: raw.bitsLen,
+			refs:    make([]*Cell, len(raw.refs)),
+		}
+		copy(c.data, raw.data)
+		if rem := raw.bitsLen % 8; rem != 0 {
+			c.data[len(c.data)-1] &= 0xFF << (8 - rem)
+		}
+		for j, idx := range raw.refs {
+			c.refs[j] = cells[idx]
+		}
+		c.finalize()
+		cells[i] = c
+	}
+
+	return cells, nil
+}
+
+// unpaddedBits 根據補位規則計算實際位元數
+func unpaddedBits(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("補位資料為空")
+	}
+	last := data[len(data)-1]
+	if last == 0 {
+		return 0, fmt.Errorf("無效的補位")
+	}
+	trailing := 0
+	for last&1 == 0 {
+		last >>= 1
+		trailing++
+	}
+	return len(data)*8 - trailing - 1, nil
+}
+
+// topologicalOrder 以拓撲順序排列所有 Cell（父節點在前），相同雜湊的 Cell 只出現一次
+func topologicalOrder(root *Cell) []*Cell {
+	visited := make(map[string]bool)
+	var postOrder []*Cell
+
+	var visit func(c *Cell)
+	visit = func(c *Cell) {
+		key := string(c.hash)
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		for _, ref := range c.refs {
+			visit(ref)
+		}
+		postOrder = append(postOrder, c)
+	}
+	visit(root)
+
+	order := make([]*Cell, len(postOrder))
+	for i, c := range postOrder {
+		order[len(postOrder)-1-i] = c
+	}
+	return order
+}
+
+// bytesFor 計算表示數值 n 所需的最少位元組數（至少 1）
+func bytesFor(n uint64) int {
+	size := 1
+	for n >= 1<<(8*uint(size)) && size < 8 {
+		size++
+	}
+	return size
+}
+
+// appendUint 以大端序附加指定位元組數的整數
+func appendUint(out []byte, v uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		out = append(out, byte(v>>(8*uint(i))))
+	}
+	return out
+}
+
+// bocReader BOC 位元組讀取器
+type bocReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bocReader) read(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("BOC 資料不完整: 需要 %d 位元組，剩餘 %d", n, len(r.data)-r.pos)
+	}
+	out := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return out, nil
+}
+
+// remaining 尚未讀取的位元組數
+func (r *bocReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *bocReader) readUint(size int) (uint64, error) {
+	b, err := r.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v, nil
+}
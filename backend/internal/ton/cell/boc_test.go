@@ -0,0 +1,120 @@
+package cell
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEmptyCellBOC(t *testing.T) {
+	c, _ := BeginCell().EndCell()
+
+	// 不含 CRC 的空 Cell 標準 BOC
+	expected := "b5ee9c72010101010002000000"
+	if got := hex.EncodeToString(c.ToBOCWithFlags(false)); got != expected {
+		t.Errorf("Expected BOC=%s, got %s", expected, got)
+	}
+
+	parsed, err := FromBOC(c.ToBOC())
+	if err != nil {
+		t.Fatalf("FromBOC() failed: %v", err)
+	}
+	if !bytes.Equal(parsed.Hash(), c.Hash()) {
+		t.Error("Expected parsed cell hash to match original")
+	}
+}
+
+func TestBOCRoundTrip(t *testing.T) {
+	shared, _ := BeginCell().StoreUInt(0xCAFE, 16).EndCell()
+	left, _ := BeginCell().StoreUInt(1, 3).StoreRef(shared).EndCell()
+	right, _ := BeginCell().StoreUInt(2, 5).StoreRef(shared).EndCell()
+	root, err := BeginCell().
+		StoreUInt(0xDEADBEEF, 32).
+		StoreRef(left).
+		StoreRef(right).
+		EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	for _, withCRC := range []bool{true, false} {
+		boc := root.ToBOCWithFlags(withCRC)
+
+		parsed, err := FromBOC(boc)
+		if err != nil {
+			t.Fatalf("FromBOC(crc=%v) failed: %v", withCRC, err)
+		}
+
+		if !bytes.Equal(parsed.Hash(), root.Hash()) {
+			t.Errorf("Expected parsed hash %x, got %x", root.Hash(), parsed.Hash())
+		}
+
+		if !bytes.Equal(parsed.ToBOCWithFlags(withCRC), boc) {
+			t.Error("Expected re-serialized BOC to be identical")
+		}
+	}
+
+	// 共用的子 Cell 只應序列化一次
+	parsed, _ := FromBOC(root.ToBOC())
+	l, _ := parsed.Ref(0)
+	r, _ := parsed.Ref(1)
+	ls, _ := l.Ref(0)
+	rs, _ := r.Ref(0)
+	if ls != rs {
+		t.Error("Expected shared cell to be deduplicated")
+	}
+}
+
+func TestBOCBase64(t *testing.T) {
+	c, _ := BeginCell().StoreUInt(0, 32).StoreStringSnake("drawWinner").EndCell()
+
+	parsed, err := FromBOCBase64(c.ToBOCBase64())
+	if err != nil {
+		t.Fatalf("FromBOCBase64() failed: %v", err)
+	}
+	if !bytes.Equal(parsed.Hash(), c.Hash()) {
+		t.Error("Expected base64 round trip to preserve hash")
+	}
+}
+
+func TestFromBOCErrors(t *testing.T) {
+	c, _ := BeginCell().StoreUInt(42, 8).EndCell()
+	boc := c.ToBOC()
+
+	t.Run("invalid magic", func(t *testing.T) {
+		broken := append([]byte{}, boc...)
+		broken[0] = 0x00
+		if _, err := FromBOC(broken); err == nil {
+			t.Fatal("Expected FromBOC() to fail with invalid magic")
+		}
+	})
+
+	t.Run("crc mismatch", func(t *testing.T) {
+		broken := append([]byte{}, boc...)
+		broken[len(broken)-5] ^= 0xFF
+		if _, err := FromBOC(broken); err == nil {
+			t.Fatal("Expected FromBOC() to fail with CRC mismatch")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		if _, err := FromBOC(boc[:8]); err == nil {
+			t.Fatal("Expected FromBOC() to fail with truncated data")
+		}
+	})
+
+	t.Run("oversized header", func(t *testing.T) {
+		// refSize=4、cells=roots=0xFFFFFFF0，不得依標頭的數量配置記憶體
+		oversized, _ := hex.DecodeString("b5ee9c720401fffffff0fffffff0000000000000")
+		if _, err := FromBOC(oversized); err == nil {
+			t.Fatal("Expected FromBOC() to fail with oversized cell count")
+		}
+
+		// Cell 數量與剩餘的 16 位元組相符，但 8 個根索引需要 32 位元組
+		oversized, _ = hex.DecodeString("b5ee9c72040100000008000000080000000000" + strings.Repeat("00", 16))
+		if _, err := FromBOC(oversized); err == nil || !strings.Contains(err.Error(), "根數量") {
+			t.Fatalf("Expected FromBOC() to fail with oversized root count, got %v", err)
+		}
+	})
+}
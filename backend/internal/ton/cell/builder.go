@@ -0,0 +1,310 @@
+package cell
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Builder Cell 建構器
+//
+// 寫入方法可以串接使用，第一個錯誤會被保留，並在 EndCell 時返回。
+type Builder struct {
+	data    []byte
+	bitsLen int
+	refs    []*Cell
+	err     error
+}
+
+// BeginCell 創建新的 Cell 建構器
+func BeginCell() *Builder {
+	return &Builder{
+		data: make([]byte, 0, (MaxBits+7)/8),
+	}
+}
+
+// BitsUsed 已使用的位元數
+func (b *Builder) BitsUsed() int {
+	return b.bitsLen
+}
+
+// BitsLeft 剩餘可用的位元數
+func (b *Builder) BitsLeft() int {
+	return MaxBits - b.bitsLen
+}
+
+// RefsUsed 已使用的引用數
+func (b *Builder) RefsUsed() int {
+	return len(b.refs)
+}
+
+// Err 獲取建構過程中的第一個錯誤
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// storeBit 寫入單一位元
+func (b *Builder) storeBit(bit bool) {
+	if b.bitsLen%8 == 0 {
+		b.data = append(b.data, 0)
+	}
+	if bit {
+		b.data[b.bitsLen/8] |= 1 << (7 - b.bitsLen%8)
+	}
+	b.bitsLen++
+}
+
+// ensureBits 檢查是否還有足夠的位元空間
+func (b *Builder) ensureBits(bits int) bool {
+	if b.err != nil {
+		return false
+	}
+	if bits < 0 {
+		b.err = fmt.Errorf("位元數不能為負數: %d", bits)
+		return false
+	}
+	if b.bitsLen+bits > MaxBits {
+		b.err = fmt.Errorf("Cell 位元溢出: 已使用 %d，需要 %d，上限 %d", b.bitsLen, bits, MaxBits)
+		return false
+	}
+	return true
+}
+
+// StoreBool 寫入布林值（1 位元）
+func (b *Builder) StoreBool(v bool) *Builder {
+	if !b.ensureBits(1) {
+		return b
+	}
+	b.storeBit(v)
+	return b
+}
+
+// StoreUInt 寫入無號整數
+func (b *Builder) StoreUInt(v uint64, bits int) *Builder {
+	if !b.ensureBits(bits) {
+		return b
+	}
+	if bits > 64 {
+		return b.StoreBigUInt(new(big.Int).SetUint64(v), bits)
+	}
+	if bits < 64 && v>>uint(bits) != 0 {
+		b.err = fmt.Errorf("數值 %d 無法以 %d 位元表示", v, bits)
+		return b
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.storeBit((v>>uint(i))&1 == 1)
+	}
+	return b
+}
+
+// StoreInt 寫入有號整數（二補數）
+func (b *Builder) StoreInt(v int64, bits int) *Builder {
+	return b.StoreBigInt(big.NewInt(v), bits)
+}
+
+// StoreBigUInt 寫入大型無號整數
+func (b *Builder) StoreBigUInt(v *big.Int, bits int) *Builder {
+	if !b.ensureBits(bits) {
+		return b
+	}
+	if v.Sign() < 0 {
+		b.err = fmt.Errorf("無號整數不能為負數: %s", v.String())
+		return b
+	}
+	if v.BitLen() > bits {
+		b.err = fmt.Errorf("數值 %s 無法以 %d 位元表示", v.String(), bits)
+		return b
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.storeBit(v.Bit(i) == 1)
+	}
+	return b
+}
+
+// StoreBigInt 寫入大型有號整數（二補數）
+func (b *Builder) StoreBigInt(v *big.Int, bits int) *Builder {
+	if !b.ensureBits(bits) {
+		return b
+	}
+	if bits == 0 {
+		if v.Sign() != 0 {
+			b.err = fmt.Errorf("數值 %s 無法以 0 位元表示", v.String())
+		}
+		return b
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	minValue := new(big.Int).Neg(limit)
+	if v.Cmp(minValue) < 0 || v.Cmp(limit) >= 0 {
+		b.err = fmt.Errorf("數值 %s 無法以 %d 位元有號整數表示", v.String(), bits)
+		return b
+	}
+
+	u := new(big.Int).Set(v)
+	if u.Sign() < 0 {
+		u.Add(u, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.storeBit(u.Bit(i) == 1)
+	}
+	return b
+}
+
+// StoreCoins 寫入 TON 金額 (VarUInteger 16)
+func (b *Builder) StoreCoins(v uint64) *Builder {
+	return b.StoreBigCoins(new(big.Int).SetUint64(v))
+}
+
+// StoreBigCoins 寫入大型 TON 金額 (VarUInteger 16)
+func (b *Builder) StoreBigCoins(v *big.Int) *Builder {
+	return b.StoreVarUInt(v, 16)
+}
+
+// StoreVarUInt 寫入 VarUInteger n：先寫入位元組長度，再寫入數值
+func (b *Builder) StoreVarUInt(v *big.Int, maxLen int) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if v.Sign() < 0 {
+		b.err = fmt.Errorf("無號整數不能為負數: %s", v.String())
+		return b
+	}
+
+	byteLen := (v.BitLen() + 7) / 8
+	if byteLen >= maxLen {
+		b.err = fmt.Errorf("數值 %s 超出 VarUInteger %d 範圍", v.String(), maxLen)
+		return b
+	}
+
+	lenBits := bitsFor(maxLen - 1)
+	b.StoreUInt(uint64(byteLen), lenBits)
+	return b.StoreBigUInt(v, byteLen*8)
+}
+
+// StoreSlice 寫入指定位元數的原始資料
+func (b *Builder) StoreSlice(data []byte, bits int) *Builder {
+	if !b.ensureBits(bits) {
+		return b
+	}
+	if len(data)*8 < bits {
+		b.err = fmt.Errorf("資料長度不足: %d 位元組無法提供 %d 位元", len(data), bits)
+		return b
+	}
+	for i := 0; i < bits; i++ {
+		b.storeBit(data[i/8]&(1<<(7-i%8)) != 0)
+	}
+	return b
+}
+
+// StoreBytes 寫入完整位元組
+func (b *Builder) StoreBytes(data []byte) *Builder {
+	return b.StoreSlice(data, len(data)*8)
+}
+
+// StoreRef 寫入引用
+func (b *Builder) StoreRef(ref *Cell) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if ref == nil {
+		b.err = fmt.Errorf("引用不能為 nil")
+		return b
+	}
+	if len(b.refs) >= MaxRefs {
+		b.err = fmt.Errorf("Cell 引用溢出: 上限 %d", MaxRefs)
+		return b
+	}
+	b.refs = append(b.refs, ref)
+	return b
+}
+
+// StoreMaybeRef 寫入可選引用 (Maybe ^Cell)
+func (b *Builder) StoreMaybeRef(ref *Cell) *Builder {
+	if ref == nil {
+		return b.StoreBool(false)
+	}
+	return b.StoreBool(true).StoreRef(ref)
+}
+
+// StoreCell 將另一個 Cell 的資料與引用附加到目前建構器
+func (b *Builder) StoreCell(c *Cell) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.refs)+len(c.refs) > MaxRefs {
+		b.err = fmt.Errorf("Cell 引用溢出: 上限 %d", MaxRefs)
+		return b
+	}
+	b.StoreSlice(c.data, c.bitsLen)
+	if b.err == nil {
+		b.refs = append(b.refs, c.refs...)
+	}
+	return b
+}
+
+// StoreBuilder 將另一個建構器的內容附加到目前建構器
+func (b *Builder) StoreBuilder(other *Builder) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if other.err != nil {
+		b.err = other.err
+		return b
+	}
+	c, err := other.EndCell()
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.StoreCell(c)
+}
+
+// StoreStringSnake 以 snake 格式寫入字串，超出容量的部分放入下一層引用
+func (b *Builder) StoreStringSnake(s string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	data := []byte(s)
+	capacity := b.BitsLeft() / 8
+	if len(data) <= capacity {
+		return b.StoreBytes(data)
+	}
+
+	b.StoreBytes(data[:capacity])
+	tail, err := BeginCell().StoreStringSnake(string(data[capacity:])).EndCell()
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.StoreRef(tail)
+}
+
+// EndCell 完成建構並返回 Cell
+func (b *Builder) EndCell() (*Cell, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	data := make([]byte, len(b.data))
+	copy(data, b.data)
+	refs := make([]*Cell, len(b.refs))
+	copy(refs, b.refs)
+
+	c := &Cell{
+		data:    data,
+		bitsLen: b.bitsLen,
+		refs:    refs,
+	}
+	c.finalize()
+	return c, nil
+}
+
+// bitsFor 計算表示數值 n 所需的位元數
+func bitsFor(n int) int {
+	bits := 0
+	for n > 0 {
+		bits++
+		n >>= 1
+	}
+	return bits
+}
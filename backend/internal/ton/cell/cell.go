@@ -0,0 +1,115 @@
+package cell
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// MaxBits 單一 Cell 可容納的最大位元數
+	MaxBits = 1023
+	// MaxRefs 單一 Cell 可容納的最大引用數
+	MaxRefs = 4
+)
+
+// Cell TON 的基本資料單元（僅支援普通 Cell，level 0）
+type Cell struct {
+	data    []byte
+	bitsLen int
+	refs    []*Cell
+
+	hash  []byte
+	depth uint16
+}
+
+// BitsSize 獲取 Cell 的資料位元數
+func (c *Cell) BitsSize() int {
+	return c.bitsLen
+}
+
+// RefsNum 獲取 Cell 的引用數量
+func (c *Cell) RefsNum() int {
+	return len(c.refs)
+}
+
+// Ref 獲取指定索引的引用
+func (c *Cell) Ref(index int) (*Cell, error) {
+	if index < 0 || index >= len(c.refs) {
+		return nil, fmt.Errorf("引用索引超出範圍: %d", index)
+	}
+	return c.refs[index], nil
+}
+
+// Data 獲取 Cell 的原始資料（不含補位）
+func (c *Cell) Data() []byte {
+	out := make([]byte, len(c.data))
+	copy(out, c.data)
+	return out
+}
+
+// BeginParse 開始解析 Cell
+func (c *Cell) BeginParse() *Slice {
+	return &Slice{
+		data:    c.data,
+		bitsLen: c.bitsLen,
+		refs:    c.refs,
+	}
+}
+
+// Hash 獲取 Cell 的表示雜湊 (representation hash)
+func (c *Cell) Hash() []byte {
+	out := make([]byte, len(c.hash))
+	copy(out, c.hash)
+	return out
+}
+
+// Depth 獲取 Cell 的深度
+func (c *Cell) Depth() uint16 {
+	return c.depth
+}
+
+// descriptors 計算 Cell 的 d1、d2 描述位元組
+func (c *Cell) descriptors() (byte, byte) {
+	d1 := byte(len(c.refs))
+	d2 := byte(c.bitsLen/8) + byte((c.bitsLen+7)/8)
+	return d1, d2
+}
+
+// paddedData 獲取補位後的資料：不足整位元組時補上 1 後接 0
+func (c *Cell) paddedData() []byte {
+	size := (c.bitsLen + 7) / 8
+	out := make([]byte, size)
+	copy(out, c.data[:size])
+	if rem := c.bitsLen % 8; rem != 0 {
+		out[size-1] &= 0xFF << (8 - rem)
+		out[size-1] |= 1 << (7 - rem)
+	}
+	return out
+}
+
+// finalize 計算深度與雜湊，建立 Cell 時呼叫一次
+func (c *Cell) finalize() {
+	var depth uint16
+	for _, ref := range c.refs {
+		if ref.depth+1 > depth {
+			depth = ref.depth + 1
+		}
+	}
+	c.depth = depth
+
+	d1, d2 := c.descriptors()
+	repr := make([]byte, 0, 2+len(c.data)+len(c.refs)*(2+32))
+	repr = append(repr, d1, d2)
+	repr = append(repr, c.paddedData()...)
+
+	for _, ref := range c.refs {
+		repr = binary.BigEndian.AppendUint16(repr, ref.depth)
+	}
+	for _, ref := range c.refs {
+		repr = append(repr, ref.hash...)
+	}
+
+	sum := sha256.Sum256(repr)
+	c.hash = sum[:]
+}
@@ -0,0 +1,219 @@
+package cell
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+)
+
+func TestEmptyCellHash(t *testing.T) {
+	c, err := BeginCell().EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	// 空 Cell 的標準雜湊值
+	expected := "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"
+	if got := hex.EncodeToString(c.Hash()); got != expected {
+		t.Errorf("Expected hash=%s, got %s", expected, got)
+	}
+
+	if c.Depth() != 0 {
+		t.Errorf("Expected depth=0, got %d", c.Depth())
+	}
+}
+
+func TestBuilderAndSlice(t *testing.T) {
+	ref, err := BeginCell().StoreUInt(0xABCD, 16).EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() for ref failed: %v", err)
+	}
+
+	bigValue, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	c, err := BeginCell().
+		StoreUInt(5, 3).
+		StoreBool(true).
+		StoreInt(-42, 16).
+		StoreCoins(1000000000).
+		StoreBigUInt(bigValue, 128).
+		StoreBytes([]byte("cat")).
+		StoreRef(ref).
+		StoreMaybeRef(nil).
+		EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	if c.RefsNum() != 1 {
+		t.Errorf("Expected 1 ref, got %d", c.RefsNum())
+	}
+
+	if c.Depth() != 1 {
+		t.Errorf("Expected depth=1, got %d", c.Depth())
+	}
+
+	s := c.BeginParse()
+
+	if v, err := s.LoadUInt(3); err != nil || v != 5 {
+		t.Errorf("Expected LoadUInt(3)=5, got %d (err=%v)", v, err)
+	}
+
+	if v, err := s.LoadBool(); err != nil || !v {
+		t.Errorf("Expected LoadBool()=true, got %v (err=%v)", v, err)
+	}
+
+	if v, err := s.LoadInt(16); err != nil || v != -42 {
+		t.Errorf("Expected LoadInt(16)=-42, got %d (err=%v)", v, err)
+	}
+
+	if v, err := s.LoadCoins(); err != nil || v != 1000000000 {
+		t.Errorf("Expected LoadCoins()=1000000000, got %d (err=%v)", v, err)
+	}
+
+	if v, err := s.LoadBigUInt(128); err != nil || v.Cmp(bigValue) != 0 {
+		t.Errorf("Expected LoadBigUInt(128)=%s, got %v (err=%v)", bigValue, v, err)
+	}
+
+	if v, err := s.LoadBytes(3); err != nil || string(v) != "cat" {
+		t.Errorf("Expected LoadBytes(3)='cat', got %q (err=%v)", v, err)
+	}
+
+	loadedRef, err := s.LoadRef()
+	if err != nil {
+		t.Fatalf("LoadRef() failed: %v", err)
+	}
+	if v, err := loadedRef.BeginParse().LoadUInt(16); err != nil || v != 0xABCD {
+		t.Errorf("Expected ref value=0xABCD, got %x (err=%v)", v, err)
+	}
+
+	if maybe, err := s.LoadMaybeRef(); err != nil || maybe != nil {
+		t.Errorf("Expected LoadMaybeRef()=nil, got %v (err=%v)", maybe, err)
+	}
+
+	if s.BitsLeft() != 0 || s.RefsLeft() != 0 {
+		t.Errorf("Expected slice to be fully consumed, bits=%d refs=%d", s.BitsLeft(), s.RefsLeft())
+	}
+}
+
+func TestBuilderOverflow(t *testing.T) {
+	t.Run("bits overflow", func(t *testing.T) {
+		_, err := BeginCell().StoreSlice(make([]byte, 128), 1024).EndCell()
+		if err == nil {
+			t.Fatal("Expected EndCell() to fail with bits overflow")
+		}
+	})
+
+	t.Run("refs overflow", func(t *testing.T) {
+		empty, _ := BeginCell().EndCell()
+		b := BeginCell()
+		for i := 0; i < MaxRefs+1; i++ {
+			b.StoreRef(empty)
+		}
+		if _, err := b.EndCell(); err == nil {
+			t.Fatal("Expected EndCell() to fail with refs overflow")
+		}
+	})
+
+	t.Run("value too large", func(t *testing.T) {
+		if _, err := BeginCell().StoreUInt(256, 8).EndCell(); err == nil {
+			t.Fatal("Expected EndCell() to fail when value does not fit")
+		}
+		if _, err := BeginCell().StoreInt(128, 8).EndCell(); err == nil {
+			t.Fatal("Expected EndCell() to fail when signed value does not fit")
+		}
+	})
+}
+
+func TestSliceUnderflow(t *testing.T) {
+	c, _ := BeginCell().StoreUInt(1, 4).EndCell()
+	s := c.BeginParse()
+
+	if _, err := s.LoadUInt(8); err == nil {
+		t.Error("Expected LoadUInt(8) to fail on 4-bit cell")
+	}
+
+	if _, err := s.LoadRef(); err == nil {
+		t.Error("Expected LoadRef() to fail on cell without refs")
+	}
+}
+
+func TestStringSnake(t *testing.T) {
+	long := strings.Repeat("貓咪抽獎", 40) // 超過單一 Cell 容量
+
+	c, err := BeginCell().StoreUInt(0, 32).StoreStringSnake(long).EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	if c.RefsNum() != 1 {
+		t.Errorf("Expected long string to spill into a ref, got %d refs", c.RefsNum())
+	}
+
+	s := c.BeginParse()
+	if _, err := s.LoadUInt(32); err != nil {
+		t.Fatalf("LoadUInt(32) failed: %v", err)
+	}
+
+	got, err := s.LoadStringSnake()
+	if err != nil {
+		t.Fatalf("LoadStringSnake() failed: %v", err)
+	}
+	if got != long {
+		t.Errorf("Expected snake string round trip, got %d bytes want %d", len(got), len(long))
+	}
+}
+
+func TestSliceToCell(t *testing.T) {
+	ref, _ := BeginCell().StoreUInt(7, 8).EndCell()
+	c, _ := BeginCell().StoreUInt(1, 8).StoreUInt(2, 8).StoreRef(ref).EndCell()
+
+	s := c.BeginParse()
+	if _, err := s.LoadUInt(8); err != nil {
+		t.Fatalf("LoadUInt(8) failed: %v", err)
+	}
+
+	rest, err := s.ToCell()
+	if err != nil {
+		t.Fatalf("ToCell() failed: %v", err)
+	}
+
+	expected, _ := BeginCell().StoreUInt(2, 8).StoreRef(ref).EndCell()
+	if hex.EncodeToString(rest.Hash()) != hex.EncodeToString(expected.Hash()) {
+		t.Error("Expected remaining slice to produce the same cell")
+	}
+}
+
+func TestStoreLoadAddress(t *testing.T) {
+	addr := address.MustParse("EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF")
+
+	c, err := BeginCell().StoreAddress(addr).StoreAddress(nil).EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	// addr_std 佔 267 位元，addr_none 佔 2 位元
+	if c.BitsSize() != 267+2 {
+		t.Errorf("Expected 269 bits, got %d", c.BitsSize())
+	}
+
+	s := c.BeginParse()
+	loaded, err := s.LoadAddress()
+	if err != nil {
+		t.Fatalf("LoadAddress() failed: %v", err)
+	}
+	if !loaded.Equals(addr) {
+		t.Errorf("Expected %s, got %s", addr, loaded)
+	}
+
+	none, err := s.LoadAddress()
+	if err != nil {
+		t.Fatalf("LoadAddress() for addr_none failed: %v", err)
+	}
+	if none != nil {
+		t.Errorf("Expected nil for addr_none, got %s", none)
+	}
+}
@@ -0,0 +1,219 @@
+package cell
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Slice Cell 讀取器，依序讀取位元與引用
+type Slice struct {
+	data    []byte
+	bitsLen int
+	bitPos  int
+	refs    []*Cell
+	refPos  int
+}
+
+// BitsLeft 剩餘未讀取的位元數
+func (s *Slice) BitsLeft() int {
+	return s.bitsLen - s.bitPos
+}
+
+// RefsLeft 剩餘未讀取的引用數
+func (s *Slice) RefsLeft() int {
+	return len(s.refs) - s.refPos
+}
+
+// loadBit 讀取單一位元（呼叫前需確認長度）
+func (s *Slice) loadBit() bool {
+	bit := s.data[s.bitPos/8]&(1<<(7-s.bitPos%8)) != 0
+	s.bitPos++
+	return bit
+}
+
+// ensureBits 檢查是否還有足夠的位元可讀
+func (s *Slice) ensureBits(bits int) error {
+	if bits < 0 {
+		return fmt.Errorf("位元數不能為負數: %d", bits)
+	}
+	if s.BitsLeft() < bits {
+		return fmt.Errorf("Slice 位元不足: 剩餘 %d，需要 %d", s.BitsLeft(), bits)
+	}
+	return nil
+}
+
+// LoadBool 讀取布林值（1 位元）
+func (s *Slice) LoadBool() (bool, error) {
+	if err := s.ensureBits(1); err != nil {
+		return false, err
+	}
+	return s.loadBit(), nil
+}
+
+// LoadUInt 讀取無號整數（最多 64 位元）
+func (s *Slice) LoadUInt(bits int) (uint64, error) {
+	if bits > 64 {
+		return 0, fmt.Errorf("LoadUInt 最多支援 64 位元，請改用 LoadBigUInt")
+	}
+	if err := s.ensureBits(bits); err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for i := 0; i < bits; i++ {
+		v <<= 1
+		if s.loadBit() {
+			v |= 1
+		}
+	}
+	return v, nil
+}
+
+// LoadInt 讀取有號整數（最多 64 位元）
+func (s *Slice) LoadInt(bits int) (int64, error) {
+	if bits > 64 {
+		return 0, fmt.Errorf("LoadInt 最多支援 64 位元，請改用 LoadBigInt")
+	}
+	v, err := s.LoadBigInt(bits)
+	if err != nil {
+		return 0, err
+	}
+	return v.Int64(), nil
+}
+
+// LoadBigUInt 讀取大型無號整數
+func (s *Slice) LoadBigUInt(bits int) (*big.Int, error) {
+	if err := s.ensureBits(bits); err != nil {
+		return nil, err
+	}
+
+	v := new(big.Int)
+	for i := 0; i < bits; i++ {
+		v.Lsh(v, 1)
+		if s.loadBit() {
+			v.SetBit(v, 0, 1)
+		}
+	}
+	return v, nil
+}
+
+// LoadBigInt 讀取大型有號整數（二補數）
+func (s *Slice) LoadBigInt(bits int) (*big.Int, error) {
+	v, err := s.LoadBigUInt(bits)
+	if err != nil {
+		return nil, err
+	}
+	if bits > 0 && v.Bit(bits-1) == 1 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	}
+	return v, nil
+}
+
+// LoadCoins 讀取 TON 金額 (VarUInteger 16)
+func (s *Slice) LoadCoins() (uint64, error) {
+	v, err := s.LoadBigCoins()
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsUint64() {
+		return 0, fmt.Errorf("金額超出 uint64 範圍: %s", v.String())
+	}
+	return v.Uint64(), nil
+}
+
+// LoadBigCoins 讀取大型 TON 金額 (VarUInteger 16)
+func (s *Slice) LoadBigCoins() (*big.Int, error) {
+	return s.LoadVarUInt(16)
+}
+
+// LoadVarUInt 讀取 VarUInteger n
+func (s *Slice) LoadVarUInt(maxLen int) (*big.Int, error) {
+	byteLen, err := s.LoadUInt(bitsFor(maxLen - 1))
+	if err != nil {
+		return nil, fmt.Errorf("讀取 VarUInteger 長度失敗: %w", err)
+	}
+	return s.LoadBigUInt(int(byteLen) * 8)
+}
+
+// LoadSlice 讀取指定位元數的原始資料，不足整位元組的部分靠左對齊
+func (s *Slice) LoadSlice(bits int) ([]byte, error) {
+	if err := s.ensureBits(bits); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, (bits+7)/8)
+	for i := 0; i < bits; i++ {
+		if s.loadBit() {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out, nil
+}
+
+// LoadBytes 讀取完整位元組
+func (s *Slice) LoadBytes(n int) ([]byte, error) {
+	return s.LoadSlice(n * 8)
+}
+
+// LoadRef 讀取下一個引用
+func (s *Slice) LoadRef() (*Cell, error) {
+	if s.RefsLeft() == 0 {
+		return nil, fmt.Errorf("Slice 沒有剩餘引用")
+	}
+	ref := s.refs[s.refPos]
+	s.refPos++
+	return ref, nil
+}
+
+// LoadMaybeRef 讀取可選引用 (Maybe ^Cell)，不存在時返回 nil
+func (s *Slice) LoadMaybeRef() (*Cell, error) {
+	present, err := s.LoadBool()
+	if err != nil {
+		return nil, err
+	}
+	if !present {
+		return nil, nil
+	}
+	return s.LoadRef()
+}
+
+// LoadStringSnake 讀取 snake 格式字串（包含所有後續引用）
+func (s *Slice) LoadStringSnake() (string, error) {
+	var out []byte
+	current := s
+	for {
+		if current.BitsLeft()%8 != 0 {
+			return "", fmt.Errorf("snake 字串位元數不是 8 的倍數: %d", current.BitsLeft())
+		}
+		chunk, err := current.LoadBytes(current.BitsLeft() / 8)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, chunk...)
+
+		if current.RefsLeft() == 0 {
+			break
+		}
+		next, err := current.LoadRef()
+		if err != nil {
+			return "", err
+		}
+		current = next.BeginParse()
+	}
+	return string(out), nil
+}
+
+// ToCell 將剩餘的位元與引用轉換為新的 Cell
+func (s *Slice) ToCell() (*Cell, error) {
+	clone := *s
+	data, err := clone.LoadSlice(clone.BitsLeft())
+	if err != nil {
+		return nil, err
+	}
+
+	b := BeginCell().StoreSlice(data, s.BitsLeft())
+	for _, ref := range s.refs[s.refPos:] {
+		b.StoreRef(ref)
+	}
+	return b.EndCell()
+}
@@ -0,0 +1,46 @@
+package ton
+
+import "context"
+
+// ChainReader 讀取抽獎服務所需的鏈上狀態
+//
+// Client 以 toncenter 實作；其他實作（其他 API 供應商、記憶體中的模擬合約）需返回與 Client 相同的錯誤：
+// 合約 get 方法失敗時為 *GetMethodError，尚未部署的錢包 GetWalletSeqno 返回 0。
+type ChainReader interface {
+	// GetLotteryContractInfo 查詢抽獎合約狀態
+	GetLotteryContractInfo(ctx context.Context, contractAddress string) (*LotteryContractInfo, error)
+	// GetParticipant 查詢目前輪次的參與者，索引上沒有參與者時返回 nil
+	GetParticipant(ctx context.Context, contractAddress string, index int) (*Participant, error)
+	// GetWinner 查詢輪次的中獎記錄，沒有記錄時返回 nil
+	GetWinner(ctx context.Context, contractAddress string, round int) (*LotteryResult, error)
+	// GetContractBalance 以合約的 getBalance get 方法查詢餘額 (nanoTON)
+	GetContractBalance(ctx context.Context, contractAddress string) (int64, error)
+	// GetAddressBalance 查詢任意地址的餘額 (nanoTON)，未部署的地址返回 0
+	GetAddressBalance(ctx context.Context, addr string) (int64, error)
+	// GetNFTContractInfo 查詢 CatNFT 合約狀態
+	GetNFTContractInfo(ctx context.Context, contractAddress string) (*NFTContractInfo, error)
+	// GetWalletSeqno 讀取錢包合約目前的 seqno
+	GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error)
+}
+
+// TxSender 發送已簽名的外部訊息
+type TxSender interface {
+	// SendTransaction 發送序列化後的 BOC，返回訊息哈希；訊息被拒絕時返回 *MessageRejectedError
+	SendTransaction(ctx context.Context, boc []byte) (string, error)
+}
+
+// TxStatusProvider 查詢已發送訊息的執行結果
+type TxStatusProvider interface {
+	// GetTransactionStatus 返回 pending（尚未找到交易）、success、failed 或 unknown
+	GetTransactionStatus(ctx context.Context, hash string) (string, error)
+}
+
+// Chain 抽獎服務使用的完整鏈上介面
+type Chain interface {
+	ChainReader
+	TxSender
+	TxStatusProvider
+}
+
+// Client 是 Chain 的 toncenter 實作
+var _ Chain = (*Client)(nil)
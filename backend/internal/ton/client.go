@@ -3,22 +3,60 @@ package ton
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
 	"ton-cat-lottery-backend/pkg/logger"
+	"ton-cat-lottery-backend/pkg/metrics"
 )
 
+// requestDuration toncenter 請求的耗時，_count 即請求次數
+//
+// status 為 HTTP 狀態碼；toncenter 以 ok=false 回應時為 api_error，連線或讀取失敗時為 network_error。
+var requestDuration = metrics.NewHistogramVec(
+	"ton_api_request_duration_seconds",
+	"Duration of toncenter API requests by method, status and endpoint.",
+	metrics.DefaultBuckets,
+	"method", "status", "endpoint",
+)
+
+// requestRetries 因暫時性錯誤重試的次數
+var requestRetries = metrics.NewCounterVec(
+	"ton_api_retries_total",
+	"Retries of toncenter API requests after transient errors.",
+	"method",
+)
+
+// defaultRequestTimeout 未設定 TON_API_TIMEOUT 時單次請求的逾時
+const defaultRequestTimeout = 30 * time.Second
+
 // Client TON API 客戶端
 type Client struct {
 	config     *config.Config
 	logger     *logger.Logger
 	httpClient *http.Client
-	baseURL    string
+
+	// endpoints 依優先順序排列的 API 端點，健康狀態與頻率限制和連到相同端點的客戶端共用
+	endpoints []*endpoint
+
+	// 暫時性錯誤的重試設定
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+
+	// hedgeDelay 讀取請求超過此時間未回應時同時向下一個端點發送，0 表示停用
+	hedgeDelay time.Duration
 }
 
 // APIResponse API 回應格式
@@ -26,6 +64,7 @@ type APIResponse struct {
 	Ok     bool            `json:"ok"`
 	Result json.RawMessage `json:"result"`
 	Error  string          `json:"error,omitempty"`
+	Code   int             `json:"code,omitempty"`
 }
 
 // ContractInfo 合約資訊
@@ -46,6 +85,20 @@ type LotteryContractInfo struct {
 	NFTContract      string `json:"nft_contract,omitempty"`
 }
 
+// NFTContractInfo CatNFT 合約狀態資訊
+type NFTContractInfo struct {
+	Owner     string `json:"owner"`
+	NextNFTId int64  `json:"next_nft_id"`
+	NFTSupply int64  `json:"nft_supply"`
+}
+
+// CatInfo CatNFT getCatInfo 返回的貓咪資訊，NFT 不存在時所有欄位為空字串
+type CatInfo struct {
+	Name    string `json:"name"`
+	Rarity  string `json:"rarity"`
+	CatType string `json:"cat_type"`
+}
+
 // Participant 參與者資訊
 type Participant struct {
 	Address   string `json:"address"`
@@ -60,15 +113,44 @@ type LotteryResult struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// HasAddress 檢查參與者是否為指定地址（比較帳戶而非字串格式）
+func (p *Participant) HasAddress(addr string) bool {
+	return address.Equal(p.Address, addr)
+}
+
+// IsWinner 檢查指定地址是否為中獎者（比較帳戶而非字串格式），沒有中獎記錄時返回 false
+func (r *LotteryResult) IsWinner(addr string) bool {
+	return r != nil && address.Equal(r.Winner, addr)
+}
+
 // NewClient 創建新的 TON 客戶端
+//
+// 請求頻率限制與重試次數由設定決定，未設定時不限制頻率也不重試。
+// 設定多個端點時依優先順序使用，暫時性錯誤時切換到下一個端點。
 func NewClient(cfg *config.Config, log *logger.Logger) *Client {
+	timeout := cfg.TONAPITimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+
+	// API key 不會出現在客戶端的任何日誌中
+	secrets := append([]string(nil), cfg.TONAPIKeys...)
+	for _, e := range cfg.TONAPIEndpointList() {
+		secrets = append(secrets, e.APIKey)
+	}
+	log = log.WithRedaction(secrets...).WithGroup("ton_client")
+
 	return &Client{
 		config: cfg,
-		logger: log.WithGroup("ton_client"),
+		logger: log,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: timeout,
 		},
-		baseURL: cfg.TONAPIEndpoint,
+		endpoints:      newEndpoints(cfg, log),
+		maxRetries:     cfg.TONAPIMaxRetries,
+		retryBaseDelay: cfg.TONAPIRetryBaseDelay,
+		retryMaxDelay:  cfg.TONAPIRetryMaxDelay,
+		hedgeDelay:     cfg.TONAPIHedgeDelay,
 	}
 }
 
@@ -76,16 +158,13 @@ func NewClient(cfg *config.Config, log *logger.Logger) *Client {
 func (c *Client) GetContractInfo(ctx context.Context, contractAddress string) (*ContractInfo, error) {
 	c.logger.Debug("查詢合約資訊", "address", contractAddress)
 
-	// 構建請求 URL
-	url := fmt.Sprintf("%sgetAddressInformation", c.baseURL)
-
 	// 構建請求參數
 	params := map[string]interface{}{
 		"address": contractAddress,
 	}
 
 	// 發送請求
-	resp, err := c.makeRequest(ctx, "GET", url, params)
+	resp, err := c.makeRequest(ctx, "GET", "getAddressInformation", params)
 	if err != nil {
 		return nil, fmt.Errorf("查詢合約資訊失敗: %w", err)
 	}
@@ -104,21 +183,42 @@ func (c *Client) GetContractInfo(ctx context.Context, contractAddress string) (*
 	return &contractInfo, nil
 }
 
-// SendTransaction 發送交易
-func (c *Client) SendTransaction(ctx context.Context, transaction []byte) (string, error) {
-	c.logger.Debug("發送交易")
+// GetAddressBalance 透過 getAddressInformation 查詢地址餘額 (nanoTON)
+func (c *Client) GetAddressBalance(ctx context.Context, addr string) (int64, error) {
+	info, err := c.GetContractInfo(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
 
-	// 構建請求 URL
-	url := fmt.Sprintf("%ssendBoc", c.baseURL)
+	// 尚未部署且從未收款的帳戶餘額可能為空
+	if info.Balance == "" {
+		return 0, nil
+	}
 
-	// 構建請求參數
+	balance, err := strconv.ParseInt(info.Balance, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析地址餘額失敗: %w", err)
+	}
+
+	return balance, nil
+}
+
+// SendTransaction 發送交易，transaction 為序列化後的 BOC
+func (c *Client) SendTransaction(ctx context.Context, transaction []byte) (string, error) {
+	c.logger.Debug("發送交易", "boc_length", len(transaction))
+
+	// 構建請求參數（toncenter 要求 BOC 以 base64 編碼）
 	params := map[string]interface{}{
-		"boc": fmt.Sprintf("%x", transaction),
+		"boc": base64.StdEncoding.EncodeToString(transaction),
 	}
 
 	// 發送請求
-	resp, err := c.makeRequest(ctx, "POST", url, params)
+	resp, err := c.makeRequest(ctx, "POST", "sendBocReturnHash", params)
 	if err != nil {
+		if rejected, ok := parseMessageRejected(err); ok {
+			c.logger.Warn("外部訊息被拒絕", "exit_code", rejected.ExitCode)
+			return "", fmt.Errorf("發送交易失敗: %w", rejected)
+		}
 		return "", fmt.Errorf("發送交易失敗: %w", err)
 	}
 
@@ -141,16 +241,13 @@ func (c *Client) SendTransaction(ctx context.Context, transaction []byte) (strin
 func (c *Client) GetTransactionStatus(ctx context.Context, hash string) (string, error) {
 	c.logger.Debug("查詢交易狀態", "hash", hash)
 
-	// 構建請求 URL
-	url := fmt.Sprintf("%sgetTransactions", c.baseURL)
-
 	// 構建請求參數
 	params := map[string]interface{}{
 		"hash": hash,
 	}
 
 	// 發送請求
-	resp, err := c.makeRequest(ctx, "GET", url, params)
+	resp, err := c.makeRequest(ctx, "GET", "getTransactions", params)
 	if err != nil {
 		return "", fmt.Errorf("查詢交易狀態失敗: %w", err)
 	}
@@ -180,76 +277,343 @@ func (c *Client) GetTransactionStatus(ctx context.Context, hash string) (string,
 	return status, nil
 }
 
-// RunGetMethod 執行合約的 get 方法
-func (c *Client) RunGetMethod(ctx context.Context, contractAddress, method string, params []interface{}) (json.RawMessage, error) {
+// getMethodResult toncenter runGetMethod 的回應格式
+type getMethodResult struct {
+	GasUsed  int64     `json:"gas_used"`
+	ExitCode int       `json:"exit_code"`
+	Stack    tvm.Stack `json:"stack"`
+}
+
+// RunGetMethod 執行合約的 get 方法並解碼返回的堆疊
+//
+// args 依序壓入堆疊，沒有參數時傳入 nil。exit code 不是 0 或 1 時返回 *GetMethodError。
+func (c *Client) RunGetMethod(ctx context.Context, contractAddress, method string, args tvm.Args) (tvm.Stack, error) {
 	c.logger.Debug("執行合約 get 方法",
 		"address", contractAddress,
 		"method", method,
-		"params", params,
+		"args", args.String(),
 	)
 
-	// 構建請求 URL
-	url := fmt.Sprintf("%srunGetMethod", c.baseURL)
-
 	// 構建請求參數
 	requestParams := map[string]interface{}{
 		"address": contractAddress,
 		"method":  method,
-		"stack":   params,
+		"stack":   args,
 	}
 
 	// 發送請求
-	resp, err := c.makeRequest(ctx, "POST", url, requestParams)
+	resp, err := c.makeRequest(ctx, "POST", "runGetMethod", requestParams)
 	if err != nil {
 		return nil, fmt.Errorf("執行合約方法失敗: %w", err)
 	}
 
-	c.logger.Debug("合約方法執行成功", "method", method)
-	return resp.Result, nil
-}
+	var result getMethodResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("解析 %s 返回的堆疊失敗: %w", method, err)
+	}
+
+	if result.ExitCode != 0 && result.ExitCode != 1 {
+		return nil, &GetMethodError{Method: method, ExitCode: result.ExitCode}
+	}
 
-// makeRequest 發送 HTTP 請求
-func (c *Client) makeRequest(ctx context.Context, method, url string, params map[string]interface{}) (*APIResponse, error) {
-	var body io.Reader
+	c.logger.Debug("合約方法執行成功", "method", method, "gas_used", result.GasUsed, "stack_size", len(result.Stack))
+	return result.Stack, nil
+}
 
-	if method == "POST" {
+// makeRequest 發送 API 請求，暫時性錯誤時切換端點或依設定重試
+//
+// 依 orderEndpoints 的順序選擇端點，每次嘗試前先取得該端點的請求配額。可重試的錯誤（見 IsRetryable）
+// 先立即切換到尚未嘗試的端點，不計入重試次數；所有端點都失敗後以指數退避加隨機抖動等待再重試。
+// 回應帶有 Retry-After 時該端點暫停發出配額，重試至少等待指定的時間，且超過重試延遲上限時直接返回錯誤。
+// sendBoc 只在 429 時切換或重試：其他錯誤時訊息可能已被節點接受，重送交由呼叫端以 seqno 判斷。
+func (c *Client) makeRequest(ctx context.Context, httpMethod, apiMethod string, params map[string]interface{}) (*APIResponse, error) {
+	var body []byte
+	if httpMethod == "POST" {
 		jsonData, err := json.Marshal(params)
 		if err != nil {
 			return nil, fmt.Errorf("編碼請求參數失敗: %w", err)
 		}
-		body = bytes.NewBuffer(jsonData)
+		body = jsonData
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	retryable := IsRetryable
+	write := strings.HasPrefix(apiMethod, "sendBoc")
+	if write {
+		retryable = IsRateLimited
+	}
+
+	tried := make(map[*endpoint]bool, len(c.endpoints))
+	var previous *endpoint
+	for attempt := 0; ; {
+		// 依目前的健康狀態選擇尚未嘗試的端點，下一個可用的端點作為延遲備援
+		var primary, hedge *endpoint
+		for _, ep := range orderEndpoints(c.endpoints, time.Now()) {
+			switch {
+			case tried[ep]:
+			case primary == nil:
+				primary = ep
+			case hedge == nil && ep.available(time.Now()):
+				hedge = ep
+			}
+		}
+		if write || c.hedgeDelay <= 0 {
+			hedge = nil
+		}
+
+		if previous != nil {
+			failovers.Inc(previous.url, primary.url)
+			c.logger.Warn("TON API 請求失敗，切換端點", "method", apiMethod, "from", previous.url, "to", primary.url)
+		}
+
+		resp, failed, err := c.send(ctx, primary, hedge, httpMethod, apiMethod, params, body)
+		if err == nil {
+			return resp, nil
+		}
+		for _, ep := range failed {
+			tried[ep] = true
+		}
+		ep := failed[0]
+
+		var apiErr *APIError
+		var retryAfter time.Duration
+		// 有多個 key 時 Retry-After 只針對收到 429 的 key，下一個請求使用其他 key
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 && ep.keys.len() < 2 {
+			retryAfter = apiErr.RetryAfter
+			ep.limiter.pause(time.Now().Add(retryAfter))
+		}
+
+		if !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		if len(tried) < len(c.endpoints) {
+			previous = ep
+			continue
+		}
+
+		if attempt >= c.maxRetries {
+			return nil, err
+		}
+		if retryAfter > c.retryMaxDelay {
+			c.logger.Warn("Retry-After 超過重試延遲上限，不再重試", "method", apiMethod, "retry_after", retryAfter)
+			return nil, err
+		}
+
+		delay := max(backoff(attempt, c.retryBaseDelay, c.retryMaxDelay), retryAfter)
+		requestRetries.Inc(apiMethod)
+		c.logger.Warn("TON API 請求失敗，稍後重試",
+			"method", apiMethod,
+			"attempt", attempt+1,
+			"max_retries", c.maxRetries,
+			"delay", delay.String(),
+			"error", err,
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+
+		attempt++
+		clear(tried)
+		previous = nil
+	}
+}
+
+// hedgeResult 延遲備援請求中單一端點的結果
+type hedgeResult struct {
+	ep   *endpoint
+	resp *APIResponse
+	err  error
+}
+
+// send 向 primary 發送請求；hedge 不為 nil 且 primary 超過 hedgeDelay 未回應時，同時向 hedge 發送相同的請求
+//
+// 返回第一個成功的回應，另一個請求隨即取消。失敗時返回失敗的端點，第一個為返回的錯誤所屬的端點：
+// 備援請求發出前 primary 就失敗時只有 primary，兩者都失敗時返回 primary 的錯誤。
+func (c *Client) send(ctx context.Context, primary, hedge *endpoint, httpMethod, apiMethod string, params map[string]interface{}, body []byte) (*APIResponse, []*endpoint, error) {
+	if hedge == nil {
+		resp, err := c.sendTo(ctx, primary, httpMethod, apiMethod, params, body)
+		if err != nil {
+			return nil, []*endpoint{primary}, err
+		}
+		return resp, nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	start := func(ep *endpoint) {
+		go func() {
+			resp, err := c.sendTo(ctx, ep, httpMethod, apiMethod, params, body)
+			results <- hedgeResult{ep: ep, resp: resp, err: err}
+		}()
+	}
+	start(primary)
+
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	hedged := false
+	var primaryErr error
+	for pending := 1; ; {
+		select {
+		case <-timer.C:
+			hedged = true
+			pending++
+			c.logger.Debug("TON API 請求未及時回應，同時向下一個端點發送", "method", apiMethod, "endpoint", hedge.url)
+			start(hedge)
+
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if hedged {
+					hedgedRequests.Inc(hedge.url, strconv.FormatBool(r.ep == hedge))
+				}
+				return r.resp, nil, nil
+			}
+			if !hedged {
+				// 備援請求尚未發出，交由 makeRequest 切換端點
+				return nil, []*endpoint{primary}, r.err
+			}
+			if r.ep == primary {
+				primaryErr = r.err
+			}
+			if pending == 0 {
+				hedgedRequests.Inc(hedge.url, "false")
+				return nil, []*endpoint{primary, hedge}, primaryErr
+			}
+		}
+	}
+}
+
+// sendTo 取得端點的請求配額後發送請求，並以結果更新端點的健康評分
+//
+// 因 ctx 取消（包含延遲備援中另一個請求已成功）而中止的請求不計入評分。
+func (c *Client) sendTo(ctx context.Context, ep *endpoint, httpMethod, apiMethod string, params map[string]interface{}, body []byte) (*APIResponse, error) {
+	if err := ep.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("等待請求配額失敗: %w", err)
+	}
+
+	index, key := ep.keys.pick()
+	resp, err := c.doRequest(ctx, ep, key, httpMethod, apiMethod, params, body)
+	if IsRateLimited(err) && ep.keys.rotate(index) {
+		keyRotations.Inc(ep.url)
+		c.logger.Warn("TON API key 觸發頻率限制，切換到下一個 key",
+			"endpoint", ep.url,
+			"key_index", index%uint64(ep.keys.len()),
+		)
+	}
+	if ctx.Err() == nil {
+		// 永久性錯誤（例如參數無效）表示端點正常回應
+		ep.record(err == nil || !IsRetryable(err), time.Now())
+	}
+	return resp, err
+}
+
+// doRequest 以 apiKey 向端點發送單次 HTTP 請求並記錄耗時，apiKey 為空時不發送 X-API-Key
+func (c *Client) doRequest(ctx context.Context, ep *endpoint, apiKey, httpMethod, apiMethod string, params map[string]interface{}, body []byte) (*APIResponse, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, httpMethod, ep.url+apiMethod, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("創建請求失敗: %w", err)
 	}
 
+	// GET 請求的參數以 query string 傳遞
+	if httpMethod == "GET" && len(params) > 0 {
+		query := req.URL.Query()
+		for key, value := range params {
+			query.Set(key, fmt.Sprint(value))
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
 	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	start := time.Now()
+	status := "network_error"
+	defer func() {
+		requestDuration.Observe(time.Since(start).Seconds(), apiMethod, status, ep.url)
+	}()
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("發送請求失敗: %w", err)
+		return nil, &NetworkError{Op: "發送請求失敗", Err: err}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("讀取回應失敗: %w", err)
+		return nil, &NetworkError{Op: "讀取回應失敗", Err: err}
 	}
+	status = strconv.Itoa(resp.StatusCode)
 
 	var apiResp APIResponse
 	if err := json.Unmarshal(bodyBytes, &apiResp); err != nil {
+		// 代理伺服器或負載平衡器的錯誤頁面不是 JSON，保留狀態碼以判斷是否可重試
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{
+				StatusCode: resp.StatusCode,
+				Message:    http.StatusText(resp.StatusCode),
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			}
+		}
 		return nil, fmt.Errorf("解析 API 回應失敗: %w", err)
 	}
 
 	if !apiResp.Ok {
-		return nil, fmt.Errorf("API 錯誤: %s", apiResp.Error)
+		if resp.StatusCode == http.StatusOK {
+			status = "api_error"
+		}
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       apiResp.Code,
+			Message:    apiResp.Error,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	return &apiResp, nil
 }
 
+// === 錢包合約方法 ===
+
+// GetWalletSeqno 從錢包合約的 seqno get 方法讀取目前序號，尚未部署的錢包返回 0
+func (c *Client) GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error) {
+	c.logger.Debug("查詢錢包 seqno", "address", walletAddress)
+
+	stack, err := c.RunGetMethod(ctx, walletAddress, "seqno", nil)
+	if IsUninitialized(err) {
+		c.logger.Debug("錢包尚未部署", "address", walletAddress)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("查詢錢包 seqno 失敗: %w", err)
+	}
+
+	r := tvm.NewReader(stack)
+	seqno := r.Int64()
+	if err := r.Err(); err != nil {
+		return 0, fmt.Errorf("解析錢包 seqno 失敗: %w", err)
+	}
+	if seqno < 0 || seqno > math.MaxUint32 {
+		return 0, fmt.Errorf("無效的 seqno 回傳值: %d", seqno)
+	}
+
+	c.logger.Debug("錢包 seqno 查詢成功", "seqno", seqno)
+	return uint32(seqno), nil
+}
+
 // === 抽獎合約專用方法 ===
 
 // GetLotteryContractInfo 獲取抽獎合約狀態
@@ -257,14 +621,14 @@ func (c *Client) GetLotteryContractInfo(ctx context.Context, contractAddress str
 	c.logger.Debug("查詢抽獎合約狀態", "address", contractAddress)
 
 	// 調用合約的 getContractInfo get 方法
-	result, err := c.RunGetMethod(ctx, contractAddress, "getContractInfo", []interface{}{})
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getContractInfo", nil)
 	if err != nil {
 		return nil, fmt.Errorf("查詢抽獎合約狀態失敗: %w", err)
 	}
 
 	// 解析結果
-	var contractInfo LotteryContractInfo
-	if err := json.Unmarshal(result, &contractInfo); err != nil {
+	contractInfo, err := decodeLotteryContractInfo(stack)
+	if err != nil {
 		return nil, fmt.Errorf("解析抽獎合約狀態失敗: %w", err)
 	}
 
@@ -274,59 +638,127 @@ func (c *Client) GetLotteryContractInfo(ctx context.Context, contractAddress str
 		"participants", contractInfo.ParticipantCount,
 	)
 
-	return &contractInfo, nil
+	return contractInfo, nil
 }
 
-// GetParticipant 獲取參與者資訊
+// GetParticipant 獲取參與者資訊，索引上沒有參與者時返回 nil
 func (c *Client) GetParticipant(ctx context.Context, contractAddress string, index int) (*Participant, error) {
 	c.logger.Debug("查詢參與者資訊", "address", contractAddress, "index", index)
 
-	result, err := c.RunGetMethod(ctx, contractAddress, "getParticipant", []interface{}{index})
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getParticipant", tvm.Args{tvm.Int(int64(index))})
 	if err != nil {
 		return nil, fmt.Errorf("查詢參與者資訊失敗: %w", err)
 	}
 
-	var participant Participant
-	if err := json.Unmarshal(result, &participant); err != nil {
+	participant, err := decodeParticipant(stack)
+	if err != nil {
 		return nil, fmt.Errorf("解析參與者資訊失敗: %w", err)
 	}
 
+	if participant == nil {
+		c.logger.Debug("參與者不存在", "index", index)
+		return nil, nil
+	}
+
 	c.logger.Debug("參與者資訊查詢成功", "address", participant.Address)
-	return &participant, nil
+	return participant, nil
 }
 
-// GetWinner 獲取中獎記錄
+// GetWinner 獲取中獎記錄，該輪次尚未開獎時返回 nil
 func (c *Client) GetWinner(ctx context.Context, contractAddress string, round int) (*LotteryResult, error) {
 	c.logger.Debug("查詢中獎記錄", "address", contractAddress, "round", round)
 
-	result, err := c.RunGetMethod(ctx, contractAddress, "getWinner", []interface{}{round})
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getWinner", tvm.Args{tvm.Int(int64(round))})
 	if err != nil {
 		return nil, fmt.Errorf("查詢中獎記錄失敗: %w", err)
 	}
 
-	var lotteryResult LotteryResult
-	if err := json.Unmarshal(result, &lotteryResult); err != nil {
+	lotteryResult, err := decodeLotteryResult(stack)
+	if err != nil {
 		return nil, fmt.Errorf("解析中獎記錄失敗: %w", err)
 	}
 
+	if lotteryResult == nil {
+		c.logger.Debug("該輪次沒有中獎記錄", "round", round)
+		return nil, nil
+	}
+
 	c.logger.Debug("中獎記錄查詢成功", "winner", lotteryResult.Winner, "nft_id", lotteryResult.NFTId)
-	return &lotteryResult, nil
+	return lotteryResult, nil
 }
 
 // GetContractBalance 獲取合約餘額
 func (c *Client) GetContractBalance(ctx context.Context, contractAddress string) (int64, error) {
 	c.logger.Debug("查詢合約餘額", "address", contractAddress)
 
-	result, err := c.RunGetMethod(ctx, contractAddress, "getBalance", []interface{}{})
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getBalance", nil)
 	if err != nil {
 		return 0, fmt.Errorf("查詢合約餘額失敗: %w", err)
 	}
 
-	var balance int64
-	if err := json.Unmarshal(result, &balance); err != nil {
+	r := tvm.NewReader(stack)
+	balance := r.Int64()
+	if err := r.Err(); err != nil {
 		return 0, fmt.Errorf("解析合約餘額失敗: %w", err)
 	}
 
 	c.logger.Debug("合約餘額查詢成功", "balance", balance)
 	return balance, nil
 }
+
+// === NFT 合約專用方法 ===
+
+// GetNFTContractInfo 獲取 CatNFT 合約狀態
+func (c *Client) GetNFTContractInfo(ctx context.Context, contractAddress string) (*NFTContractInfo, error) {
+	c.logger.Debug("查詢 NFT 合約狀態", "address", contractAddress)
+
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getContractInfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("查詢 NFT 合約狀態失敗: %w", err)
+	}
+
+	contractInfo, err := decodeNFTContractInfo(stack)
+	if err != nil {
+		return nil, fmt.Errorf("解析 NFT 合約狀態失敗: %w", err)
+	}
+
+	c.logger.Debug("NFT 合約狀態查詢成功", "owner", contractInfo.Owner, "supply", contractInfo.NFTSupply)
+	return contractInfo, nil
+}
+
+// GetNFTOwner 獲取 NFT 的擁有者，NFT 不存在時返回空字串
+func (c *Client) GetNFTOwner(ctx context.Context, contractAddress string, nftID int64) (string, error) {
+	c.logger.Debug("查詢 NFT 擁有者", "address", contractAddress, "nft_id", nftID)
+
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getNftOwner", tvm.Args{tvm.Int(nftID)})
+	if err != nil {
+		return "", fmt.Errorf("查詢 NFT 擁有者失敗: %w", err)
+	}
+
+	r := tvm.NewReader(stack)
+	owner := addressString(r.Address())
+	if err := r.Err(); err != nil {
+		return "", fmt.Errorf("解析 NFT 擁有者失敗: %w", err)
+	}
+
+	c.logger.Debug("NFT 擁有者查詢成功", "nft_id", nftID, "owner", owner)
+	return owner, nil
+}
+
+// GetCatInfo 獲取 NFT 對應的貓咪資訊
+func (c *Client) GetCatInfo(ctx context.Context, contractAddress string, nftID int64) (*CatInfo, error) {
+	c.logger.Debug("查詢貓咪資訊", "address", contractAddress, "nft_id", nftID)
+
+	stack, err := c.RunGetMethod(ctx, contractAddress, "getCatInfo", tvm.Args{tvm.Int(nftID)})
+	if err != nil {
+		return nil, fmt.Errorf("查詢貓咪資訊失敗: %w", err)
+	}
+
+	catInfo, err := decodeCatInfo(stack)
+	if err != nil {
+		return nil, fmt.Errorf("解析貓咪資訊失敗: %w", err)
+	}
+
+	c.logger.Debug("貓咪資訊查詢成功", "nft_id", nftID, "name", catInfo.Name, "rarity", catInfo.Rarity)
+	return catInfo, nil
+}
@@ -3,6 +3,7 @@ package ton
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
@@ -25,8 +28,8 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("Expected client to be created, got nil")
 	}
 
-	if client.baseURL != cfg.TONAPIEndpoint {
-		t.Errorf("Expected baseURL=%s, got %s", cfg.TONAPIEndpoint, client.baseURL)
+	if len(client.endpoints) != 1 || client.endpoints[0].url != cfg.TONAPIEndpoint {
+		t.Errorf("Expected single endpoint %s, got %d endpoints", cfg.TONAPIEndpoint, len(client.endpoints))
 	}
 
 	if client.httpClient == nil {
@@ -86,6 +89,48 @@ func TestGetContractInfo(t *testing.T) {
 	}
 }
 
+func TestGetAddressBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected int64
+		wantErr  bool
+	}{
+		{"active account", `{"balance": "2500000000", "state": "active"}`, 2500000000, false},
+		{"empty account", `{"balance": "", "state": "uninitialized"}`, 0, false},
+		{"malformed balance", `{"balance": "lots", "state": "active"}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// GET 請求的參數應以 query string 傳遞
+				if got := r.URL.Query().Get("address"); got != "EQWallet123" {
+					t.Errorf("Expected address query 'EQWallet123', got %q", got)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(tt.result)})
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{
+				TONAPIEndpoint: server.URL + "/",
+				LogLevel:       "debug",
+			}
+			client := NewClient(cfg, logger.New(cfg.LogLevel))
+
+			balance, err := client.GetAddressBalance(context.Background(), "EQWallet123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAddressBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if balance != tt.expected {
+				t.Errorf("Expected balance=%d, got %d", tt.expected, balance)
+			}
+		})
+	}
+}
+
 func TestSendTransaction(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.URL.Path, "sendBoc") {
@@ -197,6 +242,81 @@ func TestGetTransactionStatusPending(t *testing.T) {
 	}
 }
 
+const (
+	testOwnerAddress  = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	testNFTAddress    = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+	testWalletAddress = "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n"
+)
+
+// getMethodResponse 以 toncenter runGetMethod 的格式返回堆疊
+func getMethodResponse(t *testing.T, exitCode int, stack tvm.Stack) json.RawMessage {
+	t.Helper()
+
+	result, err := json.Marshal(map[string]interface{}{
+		"@type":     "smc.runResult",
+		"gas_used":  1234,
+		"exit_code": exitCode,
+		"stack":     stack,
+	})
+	if err != nil {
+		t.Fatalf("編碼堆疊失敗: %v", err)
+	}
+	return result
+}
+
+// newGetMethodServer 創建對 runGetMethod 返回固定堆疊的測試伺服器
+func newGetMethodServer(t *testing.T, method string, exitCode int, stack tvm.Stack) *httptest.Server {
+	t.Helper()
+	return newGetMethodArgsServer(t, method, "", exitCode, stack)
+}
+
+// newGetMethodArgsServer 與 newGetMethodServer 相同，並檢查請求的 stack 參數，
+// wantArgs 為空時不檢查
+func newGetMethodArgsServer(t *testing.T, method, wantArgs string, exitCode int, stack tvm.Stack) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Stack  json.RawMessage `json:"stack"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != method {
+			t.Errorf("Expected method %s, got %v", method, req.Method)
+		}
+		if wantArgs != "" && string(req.Stack) != wantArgs {
+			t.Errorf("Expected stack %s, got %s", wantArgs, req.Stack)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: getMethodResponse(t, exitCode, stack)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClient 創建連線到測試伺服器的客戶端
+func newTestClient(server *httptest.Server) *Client {
+	cfg := &config.Config{
+		TONAPIEndpoint: server.URL + "/",
+		LogLevel:       "debug",
+	}
+	return NewClient(cfg, logger.New(cfg.LogLevel))
+}
+
+// lotteryContractInfoStack CatLottery getContractInfo 返回的堆疊
+func lotteryContractInfoStack(nftContract *address.Address) tvm.Stack {
+	return tvm.Stack{tvm.Tuple(
+		tvm.Address(address.MustParse(testOwnerAddress)),
+		tvm.Int(100000000),
+		tvm.Int(10),
+		tvm.Int(1),
+		tvm.Bool(true),
+		tvm.Int(5),
+		tvm.Address(nftContract),
+	)}
+}
+
 func TestRunGetMethod(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.URL.Path, "runGetMethod") {
@@ -213,19 +333,13 @@ func TestRunGetMethod(t *testing.T) {
 			t.Fatalf("Failed to decode request body: %v", err)
 		}
 
-		if requestBody["method"] != "getContractInfo" {
-			t.Errorf("Expected method='getContractInfo', got %v", requestBody["method"])
+		if requestBody["method"] != "getBalance" {
+			t.Errorf("Expected method='getBalance', got %v", requestBody["method"])
 		}
 
 		response := APIResponse{
-			Ok: true,
-			Result: json.RawMessage(`{
-				"owner": "EQOwner123",
-				"entry_fee": 100000000,
-				"current_round": 1,
-				"lottery_active": true,
-				"participant_count": 5
-			}`),
+			Ok:     true,
+			Result: json.RawMessage(`{"@type": "smc.runResult", "gas_used": 2116, "stack": [["num", "0x12a05f200"]], "exit_code": 0}`),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -233,57 +347,65 @@ func TestRunGetMethod(t *testing.T) {
 	}))
 	defer server.Close()
 
-	cfg := &config.Config{
-		TONAPIEndpoint: server.URL + "/",
-		LogLevel:       "debug",
-	}
-	log := logger.New(cfg.LogLevel)
-
-	client := NewClient(cfg, log)
-	ctx := context.Background()
+	client := newTestClient(server)
 
-	result, err := client.RunGetMethod(ctx, "EQContract123", "getContractInfo", []interface{}{})
+	stack, err := client.RunGetMethod(context.Background(), "EQContract123", "getBalance", nil)
 	if err != nil {
 		t.Fatalf("RunGetMethod() failed: %v", err)
 	}
 
-	var parsed map[string]interface{}
-	if err := json.Unmarshal(result, &parsed); err != nil {
-		t.Fatalf("Failed to parse result: %v", err)
+	if len(stack) != 1 {
+		t.Fatalf("Expected 1 stack entry, got %d", len(stack))
 	}
 
-	if parsed["owner"] != "EQOwner123" {
-		t.Errorf("Expected owner='EQOwner123', got %v", parsed["owner"])
+	if balance, err := stack[0].Int64(); err != nil || balance != 5000000000 {
+		t.Errorf("Expected balance=5000000000, got %d (%v)", balance, err)
 	}
 }
 
-func TestGetLotteryContractInfo(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := APIResponse{
-			Ok: true,
-			Result: json.RawMessage(`{
-				"owner": "EQOwner123",
-				"entry_fee": 100000000,
-				"max_participants": 10,
-				"current_round": 1,
-				"lottery_active": true,
-				"participant_count": 5,
-				"nft_contract": "EQNFTContract456"
-			}`),
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
-
-	cfg := &config.Config{
-		TONAPIEndpoint: server.URL + "/",
-		LogLevel:       "debug",
+func TestRunGetMethodExitCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		exitCode      int
+		wantErr       bool
+		uninitialized bool
+	}{
+		{"success", 0, false, false},
+		{"alternative success", 1, false, false},
+		{"uninitialized account", -13, true, true},
+		{"method not found", 11, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newGetMethodServer(t, "getContractInfo", tt.exitCode, tvm.Stack{})
+			client := newTestClient(server)
+
+			_, err := client.RunGetMethod(context.Background(), "EQContract123", "getContractInfo", nil)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("RunGetMethod() failed: %v", err)
+				}
+				return
+			}
+
+			var getErr *GetMethodError
+			if !errors.As(err, &getErr) {
+				t.Fatalf("Expected GetMethodError, got %v", err)
+			}
+			if getErr.Method != "getContractInfo" || getErr.ExitCode != tt.exitCode {
+				t.Errorf("Unexpected error: %+v", getErr)
+			}
+			if IsUninitialized(err) != tt.uninitialized {
+				t.Errorf("Expected IsUninitialized()=%v", tt.uninitialized)
+			}
+		})
 	}
-	log := logger.New(cfg.LogLevel)
+}
 
-	client := NewClient(cfg, log)
+func TestGetLotteryContractInfo(t *testing.T) {
+	server := newGetMethodServer(t, "getContractInfo", 0, lotteryContractInfoStack(address.MustParse(testNFTAddress)))
+	client := newTestClient(server)
 	ctx := context.Background()
 
 	contractInfo, err := client.GetLotteryContractInfo(ctx, "EQContract123")
@@ -291,8 +413,8 @@ func TestGetLotteryContractInfo(t *testing.T) {
 		t.Fatalf("GetLotteryContractInfo() failed: %v", err)
 	}
 
-	if contractInfo.Owner != "EQOwner123" {
-		t.Errorf("Expected owner='EQOwner123', got %s", contractInfo.Owner)
+	if contractInfo.Owner != testOwnerAddress {
+		t.Errorf("Expected owner=%s, got %s", testOwnerAddress, contractInfo.Owner)
 	}
 
 	if contractInfo.EntryFee != 100000000 {
@@ -315,43 +437,53 @@ func TestGetLotteryContractInfo(t *testing.T) {
 		t.Errorf("Expected participant_count=5, got %d", contractInfo.ParticipantCount)
 	}
 
-	if contractInfo.NFTContract != "EQNFTContract456" {
-		t.Errorf("Expected nft_contract='EQNFTContract456', got %s", contractInfo.NFTContract)
+	if contractInfo.NFTContract != testNFTAddress {
+		t.Errorf("Expected nft_contract=%s, got %s", testNFTAddress, contractInfo.NFTContract)
 	}
 }
 
-func TestGetParticipant(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := APIResponse{
-			Ok: true,
-			Result: json.RawMessage(`{
-				"address": "EQParticipant123",
-				"amount": 100000000,
-				"timestamp": 1640995200
-			}`),
-		}
+func TestGetLotteryContractInfoWithoutNFTContract(t *testing.T) {
+	// 尚未設定 NFT 合約時 nftContract 為 null
+	server := newGetMethodServer(t, "getContractInfo", 0, lotteryContractInfoStack(nil))
+	client := newTestClient(server)
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
+	contractInfo, err := client.GetLotteryContractInfo(context.Background(), "EQContract123")
+	if err != nil {
+		t.Fatalf("GetLotteryContractInfo() failed: %v", err)
+	}
 
-	cfg := &config.Config{
-		TONAPIEndpoint: server.URL + "/",
-		LogLevel:       "debug",
+	if contractInfo.NFTContract != "" {
+		t.Errorf("Expected empty nft_contract, got %s", contractInfo.NFTContract)
 	}
-	log := logger.New(cfg.LogLevel)
+}
 
-	client := NewClient(cfg, log)
-	ctx := context.Background()
+func TestGetLotteryContractInfoInvalidStack(t *testing.T) {
+	// 欄位數量不足的 tuple
+	stack := tvm.Stack{tvm.Tuple(tvm.Address(address.MustParse(testOwnerAddress)), tvm.Int(100000000))}
+	server := newGetMethodServer(t, "getContractInfo", 0, stack)
+	client := newTestClient(server)
 
-	participant, err := client.GetParticipant(ctx, "EQContract123", 0)
+	if _, err := client.GetLotteryContractInfo(context.Background(), "EQContract123"); err == nil {
+		t.Fatal("Expected GetLotteryContractInfo() to fail with a short tuple")
+	}
+}
+
+func TestGetParticipant(t *testing.T) {
+	stack := tvm.Stack{tvm.Tuple(
+		tvm.Address(address.MustParse(testWalletAddress)),
+		tvm.Int(100000000),
+		tvm.Int(1640995200),
+	)}
+	server := newGetMethodArgsServer(t, "getParticipant", `[["num","0x0"]]`, 0, stack)
+	client := newTestClient(server)
+
+	participant, err := client.GetParticipant(context.Background(), "EQContract123", 0)
 	if err != nil {
 		t.Fatalf("GetParticipant() failed: %v", err)
 	}
 
-	if participant.Address != "EQParticipant123" {
-		t.Errorf("Expected address='EQParticipant123', got %s", participant.Address)
+	if participant.Address != testWalletAddress {
+		t.Errorf("Expected address=%s, got %s", testWalletAddress, participant.Address)
 	}
 
 	if participant.Amount != 100000000 {
@@ -363,38 +495,36 @@ func TestGetParticipant(t *testing.T) {
 	}
 }
 
-func TestGetWinner(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := APIResponse{
-			Ok: true,
-			Result: json.RawMessage(`{
-				"winner": "EQWinner123",
-				"nft_id": 42,
-				"timestamp": 1640995200
-			}`),
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer server.Close()
+func TestGetParticipantNull(t *testing.T) {
+	server := newGetMethodServer(t, "getParticipant", 0, tvm.Stack{tvm.Null()})
+	client := newTestClient(server)
 
-	cfg := &config.Config{
-		TONAPIEndpoint: server.URL + "/",
-		LogLevel:       "debug",
+	participant, err := client.GetParticipant(context.Background(), "EQContract123", 7)
+	if err != nil {
+		t.Fatalf("GetParticipant() failed: %v", err)
 	}
-	log := logger.New(cfg.LogLevel)
 
-	client := NewClient(cfg, log)
-	ctx := context.Background()
+	if participant != nil {
+		t.Errorf("Expected nil participant, got %+v", participant)
+	}
+}
 
-	winner, err := client.GetWinner(ctx, "EQContract123", 1)
+func TestGetWinner(t *testing.T) {
+	stack := tvm.Stack{tvm.Tuple(
+		tvm.Address(address.MustParse(testWalletAddress)),
+		tvm.Int(42),
+		tvm.Int(1640995200),
+	)}
+	server := newGetMethodArgsServer(t, "getWinner", `[["num","0x1"]]`, 0, stack)
+	client := newTestClient(server)
+
+	winner, err := client.GetWinner(context.Background(), "EQContract123", 1)
 	if err != nil {
 		t.Fatalf("GetWinner() failed: %v", err)
 	}
 
-	if winner.Winner != "EQWinner123" {
-		t.Errorf("Expected winner='EQWinner123', got %s", winner.Winner)
+	if winner.Winner != testWalletAddress {
+		t.Errorf("Expected winner=%s, got %s", testWalletAddress, winner.Winner)
 	}
 
 	if winner.NFTId != 42 {
@@ -406,15 +536,150 @@ func TestGetWinner(t *testing.T) {
 	}
 }
 
+func TestGetWinnerNull(t *testing.T) {
+	server := newGetMethodServer(t, "getWinner", 0, tvm.Stack{tvm.Null()})
+	client := newTestClient(server)
+
+	winner, err := client.GetWinner(context.Background(), "EQContract123", 2)
+	if err != nil {
+		t.Fatalf("GetWinner() failed: %v", err)
+	}
+
+	if winner != nil {
+		t.Errorf("Expected nil winner, got %+v", winner)
+	}
+
+	if winner.IsWinner(testWalletAddress) {
+		t.Error("Expected IsWinner() to be false without a result")
+	}
+}
+
 func TestGetContractBalance(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := APIResponse{
-			Ok:     true,
-			Result: json.RawMessage(`5000000000`), // 5 TON
-		}
+	server := newGetMethodArgsServer(t, "getBalance", `[]`, 0, tvm.Stack{tvm.Int(5000000000)}) // 5 TON
+	client := newTestClient(server)
+
+	balance, err := client.GetContractBalance(context.Background(), "EQContract123")
+	if err != nil {
+		t.Fatalf("GetContractBalance() failed: %v", err)
+	}
+
+	if balance != 5000000000 {
+		t.Errorf("Expected balance=5000000000, got %d", balance)
+	}
+}
+
+func TestGetNFTContractInfo(t *testing.T) {
+	stack := tvm.Stack{tvm.Tuple(tvm.Address(address.MustParse(testOwnerAddress)), tvm.Int(4), tvm.Int(3))}
+	server := newGetMethodServer(t, "getContractInfo", 0, stack)
+	client := newTestClient(server)
+
+	info, err := client.GetNFTContractInfo(context.Background(), "EQNFT123")
+	if err != nil {
+		t.Fatalf("GetNFTContractInfo() failed: %v", err)
+	}
+
+	if info.Owner != testOwnerAddress || info.NextNFTId != 4 || info.NFTSupply != 3 {
+		t.Errorf("Unexpected NFT contract info: %+v", info)
+	}
+}
+
+func TestGetNFTOwner(t *testing.T) {
+	server := newGetMethodArgsServer(t, "getNftOwner", `[["num","0x2a"]]`, 0,
+		tvm.Stack{tvm.Address(address.MustParse(testWalletAddress))})
+	client := newTestClient(server)
+
+	owner, err := client.GetNFTOwner(context.Background(), "EQNFT123", 42)
+	if err != nil {
+		t.Fatalf("GetNFTOwner() failed: %v", err)
+	}
+
+	if owner != testWalletAddress {
+		t.Errorf("Expected owner=%s, got %s", testWalletAddress, owner)
+	}
+}
 
+func TestGetNFTOwnerNull(t *testing.T) {
+	server := newGetMethodServer(t, "getNftOwner", 0, tvm.Stack{tvm.Null()})
+	client := newTestClient(server)
+
+	owner, err := client.GetNFTOwner(context.Background(), "EQNFT123", 99)
+	if err != nil {
+		t.Fatalf("GetNFTOwner() failed: %v", err)
+	}
+
+	if owner != "" {
+		t.Errorf("Expected empty owner for missing NFT, got %s", owner)
+	}
+}
+
+func TestGetCatInfo(t *testing.T) {
+	stack := EncodeCatInfo(&CatInfo{Name: "小橘", Rarity: "Legendary", CatType: "Orange Tabby"})
+	server := newGetMethodArgsServer(t, "getCatInfo", `[["num","0x3"]]`, 0, stack)
+	client := newTestClient(server)
+
+	info, err := client.GetCatInfo(context.Background(), "EQNFT123", 3)
+	if err != nil {
+		t.Fatalf("GetCatInfo() failed: %v", err)
+	}
+
+	if info.Name != "小橘" || info.Rarity != "Legendary" || info.CatType != "Orange Tabby" {
+		t.Errorf("Unexpected cat info: %+v", info)
+	}
+}
+
+func TestGetWalletSeqno(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected uint32
+		wantErr  bool
+	}{
+		{"deployed wallet", `{"gas_used": 500, "stack": [["num", "0x1a"]], "exit_code": 0}`, 26, false},
+		{"uninitialized wallet", `{"gas_used": 0, "stack": [], "exit_code": -13}`, 0, false},
+		{"get method failure", `{"gas_used": 0, "stack": [], "exit_code": 11}`, 0, true},
+		{"unexpected stack", `{"gas_used": 0, "stack": [["cell", {}]], "exit_code": 0}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&req)
+				if req["method"] != "seqno" {
+					t.Errorf("Expected method 'seqno', got %v", req["method"])
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(tt.result)})
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{
+				TONAPIEndpoint: server.URL + "/",
+				LogLevel:       "debug",
+			}
+			client := NewClient(cfg, logger.New(cfg.LogLevel))
+
+			seqno, err := client.GetWalletSeqno(context.Background(), "EQAuLCGHEQ1nzK9Ufchrsqql3ryxMtLrU71uIGxawiOE_C-n")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetWalletSeqno() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if seqno != tt.expected {
+				t.Errorf("Expected seqno=%d, got %d", tt.expected, seqno)
+			}
+		})
+	}
+}
+
+func TestSendTransactionRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIResponse{
+			Ok:    false,
+			Code:  500,
+			Error: "LITE_SERVER_UNKNOWN: cannot apply external message to current state : External message was not accepted\nCannot run message on account: inbound external message rejected by transaction: exitcode=33, steps=58, gas_used=0",
+		})
 	}))
 	defer server.Close()
 
@@ -422,18 +687,22 @@ func TestGetContractBalance(t *testing.T) {
 		TONAPIEndpoint: server.URL + "/",
 		LogLevel:       "debug",
 	}
-	log := logger.New(cfg.LogLevel)
-
-	client := NewClient(cfg, log)
-	ctx := context.Background()
+	client := NewClient(cfg, logger.New(cfg.LogLevel))
 
-	balance, err := client.GetContractBalance(ctx, "EQContract123")
-	if err != nil {
-		t.Fatalf("GetContractBalance() failed: %v", err)
+	_, err := client.SendTransaction(context.Background(), []byte{0xb5, 0xee})
+	if err == nil {
+		t.Fatal("Expected SendTransaction() to fail")
 	}
 
-	if balance != 5000000000 {
-		t.Errorf("Expected balance=5000000000, got %d", balance)
+	var rejected *MessageRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("Expected MessageRejectedError, got %v", err)
+	}
+	if rejected.ExitCode != ExitCodeSeqnoMismatch {
+		t.Errorf("Expected exit code 33, got %d", rejected.ExitCode)
+	}
+	if !IsSeqnoMismatch(err) {
+		t.Error("Expected IsSeqnoMismatch() to be true")
 	}
 }
 
@@ -485,3 +754,189 @@ func TestMakeRequestNetworkError(t *testing.T) {
 		t.Fatal("Expected GetContractInfo() to fail with network error")
 	}
 }
+
+func TestAddressComparison(t *testing.T) {
+	friendly := "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	raw := "0:4f07abb23458c88942c201069a71d5120bd4aa48910ca3f339c7c1ab1e63e11f"
+	nonBounceable := "UQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhH3eY"
+	other := "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+
+	participant := &Participant{Address: friendly}
+	if !participant.HasAddress(raw) {
+		t.Error("Expected participant to match raw address")
+	}
+	if !participant.HasAddress(nonBounceable) {
+		t.Error("Expected participant to match non-bounceable address")
+	}
+	if participant.HasAddress(other) {
+		t.Error("Expected participant not to match other address")
+	}
+
+	result := &LotteryResult{Winner: raw}
+	if !result.IsWinner(friendly) {
+		t.Error("Expected winner to match friendly address")
+	}
+	if result.IsWinner(other) {
+		t.Error("Expected other address not to be the winner")
+	}
+}
+
+func TestRequestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "getAddressInformation"):
+			json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`{"balance": "1"}`)})
+		case strings.HasSuffix(r.URL.Path, "getTransactions"):
+			json.NewEncoder(w).Encode(APIResponse{Ok: false, Error: "bad hash"})
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(APIResponse{Ok: false, Code: 429, Error: "Ratelimit exceed"})
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	before := map[[2]string]uint64{}
+	series := [][2]string{
+		{"getAddressInformation", "200"},
+		{"getTransactions", "api_error"},
+		{"runGetMethod", "429"},
+		{"getAddressInformation", "network_error"},
+	}
+	for _, s := range series {
+		before[s] = requestDuration.Count(s[0], s[1], server.URL+"/")
+	}
+
+	client.GetContractInfo(ctx, "EQTest123")
+	client.GetTransactionStatus(ctx, "hash")
+	client.RunGetMethod(ctx, "EQTest123", "seqno", nil)
+	server.Close()
+	client.GetContractInfo(ctx, "EQTest123")
+
+	for _, s := range series {
+		if got := requestDuration.Count(s[0], s[1], server.URL+"/") - before[s]; got != 1 {
+			t.Errorf("Expected 1 request with method=%s status=%s, got %d", s[0], s[1], got)
+		}
+	}
+}
+
+// newRetryTestClient 創建會重試的客戶端，延遲縮短以加快測試
+func newRetryTestClient(server *httptest.Server) *Client {
+	cfg := &config.Config{
+		TONAPIEndpoint:       server.URL + "/",
+		LogLevel:             "debug",
+		TONAPIMaxRetries:     3,
+		TONAPIRetryBaseDelay: time.Millisecond,
+		TONAPIRetryMaxDelay:  2 * time.Second,
+	}
+	return NewClient(cfg, logger.New("error"))
+}
+
+func TestRequestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int // 依序回應的狀態碼，200 表示成功
+		path      string
+		wantCalls int
+		wantErr   bool
+	}{
+		{"retries rate limit", []int{429, 429, 200}, "getAddressInformation", 3, false},
+		{"retries server error", []int{502, 200}, "getAddressInformation", 2, false},
+		{"gives up after max retries", []int{503, 503, 503, 503, 503}, "getAddressInformation", 4, true},
+		{"permanent error", []int{400, 200}, "getAddressInformation", 1, true},
+		{"send retries rate limit", []int{429, 200}, "sendBocReturnHash", 2, false},
+		{"send does not retry server error", []int{500, 200}, "sendBocReturnHash", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.responses[min(calls, len(tt.responses)-1)]
+				calls++
+
+				w.Header().Set("Content-Type", "application/json")
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					json.NewEncoder(w).Encode(APIResponse{Ok: false, Code: status, Error: http.StatusText(status)})
+					return
+				}
+				json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`{"hash": "abc", "balance": "1"}`)})
+			}))
+			defer server.Close()
+
+			client := newRetryTestClient(server)
+			var err error
+			if tt.path == "sendBocReturnHash" {
+				_, err = client.SendTransaction(context.Background(), []byte{1})
+			} else {
+				_, err = client.GetContractInfo(context.Background(), "EQTest123")
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRequestRetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(APIResponse{Ok: false, Code: 429, Error: "Ratelimit exceed"})
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`{"balance": "1"}`)})
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(server)
+	if _, err := client.GetContractInfo(context.Background(), "EQTest123"); err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if len(times) != 2 || times[1].Sub(times[0]) < 900*time.Millisecond {
+		t.Errorf("Expected retry after at least 1s, got %d calls %v apart", len(times), times[len(times)-1].Sub(times[0]))
+	}
+
+	// Retry-After 超過重試延遲上限時不重試
+	times = nil
+	client.retryMaxDelay = 500 * time.Millisecond
+	_, err := client.GetContractInfo(context.Background(), "EQTest123")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Second || len(times) != 1 {
+		t.Errorf("Expected 429 with Retry-After without retrying, got %v after %d calls", err, len(times))
+	}
+}
+
+func TestRequestRetryCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(server)
+	client.retryBaseDelay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetContractInfo(ctx, "EQTest123")
+	if !IsRetryable(err) {
+		t.Errorf("Expected the last retryable error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected cancellation to stop the backoff, took %v", elapsed)
+	}
+}
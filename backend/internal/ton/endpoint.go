@@ -0,0 +1,167 @@
+package ton
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/pkg/logger"
+	"ton-cat-lottery-backend/pkg/metrics"
+)
+
+// 端點健康評分
+const (
+	// healthDecay 評分的指數移動平均權重：新評分 = 舊評分×(1-healthDecay) + 結果×healthDecay
+	healthDecay = 0.3
+
+	// unhealthyScore 評分低於此值時暫停使用端點；從 1 開始連續兩次失敗即低於此值
+	unhealthyScore = 0.5
+
+	// 暫停使用的時間，每次恢復失敗後加倍，成功後重設
+	minCooldown = 30 * time.Second
+	maxCooldown = 5 * time.Minute
+)
+
+var (
+	endpointHealth = metrics.NewGaugeVec(
+		"ton_api_endpoint_health_score",
+		"Health score of each toncenter endpoint between 0 and 1.",
+		"endpoint",
+	)
+	endpointUp = metrics.NewGaugeVec(
+		"ton_api_endpoint_up",
+		"Whether the endpoint is currently used (1) or cooling down after failures (0).",
+		"endpoint",
+	)
+	failovers = metrics.NewCounterVec(
+		"ton_api_failovers_total",
+		"Requests moved to another endpoint after a transient error.",
+		"from", "to",
+	)
+	hedgedRequests = metrics.NewCounterVec(
+		"ton_api_hedged_requests_total",
+		"Read requests also sent to the next endpoint after the hedge delay, by hedge endpoint and whether it won.",
+		"endpoint", "won",
+	)
+)
+
+// endpoint 單一 TON API 端點及其健康狀態
+//
+// 每個請求的結果更新評分；評分低於 unhealthyScore 時暫停使用一段時間，期滿後重新嘗試（成功即恢復，
+// 失敗則加倍暫停時間）。所有端點都在暫停中時仍會使用最快期滿的端點，請求不會因此被拒絕。
+type endpoint struct {
+	url     string // 同時作為指標與日誌的標籤，不包含 API key
+	keys    *keyRing
+	limiter *tokenBucket
+	logger  *logger.Logger
+
+	mu        sync.Mutex
+	score     float64
+	downUntil time.Time
+	cooldown  time.Duration
+}
+
+// sharedEndpoints 依 URL 共用的端點狀態
+//
+// 與 sharedBuckets 相同，同一程序中連到相同端點的客戶端共用健康評分，避免各自重新發現故障的端點。
+var sharedEndpoints = struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpoint
+}{endpoints: make(map[string]*endpoint)}
+
+// newEndpoints 依設定的優先順序返回端點，第一個客戶端的設定決定 API key 與頻率限制
+//
+// 在 TON_API_ENDPOINTS 中指定 key 的端點只使用該 key，其他端點輪替使用 TONAPIKeys。
+func newEndpoints(cfg *config.Config, log *logger.Logger) []*endpoint {
+	sharedEndpoints.mu.Lock()
+	defer sharedEndpoints.mu.Unlock()
+
+	var endpoints []*endpoint
+	for _, e := range cfg.TONAPIEndpointList() {
+		ep, ok := sharedEndpoints.endpoints[e.URL]
+		if !ok {
+			keys := cfg.TONAPIKeys
+			if e.APIKey != "" {
+				keys = []string{e.APIKey}
+			}
+			ep = &endpoint{
+				url:      e.URL,
+				keys:     newKeyRing(keys, cfg.TONAPIKeyRotation == config.KeyRotationRoundRobin),
+				limiter:  sharedTokenBucket(e.URL, cfg.TONAPIRateLimit, cfg.TONAPIBurst),
+				logger:   log,
+				score:    1,
+				cooldown: minCooldown,
+			}
+			sharedEndpoints.endpoints[e.URL] = ep
+			endpointHealth.Set(1, ep.url)
+			endpointUp.Set(1, ep.url)
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// record 以請求結果更新評分，healthy 表示端點有正常回應（包含永久性錯誤）
+func (e *endpoint) record(healthy bool, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := 0.0
+	if healthy {
+		result = 1
+	}
+	e.score = e.score*(1-healthDecay) + result*healthDecay
+	endpointHealth.Set(e.score, e.url)
+
+	wasDown := !e.downUntil.IsZero()
+	switch {
+	case e.score >= unhealthyScore && wasDown:
+		e.downUntil = time.Time{}
+		e.cooldown = minCooldown
+		endpointUp.Set(1, e.url)
+		e.logger.Info("TON API 端點恢復使用", "endpoint", e.url, "score", e.score)
+
+	case e.score < unhealthyScore && (!wasDown || !now.Before(e.downUntil)):
+		// 剛變為不健康，或暫停期滿後的嘗試再次失敗
+		if wasDown {
+			e.cooldown = min(e.cooldown*2, maxCooldown)
+		}
+		e.downUntil = now.Add(e.cooldown)
+		endpointUp.Set(0, e.url)
+		e.logger.Warn("TON API 端點暫停使用", "endpoint", e.url, "score", e.score, "cooldown", e.cooldown.String())
+	}
+}
+
+// available 端點目前是否可用，暫停期滿的端點可再次嘗試
+func (e *endpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+// state 返回評分與暫停結束時間
+func (e *endpoint) state() (float64, time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.score, e.downUntil
+}
+
+// orderEndpoints 返回請求嘗試的順序：可用的端點依優先順序在前，暫停中的端點依暫停結束時間在後
+func orderEndpoints(endpoints []*endpoint, now time.Time) []*endpoint {
+	var up, down []*endpoint
+	for _, ep := range endpoints {
+		if ep.available(now) {
+			up = append(up, ep)
+		} else {
+			down = append(down, ep)
+		}
+	}
+
+	sort.SliceStable(down, func(i, j int) bool {
+		_, a := down[i].state()
+		_, b := down[j].state()
+		return a.Before(b)
+	})
+	return append(up, down...)
+}
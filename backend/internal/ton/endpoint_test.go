@@ -0,0 +1,219 @@
+package ton
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+func newTestEndpoint(url string) *endpoint {
+	return &endpoint{url: url, logger: logger.New("error"), score: 1, cooldown: minCooldown}
+}
+
+func TestEndpointHealth(t *testing.T) {
+	ep := newTestEndpoint("https://health.example/")
+	now := time.Now()
+
+	// 單次失敗不暫停
+	ep.record(false, now)
+	if !ep.available(now) {
+		t.Fatal("Expected endpoint to stay available after one failure")
+	}
+
+	// 連續兩次失敗後暫停 minCooldown
+	ep.record(false, now)
+	if ep.available(now) || !ep.available(now.Add(minCooldown)) {
+		t.Fatalf("Expected endpoint to cool down for %v", minCooldown)
+	}
+	if got, _ := endpointUp.Value(ep.url); got != 0 {
+		t.Errorf("Expected up gauge 0, got %v", got)
+	}
+
+	// 暫停期滿後的嘗試再次失敗，暫停時間加倍
+	now = now.Add(minCooldown)
+	ep.record(false, now)
+	if ep.available(now.Add(minCooldown)) || !ep.available(now.Add(2*minCooldown)) {
+		t.Errorf("Expected cooldown to double to %v", 2*minCooldown)
+	}
+
+	// 恢復正常回應後重新使用
+	now = now.Add(2 * minCooldown)
+	for i := 0; i < 3; i++ {
+		ep.record(true, now)
+	}
+	score, downUntil := ep.state()
+	if !downUntil.IsZero() || score < unhealthyScore {
+		t.Errorf("Expected endpoint to recover, score=%v down_until=%v", score, downUntil)
+	}
+	if got, _ := endpointHealth.Value(ep.url); got != score {
+		t.Errorf("Expected health gauge %v, got %v", score, got)
+	}
+	if ep.cooldown != minCooldown {
+		t.Errorf("Expected cooldown to reset to %v, got %v", minCooldown, ep.cooldown)
+	}
+}
+
+func TestOrderEndpoints(t *testing.T) {
+	now := time.Now()
+	a, b, c := newTestEndpoint("a/"), newTestEndpoint("b/"), newTestEndpoint("c/")
+	a.downUntil = now.Add(time.Minute)
+	b.downUntil = now.Add(time.Second)
+
+	got := orderEndpoints([]*endpoint{a, b, c}, now)
+	if got[0] != c || got[1] != b || got[2] != a {
+		t.Errorf("Expected available endpoints first, then by cooldown end, got %s %s %s", got[0].url, got[1].url, got[2].url)
+	}
+}
+
+// newEndpointServer 創建回應固定狀態碼的 mock 端點，延遲 delay 後回應並記錄請求次數與 API key
+func newEndpointServer(t *testing.T, status *atomic.Int32, delay time.Duration, calls *atomic.Int32, apiKey *atomic.Value) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if apiKey != nil {
+			apiKey.Store(r.Header.Get("X-API-Key"))
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(APIResponse{Ok: false, Code: code, Error: http.StatusText(code)})
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`{"balance": "1"}`)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRequestFailover(t *testing.T) {
+	var primaryStatus, backupStatus atomic.Int32
+	primaryStatus.Store(http.StatusServiceUnavailable)
+	backupStatus.Store(http.StatusOK)
+
+	var primaryCalls, backupCalls atomic.Int32
+	var backupKey atomic.Value
+	primary := newEndpointServer(t, &primaryStatus, 0, &primaryCalls, nil)
+	backup := newEndpointServer(t, &backupStatus, 0, &backupCalls, &backupKey)
+
+	// 不重試，切換端點不計入重試次數
+	cfg := &config.Config{
+		TONAPIEndpoints: primary.URL + "/," + backup.URL + "/|backup-key",
+	}
+	client := NewClient(cfg, logger.New("error"))
+	ctx := context.Background()
+	before := failovers.Value(primary.URL+"/", backup.URL+"/")
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetContractInfo(ctx, "EQTest123"); err != nil {
+			t.Fatalf("GetContractInfo() failed: %v", err)
+		}
+	}
+	if backupKey.Load() != "backup-key" {
+		t.Errorf("Expected backup API key to be sent, got %v", backupKey.Load())
+	}
+
+	// 兩次失敗後主要端點暫停使用，第三個請求直接送到備用端點
+	if primaryCalls.Load() != 2 || backupCalls.Load() != 3 {
+		t.Errorf("Expected 2 primary and 3 backup calls, got %d and %d", primaryCalls.Load(), backupCalls.Load())
+	}
+	if got := failovers.Value(primary.URL+"/", backup.URL+"/") - before; got != 2 {
+		t.Errorf("Expected 2 failovers, got %d", got)
+	}
+
+	// 暫停期滿且主要端點恢復後切回主要端點
+	primaryStatus.Store(http.StatusOK)
+	ep := client.endpoints[0]
+	ep.mu.Lock()
+	ep.downUntil = time.Now().Add(-time.Second)
+	ep.mu.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetContractInfo(ctx, "EQTest123"); err != nil {
+			t.Fatalf("GetContractInfo() failed: %v", err)
+		}
+	}
+	if primaryCalls.Load() != 4 || backupCalls.Load() != 3 {
+		t.Errorf("Expected requests to fail back to the primary, got %d primary and %d backup calls", primaryCalls.Load(), backupCalls.Load())
+	}
+	if _, downUntil := ep.state(); !downUntil.IsZero() {
+		t.Errorf("Expected primary to be marked up, down until %v", downUntil)
+	}
+}
+
+func TestRequestFailoverAllEndpointsDown(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+
+	var firstCalls, secondCalls atomic.Int32
+	first := newEndpointServer(t, &status, 0, &firstCalls, nil)
+	second := newEndpointServer(t, &status, 0, &secondCalls, nil)
+
+	cfg := &config.Config{
+		TONAPIEndpoints:      first.URL + "/," + second.URL + "/",
+		TONAPIMaxRetries:     1,
+		TONAPIRetryBaseDelay: time.Millisecond,
+		TONAPIRetryMaxDelay:  time.Millisecond,
+	}
+	client := NewClient(cfg, logger.New("error"))
+
+	// 每輪嘗試所有端點，重試一次後返回錯誤
+	if _, err := client.GetContractInfo(context.Background(), "EQTest123"); !IsRetryable(err) {
+		t.Fatalf("Expected retryable error, got %v", err)
+	}
+	if firstCalls.Load() != 2 || secondCalls.Load() != 2 {
+		t.Errorf("Expected 2 calls to each endpoint, got %d and %d", firstCalls.Load(), secondCalls.Load())
+	}
+}
+
+func TestRequestHedge(t *testing.T) {
+	var ok atomic.Int32
+	ok.Store(http.StatusOK)
+
+	var slowCalls, fastCalls atomic.Int32
+	slow := newEndpointServer(t, &ok, time.Second, &slowCalls, nil)
+	fast := newEndpointServer(t, &ok, 0, &fastCalls, nil)
+
+	cfg := &config.Config{
+		TONAPIEndpoints:  slow.URL + "/," + fast.URL + "/",
+		TONAPIHedgeDelay: 20 * time.Millisecond,
+	}
+	client := NewClient(cfg, logger.New("error"))
+	ctx := context.Background()
+	before := hedgedRequests.Value(fast.URL+"/", "true")
+
+	start := time.Now()
+	if _, err := client.GetContractInfo(ctx, "EQTest123"); err != nil {
+		t.Fatalf("GetContractInfo() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected hedged request to return early, took %v", elapsed)
+	}
+	if got := hedgedRequests.Value(fast.URL+"/", "true") - before; got != 1 {
+		t.Errorf("Expected 1 hedged request won by the backup, got %d", got)
+	}
+
+	// 取消的慢請求不影響主要端點的評分
+	if score, _ := client.endpoints[0].state(); score != 1 {
+		t.Errorf("Expected cancelled request not to affect health, score=%v", score)
+	}
+
+	// 發送交易不使用延遲備援
+	fastCalls.Store(0)
+	client.SendTransaction(ctx, []byte{1})
+	if fastCalls.Load() != 0 {
+		t.Errorf("Expected sendBoc not to be hedged, got %d backup calls", fastCalls.Load())
+	}
+}
@@ -0,0 +1,149 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ExitCodeSeqnoMismatch 錢包合約拒絕外部訊息時常見的 exit code（seqno 不符）
+	ExitCodeSeqnoMismatch = 33
+	// ExitCodeUninitialized 帳戶尚未部署時 get 方法的 exit code
+	ExitCodeUninitialized = -13
+)
+
+// APIError toncenter 回傳 ok=false 時的錯誤
+type APIError struct {
+	StatusCode int           // HTTP 狀態碼
+	Code       int           // toncenter 回應中的錯誤碼
+	Message    string        // toncenter 回應中的錯誤訊息
+	RetryAfter time.Duration // Retry-After 標頭指定的等待時間，0 表示未指定
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API 錯誤: %s", e.Message)
+}
+
+// RateLimited 是否因超過 toncenter 的請求頻率限制被拒絕 (HTTP 429)
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Code == http.StatusTooManyRequests
+}
+
+// NetworkError 請求沒有取得完整的 HTTP 回應（連線失敗、逾時或讀取中斷）
+type NetworkError struct {
+	Op  string // 失敗的步驟，例如「發送請求失敗」
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable 錯誤是否為暫時性，稍後重試同一個請求可能成功
+//
+// 可重試：HTTP 429、5xx 與網路錯誤。其餘（參數錯誤、4xx、回應格式錯誤、get 方法失敗、
+// 外部訊息被拒絕、呼叫端取消）皆為永久性錯誤，重試只會得到相同的結果。
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || IsMessageRejected(err) {
+		return false
+	}
+	if _, rejected := parseMessageRejected(err); rejected {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RateLimited() || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr *NetworkError
+	return errors.As(err, &netErr)
+}
+
+// IsRateLimited 錯誤是否為 toncenter 的請求頻率限制 (HTTP 429)
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.RateLimited()
+}
+
+// GetMethodError get 方法以失敗的 exit code 結束（0 與 1 代表成功）
+type GetMethodError struct {
+	Method   string // get 方法名稱
+	ExitCode int    // TVM exit code
+}
+
+func (e *GetMethodError) Error() string {
+	if e.ExitCode == ExitCodeUninitialized {
+		return fmt.Sprintf("get 方法 %s 執行失敗: 帳戶尚未部署 (exit code %d)", e.Method, e.ExitCode)
+	}
+	return fmt.Sprintf("get 方法 %s 執行失敗 (exit code %d)", e.Method, e.ExitCode)
+}
+
+// IsUninitialized 錯誤是否代表 get 方法的目標帳戶尚未部署
+func IsUninitialized(err error) bool {
+	var getErr *GetMethodError
+	return errors.As(err, &getErr) && getErr.ExitCode == ExitCodeUninitialized
+}
+
+// MessageRejectedError 外部訊息未被錢包合約接受
+type MessageRejectedError struct {
+	ExitCode int    // 合約的 exit code，無法解析時為 0
+	Reason   string // 節點回傳的原始原因
+}
+
+func (e *MessageRejectedError) Error() string {
+	if e.ExitCode != 0 {
+		return fmt.Sprintf("外部訊息被拒絕 (exit code %d): %s", e.ExitCode, e.Reason)
+	}
+	return fmt.Sprintf("外部訊息被拒絕: %s", e.Reason)
+}
+
+var exitCodePattern = regexp.MustCompile(`exit_?code[=: ]+(-?\d+)`)
+
+// parseMessageRejected 判斷 API 錯誤是否代表外部訊息被拒絕
+func parseMessageRejected(err error) (*MessageRejectedError, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return nil, false
+	}
+
+	lower := strings.ToLower(apiErr.Message)
+	if !strings.Contains(lower, "external message was not accepted") &&
+		!strings.Contains(lower, "inbound external message rejected") &&
+		!exitCodePattern.MatchString(lower) {
+		return nil, false
+	}
+
+	rejected := &MessageRejectedError{Reason: apiErr.Message}
+	if m := exitCodePattern.FindStringSubmatch(lower); m != nil {
+		rejected.ExitCode, _ = strconv.Atoi(m[1])
+	}
+	return rejected, true
+}
+
+// IsMessageRejected 錯誤是否代表外部訊息被錢包合約拒絕
+func IsMessageRejected(err error) bool {
+	var rejected *MessageRejectedError
+	return errors.As(err, &rejected)
+}
+
+// IsSeqnoMismatch 錯誤是否代表外部訊息因 seqno 不符被拒絕
+//
+// 無法解析 exit code 的拒絕同樣視為可能的 seqno 不符，以便呼叫端重新同步。
+func IsSeqnoMismatch(err error) bool {
+	var rejected *MessageRejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	return rejected.ExitCode == ExitCodeSeqnoMismatch || rejected.ExitCode == 0
+}
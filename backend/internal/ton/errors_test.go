@@ -0,0 +1,114 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseMessageRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		rejected bool
+		exitCode int
+		mismatch bool
+	}{
+		{
+			name:     "seqno mismatch",
+			err:      &APIError{Message: "External message was not accepted: exitcode=33, steps=58"},
+			rejected: true,
+			exitCode: 33,
+			mismatch: true,
+		},
+		{
+			name:     "invalid signature",
+			err:      &APIError{Message: "inbound external message rejected by transaction: exit_code: 35"},
+			rejected: true,
+			exitCode: 35,
+			mismatch: false,
+		},
+		{
+			name:     "rejected without exit code",
+			err:      &APIError{Message: "External message was not accepted"},
+			rejected: true,
+			exitCode: 0,
+			mismatch: true,
+		},
+		{
+			name:     "other API error",
+			err:      &APIError{Message: "Incorrect address"},
+			rejected: false,
+		},
+		{
+			name:     "network error",
+			err:      errors.New("connection refused"),
+			rejected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected, ok := parseMessageRejected(fmt.Errorf("wrapped: %w", tt.err))
+			if ok != tt.rejected {
+				t.Fatalf("Expected rejected=%v, got %v", tt.rejected, ok)
+			}
+			if !ok {
+				return
+			}
+
+			if rejected.ExitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, rejected.ExitCode)
+			}
+
+			wrapped := fmt.Errorf("發送交易失敗: %w", rejected)
+			if !IsMessageRejected(wrapped) {
+				t.Error("Expected IsMessageRejected() to be true")
+			}
+			if IsSeqnoMismatch(wrapped) != tt.mismatch {
+				t.Errorf("Expected IsSeqnoMismatch()=%v", tt.mismatch)
+			}
+		})
+	}
+
+	if IsSeqnoMismatch(errors.New("timeout")) {
+		t.Error("Expected IsSeqnoMismatch() to be false for unrelated errors")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"rate limited", &APIError{StatusCode: 429, Message: "Ratelimit exceed"}, true},
+		{"rate limited code", &APIError{StatusCode: 200, Code: 429}, true},
+		{"server error", &APIError{StatusCode: 502, Message: "Bad Gateway"}, true},
+		{"network error", &NetworkError{Op: "發送請求失敗", Err: errors.New("connection refused")}, true},
+		{"bad request", &APIError{StatusCode: 400, Message: "Incorrect address"}, false},
+		{"api error with ok status", &APIError{StatusCode: 200, Message: "bad hash"}, false},
+		{"message rejected", &APIError{StatusCode: 500, Message: "External message was not accepted: exitcode=33"}, false},
+		{"get method failed", &GetMethodError{Method: "seqno", ExitCode: 11}, false},
+		{"canceled", &NetworkError{Op: "發送請求失敗", Err: context.Canceled}, false},
+		{"decode error", errors.New("解析 API 回應失敗"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err
+			if err != nil {
+				err = fmt.Errorf("wrapped: %w", err)
+			}
+			if got := IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+
+	if !IsRateLimited(fmt.Errorf("wrapped: %w", &APIError{StatusCode: 429})) || IsRateLimited(&APIError{StatusCode: 503}) {
+		t.Error("IsRateLimited() should only match 429")
+	}
+}
@@ -0,0 +1,256 @@
+package ton
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// EventType CatLottery 以 emit 發送的事件類型
+type EventType string
+
+const (
+	EventParticipantJoined EventType = "participant_joined"
+	EventLotteryFull       EventType = "lottery_full"
+	EventWinnerDrawn       EventType = "winner_drawn"
+	EventNFTSent           EventType = "nft_sent"
+)
+
+// 事件訊息的 opcode
+var (
+	OpParticipantJoined = TactOpcode("ParticipantJoined{participant:address,amount:int257,participantIndex:int257,round:int257}")
+	OpLotteryFull       = TactOpcode("LotteryFull{round:int257}")
+	OpWinnerDrawn       = TactOpcode("WinnerDrawn{winner:address,nftId:int257,round:int257,participantCount:int257}")
+	OpNFTSent           = TactOpcode("NFTSent{recipient:address,nftId:int257,nftContract:address,timestamp:int257}")
+)
+
+// ErrUnknownEvent 訊息的 opcode 不是 CatLottery 的事件
+var ErrUnknownEvent = errors.New("未知的合約事件")
+
+// EventPayload 已解碼的事件內容
+type EventPayload interface {
+	EventType() EventType
+}
+
+// ParticipantJoined 參與者加入事件
+type ParticipantJoined struct {
+	Participant      string `json:"participant"`
+	Amount           int64  `json:"amount"`
+	ParticipantIndex int    `json:"participant_index"`
+	Round            int    `json:"round"`
+}
+
+// LotteryFull 抽獎滿員事件
+type LotteryFull struct {
+	Round int `json:"round"`
+}
+
+// WinnerDrawn 中獎者抽出事件
+type WinnerDrawn struct {
+	Winner           string `json:"winner"`
+	NFTId            int64  `json:"nft_id"`
+	Round            int    `json:"round"`
+	ParticipantCount int    `json:"participant_count"`
+}
+
+// NFTSent NFT 發送事件
+type NFTSent struct {
+	Recipient   string `json:"recipient"`
+	NFTId       int64  `json:"nft_id"`
+	NFTContract string `json:"nft_contract"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+func (*ParticipantJoined) EventType() EventType { return EventParticipantJoined }
+func (*LotteryFull) EventType() EventType       { return EventLotteryFull }
+func (*WinnerDrawn) EventType() EventType       { return EventWinnerDrawn }
+func (*NFTSent) EventType() EventType           { return EventNFTSent }
+
+// DecodeEvent 解析事件訊息的內容，opcode 不是 CatLottery 事件時返回 ErrUnknownEvent
+//
+// Tact 依序寫入欄位，超過單一 cell 的 1023 位元時，剩餘欄位寫入引用的下一個 cell。
+func DecodeEvent(body *cell.Cell) (EventPayload, error) {
+	r := &eventReader{s: body.BeginParse()}
+	op := r.uint32()
+	if r.err != nil {
+		return nil, fmt.Errorf("讀取事件 opcode 失敗: %w", r.err)
+	}
+
+	var payload EventPayload
+	switch op {
+	case OpParticipantJoined:
+		e := &ParticipantJoined{}
+		e.Participant = r.address()
+		e.Amount = r.int257()
+		e.ParticipantIndex = int(r.int257())
+		r.next()
+		e.Round = int(r.int257())
+		payload = e
+
+	case OpLotteryFull:
+		payload = &LotteryFull{Round: int(r.int257())}
+
+	case OpWinnerDrawn:
+		e := &WinnerDrawn{}
+		e.Winner = r.address()
+		e.NFTId = r.int257()
+		e.Round = int(r.int257())
+		r.next()
+		e.ParticipantCount = int(r.int257())
+		payload = e
+
+	case OpNFTSent:
+		e := &NFTSent{}
+		e.Recipient = r.address()
+		e.NFTId = r.int257()
+		e.NFTContract = r.address()
+		r.next()
+		e.Timestamp = r.int257()
+		payload = e
+
+	default:
+		return nil, fmt.Errorf("%w: opcode 0x%08x", ErrUnknownEvent, op)
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("解析 %s 事件失敗: %w", payload.EventType(), r.err)
+	}
+	return payload, nil
+}
+
+// EncodeEvent 以合約的格式編碼事件，供測試與模擬節點使用
+func EncodeEvent(payload EventPayload) (*cell.Cell, error) {
+	b := cell.BeginCell()
+
+	switch e := payload.(type) {
+	case *ParticipantJoined:
+		addr, err := parseEventAddress(e.Participant)
+		if err != nil {
+			return nil, err
+		}
+		next, err := cell.BeginCell().StoreBigInt(big.NewInt(int64(e.Round)), 257).EndCell()
+		if err != nil {
+			return nil, err
+		}
+		b.StoreUInt(uint64(OpParticipantJoined), 32).
+			StoreAddress(addr).
+			StoreBigInt(big.NewInt(e.Amount), 257).
+			StoreBigInt(big.NewInt(int64(e.ParticipantIndex)), 257).
+			StoreRef(next)
+
+	case *LotteryFull:
+		b.StoreUInt(uint64(OpLotteryFull), 32).
+			StoreBigInt(big.NewInt(int64(e.Round)), 257)
+
+	case *WinnerDrawn:
+		addr, err := parseEventAddress(e.Winner)
+		if err != nil {
+			return nil, err
+		}
+		next, err := cell.BeginCell().StoreBigInt(big.NewInt(int64(e.ParticipantCount)), 257).EndCell()
+		if err != nil {
+			return nil, err
+		}
+		b.StoreUInt(uint64(OpWinnerDrawn), 32).
+			StoreAddress(addr).
+			StoreBigInt(big.NewInt(e.NFTId), 257).
+			StoreBigInt(big.NewInt(int64(e.Round)), 257).
+			StoreRef(next)
+
+	case *NFTSent:
+		recipient, err := parseEventAddress(e.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		nftContract, err := parseEventAddress(e.NFTContract)
+		if err != nil {
+			return nil, err
+		}
+		next, err := cell.BeginCell().StoreBigInt(big.NewInt(e.Timestamp), 257).EndCell()
+		if err != nil {
+			return nil, err
+		}
+		b.StoreUInt(uint64(OpNFTSent), 32).
+			StoreAddress(recipient).
+			StoreBigInt(big.NewInt(e.NFTId), 257).
+			StoreAddress(nftContract).
+			StoreRef(next)
+
+	default:
+		return nil, fmt.Errorf("不支援的事件類型 %T", payload)
+	}
+
+	return b.EndCell()
+}
+
+// parseEventAddress 解析事件中的地址欄位，Address 在合約中不可為 null
+func parseEventAddress(s string) (*address.Address, error) {
+	addr, err := address.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("無效的事件地址 %q: %w", s, err)
+	}
+	return addr, nil
+}
+
+// eventReader 依序讀取事件欄位，與 tvm.Reader 相同保留第一個錯誤
+type eventReader struct {
+	s   *cell.Slice
+	err error
+}
+
+func (r *eventReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := r.s.LoadUInt(32)
+	r.err = err
+	return uint32(v)
+}
+
+func (r *eventReader) address() string {
+	if r.err != nil {
+		return ""
+	}
+	addr, err := r.s.LoadAddress()
+	if err != nil {
+		r.err = err
+		return ""
+	}
+	if addr == nil {
+		r.err = fmt.Errorf("地址欄位為 addr_none")
+		return ""
+	}
+	return addr.String()
+}
+
+func (r *eventReader) int257() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := r.s.LoadBigInt(257)
+	if err != nil {
+		r.err = err
+		return 0
+	}
+	if !v.IsInt64() {
+		r.err = fmt.Errorf("整數 %s 超出 int64 範圍", v)
+		return 0
+	}
+	return v.Int64()
+}
+
+// next 切換到引用的下一個 cell
+func (r *eventReader) next() {
+	if r.err != nil {
+		return
+	}
+	ref, err := r.s.LoadRef()
+	if err != nil {
+		r.err = fmt.Errorf("讀取下一個 cell 失敗: %w", err)
+		return
+	}
+	r.s = ref.BeginParse()
+}
@@ -0,0 +1,74 @@
+package ton
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	events := []EventPayload{
+		&ParticipantJoined{Participant: testWalletAddress, Amount: 100000000, ParticipantIndex: 2, Round: 7},
+		&LotteryFull{Round: 7},
+		&WinnerDrawn{Winner: testWalletAddress, NFTId: 7042, Round: 7, ParticipantCount: 3},
+		&NFTSent{Recipient: testWalletAddress, NFTId: 7042, NFTContract: testNFTAddress, Timestamp: 1700000000},
+	}
+
+	for _, event := range events {
+		t.Run(string(event.EventType()), func(t *testing.T) {
+			body, err := EncodeEvent(event)
+			if err != nil {
+				t.Fatalf("EncodeEvent() failed: %v", err)
+			}
+
+			// 經過 BOC 序列化，與從 toncenter 讀取的格式相同
+			body, err = cell.FromBOCBase64(body.ToBOCBase64())
+			if err != nil {
+				t.Fatalf("FromBOCBase64() failed: %v", err)
+			}
+
+			decoded, err := DecodeEvent(body)
+			if err != nil {
+				t.Fatalf("DecodeEvent() failed: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, event) {
+				t.Errorf("Expected %+v, got %+v", event, decoded)
+			}
+		})
+	}
+}
+
+func TestEventLayout(t *testing.T) {
+	// 32 位元 opcode + 267 位元地址 + 2 個 int257 後，第 4 個欄位放入下一個 cell
+	body, err := EncodeEvent(&ParticipantJoined{Participant: testWalletAddress, Amount: 1, ParticipantIndex: 0, Round: 1})
+	if err != nil {
+		t.Fatalf("EncodeEvent() failed: %v", err)
+	}
+	if body.BitsSize() != 32+267+257*2 || body.RefsNum() != 1 {
+		t.Errorf("Expected 813 bits and 1 ref, got %d bits and %d refs", body.BitsSize(), body.RefsNum())
+	}
+}
+
+func TestDecodeEventErrors(t *testing.T) {
+	unknown, _ := cell.BeginCell().StoreUInt(0x12345678, 32).EndCell()
+	if _, err := DecodeEvent(unknown); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Expected ErrUnknownEvent, got %v", err)
+	}
+
+	empty, _ := cell.BeginCell().EndCell()
+	if _, err := DecodeEvent(empty); err == nil || errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Expected empty body to fail, got %v", err)
+	}
+
+	// 缺少存放剩餘欄位的引用
+	truncated, _ := cell.BeginCell().StoreUInt(uint64(OpWinnerDrawn), 32).EndCell()
+	if _, err := DecodeEvent(truncated); err == nil || errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Expected truncated event to fail, got %v", err)
+	}
+
+	if _, err := EncodeEvent(&WinnerDrawn{Winner: "not-an-address"}); err == nil {
+		t.Error("Expected invalid address to fail")
+	}
+}
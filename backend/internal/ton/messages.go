@@ -0,0 +1,20 @@
+package ton
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// OpComment 文字評論訊息的 opcode，CatLottery 的管理操作與參加皆以文字評論發送
+const OpComment uint32 = 0
+
+// OpSetNFTContract CatLottery 的 SetNFTContract 訊息 opcode
+var OpSetNFTContract = TactOpcode("SetNFTContract{nftContract:address}")
+
+// TactOpcode 計算 Tact 訊息的 opcode
+//
+// Tact 以訊息 TL-B 簽名的 SHA-256 前 4 個位元組作為 opcode，Int 欄位預設為 int257。
+func TactOpcode(signature string) uint32 {
+	sum := sha256.Sum256([]byte(signature))
+	return binary.BigEndian.Uint32(sum[:4])
+}
@@ -0,0 +1,10 @@
+package ton
+
+import "testing"
+
+func TestTactOpcode(t *testing.T) {
+	// Tact 標準庫 Deploy 訊息的 opcode
+	if op := TactOpcode("Deploy{queryId:uint64}"); op != 0x946a98b6 {
+		t.Errorf("Expected 0x946a98b6, got 0x%08x", op)
+	}
+}
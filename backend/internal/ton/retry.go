@@ -0,0 +1,156 @@
+package ton
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenBucket 客戶端的請求頻率限制，所有請求（包含重試）共用
+//
+// 每秒補充 rate 個配額，最多累積 burst 個；配額不足時 Wait 會預約下一個配額並等待。
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time // 上次補充配額的時間；暫停時為暫停結束的時間
+}
+
+// newTokenBucket 創建每秒 rate 個請求的限制，rate 不大於 0 時返回 nil（不限制）
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// sharedBuckets 依 API 端點共用的頻率限制
+//
+// toncenter 依來源 IP 計算請求頻率，同一程序中連到相同端點的客戶端（抽獎服務、事件索引器）必須共用配額。
+var sharedBuckets = struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}{buckets: make(map[string]*tokenBucket)}
+
+// sharedTokenBucket 返回 endpoint 共用的頻率限制，第一個客戶端的設定決定速率；rate 不大於 0 時返回 nil
+func sharedTokenBucket(endpoint string, rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+
+	sharedBuckets.mu.Lock()
+	defer sharedBuckets.mu.Unlock()
+
+	if b, ok := sharedBuckets.buckets[endpoint]; ok {
+		return b
+	}
+	b := newTokenBucket(rate, burst)
+	sharedBuckets.buckets[endpoint] = b
+	return b
+}
+
+// refill 補充到 now 為止的配額，呼叫端需持有鎖
+func (b *tokenBucket) refill(now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// Wait 取得一個配額，必要時等待；ctx 取消時歸還預約的配額並返回 ctx 的錯誤
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+	b.tokens--
+	var wait time.Duration
+	if b.last.After(now) {
+		wait = b.last.Sub(now)
+	}
+	if b.tokens < 0 {
+		wait += time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// pause 在 until 之前不發出配額，用於遵守 429 回應的 Retry-After
+func (b *tokenBucket) pause(until time.Time) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if until.After(b.last) {
+		b.last = until
+	}
+	b.tokens = min(b.tokens, 0)
+}
+
+// backoff 第 attempt 次重試（從 0 開始）前的等待時間：指數成長並加上隨機抖動
+//
+// 延遲為 base×2^attempt（不超過 maxDelay）的一半加上 0 到另一半之間的隨機值，避免多個請求同時重試。
+func backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	delay := maxDelay
+	if attempt < 62 && base<<attempt > 0 && base<<attempt < maxDelay {
+		delay = base << attempt
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// parseRetryAfter 解析 Retry-After 標頭（秒數或 HTTP 日期），無法解析或已過期時返回 0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
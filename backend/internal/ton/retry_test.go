@@ -0,0 +1,130 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+func TestTokenBucket(t *testing.T) {
+	if newTokenBucket(0, 1) != nil {
+		t.Error("Expected rate 0 to disable limiting")
+	}
+
+	b := newTokenBucket(50, 2) // 每 20ms 一個配額
+	ctx := context.Background()
+
+	// 累積的配額可立即使用
+	start := time.Now()
+	b.Wait(ctx)
+	b.Wait(ctx)
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("Expected burst to pass immediately, took %v", elapsed)
+	}
+
+	// 之後每個請求需等待補充
+	start = time.Now()
+	b.Wait(ctx)
+	b.Wait(ctx)
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected 2 requests to wait about 40ms, took %v", elapsed)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	b := newTokenBucket(1, 1)
+	b.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	// 取消的等待歸還預約的配額
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("Expected reservation to be returned, tokens = %v", tokens)
+	}
+}
+
+func TestTokenBucketPause(t *testing.T) {
+	b := newTokenBucket(1000, 5)
+	b.pause(time.Now().Add(50 * time.Millisecond))
+
+	start := time.Now()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected Wait() to respect the pause, took %v", elapsed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, maxDelay := 100*time.Millisecond, time.Second
+
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if got := backoff(attempt, base, maxDelay); got < want/2 || got > want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+
+	if got := backoff(100, base, maxDelay); got > maxDelay {
+		t.Errorf("backoff() overflowed: %v", got)
+	}
+	if got := backoff(3, 0, maxDelay); got != 0 {
+		t.Errorf("backoff() without base delay = %v, want 0", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 8, 1, 6, 40, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{" 10 ", 10 * time.Second},
+		{"0", 0},
+		{"-1", 0},
+		{now.Add(5 * time.Second).Format(http.TimeFormat), 5 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSharedTokenBucket(t *testing.T) {
+	if sharedTokenBucket("https://shared.example/", 0, 1) != nil {
+		t.Error("Expected rate 0 to disable limiting")
+	}
+
+	cfg := &config.Config{TONAPIEndpoint: "https://shared.example/", TONAPIRateLimit: 1}
+	first := NewClient(cfg, logger.New("error"))
+	second := NewClient(cfg, logger.New("error"))
+	if first.endpoints[0].limiter == nil || first.endpoints[0].limiter != second.endpoints[0].limiter {
+		t.Error("Expected clients of the same endpoint to share the limiter")
+	}
+
+	other := NewClient(&config.Config{TONAPIEndpoint: "https://other.example/", TONAPIRateLimit: 1}, logger.New("error"))
+	if other.endpoints[0].limiter == first.endpoints[0].limiter {
+		t.Error("Expected different endpoints to use separate limiters")
+	}
+}
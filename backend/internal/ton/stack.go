@@ -0,0 +1,196 @@
+package ton
+
+import (
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+// 以下函式在 Tact get 方法返回的堆疊與 Go 型別之間轉換。
+// Tact 的 struct 以 tuple 返回，欄位順序與合約中的宣告順序相同。
+// Encode* 函式供測試與模擬節點產生與合約相同格式的返回值。
+
+// decodeLotteryContractInfo 解析 CatLottery 的 ContractInfo
+//
+//	struct ContractInfo { owner: Address; entryFee: Int; maxParticipants: Int; currentRound: Int;
+//	                      lotteryActive: Bool; participantCount: Int; nftContract: Address? }
+func decodeLotteryContractInfo(stack tvm.Stack) (*LotteryContractInfo, error) {
+	fields := tvm.NewReader(stack).Tuple()
+
+	info := &LotteryContractInfo{}
+	info.Owner = addressString(fields.RequiredAddress())
+	info.EntryFee = fields.Int64()
+	info.MaxParticipants = fields.Int()
+	info.CurrentRound = fields.Int()
+	info.LotteryActive = fields.Bool()
+	info.ParticipantCount = fields.Int()
+	info.NFTContract = addressString(fields.Address())
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// decodeParticipant 解析 getParticipant 返回的 Participant?，null 返回 nil
+//
+//	struct Participant { address: Address; amount: Int; timestamp: Int }
+func decodeParticipant(stack tvm.Stack) (*Participant, error) {
+	r := tvm.NewReader(stack)
+	fields := r.OptionalTuple()
+	if fields == nil {
+		return nil, r.Err()
+	}
+
+	participant := &Participant{}
+	participant.Address = addressString(fields.RequiredAddress())
+	participant.Amount = fields.Int64()
+	participant.Timestamp = fields.Int64()
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+// decodeLotteryResult 解析 getWinner 返回的 LotteryResult?，null 返回 nil
+//
+//	struct LotteryResult { winner: Address; nftId: Int; timestamp: Int }
+func decodeLotteryResult(stack tvm.Stack) (*LotteryResult, error) {
+	r := tvm.NewReader(stack)
+	fields := r.OptionalTuple()
+	if fields == nil {
+		return nil, r.Err()
+	}
+
+	result := &LotteryResult{}
+	result.Winner = addressString(fields.RequiredAddress())
+	result.NFTId = fields.Int64()
+	result.Timestamp = fields.Int64()
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeNFTContractInfo 解析 CatNFT 的 ContractInfo
+//
+//	struct ContractInfo { owner: Address; nextNftId: Int; nftSupply: Int }
+func decodeNFTContractInfo(stack tvm.Stack) (*NFTContractInfo, error) {
+	fields := tvm.NewReader(stack).Tuple()
+
+	info := &NFTContractInfo{}
+	info.Owner = addressString(fields.RequiredAddress())
+	info.NextNFTId = fields.Int64()
+	info.NFTSupply = fields.Int64()
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// decodeCatInfo 解析 CatNFT 的 CatInfo
+//
+//	struct CatInfo { name: String; rarity: String; catType: String }
+func decodeCatInfo(stack tvm.Stack) (*CatInfo, error) {
+	fields := tvm.NewReader(stack).Tuple()
+
+	info := &CatInfo{}
+	info.Name = fields.Text()
+	info.Rarity = fields.Text()
+	info.CatType = fields.Text()
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// EncodeLotteryContractInfo 將抽獎合約狀態編碼為 getContractInfo 返回的堆疊
+func EncodeLotteryContractInfo(info *LotteryContractInfo) (tvm.Stack, error) {
+	owner, err := parseStackAddress(info.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 owner 地址: %w", err)
+	}
+	nftContract, err := parseStackAddress(info.NFTContract)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 NFT 合約地址: %w", err)
+	}
+
+	return tvm.Stack{tvm.Tuple(
+		owner,
+		tvm.Int(info.EntryFee),
+		tvm.Int(int64(info.MaxParticipants)),
+		tvm.Int(int64(info.CurrentRound)),
+		tvm.Bool(info.LotteryActive),
+		tvm.Int(int64(info.ParticipantCount)),
+		nftContract,
+	)}, nil
+}
+
+// EncodeParticipant 將參與者編碼為 getParticipant 返回的堆疊，nil 編碼為 null
+func EncodeParticipant(participant *Participant) (tvm.Stack, error) {
+	if participant == nil {
+		return tvm.Stack{tvm.Null()}, nil
+	}
+
+	addr, err := parseStackAddress(participant.Address)
+	if err != nil {
+		return nil, fmt.Errorf("無效的參與者地址: %w", err)
+	}
+
+	return tvm.Stack{tvm.Tuple(addr, tvm.Int(participant.Amount), tvm.Int(participant.Timestamp))}, nil
+}
+
+// EncodeLotteryResult 將中獎記錄編碼為 getWinner 返回的堆疊，nil 編碼為 null
+func EncodeLotteryResult(result *LotteryResult) (tvm.Stack, error) {
+	if result == nil {
+		return tvm.Stack{tvm.Null()}, nil
+	}
+
+	winner, err := parseStackAddress(result.Winner)
+	if err != nil {
+		return nil, fmt.Errorf("無效的中獎者地址: %w", err)
+	}
+
+	return tvm.Stack{tvm.Tuple(winner, tvm.Int(result.NFTId), tvm.Int(result.Timestamp))}, nil
+}
+
+// EncodeNFTContractInfo 將 NFT 合約狀態編碼為 CatNFT getContractInfo 返回的堆疊
+func EncodeNFTContractInfo(info *NFTContractInfo) (tvm.Stack, error) {
+	owner, err := parseStackAddress(info.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("無效的 owner 地址: %w", err)
+	}
+
+	return tvm.Stack{tvm.Tuple(owner, tvm.Int(info.NextNFTId), tvm.Int(info.NFTSupply))}, nil
+}
+
+// EncodeCatInfo 將貓咪資訊編碼為 getCatInfo 返回的堆疊
+func EncodeCatInfo(info *CatInfo) tvm.Stack {
+	return tvm.Stack{tvm.Tuple(tvm.Text(info.Name), tvm.Text(info.Rarity), tvm.Text(info.CatType))}
+}
+
+// parseStackAddress 將地址字串轉換為堆疊項目，空字串轉換為 null
+func parseStackAddress(s string) (tvm.Value, error) {
+	if s == "" {
+		return tvm.Null(), nil
+	}
+
+	addr, err := address.Parse(s)
+	if err != nil {
+		return tvm.Value{}, err
+	}
+	return tvm.Address(addr), nil
+}
+
+// addressString 以使用者友善格式輸出地址，nil（null 或 addr_none）返回空字串
+func addressString(addr *address.Address) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
@@ -0,0 +1,124 @@
+package ton
+
+import (
+	"reflect"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/tvm"
+)
+
+func TestLotteryStackRoundTrip(t *testing.T) {
+	info := &LotteryContractInfo{
+		Owner:            testOwnerAddress,
+		EntryFee:         100000000,
+		MaxParticipants:  3,
+		CurrentRound:     7,
+		LotteryActive:    true,
+		ParticipantCount: 2,
+		NFTContract:      testNFTAddress,
+	}
+
+	stack, err := EncodeLotteryContractInfo(info)
+	if err != nil {
+		t.Fatalf("EncodeLotteryContractInfo() failed: %v", err)
+	}
+	decoded, err := decodeLotteryContractInfo(stack)
+	if err != nil {
+		t.Fatalf("decodeLotteryContractInfo() failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, info) {
+		t.Errorf("Expected %+v, got %+v", info, decoded)
+	}
+
+	participant := &Participant{Address: testWalletAddress, Amount: 100000000, Timestamp: 1700000000}
+	stack, err = EncodeParticipant(participant)
+	if err != nil {
+		t.Fatalf("EncodeParticipant() failed: %v", err)
+	}
+	if decoded, err := decodeParticipant(stack); err != nil || !reflect.DeepEqual(decoded, participant) {
+		t.Errorf("Expected %+v, got %+v (%v)", participant, decoded, err)
+	}
+
+	result := &LotteryResult{Winner: testWalletAddress, NFTId: 12, Timestamp: 1700000000}
+	stack, err = EncodeLotteryResult(result)
+	if err != nil {
+		t.Fatalf("EncodeLotteryResult() failed: %v", err)
+	}
+	if decoded, err := decodeLotteryResult(stack); err != nil || !reflect.DeepEqual(decoded, result) {
+		t.Errorf("Expected %+v, got %+v (%v)", result, decoded, err)
+	}
+
+	nftInfo := &NFTContractInfo{Owner: testOwnerAddress, NextNFTId: 5, NFTSupply: 4}
+	stack, err = EncodeNFTContractInfo(nftInfo)
+	if err != nil {
+		t.Fatalf("EncodeNFTContractInfo() failed: %v", err)
+	}
+	if decoded, err := decodeNFTContractInfo(stack); err != nil || !reflect.DeepEqual(decoded, nftInfo) {
+		t.Errorf("Expected %+v, got %+v (%v)", nftInfo, decoded, err)
+	}
+
+	// 不存在的 NFT 返回空字串
+	for _, catInfo := range []*CatInfo{{Name: "Whiskers", Rarity: "Rare", CatType: "Siamese"}, {}} {
+		if decoded, err := decodeCatInfo(EncodeCatInfo(catInfo)); err != nil || !reflect.DeepEqual(decoded, catInfo) {
+			t.Errorf("Expected %+v, got %+v (%v)", catInfo, decoded, err)
+		}
+	}
+}
+
+func TestDecodeOptionalResults(t *testing.T) {
+	for name, stack := range map[string]tvm.Stack{
+		"null":        {tvm.Null()},
+		"encoded nil": mustStack(EncodeLotteryResult(nil)),
+		"zero value":  {tvm.Value{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			participant, err := decodeParticipant(stack)
+			if err != nil || participant != nil {
+				t.Errorf("Expected nil participant, got %+v (%v)", participant, err)
+			}
+
+			result, err := decodeLotteryResult(stack)
+			if err != nil || result != nil {
+				t.Errorf("Expected nil result, got %+v (%v)", result, err)
+			}
+		})
+	}
+
+	// 空堆疊代表合約沒有返回值，應視為錯誤而非 null
+	if _, err := decodeLotteryResult(tvm.Stack{}); err == nil {
+		t.Error("Expected empty stack to fail")
+	}
+}
+
+func TestDecodeRequiredAddress(t *testing.T) {
+	// owner、參與者與中獎者在合約中為 Address，null 代表堆疊格式錯誤
+	info := tvm.Stack{tvm.Tuple(tvm.Null(), tvm.Int(1), tvm.Int(10), tvm.Int(1), tvm.Bool(true), tvm.Int(0), tvm.Null())}
+	if _, err := decodeLotteryContractInfo(info); err == nil {
+		t.Error("Expected null owner to fail")
+	}
+	if _, err := decodeNFTContractInfo(tvm.Stack{tvm.Tuple(tvm.Null(), tvm.Int(1), tvm.Int(0))}); err == nil {
+		t.Error("Expected null NFT owner to fail")
+	}
+	if _, err := decodeParticipant(tvm.Stack{tvm.Tuple(tvm.Null(), tvm.Int(1), tvm.Int(1))}); err == nil {
+		t.Error("Expected null participant address to fail")
+	}
+	if _, err := decodeLotteryResult(tvm.Stack{tvm.Tuple(tvm.Null(), tvm.Int(1), tvm.Int(1))}); err == nil {
+		t.Error("Expected null winner to fail")
+	}
+}
+
+func TestEncodeInvalidAddress(t *testing.T) {
+	if _, err := EncodeLotteryContractInfo(&LotteryContractInfo{Owner: "EQOwner123"}); err == nil {
+		t.Error("Expected invalid owner address to fail")
+	}
+	if _, err := EncodeParticipant(&Participant{Address: "not-an-address"}); err == nil {
+		t.Error("Expected invalid participant address to fail")
+	}
+}
+
+func mustStack(stack tvm.Stack, err error) tvm.Stack {
+	if err != nil {
+		panic(err)
+	}
+	return stack
+}
@@ -0,0 +1,112 @@
+package ton
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// TransactionID 交易的邏輯時間與雜湊 (base64)，用於分頁與記錄索引位置
+type TransactionID struct {
+	LT   uint64 `json:"lt,string"`
+	Hash string `json:"hash"`
+}
+
+// IsZero 是否為空的交易 ID
+func (id TransactionID) IsZero() bool {
+	return id.LT == 0 && id.Hash == ""
+}
+
+// Transaction toncenter getTransactions 返回的交易
+type Transaction struct {
+	ID      TransactionID `json:"transaction_id"`
+	Utime   int64         `json:"utime"`
+	Fee     string        `json:"fee"`
+	InMsg   *Message      `json:"in_msg,omitempty"`
+	OutMsgs []Message     `json:"out_msgs"`
+}
+
+// Message 交易的入站或出站訊息
+//
+// 合約以 emit 發送的事件是外部出站訊息，沒有目的地址。
+type Message struct {
+	Source      string      `json:"source"`
+	Destination string      `json:"destination"`
+	Value       string      `json:"value"`
+	CreatedLT   string      `json:"created_lt"`
+	MsgData     MessageData `json:"msg_data"`
+}
+
+// MessageData 訊息內容，Body 為 base64 編碼的 BOC
+type MessageData struct {
+	Type string `json:"@type"`
+	Body string `json:"body,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// IsExternalOut 是否為外部出站訊息（事件）
+func (m *Message) IsExternalOut() bool {
+	return m.Destination == ""
+}
+
+// BodyCell 解析訊息內容的 BOC，沒有內容時返回 nil
+func (m *Message) BodyCell() (*cell.Cell, error) {
+	if m.MsgData.Body == "" {
+		return nil, nil
+	}
+
+	body, err := cell.FromBOCBase64(m.MsgData.Body)
+	if err != nil {
+		return nil, fmt.Errorf("解析訊息內容失敗: %w", err)
+	}
+	return body, nil
+}
+
+// GetTransactions 查詢帳戶的交易，由新到舊排列
+//
+// from 為空時從最新的交易開始，否則從 from 這筆交易（包含）往前查詢；
+// toLT 大於 0 時只返回邏輯時間大於 toLT 的交易。
+func (c *Client) GetTransactions(ctx context.Context, addr string, limit int, from TransactionID, toLT uint64) ([]Transaction, error) {
+	c.logger.Debug("查詢帳戶交易", "address", addr, "limit", limit, "lt", from.LT, "to_lt", toLT)
+
+	// 構建請求參數
+	params := map[string]interface{}{
+		"address":  addr,
+		"limit":    limit,
+		"archival": true,
+	}
+	if !from.IsZero() {
+		params["lt"] = from.LT
+		params["hash"] = from.Hash
+	}
+	if toLT > 0 {
+		params["to_lt"] = toLT
+	}
+
+	// 發送請求
+	resp, err := c.makeRequest(ctx, "GET", "getTransactions", params)
+	if err != nil {
+		return nil, fmt.Errorf("查詢帳戶交易失敗: %w", err)
+	}
+
+	var transactions []Transaction
+	if err := json.Unmarshal(resp.Result, &transactions); err != nil {
+		return nil, fmt.Errorf("解析帳戶交易失敗: %w", err)
+	}
+
+	// toncenter 的 to_lt 行為依節點版本不同，這裡再過濾一次
+	if toLT > 0 {
+		filtered := transactions[:0]
+		for _, tx := range transactions {
+			if tx.ID.LT > toLT {
+				filtered = append(filtered, tx)
+			}
+		}
+		transactions = filtered
+	}
+
+	c.logger.Debug("帳戶交易查詢成功", "address", addr, "count", len(transactions))
+	return transactions, nil
+}
@@ -0,0 +1,80 @@
+package ton
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTransactions(t *testing.T) {
+	body, err := EncodeEvent(&LotteryFull{Round: 3})
+	if err != nil {
+		t.Fatalf("EncodeEvent() failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("address") != testOwnerAddress || query.Get("limit") != "10" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		if query.Get("lt") != "200" || query.Get("hash") != "aGFzaDI=" || query.Get("to_lt") != "100" {
+			t.Errorf("Expected paging parameters, got %s", r.URL.RawQuery)
+		}
+
+		// toncenter 的 to_lt 可能包含邊界上的交易，客戶端應過濾
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(APIResponse{Ok: true, Result: json.RawMessage(`[
+			{
+				"@type": "raw.transaction",
+				"utime": 1700000200,
+				"transaction_id": {"@type": "internal.transactionId", "lt": "200", "hash": "aGFzaDI="},
+				"fee": "1000",
+				"in_msg": {"source": "` + testWalletAddress + `", "destination": "` + testOwnerAddress + `", "value": "100000000", "msg_data": {"@type": "msg.dataText", "text": ""}},
+				"out_msgs": [
+					{"source": "` + testOwnerAddress + `", "destination": "", "value": "0", "created_lt": "201", "msg_data": {"@type": "msg.dataRaw", "body": "` + body.ToBOCBase64() + `"}}
+				]
+			},
+			{
+				"@type": "raw.transaction",
+				"utime": 1700000100,
+				"transaction_id": {"@type": "internal.transactionId", "lt": "100", "hash": "aGFzaDE="},
+				"out_msgs": []
+			}
+		]`)})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	txs, err := client.GetTransactions(context.Background(), testOwnerAddress, 10, TransactionID{LT: 200, Hash: "aGFzaDI="}, 100)
+	if err != nil {
+		t.Fatalf("GetTransactions() failed: %v", err)
+	}
+
+	if len(txs) != 1 {
+		t.Fatalf("Expected 1 transaction after to_lt, got %d", len(txs))
+	}
+	tx := txs[0]
+	if tx.ID.LT != 200 || tx.ID.Hash != "aGFzaDI=" || tx.Utime != 1700000200 {
+		t.Errorf("Unexpected transaction: %+v", tx)
+	}
+	if tx.InMsg == nil || tx.InMsg.IsExternalOut() || tx.InMsg.Value != "100000000" {
+		t.Errorf("Unexpected in_msg: %+v", tx.InMsg)
+	}
+
+	if len(tx.OutMsgs) != 1 || !tx.OutMsgs[0].IsExternalOut() {
+		t.Fatalf("Expected one external out message, got %+v", tx.OutMsgs)
+	}
+	msgBody, err := tx.OutMsgs[0].BodyCell()
+	if err != nil {
+		t.Fatalf("BodyCell() failed: %v", err)
+	}
+	event, err := DecodeEvent(msgBody)
+	if err != nil {
+		t.Fatalf("DecodeEvent() failed: %v", err)
+	}
+	if full, ok := event.(*LotteryFull); !ok || full.Round != 3 {
+		t.Errorf("Expected LotteryFull round 3, got %+v", event)
+	}
+}
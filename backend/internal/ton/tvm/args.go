@@ -0,0 +1,90 @@
+package tvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Args get 方法的參數，依序壓入堆疊（最後一個參數位於堆疊頂端）
+//
+// 以 Int、BigInt、Address、Cell、Slice 建立元素，例如：
+//
+//	tvm.Args{tvm.Int(nftId)}
+//	tvm.Args{tvm.Address(owner)}
+//
+// toncenter 的 runGetMethod 只接受整數、cell 與 slice 參數，
+// null 與 tuple 會在編碼時返回錯誤。
+type Args []Value
+
+// MarshalJSON 以 toncenter runGetMethod 請求的 stack 格式輸出參數
+//
+//	["num", "0x5"]、["tvm.Cell", "<boc>"]、["tvm.Slice", "<boc>"]
+func (a Args) MarshalJSON() ([]byte, error) {
+	entries := make([][2]string, len(a))
+	for i, v := range a {
+		switch v.Type() {
+		case TypeNum:
+			entries[i] = [2]string{"num", formatHex(v.num)}
+		case TypeCell:
+			entries[i] = [2]string{"tvm.Cell", v.cell.ToBOCBase64()}
+		case TypeSlice:
+			entries[i] = [2]string{"tvm.Slice", v.cell.ToBOCBase64()}
+		default:
+			return nil, fmt.Errorf("第 %d 個參數: 不支援 %s 類型的 get 方法參數", i, v.Type())
+		}
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON 解析 runGetMethod 請求的 stack 參數，供模擬節點使用
+//
+// 除 MarshalJSON 的格式外，也接受 toncenter 允許的十進位整數 ["num", 5]。
+func (a *Args) UnmarshalJSON(data []byte) error {
+	var entries [][]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("無效的 get 方法參數: %w", err)
+	}
+
+	args := make(Args, len(entries))
+	for i, entry := range entries {
+		if len(entry) != 2 {
+			return fmt.Errorf("第 %d 個參數應包含 2 個欄位，實際為 %d", i, len(entry))
+		}
+
+		var typ string
+		if err := json.Unmarshal(entry[0], &typ); err != nil {
+			return fmt.Errorf("第 %d 個參數的類型無效: %w", i, err)
+		}
+
+		// 數值可能是字串或 JSON 數字
+		value := strings.Trim(string(entry[1]), `"`)
+
+		var err error
+		switch typ {
+		case "num", "int":
+			err = args[i].setNumber(value)
+		case "tvm.Cell", "cell":
+			err = args[i].setBytes(TypeCell, value)
+		case "tvm.Slice", "slice":
+			err = args[i].setBytes(TypeSlice, value)
+		default:
+			err = fmt.Errorf("不支援的參數類型: %s", typ)
+		}
+		if err != nil {
+			return fmt.Errorf("第 %d 個參數: %w", i, err)
+		}
+	}
+
+	*a = args
+	return nil
+}
+
+// String 以簡短格式輸出參數，供日誌使用
+func (a Args) String() string {
+	parts := make([]string, len(a))
+	for i, v := range a {
+		parts[i] = v.String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
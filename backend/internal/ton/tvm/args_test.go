@@ -0,0 +1,67 @@
+package tvm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+func TestArgsMarshal(t *testing.T) {
+	data, err := cell.BeginCell().StoreUInt(0xCA7, 12).EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	args := Args{Int(5), Int(-255), Cell(data), Address(address.MustParse(testAddress))}
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	expected := `[["num","0x5"],["num","-0xff"],["tvm.Cell","` + data.ToBOCBase64() + `"],["tvm.Slice","` + testAddressSlice + `"]]`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+
+	// 沒有參數時必須是空陣列而不是 null
+	if encoded, err := json.Marshal(map[string]Args{"stack": nil}); err != nil || string(encoded) != `{"stack":[]}` {
+		t.Errorf("Expected empty stack, got %s (%v)", encoded, err)
+	}
+
+	var decoded Args
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if decoded.String() != args.String() {
+		t.Errorf("Expected %s, got %s", args, decoded)
+	}
+}
+
+func TestArgsUnsupported(t *testing.T) {
+	for _, args := range []Args{{Null()}, {Int(1), Tuple(Int(2))}} {
+		if _, err := json.Marshal(args); err == nil || !strings.Contains(err.Error(), "不支援") {
+			t.Errorf("Expected %s to fail, got %v", args, err)
+		}
+	}
+}
+
+func TestArgsUnmarshalDecimal(t *testing.T) {
+	var args Args
+	if err := json.Unmarshal([]byte(`[["num", 42], ["num", "-7"]]`), &args); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	r := NewReader(args)
+	if a, b := r.Int64(), r.Int64(); r.Err() != nil || a != 42 || b != -7 {
+		t.Errorf("Expected 42 and -7, got %d and %d (%v)", a, b, r.Err())
+	}
+
+	for _, data := range []string{`[["num"]]`, `[["tuple", "1"]]`, `[["tvm.Cell", "not-a-boc"]]`} {
+		if err := json.Unmarshal([]byte(data), &args); err == nil {
+			t.Errorf("Expected %s to fail", data)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package tvm
+
+import (
+	"fmt"
+	"math/big"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// Reader 依序讀取堆疊或 tuple 的項目
+//
+// 與 cell.Builder 相同，第一個錯誤會被保留，之後的讀取返回零值，
+// 讀取完所有欄位後再以 Err 檢查。
+type Reader struct {
+	values []Value
+	pos    int
+	err    error
+}
+
+// NewReader 創建讀取 values 的 Reader
+func NewReader(values []Value) *Reader {
+	return &Reader{values: values}
+}
+
+// Err 返回第一個讀取錯誤
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Remaining 尚未讀取的項目數量
+func (r *Reader) Remaining() int {
+	return len(r.values) - r.pos
+}
+
+// fail 記錄第 index 項的錯誤
+func (r *Reader) fail(index int, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("第 %d 項: %w", index, err)
+	}
+}
+
+// Value 讀取下一個項目
+func (r *Reader) Value() Value {
+	if r.err != nil {
+		return Null()
+	}
+	if r.pos >= len(r.values) {
+		r.fail(r.pos, fmt.Errorf("堆疊只有 %d 項", len(r.values)))
+		return Null()
+	}
+
+	v := r.values[r.pos]
+	r.pos++
+	return v
+}
+
+// BigInt 讀取整數
+func (r *Reader) BigInt() *big.Int {
+	v := r.Value()
+	if r.err != nil {
+		return nil
+	}
+
+	n, err := v.BigInt()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return n
+}
+
+// Int64 讀取 int64 範圍內的整數
+func (r *Reader) Int64() int64 {
+	v := r.Value()
+	if r.err != nil {
+		return 0
+	}
+
+	n, err := v.Int64()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return n
+}
+
+// Int 讀取 int 範圍內的整數
+func (r *Reader) Int() int {
+	n := r.Int64()
+	if r.err == nil && int64(int(n)) != n {
+		r.fail(r.pos-1, fmt.Errorf("整數 %d 超出 int 範圍", n))
+		return 0
+	}
+	return int(n)
+}
+
+// Bool 讀取布林值
+func (r *Reader) Bool() bool {
+	v := r.Value()
+	if r.err != nil {
+		return false
+	}
+
+	b, err := v.Bool()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return b
+}
+
+// Cell 讀取 cell 或 slice 的內容
+func (r *Reader) Cell() *cell.Cell {
+	v := r.Value()
+	if r.err != nil {
+		return nil
+	}
+
+	c, err := v.Cell()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return c
+}
+
+// Address 讀取 Address 或 Address?，null 返回 nil
+func (r *Reader) Address() *address.Address {
+	v := r.Value()
+	if r.err != nil {
+		return nil
+	}
+
+	addr, err := v.Address()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return addr
+}
+
+// RequiredAddress 讀取不可為 null 的 Address，null 與 addr_none 視為錯誤
+func (r *Reader) RequiredAddress() *address.Address {
+	addr := r.Address()
+	if r.err == nil && addr == nil {
+		r.fail(r.pos-1, fmt.Errorf("預期 Address，實際為 null"))
+	}
+	return addr
+}
+
+// Text 讀取 String
+func (r *Reader) Text() string {
+	v := r.Value()
+	if r.err != nil {
+		return ""
+	}
+
+	s, err := v.Text()
+	if err != nil {
+		r.fail(r.pos-1, err)
+	}
+	return s
+}
+
+// Tuple 讀取 tuple 並返回讀取其元素的 Reader，錯誤會回報到目前的 Reader
+func (r *Reader) Tuple() *Reader {
+	v := r.Value()
+	if r.err != nil {
+		return &Reader{err: r.err}
+	}
+
+	elems, err := v.Tuple()
+	if err != nil {
+		r.fail(r.pos-1, err)
+		return &Reader{err: r.err}
+	}
+	return &Reader{values: elems}
+}
+
+// OptionalTuple 讀取可為 null 的 tuple（對應 Tact 的 struct?），null 返回 nil
+func (r *Reader) OptionalTuple() *Reader {
+	if r.err == nil && r.pos < len(r.values) && r.values[r.pos].IsNull() {
+		r.pos++
+		return nil
+	}
+	return r.Tuple()
+}
@@ -0,0 +1,449 @@
+package tvm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// Type TVM 堆疊項目的類型
+type Type string
+
+const (
+	TypeNull  Type = "null"
+	TypeNum   Type = "num"
+	TypeCell  Type = "cell"
+	TypeSlice Type = "slice"
+	TypeTuple Type = "tuple"
+)
+
+// Value TVM 堆疊上的單一項目
+//
+// 零值為 null。Tact 的 struct 以 tuple 表示，Address 為 slice，Bool 為 -1/0，
+// 可選值 (T?) 為 null 或對應的值。
+type Value struct {
+	typ   Type
+	num   *big.Int
+	cell  *cell.Cell
+	tuple []Value
+}
+
+// Stack get 方法返回的堆疊，第一個元素為最先返回的值
+type Stack []Value
+
+// Null 創建 null 項目
+func Null() Value {
+	return Value{typ: TypeNull}
+}
+
+// Int 創建整數項目
+func Int(v int64) Value {
+	return BigInt(big.NewInt(v))
+}
+
+// BigInt 創建大整數項目，nil 視為 null
+func BigInt(v *big.Int) Value {
+	if v == nil {
+		return Null()
+	}
+	return Value{typ: TypeNum, num: new(big.Int).Set(v)}
+}
+
+// Bool 創建布林項目（TVM 以 -1 表示 true）
+func Bool(v bool) Value {
+	if v {
+		return Int(-1)
+	}
+	return Int(0)
+}
+
+// Cell 創建 cell 項目，nil 視為 null
+func Cell(c *cell.Cell) Value {
+	if c == nil {
+		return Null()
+	}
+	return Value{typ: TypeCell, cell: c}
+}
+
+// Slice 創建 slice 項目，內容為 c 的所有位元與引用，nil 視為 null
+func Slice(c *cell.Cell) Value {
+	if c == nil {
+		return Null()
+	}
+	return Value{typ: TypeSlice, cell: c}
+}
+
+// Address 創建包含 MsgAddress 的 slice 項目，nil 視為 null（對應 Tact 的 Address?）
+func Address(addr *address.Address) Value {
+	if addr == nil {
+		return Null()
+	}
+
+	c, err := cell.BeginCell().StoreAddress(addr).EndCell()
+	if err != nil {
+		// addr_std 固定 267 位元，不會超出 Cell 容量
+		panic(fmt.Sprintf("tvm: 編碼地址失敗: %v", err))
+	}
+	return Slice(c)
+}
+
+// Text 創建以 snake 格式存放字串的 cell 項目（對應 Tact 的 String）
+func Text(s string) Value {
+	c, err := cell.BeginCell().StoreStringSnake(s).EndCell()
+	if err != nil {
+		// 超出單一 Cell 的部分會放入下一層引用，不會超出容量
+		panic(fmt.Sprintf("tvm: 編碼字串失敗: %v", err))
+	}
+	return Cell(c)
+}
+
+// Tuple 創建 tuple 項目
+func Tuple(values ...Value) Value {
+	return Value{typ: TypeTuple, tuple: append([]Value{}, values...)}
+}
+
+// Type 返回項目的類型
+func (v Value) Type() Type {
+	if v.typ == "" {
+		return TypeNull
+	}
+	return v.typ
+}
+
+// IsNull 項目是否為 null
+func (v Value) IsNull() bool {
+	return v.Type() == TypeNull
+}
+
+// BigInt 讀取整數
+func (v Value) BigInt() (*big.Int, error) {
+	if v.typ != TypeNum {
+		return nil, fmt.Errorf("預期 num，實際為 %s", v.Type())
+	}
+	return new(big.Int).Set(v.num), nil
+}
+
+// Int64 讀取 int64 範圍內的整數
+func (v Value) Int64() (int64, error) {
+	n, err := v.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("整數 %s 超出 int64 範圍", n)
+	}
+	return n.Int64(), nil
+}
+
+// Bool 讀取布林值，僅接受 0 與 -1
+func (v Value) Bool() (bool, error) {
+	n, err := v.Int64()
+	if err != nil {
+		return false, err
+	}
+
+	switch n {
+	case -1:
+		return true, nil
+	case 0:
+		return false, nil
+	default:
+		return false, fmt.Errorf("無效的布林值: %d", n)
+	}
+}
+
+// Cell 讀取 cell 或 slice 的內容
+func (v Value) Cell() (*cell.Cell, error) {
+	if v.typ != TypeCell && v.typ != TypeSlice {
+		return nil, fmt.Errorf("預期 cell 或 slice，實際為 %s", v.Type())
+	}
+	return v.cell, nil
+}
+
+// Address 讀取 slice 中的 MsgAddress，null 與 addr_none 返回 nil
+func (v Value) Address() (*address.Address, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+
+	c, err := v.Cell()
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := c.BeginParse().LoadAddress()
+	if err != nil {
+		return nil, fmt.Errorf("解析地址失敗: %w", err)
+	}
+	return addr, nil
+}
+
+// Text 讀取 cell 中的 snake 格式字串（對應 Tact 的 String）
+func (v Value) Text() (string, error) {
+	c, err := v.Cell()
+	if err != nil {
+		return "", err
+	}
+
+	s, err := c.BeginParse().LoadStringSnake()
+	if err != nil {
+		return "", fmt.Errorf("解析字串失敗: %w", err)
+	}
+	return s, nil
+}
+
+// Tuple 讀取 tuple 的元素
+func (v Value) Tuple() ([]Value, error) {
+	if v.typ != TypeTuple {
+		return nil, fmt.Errorf("預期 tuple，實際為 %s", v.Type())
+	}
+	return v.tuple, nil
+}
+
+// String 以簡短格式輸出項目，供日誌與錯誤訊息使用
+func (v Value) String() string {
+	switch v.Type() {
+	case TypeNum:
+		return v.num.String()
+	case TypeCell, TypeSlice:
+		return fmt.Sprintf("%s(%x)", v.typ, v.cell.Hash())
+	case TypeTuple:
+		parts := make([]string, len(v.tuple))
+		for i, elem := range v.tuple {
+			parts[i] = elem.String()
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	default:
+		return "null"
+	}
+}
+
+// === JSON 編碼 ===
+//
+// toncenter 的 runGetMethod 在最外層以 [type, value] 表示項目：
+//
+//	["num", "0x1f"]、["cell", {"bytes": "<boc>"}]、["tuple", {"elements": [...]}]、["null"]
+//
+// tuple 與 list 內的元素則沿用 tonlib 的格式：
+//
+//	{"@type": "tvm.stackEntryNumber", "number": {"number": "31"}}
+
+// stackBytes cell 與 slice 的 BOC 內容
+type stackBytes struct {
+	Bytes string `json:"bytes"`
+}
+
+// stackElements tuple 與 list 的元素
+type stackElements struct {
+	Type     string  `json:"@type,omitempty"`
+	Elements []Value `json:"elements"`
+}
+
+// tonlibNumber tonlib 的 tvm.numberDecimal
+type tonlibNumber struct {
+	Number string `json:"number"`
+}
+
+// tonlibEntry tonlib 的 tvm.stackEntry*
+type tonlibEntry struct {
+	Type   string         `json:"@type"`
+	Number *tonlibNumber  `json:"number,omitempty"`
+	Cell   *stackBytes    `json:"cell,omitempty"`
+	Slice  *stackBytes    `json:"slice,omitempty"`
+	Tuple  *stackElements `json:"tuple,omitempty"`
+	List   *stackElements `json:"list,omitempty"`
+}
+
+// UnmarshalJSON 解析 toncenter 的 [type, value] 項目或 tonlib 的 tvm.stackEntry* 物件
+func (v *Value) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*v = Null()
+		return nil
+	case len(data) > 0 && data[0] == '[':
+		return v.unmarshalToncenter(data)
+	default:
+		return v.unmarshalTonlib(data)
+	}
+}
+
+func (v *Value) unmarshalToncenter(data []byte) error {
+	var entry []json.RawMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("無效的堆疊項目: %w", err)
+	}
+	if len(entry) == 0 {
+		return fmt.Errorf("空的堆疊項目")
+	}
+
+	var typ string
+	if err := json.Unmarshal(entry[0], &typ); err != nil {
+		return fmt.Errorf("無效的堆疊項目類型: %w", err)
+	}
+
+	if typ == "null" {
+		*v = Null()
+		return nil
+	}
+	if len(entry) != 2 {
+		return fmt.Errorf("%s 項目應包含 2 個欄位，實際為 %d", typ, len(entry))
+	}
+
+	switch typ {
+	case "num", "int":
+		var s string
+		if err := json.Unmarshal(entry[1], &s); err != nil {
+			return fmt.Errorf("無效的 num 項目: %w", err)
+		}
+		return v.setNumber(s)
+	case "cell", "slice":
+		var b stackBytes
+		if err := json.Unmarshal(entry[1], &b); err != nil {
+			return fmt.Errorf("無效的 %s 項目: %w", typ, err)
+		}
+		return v.setBytes(Type(typ), b.Bytes)
+	case "tuple", "list":
+		var elems stackElements
+		if err := json.Unmarshal(entry[1], &elems); err != nil {
+			return fmt.Errorf("無效的 %s 項目: %w", typ, err)
+		}
+		*v = Tuple(elems.Elements...)
+		return nil
+	default:
+		return fmt.Errorf("不支援的堆疊項目類型: %s", typ)
+	}
+}
+
+func (v *Value) unmarshalTonlib(data []byte) error {
+	var entry tonlibEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("無效的堆疊項目: %w", err)
+	}
+
+	switch entry.Type {
+	case "tvm.stackEntryNumber":
+		if entry.Number == nil {
+			return fmt.Errorf("tvm.stackEntryNumber 缺少 number")
+		}
+		return v.setNumber(entry.Number.Number)
+	case "tvm.stackEntryCell":
+		if entry.Cell == nil {
+			return fmt.Errorf("tvm.stackEntryCell 缺少 cell")
+		}
+		return v.setBytes(TypeCell, entry.Cell.Bytes)
+	case "tvm.stackEntrySlice":
+		if entry.Slice == nil {
+			return fmt.Errorf("tvm.stackEntrySlice 缺少 slice")
+		}
+		return v.setBytes(TypeSlice, entry.Slice.Bytes)
+	case "tvm.stackEntryTuple":
+		if entry.Tuple == nil {
+			return fmt.Errorf("tvm.stackEntryTuple 缺少 tuple")
+		}
+		*v = Tuple(entry.Tuple.Elements...)
+		return nil
+	case "tvm.stackEntryList":
+		if entry.List == nil {
+			return fmt.Errorf("tvm.stackEntryList 缺少 list")
+		}
+		*v = Tuple(entry.List.Elements...)
+		return nil
+	case "tvm.stackEntryUnsupported":
+		// tonlib 以 unsupported 表示 tuple 中的 null
+		*v = Null()
+		return nil
+	default:
+		return fmt.Errorf("不支援的堆疊項目類型: %s", entry.Type)
+	}
+}
+
+// setNumber 解析十六進位 (0x..., -0x...) 或十進位整數
+func (v *Value) setNumber(s string) error {
+	digits, negative := strings.CutPrefix(s, "-")
+
+	base := 10
+	if hex, ok := strings.CutPrefix(digits, "0x"); ok {
+		digits, base = hex, 16
+	}
+
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok || digits == "" {
+		return fmt.Errorf("無效的整數: %q", s)
+	}
+	if negative {
+		n.Neg(n)
+	}
+
+	*v = Value{typ: TypeNum, num: n}
+	return nil
+}
+
+// setBytes 解析 base64 編碼的 BOC
+func (v *Value) setBytes(typ Type, boc string) error {
+	c, err := cell.FromBOCBase64(boc)
+	if err != nil {
+		return fmt.Errorf("解析 %s 失敗: %w", typ, err)
+	}
+
+	*v = Value{typ: typ, cell: c}
+	return nil
+}
+
+// MarshalJSON 以 toncenter 最外層的 [type, value] 格式輸出項目
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.Type() {
+	case TypeNum:
+		return json.Marshal([]interface{}{"num", formatHex(v.num)})
+	case TypeCell, TypeSlice:
+		return json.Marshal([]interface{}{string(v.typ), stackBytes{Bytes: v.cell.ToBOCBase64()}})
+	case TypeTuple:
+		elems := make([]tonlibValue, len(v.tuple))
+		for i, elem := range v.tuple {
+			elems[i] = tonlibValue(elem)
+		}
+		return json.Marshal([]interface{}{"tuple", map[string]interface{}{"@type": "tvm.tuple", "elements": elems}})
+	default:
+		return json.Marshal([]interface{}{"null"})
+	}
+}
+
+// tonlibValue 以 tonlib 格式輸出的項目，用於 tuple 的元素
+type tonlibValue Value
+
+func (t tonlibValue) MarshalJSON() ([]byte, error) {
+	v := Value(t)
+
+	entry := tonlibEntry{Type: "tvm.stackEntryUnsupported"}
+	switch v.Type() {
+	case TypeNum:
+		entry = tonlibEntry{Type: "tvm.stackEntryNumber", Number: &tonlibNumber{Number: v.num.String()}}
+	case TypeCell:
+		entry = tonlibEntry{Type: "tvm.stackEntryCell", Cell: &stackBytes{Bytes: v.cell.ToBOCBase64()}}
+	case TypeSlice:
+		entry = tonlibEntry{Type: "tvm.stackEntrySlice", Slice: &stackBytes{Bytes: v.cell.ToBOCBase64()}}
+	case TypeTuple:
+		elems := make([]tonlibValue, len(v.tuple))
+		for i, elem := range v.tuple {
+			elems[i] = tonlibValue(elem)
+		}
+		return json.Marshal(map[string]interface{}{
+			"@type": "tvm.stackEntryTuple",
+			"tuple": map[string]interface{}{"@type": "tvm.tuple", "elements": elems},
+		})
+	}
+	return json.Marshal(entry)
+}
+
+// formatHex 以 toncenter 的格式輸出十六進位整數
+func formatHex(n *big.Int) string {
+	if n.Sign() < 0 {
+		return "-0x" + new(big.Int).Neg(n).Text(16)
+	}
+	return "0x" + n.Text(16)
+}
@@ -0,0 +1,168 @@
+package tvm
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+const testAddress = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+
+// testAddressSlice testAddress 以 MsgAddress 編碼後的 BOC (base64)
+const testAddressSlice = "te6cckEBAQEAJAAAQ4AUzz2O7J07cQGJVsCRL/06vg/nTl/0bMaS61Y9ZjXfQHBGW03W"
+
+func TestUnmarshalToncenterStack(t *testing.T) {
+	// toncenter runGetMethod 的回應：最外層為 [type, value]，tuple 內為 tonlib 格式
+	data := `[
+		["num", "0x1f"],
+		["num", "-0x2"],
+		["cell", {"bytes": "` + testAddressSlice + `", "object": {"data": {"b64": "", "len": 267}, "refs": []}}],
+		["null"],
+		["tuple", {"@type": "tvm.tuple", "elements": [
+			{"@type": "tvm.stackEntrySlice", "slice": {"@type": "tvm.slice", "bytes": "` + testAddressSlice + `"}},
+			{"@type": "tvm.stackEntryNumber", "number": {"@type": "tvm.numberDecimal", "number": "-1"}},
+			{"@type": "tvm.stackEntryUnsupported"},
+			{"@type": "tvm.stackEntryTuple", "tuple": {"@type": "tvm.tuple", "elements": [
+				{"@type": "tvm.stackEntryNumber", "number": {"number": "123456789012345678901234567890"}}
+			]}}
+		]}]
+	]`
+
+	var stack Stack
+	if err := json.Unmarshal([]byte(data), &stack); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if len(stack) != 5 {
+		t.Fatalf("Expected 5 entries, got %d", len(stack))
+	}
+
+	r := NewReader(stack)
+	if n := r.Int64(); n != 31 {
+		t.Errorf("Expected 31, got %d", n)
+	}
+	if n := r.Int64(); n != -2 {
+		t.Errorf("Expected -2, got %d", n)
+	}
+	if addr := r.Address(); addr == nil || addr.String() != testAddress {
+		t.Errorf("Expected address %s, got %v", testAddress, addr)
+	}
+	if addr := r.Address(); addr != nil {
+		t.Errorf("Expected null address to be nil, got %s", addr)
+	}
+
+	fields := r.Tuple()
+	if addr := fields.Address(); addr == nil || addr.String() != testAddress {
+		t.Errorf("Expected tuple address %s, got %v", testAddress, addr)
+	}
+	if !fields.Bool() {
+		t.Error("Expected -1 to decode as true")
+	}
+	if fields.OptionalTuple() != nil {
+		t.Error("Expected unsupported entry to decode as null")
+	}
+
+	nested := fields.Tuple()
+	expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if n := nested.BigInt(); n == nil || n.Cmp(expected) != 0 {
+		t.Errorf("Expected %s, got %v", expected, n)
+	}
+
+	for _, reader := range []*Reader{r, fields, nested} {
+		if err := reader.Err(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if reader.Remaining() != 0 {
+			t.Errorf("Expected all entries to be read, %d left", reader.Remaining())
+		}
+	}
+}
+
+func TestStackRoundTrip(t *testing.T) {
+	addr := address.MustParse(testAddress)
+	data, err := cell.BeginCell().StoreUInt(0xCA7, 12).EndCell()
+	if err != nil {
+		t.Fatalf("EndCell() failed: %v", err)
+	}
+
+	stack := Stack{
+		Int(0),
+		Int(-100),
+		Bool(true),
+		Cell(data),
+		Address(nil),
+		Tuple(Address(addr), Int(1700000000), Null(), Tuple(Bool(false))),
+	}
+
+	encoded, err := json.Marshal(stack)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	if !strings.Contains(string(encoded), `["num","-0x64"]`) {
+		t.Errorf("Expected toncenter hex format, got %s", encoded)
+	}
+	if !strings.Contains(string(encoded), `"tvm.stackEntrySlice"`) {
+		t.Errorf("Expected tonlib entries inside the tuple, got %s", encoded)
+	}
+
+	var decoded Stack
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if len(decoded) != len(stack) {
+		t.Fatalf("Expected %d entries, got %d", len(stack), len(decoded))
+	}
+	for i := range stack {
+		if decoded[i].String() != stack[i].String() {
+			t.Errorf("Entry %d: expected %s, got %s", i, stack[i], decoded[i])
+		}
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stack Stack
+		read  func(r *Reader) error
+		want  string
+	}{
+		{"missing entry", Stack{Int(1)}, func(r *Reader) error { r.Int64(); r.Int64(); return r.Err() }, "第 1 項"},
+		{"num expected", Stack{Null()}, func(r *Reader) error { r.Int64(); return r.Err() }, "預期 num"},
+		{"invalid bool", Stack{Int(2)}, func(r *Reader) error { r.Bool(); return r.Err() }, "無效的布林值"},
+		{"int64 overflow", Stack{BigInt(new(big.Int).Lsh(big.NewInt(1), 64))}, func(r *Reader) error { r.Int64(); return r.Err() }, "超出 int64"},
+		{"tuple expected", Stack{Int(1)}, func(r *Reader) error { return r.Tuple().Err() }, "預期 tuple"},
+		{"address expected", Stack{Int(1)}, func(r *Reader) error { r.Address(); return r.Err() }, "預期 cell 或 slice"},
+		{"required address", Stack{Null()}, func(r *Reader) error { r.RequiredAddress(); return r.Err() }, "預期 Address"},
+		{"error inside tuple", Stack{Tuple(Null())}, func(r *Reader) error { fields := r.Tuple(); fields.Int64(); return fields.Err() }, "第 0 項"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read(NewReader(tt.stack))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalidEntries(t *testing.T) {
+	for _, data := range []string{
+		`[["num", "0xZZ"]]`,
+		`[["num", ""]]`,
+		`[["cell", {"bytes": "not-a-boc"}]]`,
+		`[["unknown", "1"]]`,
+		`[[]]`,
+		`[{"@type": "tvm.stackEntryWhatever"}]`,
+	} {
+		var stack Stack
+		if err := json.Unmarshal([]byte(data), &stack); err == nil {
+			t.Errorf("Expected %s to fail", data)
+		}
+	}
+}
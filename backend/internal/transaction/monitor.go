@@ -2,19 +2,44 @@ package transaction
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/stream"
 	"ton-cat-lottery-backend/internal/ton"
 	"ton-cat-lottery-backend/pkg/logger"
+	"ton-cat-lottery-backend/pkg/metrics"
 )
 
+// confirmationWait 從開始監控到交易確認、失敗或逾時的時間，status 與交易記錄的狀態相同
+var confirmationWait = metrics.NewHistogramVec(
+	"tx_confirmation_wait_seconds",
+	"Time spent waiting for transaction confirmation by final status.",
+	[]float64{5, 10, 20, 30, 60, 120, 300, 600, 1200},
+	"status",
+)
+
+// defaultPollInterval 預設查詢交易狀態的間隔
+const defaultPollInterval = 10 * time.Second
+
 // Monitor 交易監控器
 type Monitor struct {
-	config    *config.Config
-	logger    *logger.Logger
-	tonClient *ton.Client
+	config   *config.Config
+	logger   *logger.Logger
+	txStatus ton.TxStatusProvider
+
+	// pollInterval 查詢交易狀態的間隔
+	pollInterval time.Duration
+
+	// store 交易記錄，設定後會寫入確認結果，nil 表示不記錄
+	store store.Repository
+
+	// events 推送交易確認事件，nil 表示不推送
+	events *stream.Broker
 }
 
 // Result 交易監控結果
@@ -24,15 +49,33 @@ type Result struct {
 	Error  error
 }
 
-// NewMonitor 創建新的交易監控器
-func NewMonitor(cfg *config.Config, log *logger.Logger, tonClient *ton.Client) *Monitor {
+// NewMonitor 創建新的交易監控器，以 txStatus 查詢交易的執行結果
+func NewMonitor(cfg *config.Config, log *logger.Logger, txStatus ton.TxStatusProvider) *Monitor {
 	return &Monitor{
-		config:    cfg,
-		logger:    log.WithGroup("tx_monitor"),
-		tonClient: tonClient,
+		config:       cfg,
+		logger:       log.WithGroup("tx_monitor"),
+		txStatus:     txStatus,
+		pollInterval: defaultPollInterval,
 	}
 }
 
+// SetPollInterval 設定查詢交易狀態的間隔，記憶體中的模擬鏈可使用較短的間隔
+func (m *Monitor) SetPollInterval(interval time.Duration) {
+	if interval > 0 {
+		m.pollInterval = interval
+	}
+}
+
+// SetStore 設定交易記錄，確認結果會寫入對應的交易
+func (m *Monitor) SetStore(repo store.Repository) {
+	m.store = repo
+}
+
+// SetEvents 設定事件廣播，交易在鏈上執行（成功或失敗）時推送 tx_confirmed 事件
+func (m *Monitor) SetEvents(events *stream.Broker) {
+	m.events = events
+}
+
 // WaitForConfirmation 等待交易確認
 func (m *Monitor) WaitForConfirmation(ctx context.Context, txHash string) (*Result, error) {
 	m.logger.Info("開始監控交易", "hash", txHash)
@@ -47,7 +90,7 @@ func (m *Monitor) WaitForConfirmation(ctx context.Context, txHash string) (*Resu
 	defer timeout.Stop()
 
 	// 設定檢查間隔
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(m.pollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -61,7 +104,7 @@ func (m *Monitor) WaitForConfirmation(ctx context.Context, txHash string) (*Resu
 			return result, result.Error
 
 		case <-ticker.C:
-			status, err := m.tonClient.GetTransactionStatus(ctx, txHash)
+			status, err := m.txStatus.GetTransactionStatus(ctx, txHash)
 			if err != nil {
 				m.logger.Warn("查詢交易狀態失敗", "hash", txHash, "error", err)
 				// 繼續嘗試，不立即返回錯誤
@@ -94,7 +137,20 @@ func (m *Monitor) WaitForConfirmation(ctx context.Context, txHash string) (*Resu
 }
 
 // WaitForConfirmationWithRetry 帶重試機制的交易確認
+//
+// 設定了交易記錄時，最終結果會寫入記錄；因 ctx 取消而中斷時保留 pending 狀態，重啟後由 ResumePending 繼續追蹤。
 func (m *Monitor) WaitForConfirmationWithRetry(ctx context.Context, txHash string, maxRetries int) (*Result, error) {
+	start := time.Now()
+	result, err := m.waitWithRetry(ctx, txHash, maxRetries)
+	if ctx.Err() == nil {
+		confirmationWait.Observe(time.Since(start).Seconds(), finalStatus(result, err))
+	}
+	m.recordResult(ctx, txHash, result, err)
+	m.publishResult(txHash, result, err)
+	return result, err
+}
+
+func (m *Monitor) waitWithRetry(ctx context.Context, txHash string, maxRetries int) (*Result, error) {
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		m.logger.Info("交易確認嘗試", "hash", txHash, "attempt", attempt, "max", maxRetries)
 
@@ -123,3 +179,82 @@ func (m *Monitor) WaitForConfirmationWithRetry(ctx context.Context, txHash strin
 
 	return nil, fmt.Errorf("達到最大重試次數")
 }
+
+// recordResult 將確認結果寫入交易記錄
+func (m *Monitor) recordResult(ctx context.Context, txHash string, result *Result, err error) {
+	if m.store == nil || ctx.Err() != nil {
+		return
+	}
+
+	status, errMsg := finalStatus(result, err), ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	if err := m.store.UpdateTransactionStatus(txHash, status, errMsg); err != nil && !errors.Is(err, store.ErrNotFound) {
+		m.logger.Error("更新交易記錄失敗", "hash", txHash, "status", status, "error", err)
+	}
+}
+
+// finalStatus 將確認結果對應到交易記錄的狀態
+func finalStatus(result *Result, err error) string {
+	switch {
+	case err == nil:
+		return store.TxStatusSuccess
+	case result != nil && result.Status == "failed":
+		return store.TxStatusFailed
+	default:
+		return store.TxStatusTimeout
+	}
+}
+
+// publishResult 交易已在鏈上執行時推送 tx_confirmed 事件，逾時或取消時不推送
+func (m *Monitor) publishResult(txHash string, result *Result, err error) {
+	if m.events == nil || result == nil || (err != nil && result.Status != "failed") {
+		return
+	}
+
+	confirmed := stream.TxConfirmed{Hash: txHash, Status: result.Status}
+	if err != nil {
+		confirmed.Error = err.Error()
+	}
+	if m.store != nil {
+		if tx, err := m.store.GetTransaction(txHash); err == nil {
+			confirmed.Operation = tx.Operation
+		}
+	}
+
+	if _, err := m.events.Publish(stream.EventTxConfirmed, confirmed); err != nil {
+		m.logger.Warn("推送交易確認事件失敗", "hash", txHash, "error", err)
+	}
+}
+
+// ResumePending 繼續追蹤交易記錄中仍為 pending 的交易（例如服務重啟前尚未確認的交易），全部結束後返回
+func (m *Monitor) ResumePending(ctx context.Context, maxRetries int) {
+	if m.store == nil {
+		return
+	}
+
+	pending, err := m.store.ListTransactions(store.TxStatusPending, 0)
+	if err != nil {
+		m.logger.Error("讀取待確認交易失敗", "error", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	m.logger.Info("繼續追蹤待確認交易", "count", len(pending))
+
+	var wg sync.WaitGroup
+	for _, tx := range pending {
+		wg.Add(1)
+		go func(tx store.Transaction) {
+			defer wg.Done()
+			if _, err := m.WaitForConfirmationWithRetry(ctx, tx.Hash, maxRetries); err != nil {
+				m.logger.Warn("待確認交易未成功", "hash", tx.Hash, "operation", tx.Operation, "error", err)
+			}
+		}(tx)
+	}
+	wg.Wait()
+}
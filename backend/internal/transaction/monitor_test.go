@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/store"
+	"ton-cat-lottery-backend/internal/stream"
 	"ton-cat-lottery-backend/internal/ton"
 	"ton-cat-lottery-backend/pkg/logger"
 )
@@ -32,13 +35,23 @@ func TestNewMonitor(t *testing.T) {
 		t.Error("Expected config to be set")
 	}
 
-	if monitor.tonClient != tonClient {
-		t.Error("Expected tonClient to be set")
+	if monitor.txStatus != tonClient {
+		t.Error("Expected txStatus to be set")
 	}
 
 	if monitor.logger == nil {
 		t.Error("Expected logger to be set")
 	}
+
+	if monitor.pollInterval != defaultPollInterval {
+		t.Errorf("Expected default poll interval %v, got %v", defaultPollInterval, monitor.pollInterval)
+	}
+
+	monitor.SetPollInterval(10 * time.Millisecond)
+	monitor.SetPollInterval(0)
+	if monitor.pollInterval != 10*time.Millisecond {
+		t.Errorf("Expected poll interval 10ms, got %v", monitor.pollInterval)
+	}
 }
 
 func TestWaitForConfirmation(t *testing.T) {
@@ -407,3 +420,111 @@ func TestWaitForConfirmationWithRetry(t *testing.T) {
 		}
 	})
 }
+
+func TestRecordResult(t *testing.T) {
+	cfg := &config.Config{TONAPIEndpoint: "https://testnet.toncenter.com/api/v2/", LogLevel: "debug"}
+	log := logger.New(cfg.LogLevel)
+	monitor := NewMonitor(cfg, log, ton.NewClient(cfg, log))
+
+	repo, err := store.Open("")
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	monitor.SetStore(repo)
+
+	cases := []struct {
+		hash   string
+		result *Result
+		err    error
+		want   string
+	}{
+		{"success", &Result{Status: "success"}, nil, store.TxStatusSuccess},
+		{"failed", &Result{Status: "failed"}, errors.New("交易執行失敗"), store.TxStatusFailed},
+		{"timeout", &Result{Status: "pending"}, errors.New("交易確認超時"), store.TxStatusTimeout},
+	}
+	for _, tc := range cases {
+		if err := repo.SaveTransaction(store.Transaction{Hash: tc.hash, Status: store.TxStatusPending}); err != nil {
+			t.Fatal(err)
+		}
+		monitor.recordResult(context.Background(), tc.hash, tc.result, tc.err)
+
+		if tx, _ := repo.GetTransaction(tc.hash); tx.Status != tc.want {
+			t.Errorf("%s: expected status %s, got %s", tc.hash, tc.want, tx.Status)
+		}
+	}
+
+	// 服務關閉時保留 pending，重啟後繼續追蹤
+	if err := repo.SaveTransaction(store.Transaction{Hash: "canceled", Status: store.TxStatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	monitor.recordResult(ctx, "canceled", &Result{Status: "pending"}, ctx.Err())
+	if tx, _ := repo.GetTransaction("canceled"); tx.Status != store.TxStatusPending {
+		t.Errorf("Expected canceled transaction to stay pending, got %s", tx.Status)
+	}
+
+	pending, _ := repo.ListTransactions(store.TxStatusPending, 0)
+	if len(pending) != 1 || pending[0].Hash != "canceled" {
+		t.Errorf("Expected only the canceled transaction to be pending, got %+v", pending)
+	}
+}
+
+func TestPublishResult(t *testing.T) {
+	cfg := &config.Config{TONAPIEndpoint: "https://testnet.toncenter.com/api/v2/", LogLevel: "debug"}
+	log := logger.New(cfg.LogLevel)
+	monitor := NewMonitor(cfg, log, ton.NewClient(cfg, log))
+
+	repo, _ := store.Open("")
+	repo.SaveTransaction(store.Transaction{Hash: "hash-draw", Operation: "draw_winner", Status: store.TxStatusPending})
+	monitor.SetStore(repo)
+
+	events := stream.NewBroker(0)
+	monitor.SetEvents(events)
+	sub := events.Subscribe(0)
+	defer sub.Close()
+
+	monitor.publishResult("hash-draw", &Result{Status: "success"}, nil)
+	monitor.publishResult("hash-failed", &Result{Status: "failed"}, errors.New("交易執行失敗"))
+	// 逾時未確認不推送
+	monitor.publishResult("hash-timeout", &Result{Status: "pending"}, errors.New("交易確認超時"))
+
+	want := []stream.TxConfirmed{
+		{Hash: "hash-draw", Operation: "draw_winner", Status: "success"},
+		{Hash: "hash-failed", Status: "failed", Error: "交易執行失敗"},
+	}
+	for _, w := range want {
+		event := <-sub.Events()
+		var got stream.TxConfirmed
+		if err := json.Unmarshal(event.Data, &got); err != nil || event.Type != stream.EventTxConfirmed || got != w {
+			t.Errorf("Expected %+v, got %s %s (%v)", w, event.Type, event.Data, err)
+		}
+	}
+	select {
+	case event := <-sub.Events():
+		t.Errorf("Unexpected event for timed out transaction: %+v", event)
+	default:
+	}
+}
+
+func TestConfirmationWaitMetrics(t *testing.T) {
+	cfg := &config.Config{TONAPIEndpoint: "https://testnet.toncenter.com/api/v2/", LogLevel: "debug"}
+	log := logger.New(cfg.LogLevel)
+	monitor := NewMonitor(cfg, log, ton.NewClient(cfg, log))
+
+	before := confirmationWait.Count(store.TxStatusTimeout)
+
+	// 沒有重試次數時立即以逾時結束
+	monitor.WaitForConfirmationWithRetry(context.Background(), "hash-metrics", 0)
+	if got := confirmationWait.Count(store.TxStatusTimeout) - before; got != 1 {
+		t.Errorf("Expected 1 timeout observation, got %d", got)
+	}
+
+	// 服務關閉造成的中斷不計入
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	monitor.WaitForConfirmationWithRetry(ctx, "hash-canceled", 1)
+	if got := confirmationWait.Count(store.TxStatusTimeout) - before; got != 1 {
+		t.Errorf("Expected canceled wait not to be observed, got %d observations", got)
+	}
+}
@@ -0,0 +1,147 @@
+package wallet
+
+import (
+	"fmt"
+	"sync"
+
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// Version 錢包合約版本
+type Version string
+
+const (
+	VersionV3R2 Version = "v3r2"
+	VersionV4R2 Version = "v4r2"
+	VersionV5R1 Version = "v5r1"
+
+	// DefaultVersion 未設定時使用的錢包版本
+	DefaultVersion = VersionV4R2
+)
+
+const (
+	// DefaultSubwalletID v3/v4 錢包的預設 subwallet id (698983191 + workchain)
+	DefaultSubwalletID uint32 = 698983191
+
+	// 網路全域 ID，v5r1 錢包用於計算 wallet id
+	mainnetGlobalID int32 = -239
+	testnetGlobalID int32 = -3
+)
+
+// 各版本錢包合約程式碼 (BOC, base64)，與官方錢包完全一致
+var walletCodeBOC = map[Version]string{
+	VersionV3R2: "te6cckEBAQEAcQAA3v8AIN0gggFMl7ohggEznLqxn3Gw7UTQ0x/THzHXC//jBOCk8mCDCNcYINMf0x/TH/gjE7vyY+1E0NMf0x/T/9FRMrryoVFEuvKiBPkBVBBV+RDyo/gAkyDXSpbTB9QC+wDo0QGkyMsfyx/L/8ntVBC9ba0=",
+	VersionV4R2: "te6cckECFAEAAtQAART/APSkE/S88sgLAQIBIAIDAgFIBAUE+PKDCNcYINMf0x/THwL4I7vyZO1E0NMf0x/T//QE0VFDuvKhUVG68qIF+QFUEGT5EPKj+AAkpMjLH1JAyx9SMMv/UhD0AMntVPgPAdMHIcAAn2xRkyDXSpbTB9QC+wDoMOAhwAHjACHAAuMAAcADkTDjDQOkyMsfEssfy/8QERITAubQAdDTAyFxsJJfBOAi10nBIJJfBOAC0x8hghBwbHVnvSKCEGRzdHK9sJJfBeAD+kAwIPpEAcjKB8v/ydDtRNCBAUDXIfQEMFyBAQj0Cm+hMbOSXwfgBdM/yCWCEHBsdWe6kjgw4w0DghBkc3RyupJfBuMNBgcCASAICQB4AfoA9AQw+CdvIjBQCqEhvvLgUIIQcGx1Z4MesXCAGFAEywUmzxZY+gIZ9ADLaRfLH1Jgyz8gyYBA+wAGAIpQBIEBCPRZMO1E0IEBQNcgyAHPFvQAye1UAXKwjiOCEGRzdHKDHrFwgBhQBcsFUAPPFiP6AhPLassfyz/JgED7AJJfA+ICASAKCwBZvSQrb2omhAgKBrkPoCGEcNQICEekk30pkQzmkD6f+YN4EoAbeBAUiYcVnzGEAgFYDA0AEbjJftRNDXCx+AA9sp37UTQgQFA1yH0BDACyMoHy//J0AGBAQj0Cm+hMYAIBIA4PABmtznaiaEAga5Drhf/AABmvHfaiaEAQa5DrhY/AAG7SB/oA1NQi+QAFyMoHFcv/ydB3dIAYyMsFywIizxZQBfoCFMtrEszMyXP7AMhAFIEBCPRR8qcCAHCBAQjXGPoA0z/IVCBHgQEI9FHyp4IQbm90ZXB0gBjIywXLAlAGzxZQBPoCFMtqEssfyz/Jc/sAAgBsgQEI1xj6ANM/MFIkgQEI9Fnyp4IQZHN0cnB0gBjIywXLAlAFzxZQA/oCE8tqyx8Syz/Jc/sAAAr0AMntVGliJeU=",
+	VersionV5R1: "te6cckECFAEAAoEAART/APSkE/S88sgLAQIBIAINAgFIAwQC3NAg10nBIJFbj2Mg1wsfIIIQZXh0br0hghBzaW50vbCSXwPgghBleHRuuo60gCDXIQHQdNch+kAw+kT4KPpEMFi9kVvg7UTQgQFB1yH0BYMH9A5voTGRMOGAQNchcH/bPOAxINdJgQKAuZEw4HDiEA8CASAFDAIBIAYJAgFuBwgAGa3OdqJoQCDrkOuF/8AAGa8d9qJoQBDrkOuFj8ACAUgKCwAXsyX7UTQcdch1wsfgABGyYvtRNDXCgCAAGb5fD2omhAgKDrkPoCwBAvIOAR4g1wsfghBzaWduuvLgin8PAeaO8O2i7fshgwjXIgKDCNcjIIAg1yHTH9Mf0x/tRNDSANMfINMf0//XCgAK+QFAzPkQmiiUXwrbMeHywIffArNQB7Dy0IRRJbry4IVQNrry4Ib4I7vy0IgikvgA3gGkf8jKAMsfAc8Wye1UIJL4D95w2zzYEAP27aLt+wL0BCFukmwhjkwCIdc5MHCUIccAs44tAdcoIHYeQ2wg10nACPLgkyDXSsAC8uCTINcdBscSwgBSMLDy0InXTNc5MAGk6GwShAe78uCT10rAAPLgk+1V4tIAAcAAkVvg69csCBQgkXCWAdcsCBwS4lIQseMPINdKERITAJYB+kAB+kT4KPpEMFi68uCR7UTQgQFB1xj0BQSdf8jKAEAEgwf0U/Lgi44UA4MH9Fvy4Iwi1woAIW4Bs7Dy0JDiyFADzxYS9ADJ7VQAcjDXLAgkji0h8uCS0gDtRNDSAFETuvLQj1RQMJExnAGBAUDXIdcKAPLgjuLIygBYzxbJ7VST8sCN4gAQk1vbMeHXTNC01sNe",
+}
+
+var (
+	walletCodeOnce  sync.Once
+	walletCodeCells map[Version]*cell.Cell
+	walletCodeErr   error
+)
+
+// ParseVersion 解析錢包版本字串，空字串返回預設版本
+func ParseVersion(s string) (Version, error) {
+	if s == "" {
+		return DefaultVersion, nil
+	}
+	v := Version(s)
+	if _, ok := walletCodeBOC[v]; !ok {
+		return "", fmt.Errorf("不支援的錢包版本: %s（支援 v3r2、v4r2、v5r1）", s)
+	}
+	return v, nil
+}
+
+// walletCode 獲取指定版本的錢包合約程式碼 Cell
+func walletCode(version Version) (*cell.Cell, error) {
+	walletCodeOnce.Do(func() {
+		walletCodeCells = make(map[Version]*cell.Cell, len(walletCodeBOC))
+		for v, boc := range walletCodeBOC {
+			code, err := cell.FromBOCBase64(boc)
+			if err != nil {
+				walletCodeErr = fmt.Errorf("解析 %s 錢包程式碼失敗: %w", v, err)
+				return
+			}
+			walletCodeCells[v] = code
+		}
+	})
+
+	if walletCodeErr != nil {
+		return nil, walletCodeErr
+	}
+
+	code, ok := walletCodeCells[version]
+	if !ok {
+		return nil, fmt.Errorf("不支援的錢包版本: %s", version)
+	}
+	return code, nil
+}
+
+// walletID 計算錢包合約資料中使用的 wallet id
+//
+// v3/v4 直接使用 subwallet id；v5r1 則由網路全域 ID 與 subwallet 編號組合而成。
+func walletID(version Version, network string, workchain int8, subwallet uint32) uint32 {
+	if version != VersionV5R1 {
+		if subwallet == 0 {
+			return DefaultSubwalletID + uint32(int32(workchain))
+		}
+		return subwallet
+	}
+
+	globalID := mainnetGlobalID
+	if network == "testnet" {
+		globalID = testnetGlobalID
+	}
+
+	// context = is_client(1) | workchain(8) | wallet_version(8) | subwallet_number(15)
+	context := uint32(1)<<31 |
+		uint32(uint8(workchain))<<23 |
+		uint32(0)<<15 |
+		subwallet&0x7fff
+
+	return uint32(globalID) ^ context
+}
+
+// buildWalletData 構建錢包合約的初始資料 Cell
+func buildWalletData(version Version, walletID uint32, publicKey []byte) (*cell.Cell, error) {
+	switch version {
+	case VersionV3R2:
+		return cell.BeginCell().
+			StoreUInt(0, 32). // seqno
+			StoreUInt(uint64(walletID), 32).
+			StoreBytes(publicKey).
+			EndCell()
+
+	case VersionV4R2:
+		return cell.BeginCell().
+			StoreUInt(0, 32). // seqno
+			StoreUInt(uint64(walletID), 32).
+			StoreBytes(publicKey).
+			StoreBool(false). // plugins: 空字典
+			EndCell()
+
+	case VersionV5R1:
+		return cell.BeginCell().
+			StoreBool(true).  // is_signature_allowed
+			StoreUInt(0, 32). // seqno
+			StoreUInt(uint64(walletID), 32).
+			StoreBytes(publicKey).
+			StoreBool(false). // extensions: 空字典
+			EndCell()
+
+	default:
+		return nil, fmt.Errorf("不支援的錢包版本: %s", version)
+	}
+}
+
+// buildStateInit 構建 StateInit Cell：僅包含 code 與 data
+func buildStateInit(code, data *cell.Cell) (*cell.Cell, error) {
+	return cell.BeginCell().
+		StoreBool(false). // split_depth
+		StoreBool(false). // special
+		StoreMaybeRef(code).
+		StoreMaybeRef(data).
+		StoreBool(false). // library
+		EndCell()
+}
@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWalletCodeHash(t *testing.T) {
+	// 官方錢包合約程式碼雜湊
+	expected := map[Version]string{
+		VersionV3R2: "84dafa449f98a6987789ba232358072bc0f76dc4524002a5d0918b9a75d2d599",
+		VersionV4R2: "feb5ff6820e2ff0d9483e7e0d62c817d846789fb4ae580c878866d959dabd5c0",
+		VersionV5R1: "20834b7b72b112147e1b2fb457b84e74d1a30f04f737d4f62a668e9552d2b72f",
+	}
+
+	for version, hash := range expected {
+		code, err := walletCode(version)
+		if err != nil {
+			t.Fatalf("walletCode(%s) failed: %v", version, err)
+		}
+		if got := hex.EncodeToString(code.Hash()); got != hash {
+			t.Errorf("Expected %s code hash=%s, got %s", version, hash, got)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		wantErr  bool
+	}{
+		{"", DefaultVersion, false},
+		{"v3r2", VersionV3R2, false},
+		{"v4r2", VersionV4R2, false},
+		{"v5r1", VersionV5R1, false},
+		{"v4r1", "", true},
+		{"V4R2", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestWalletID(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   Version
+		network   string
+		subwallet uint32
+		expected  uint32
+	}{
+		{"v4r2 default", VersionV4R2, "mainnet", 0, DefaultSubwalletID},
+		{"v3r2 custom", VersionV3R2, "testnet", 42, 42},
+		{"v5r1 mainnet", VersionV5R1, "mainnet", 0, 2147483409},
+		{"v5r1 testnet", VersionV5R1, "testnet", 0, 2147483645},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := walletID(tt.version, tt.network, 0, tt.subwallet); got != tt.expected {
+				t.Errorf("Expected wallet id=%d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildWalletData(t *testing.T) {
+	publicKey := bytes.Repeat([]byte{0xAB}, 32)
+
+	tests := []struct {
+		version Version
+		bits    int
+	}{
+		{VersionV3R2, 32 + 32 + 256},
+		{VersionV4R2, 32 + 32 + 256 + 1},
+		{VersionV5R1, 1 + 32 + 32 + 256 + 1},
+	}
+
+	for _, tt := range tests {
+		data, err := buildWalletData(tt.version, 1, publicKey)
+		if err != nil {
+			t.Fatalf("buildWalletData(%s) failed: %v", tt.version, err)
+		}
+		if data.BitsSize() != tt.bits {
+			t.Errorf("Expected %s data to have %d bits, got %d", tt.version, tt.bits, data.BitsSize())
+		}
+	}
+
+	if _, err := buildWalletData("v1", 1, publicKey); err == nil {
+		t.Error("Expected buildWalletData() to fail with unsupported version")
+	}
+}
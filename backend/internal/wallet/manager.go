@@ -1,14 +1,17 @@
 package wallet
 
 import (
+	"context"
 	"crypto/ed25519"
-	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
@@ -19,7 +22,20 @@ type Manager struct {
 	privateKey ed25519.PrivateKey
 	publicKey  ed25519.PublicKey
 	address    string
-	seqno      uint32 // 序號用於交易防重放
+
+	// seqno 由鏈上同步並透過保留機制使用，確保發送失敗後不會錯位
+	seqnoMu     sync.Mutex
+	seqno       uint32
+	seqnoSynced bool
+	seqnoReader SeqnoReader
+	seqnoSlot   chan struct{}
+
+	balanceReader BalanceReader
+
+	walletAddress *address.Address // 錢包合約地址，外部訊息的目的地
+	version       Version          // 錢包合約版本
+	walletID      uint32           // 錢包合約資料中的 wallet id (subwallet)
+	stateInit     *cell.Cell       // 錢包合約 StateInit，其雜湊即為帳戶地址
 }
 
 // MessageType 消息類型
@@ -36,8 +52,9 @@ const (
 // NewManager 創建新的錢包管理器
 func NewManager(cfg *config.Config, log *logger.Logger) (*Manager, error) {
 	manager := &Manager{
-		config: cfg,
-		logger: log.WithGroup("wallet"),
+		config:    cfg,
+		logger:    log.WithGroup("wallet"),
+		seqnoSlot: make(chan struct{}, 1),
 	}
 
 	// 初始化錢包
@@ -50,6 +67,13 @@ func NewManager(cfg *config.Config, log *logger.Logger) (*Manager, error) {
 
 // initWallet 初始化錢包
 func (m *Manager) initWallet() error {
+	version, err := ParseVersion(m.config.WalletVersion)
+	if err != nil {
+		return err
+	}
+	m.version = version
+	m.walletID = walletID(version, m.config.TONNetwork, 0, m.config.WalletSubwalletID)
+
 	// 優先使用私鑰
 	if m.config.WalletPrivateKey != "" {
 		return m.loadFromPrivateKey(m.config.WalletPrivateKey)
@@ -77,43 +101,79 @@ func (m *Manager) loadFromPrivateKey(privateKeyHex string) error {
 	}
 
 	// 驗證私鑰長度：可以是32字節的種子或64字節的完整私鑰
+	var privateKey ed25519.PrivateKey
 	if len(privateKeyBytes) == ed25519.SeedSize {
 		// 32字節的種子，需要生成完整的私鑰
-		m.privateKey = ed25519.NewKeyFromSeed(privateKeyBytes)
-		m.publicKey = m.privateKey.Public().(ed25519.PublicKey)
+		privateKey = ed25519.NewKeyFromSeed(privateKeyBytes)
 	} else if len(privateKeyBytes) == ed25519.PrivateKeySize {
 		// 64字節的完整私鑰
-		m.privateKey = ed25519.PrivateKey(privateKeyBytes)
-		m.publicKey = m.privateKey.Public().(ed25519.PublicKey)
+		privateKey = ed25519.PrivateKey(privateKeyBytes)
 	} else {
 		return fmt.Errorf("私鑰長度無效，預期 %d bytes（種子）或 %d bytes（完整私鑰），實際 %d bytes",
 			ed25519.SeedSize, ed25519.PrivateKeySize, len(privateKeyBytes))
 	}
 
-	// 生成地址 (簡化版本，實際需要根據 TON 地址規範)
-	m.address = m.generateAddress()
-
-	m.logger.Info("錢包載入成功", "address", m.address)
-	return nil
+	return m.setPrivateKey(privateKey)
 }
 
 // loadFromMnemonic 從助記詞載入錢包
 func (m *Manager) loadFromMnemonic(mnemonic string) error {
-	m.logger.Debug("從助記詞載入錢包")
+	m.logger.Debug("從助記詞載入錢包",
+		"password_protected", m.config.WalletMnemonicPassword != "",
+	)
 
-	// TODO: 實作從助記詞生成私鑰的邏輯
-	// 這需要使用 TON 的助記詞到私鑰的轉換算法
+	privateKey, err := MnemonicToPrivateKey(mnemonic, m.config.WalletMnemonicPassword)
+	if err != nil {
+		return fmt.Errorf("助記詞無效: %w", err)
+	}
 
-	return fmt.Errorf("助記詞載入功能尚未實作")
+	return m.setPrivateKey(privateKey)
 }
 
-// generateAddress 生成 TON 地址
-func (m *Manager) generateAddress() string {
-	// TODO: 實作正確的 TON 地址生成邏輯
-	// 這是一個簡化版本，實際需要根據 TON 地址規範實作
+// setPrivateKey 設定錢包金鑰並推導地址
+func (m *Manager) setPrivateKey(privateKey ed25519.PrivateKey) error {
+	m.privateKey = privateKey
+	m.publicKey = privateKey.Public().(ed25519.PublicKey)
+
+	// 由錢包合約 StateInit 推導地址
+	if err := m.generateAddress(); err != nil {
+		return fmt.Errorf("生成錢包地址失敗: %w", err)
+	}
 
-	publicKeyHex := hex.EncodeToString(m.publicKey)
-	return fmt.Sprintf("EQ%s", publicKeyHex[:40]) // 簡化版本
+	m.logger.Info("錢包載入成功",
+		"address", m.address,
+		"version", m.version,
+		"wallet_id", m.walletID,
+	)
+	return nil
+}
+
+// generateAddress 構建錢包合約 StateInit，並以其雜湊作為 workchain 0 上的帳戶地址
+func (m *Manager) generateAddress() error {
+	code, err := walletCode(m.version)
+	if err != nil {
+		return err
+	}
+
+	data, err := buildWalletData(m.version, m.walletID, m.publicKey)
+	if err != nil {
+		return fmt.Errorf("構建錢包資料失敗: %w", err)
+	}
+
+	stateInit, err := buildStateInit(code, data)
+	if err != nil {
+		return fmt.Errorf("構建 StateInit 失敗: %w", err)
+	}
+
+	addr, err := address.NewAddress(0, stateInit.Hash())
+	if err != nil {
+		return err
+	}
+
+	m.stateInit = stateInit
+	m.walletAddress = addr
+	m.address = addr.String()
+	return nil
 }
 
 // GetAddress 獲取錢包地址
@@ -121,6 +181,21 @@ func (m *Manager) GetAddress() string {
 	return m.address
 }
 
+// GetVersion 獲取錢包合約版本
+func (m *Manager) GetVersion() Version {
+	return m.version
+}
+
+// GetWalletID 獲取錢包合約的 wallet id
+func (m *Manager) GetWalletID() uint32 {
+	return m.walletID
+}
+
+// GetStateInit 獲取錢包合約 StateInit（部署錢包時需要附帶）
+func (m *Manager) GetStateInit() *cell.Cell {
+	return m.stateInit
+}
+
 // GetPublicKey 獲取公鑰
 func (m *Manager) GetPublicKey() ed25519.PublicKey {
 	return m.publicKey
@@ -140,116 +215,118 @@ func (m *Manager) SignMessage(message []byte) ([]byte, error) {
 	return signature, nil
 }
 
-// CreateTransaction 創建交易
-func (m *Manager) CreateTransaction(to string, amount int64, payload []byte) ([]byte, error) {
+// CreateTransaction 創建以文字評論為 body 的轉帳交易，返回序列化後的外部訊息 BOC
+func (m *Manager) CreateTransaction(to string, amount int64, payload []byte, seqno uint32) ([]byte, error) {
+	return m.CreateMessage(to, amount, MessageTypeText, payload, seqno)
+}
+
+// CreateMessage 創建簽名後的錢包外部訊息，返回序列化後的 BOC
+//
+// 外部訊息包含簽名、subwallet id、valid_until、seqno、發送模式，
+// 以及一則發送到 to 的內部訊息，其 body 由 msgType 與 payload 決定。
+// seqno 應來自 ReserveSeqno，以確保與鏈上一致（防重放攻擊）。
+func (m *Manager) CreateMessage(to string, amount int64, msgType MessageType, payload []byte, seqno uint32) ([]byte, error) {
 	m.logger.Debug("創建交易",
 		"to", to,
 		"amount", amount,
+		"type", msgType,
+		"seqno", seqno,
 		"payload_length", len(payload),
 	)
 
-	// 增加序號（防重放攻擊）
-	m.seqno++
-
-	// 創建交易消息結構（簡化版本）
-	// 注意：這是一個簡化實現，實際TON交易需要更複雜的編碼
-	transaction, err := m.buildTransaction(to, amount, payload, m.seqno)
+	message, err := m.buildTransaction(to, amount, msgType, payload, seqno)
 	if err != nil {
 		return nil, fmt.Errorf("構建交易失敗: %w", err)
 	}
 
-	// 簽名交易
-	signedTransaction, err := m.signTransaction(transaction)
-	if err != nil {
-		return nil, fmt.Errorf("簽名交易失敗: %w", err)
-	}
-
-	m.logger.Info("交易創建成功", "transaction_length", len(signedTransaction))
-	return signedTransaction, nil
+	boc := message.ToBOC()
+	m.logger.Info("交易創建成功",
+		"type", msgType,
+		"seqno", seqno,
+		"transaction_length", len(boc),
+	)
+	return boc, nil
 }
 
-// buildTransaction 構建交易（簡化版本）
-func (m *Manager) buildTransaction(to string, amount int64, payload []byte, seqno uint32) ([]byte, error) {
-	// TODO: 實現正確的TON交易格式
-	// 這是一個簡化版本，實際需要使用TL-B編碼和Cell結構
-
-	// 構建基本交易結構
-	var txData []byte
+// buildTransaction 構建完整的錢包外部訊息 Cell
+func (m *Manager) buildTransaction(to string, amount int64, msgType MessageType, payload []byte, seqno uint32) (*cell.Cell, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("金額不能為負數: %d", amount)
+	}
 
-	// 添加發送方地址
-	txData = append(txData, []byte(m.address)...)
+	// 接收方地址
+	destination, err := address.Parse(to)
+	if err != nil {
+		return nil, fmt.Errorf("無效的接收方地址: %w", err)
+	}
 
-	// 添加接收方地址
-	txData = append(txData, []byte(to)...)
+	body, err := buildMessageBody(msgType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("編碼載荷失敗: %w", err)
+	}
 
-	// 添加金額（8字節）
-	amountBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(amountBytes, uint64(amount))
-	txData = append(txData, amountBytes...)
+	internal, err := buildInternalMessage(destination, uint64(amount), body)
+	if err != nil {
+		return nil, fmt.Errorf("構建內部訊息失敗: %w", err)
+	}
 
-	// 添加序號（4字節）
-	seqnoBytes := make([]byte, 4)
-	binary.BigEndian.PutUint32(seqnoBytes, seqno)
-	txData = append(txData, seqnoBytes...)
+	validUntil := validUntilFor(seqno, time.Now(), DefaultMessageTTL)
+	signing, err := buildSigningMessage(m.version, m.walletID, validUntil, seqno, DefaultSendMode, internal)
+	if err != nil {
+		return nil, fmt.Errorf("構建簽名內容失敗: %w", err)
+	}
 
-	// 添加時間戳（8字節）
-	timestamp := time.Now().Unix()
-	timestampBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(timestampBytes, uint64(timestamp))
-	txData = append(txData, timestampBytes...)
+	signed, err := m.signTransaction(signing)
+	if err != nil {
+		return nil, fmt.Errorf("簽名交易失敗: %w", err)
+	}
 
-	// 添加載荷
-	if len(payload) > 0 {
-		txData = append(txData, payload...)
+	// 錢包尚未部署時需附帶 StateInit
+	var stateInit *cell.Cell
+	if seqno == 0 {
+		stateInit = m.stateInit
 	}
 
-	return txData, nil
+	return buildExternalMessage(m.walletAddress, stateInit, signed)
 }
 
-// signTransaction 簽名交易
-func (m *Manager) signTransaction(transaction []byte) ([]byte, error) {
-	// 使用私鑰簽名交易
-	signature := ed25519.Sign(m.privateKey, transaction)
-
-	// 組合簽名和交易（簡化版本）
-	signedTx := make([]byte, 0, len(signature)+len(transaction))
-	signedTx = append(signedTx, signature...)
-	signedTx = append(signedTx, transaction...)
+// signTransaction 對簽名內容的雜湊簽名，並依錢包版本組合簽名與內容
+func (m *Manager) signTransaction(signing *cell.Cell) (*cell.Cell, error) {
+	if m.privateKey == nil {
+		return nil, fmt.Errorf("錢包未初始化")
+	}
 
-	return signedTx, nil
+	signature := ed25519.Sign(m.privateKey, signing.Hash())
+	return buildSignedBody(m.version, signing, signature)
 }
 
 // CreateDrawWinnerTransaction 創建抽獎交易
-func (m *Manager) CreateDrawWinnerTransaction(contractAddress string) ([]byte, error) {
+func (m *Manager) CreateDrawWinnerTransaction(contractAddress string, seqno uint32) ([]byte, error) {
 	m.logger.Debug("創建抽獎交易", "contract", contractAddress)
 
-	// 創建 "drawWinner" 消息載荷
-	payload := []byte("drawWinner")
-
 	// 創建交易（需要支付少量gas費用）
-	return m.CreateTransaction(contractAddress, 50000000, payload) // 0.05 TON gas費
+	return m.CreateMessage(contractAddress, 50000000, MessageTypeDrawWinner, nil, seqno) // 0.05 TON gas費
 }
 
 // CreateStartNewRoundTransaction 創建開始新輪次交易
-func (m *Manager) CreateStartNewRoundTransaction(contractAddress string) ([]byte, error) {
+func (m *Manager) CreateStartNewRoundTransaction(contractAddress string, seqno uint32) ([]byte, error) {
 	m.logger.Debug("創建開始新輪次交易", "contract", contractAddress)
 
-	// 創建 "startNewRound" 消息載荷
-	payload := []byte("startNewRound")
+	return m.CreateMessage(contractAddress, 50000000, MessageTypeStartNewRound, nil, seqno) // 0.05 TON gas費
+}
+
+// CreateWithdrawTransaction 創建提取合約餘額交易
+func (m *Manager) CreateWithdrawTransaction(contractAddress string, seqno uint32) ([]byte, error) {
+	m.logger.Debug("創建提取餘額交易", "contract", contractAddress)
 
-	// 創建交易
-	return m.CreateTransaction(contractAddress, 50000000, payload) // 0.05 TON gas費
+	return m.CreateMessage(contractAddress, 50000000, MessageTypeWithdraw, nil, seqno) // 0.05 TON gas費
 }
 
 // CreateSetNFTContractTransaction 創建設定NFT合約交易
-func (m *Manager) CreateSetNFTContractTransaction(contractAddress, nftAddress string) ([]byte, error) {
+func (m *Manager) CreateSetNFTContractTransaction(contractAddress, nftAddress string, seqno uint32) ([]byte, error) {
 	m.logger.Debug("創建設定NFT合約交易", "contract", contractAddress, "nft", nftAddress)
 
-	// 創建 SetNFTContract 消息載荷（簡化版本）
-	// 實際需要根據TL-B格式編碼
-	payload := []byte(fmt.Sprintf("setNFTContract:%s", nftAddress))
-
-	return m.CreateTransaction(contractAddress, 50000000, payload) // 0.05 TON gas費
+	return m.CreateMessage(contractAddress, 50000000, MessageTypeSetNFTContract, []byte(nftAddress), seqno) // 0.05 TON gas費
 }
 
 // VerifySignature 驗證簽名
@@ -257,11 +334,29 @@ func (m *Manager) VerifySignature(message, signature []byte, publicKey ed25519.P
 	return ed25519.Verify(publicKey, message, signature)
 }
 
-// GetBalance 獲取錢包餘額
-func (m *Manager) GetBalance() (int64, error) {
-	// TODO: 實作查詢錢包餘額的邏輯
-	// 這需要調用 TON API 來查詢地址餘額
+// BalanceReader 查詢地址餘額 (nanoTON)
+type BalanceReader interface {
+	GetAddressBalance(ctx context.Context, addr string) (int64, error)
+}
+
+// SetBalanceReader 設定餘額查詢來源，需在使用 GetBalance 前設定
+func (m *Manager) SetBalanceReader(reader BalanceReader) {
+	m.balanceReader = reader
+}
 
+// GetBalance 獲取錢包餘額 (nanoTON)
+func (m *Manager) GetBalance(ctx context.Context) (int64, error) {
 	m.logger.Debug("查詢錢包餘額", "address", m.address)
-	return 0, fmt.Errorf("餘額查詢功能尚未實作")
+
+	if m.balanceReader == nil {
+		return 0, fmt.Errorf("未設定餘額查詢來源")
+	}
+
+	balance, err := m.balanceReader.GetAddressBalance(ctx, m.address)
+	if err != nil {
+		return 0, fmt.Errorf("查詢錢包餘額失敗: %w", err)
+	}
+
+	m.logger.Debug("錢包餘額查詢成功", "balance", balance)
+	return balance, nil
 }
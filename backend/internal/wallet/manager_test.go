@@ -1,14 +1,24 @@
 package wallet
 
 import (
+	"context"
 	"crypto/ed25519"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton/address"
 	"ton-cat-lottery-backend/pkg/logger"
 )
 
+// 測試用合約地址（合法的使用者友善格式）
+const (
+	testLotteryAddress = "EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd"
+	testNFTAddress     = "EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A"
+)
+
 func TestNewManager(t *testing.T) {
 	cfg := &config.Config{
 		WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
@@ -258,11 +268,11 @@ func TestCreateTransaction(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	to := "EQTestAddress123"
+	to := testLotteryAddress
 	amount := int64(1000000000) // 1 TON
 	payload := []byte("test payload")
 
-	transaction, err := manager.CreateTransaction(to, amount, payload)
+	transaction, err := manager.CreateTransaction(to, amount, payload, 7)
 	if err != nil {
 		t.Fatalf("CreateTransaction() failed: %v", err)
 	}
@@ -271,16 +281,42 @@ func TestCreateTransaction(t *testing.T) {
 		t.Error("Expected non-empty transaction")
 	}
 
-	// 檢查序號是否增加
-	initialSeqno := manager.seqno
-	_, err = manager.CreateTransaction(to, amount, payload)
+	// 交易應為合法的外部訊息，且簽名覆蓋簽名內容雜湊
+	msg := decodeExternalMessage(t, transaction, manager.GetVersion())
+
+	if !ed25519.Verify(manager.publicKey, msg.signing.Hash(), msg.signature) {
+		t.Error("Expected signature to verify against signing message hash")
+	}
+
+	if msg.seqno != 7 {
+		t.Errorf("Expected seqno=7 in message, got %d", msg.seqno)
+	}
+
+	if comment := decodeComment(t, msg.body); comment != string(payload) {
+		t.Errorf("Expected comment %q, got %q", payload, comment)
+	}
+
+	// 創建交易不應改變本地 seqno，seqno 只在保留提交後前進
+	if seqno, synced := manager.GetSeqno(); seqno != 0 || synced {
+		t.Errorf("Expected CreateTransaction() not to touch seqno state, got seqno=%d synced=%v", seqno, synced)
+	}
+}
+
+func TestCreateTransactionInvalidAddress(t *testing.T) {
+	cfg := &config.Config{
+		WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		LogLevel:         "debug",
+	}
+	log := logger.New(cfg.LogLevel)
+
+	manager, err := NewManager(cfg, log)
 	if err != nil {
-		t.Fatalf("Second CreateTransaction() failed: %v", err)
+		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	if manager.seqno != initialSeqno+1 {
-		t.Errorf("Expected seqno to increment from %d to %d, got %d",
-			initialSeqno, initialSeqno+1, manager.seqno)
+	_, err = manager.CreateTransaction("EQTestAddress123", 1000000000, []byte("test payload"), 1)
+	if err == nil {
+		t.Fatal("Expected CreateTransaction() to fail with malformed address")
 	}
 }
 
@@ -296,8 +332,8 @@ func TestCreateDrawWinnerTransaction(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	contractAddress := "EQLotteryContract123"
-	transaction, err := manager.CreateDrawWinnerTransaction(contractAddress)
+	contractAddress := testLotteryAddress
+	transaction, err := manager.CreateDrawWinnerTransaction(contractAddress, 1)
 	if err != nil {
 		t.Fatalf("CreateDrawWinnerTransaction() failed: %v", err)
 	}
@@ -319,8 +355,8 @@ func TestCreateStartNewRoundTransaction(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	contractAddress := "EQLotteryContract123"
-	transaction, err := manager.CreateStartNewRoundTransaction(contractAddress)
+	contractAddress := testLotteryAddress
+	transaction, err := manager.CreateStartNewRoundTransaction(contractAddress, 1)
 	if err != nil {
 		t.Fatalf("CreateStartNewRoundTransaction() failed: %v", err)
 	}
@@ -330,6 +366,29 @@ func TestCreateStartNewRoundTransaction(t *testing.T) {
 	}
 }
 
+func TestCreateWithdrawTransaction(t *testing.T) {
+	cfg := &config.Config{
+		WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		LogLevel:         "debug",
+	}
+	log := logger.New(cfg.LogLevel)
+
+	manager, err := NewManager(cfg, log)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	transaction, err := manager.CreateWithdrawTransaction(testLotteryAddress, 1)
+	if err != nil {
+		t.Fatalf("CreateWithdrawTransaction() failed: %v", err)
+	}
+
+	msg := decodeExternalMessage(t, transaction, manager.GetVersion())
+	if comment := decodeComment(t, msg.body); comment != "withdraw" {
+		t.Errorf("Expected comment 'withdraw', got %q", comment)
+	}
+}
+
 func TestCreateSetNFTContractTransaction(t *testing.T) {
 	cfg := &config.Config{
 		WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
@@ -342,10 +401,10 @@ func TestCreateSetNFTContractTransaction(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	contractAddress := "EQLotteryContract123"
-	nftAddress := "EQNFTContract456"
+	contractAddress := testLotteryAddress
+	nftAddress := testNFTAddress
 
-	transaction, err := manager.CreateSetNFTContractTransaction(contractAddress, nftAddress)
+	transaction, err := manager.CreateSetNFTContractTransaction(contractAddress, nftAddress, 1)
 	if err != nil {
 		t.Fatalf("CreateSetNFTContractTransaction() failed: %v", err)
 	}
@@ -367,7 +426,7 @@ func TestGenerateAddress(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	address1 := manager.generateAddress()
+	address1 := manager.GetAddress()
 
 	// 使用不同的私鑰創建另一個管理器
 	cfg2 := &config.Config{
@@ -380,7 +439,7 @@ func TestGenerateAddress(t *testing.T) {
 		t.Fatalf("NewManager() for second key failed: %v", err)
 	}
 
-	address2 := manager2.generateAddress()
+	address2 := manager2.GetAddress()
 
 	// 不同的私鑰應該產生不同的地址
 	if address1 == address2 {
@@ -395,24 +454,150 @@ func TestGenerateAddress(t *testing.T) {
 	if !strings.HasPrefix(address2, "EQ") {
 		t.Errorf("Expected address to start with 'EQ', got %s", address2)
 	}
+
+	// 地址應該是合法的使用者友善格式
+	parsed, err := address.Parse(address1)
+	if err != nil {
+		t.Fatalf("Expected generated address to be parseable, got %v", err)
+	}
+
+	// 帳戶 ID 應為 StateInit 的雜湊
+	if string(parsed.Data()) != string(manager.GetStateInit().Hash()) {
+		t.Error("Expected address to be derived from StateInit hash")
+	}
+
+	// 重新生成應得到相同地址
+	if err := manager.generateAddress(); err != nil {
+		t.Fatalf("generateAddress() failed: %v", err)
+	}
+	if manager.GetAddress() != address1 {
+		t.Errorf("Expected deterministic address, got %s and %s", address1, manager.GetAddress())
+	}
 }
 
-func TestLoadFromMnemonic(t *testing.T) {
-	cfg := &config.Config{
-		WalletMnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
-		LogLevel:       "debug",
+func TestWalletVersionAndSubwallet(t *testing.T) {
+	log := logger.New("debug")
+	privateKey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	newManager := func(version string, subwallet uint32) *Manager {
+		t.Helper()
+		manager, err := NewManager(&config.Config{
+			WalletPrivateKey:  privateKey,
+			WalletVersion:     version,
+			WalletSubwalletID: subwallet,
+			TONNetwork:        "testnet",
+		}, log)
+		if err != nil {
+			t.Fatalf("NewManager(%q, %d) failed: %v", version, subwallet, err)
+		}
+		return manager
+	}
+
+	defaultManager := newManager("", 0)
+	if defaultManager.GetVersion() != VersionV4R2 {
+		t.Errorf("Expected default version v4r2, got %s", defaultManager.GetVersion())
+	}
+	if defaultManager.GetWalletID() != DefaultSubwalletID {
+		t.Errorf("Expected default wallet id %d, got %d", DefaultSubwalletID, defaultManager.GetWalletID())
+	}
+
+	if newManager("v4r2", 0).GetAddress() != defaultManager.GetAddress() {
+		t.Error("Expected explicit v4r2 to match default version")
+	}
+
+	// 不同版本或 subwallet 應產生不同地址
+	seen := map[string]string{}
+	for _, tc := range []struct {
+		version   string
+		subwallet uint32
+	}{
+		{"v3r2", 0},
+		{"v4r2", 0},
+		{"v4r2", 1},
+		{"v5r1", 0},
+		{"v5r1", 1},
+	} {
+		addr := newManager(tc.version, tc.subwallet).GetAddress()
+		key := fmt.Sprintf("%s/%d", tc.version, tc.subwallet)
+		for other, otherAddr := range seen {
+			if otherAddr == addr {
+				t.Errorf("Expected %s and %s to have different addresses", key, other)
+			}
+		}
+		seen[key] = addr
 	}
-	log := logger.New(cfg.LogLevel)
 
-	// 由於 loadFromMnemonic 尚未實作，應該返回錯誤
-	_, err := NewManager(cfg, log)
+	_, err := NewManager(&config.Config{
+		WalletPrivateKey: privateKey,
+		WalletVersion:    "v2r1",
+	}, log)
 	if err == nil {
-		t.Fatal("Expected NewManager() to fail with unimplemented mnemonic loading")
+		t.Error("Expected NewManager() to fail with unsupported wallet version")
 	}
+}
 
-	if !strings.Contains(err.Error(), "助記詞載入功能尚未實作") {
-		t.Errorf("Expected specific error message about unimplemented mnemonic, got: %v", err)
-	}
+func TestLoadFromMnemonic(t *testing.T) {
+	log := logger.New("debug")
+
+	t.Run("basic mnemonic", func(t *testing.T) {
+		manager, err := NewManager(&config.Config{WalletMnemonic: testMnemonic}, log)
+		if err != nil {
+			t.Fatalf("NewManager() failed: %v", err)
+		}
+
+		// 與以相同種子作為私鑰載入的錢包一致
+		fromKey, err := NewManager(&config.Config{WalletPrivateKey: testMnemonicSeed}, log)
+		if err != nil {
+			t.Fatalf("NewManager() from seed failed: %v", err)
+		}
+		if manager.GetAddress() != fromKey.GetAddress() {
+			t.Errorf("Expected address %s, got %s", fromKey.GetAddress(), manager.GetAddress())
+		}
+	})
+
+	t.Run("password-protected mnemonic", func(t *testing.T) {
+		manager, err := NewManager(&config.Config{
+			WalletMnemonic:         testPasswordMnemonic,
+			WalletMnemonicPassword: testMnemonicPassword,
+		}, log)
+		if err != nil {
+			t.Fatalf("NewManager() failed: %v", err)
+		}
+		if manager.GetAddress() == "" {
+			t.Error("Expected non-empty address")
+		}
+	})
+
+	t.Run("invalid mnemonic", func(t *testing.T) {
+		cfg := &config.Config{
+			WalletMnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		}
+
+		_, err := NewManager(cfg, log)
+		if err == nil {
+			t.Fatal("Expected NewManager() to fail with invalid mnemonic")
+		}
+		if !strings.Contains(err.Error(), "助記詞無效") {
+			t.Errorf("Expected mnemonic validation error, got: %v", err)
+		}
+	})
+
+	t.Run("missing password", func(t *testing.T) {
+		_, err := NewManager(&config.Config{WalletMnemonic: testPasswordMnemonic}, log)
+		if !errors.Is(err, ErrMnemonicPasswordRequired) {
+			t.Errorf("Expected ErrMnemonicPasswordRequired, got: %v", err)
+		}
+	})
+}
+
+// fakeBalanceReader 測試用的餘額查詢來源
+type fakeBalanceReader struct {
+	balances map[string]int64
+	err      error
+}
+
+func (f *fakeBalanceReader) GetAddressBalance(ctx context.Context, addr string) (int64, error) {
+	return f.balances[addr], f.err
 }
 
 func TestGetBalance(t *testing.T) {
@@ -427,13 +612,27 @@ func TestGetBalance(t *testing.T) {
 		t.Fatalf("NewManager() failed: %v", err)
 	}
 
-	// 由於 GetBalance 尚未實作，應該返回錯誤
-	_, err = manager.GetBalance()
-	if err == nil {
-		t.Fatal("Expected GetBalance() to fail with unimplemented balance query")
+	ctx := context.Background()
+
+	// 未設定查詢來源時應該返回錯誤
+	if _, err := manager.GetBalance(ctx); err == nil {
+		t.Fatal("Expected GetBalance() to fail without balance reader")
+	}
+
+	manager.SetBalanceReader(&fakeBalanceReader{
+		balances: map[string]int64{manager.GetAddress(): 1500000000},
+	})
+
+	balance, err := manager.GetBalance(ctx)
+	if err != nil {
+		t.Fatalf("GetBalance() failed: %v", err)
+	}
+	if balance != 1500000000 {
+		t.Errorf("Expected balance=1500000000, got %d", balance)
 	}
 
-	if !strings.Contains(err.Error(), "餘額查詢功能尚未實作") {
-		t.Errorf("Expected specific error message about unimplemented balance query, got: %v", err)
+	manager.SetBalanceReader(&fakeBalanceReader{err: errors.New("network down")})
+	if _, err := manager.GetBalance(ctx); err == nil || !strings.Contains(err.Error(), "network down") {
+		t.Errorf("Expected GetBalance() to wrap reader error, got %v", err)
 	}
 }
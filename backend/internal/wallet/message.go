@@ -0,0 +1,202 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+)
+
+// 發送模式 (send mode) 旗標
+const (
+	SendModePayFeesSeparately uint8 = 1 // 手續費另外從錢包餘額支付
+	SendModeIgnoreErrors      uint8 = 2 // 忽略動作階段的錯誤
+
+	// DefaultSendMode 錢包外部訊息預設使用的發送模式
+	DefaultSendMode = SendModePayFeesSeparately | SendModeIgnoreErrors
+)
+
+// DefaultMessageTTL 外部訊息的預設有效期限 (valid_until = 現在 + TTL)
+const DefaultMessageTTL = 60 * time.Second
+
+const (
+	// v4r2 錢包的簡單轉帳操作
+	opV4SimpleSend uint8 = 0
+
+	// v5r1 錢包的外部簽名請求與發送訊息動作
+	opV5SignedExternal uint32 = 0x7369676e
+	opV5ActionSendMsg  uint32 = 0x0ec3c86d
+)
+
+// String 返回消息類型的名稱
+func (t MessageType) String() string {
+	switch t {
+	case MessageTypeText:
+		return "text"
+	case MessageTypeDrawWinner:
+		return "drawWinner"
+	case MessageTypeStartNewRound:
+		return "startNewRound"
+	case MessageTypeSetNFTContract:
+		return "setNFTContract"
+	case MessageTypeWithdraw:
+		return "withdraw"
+	default:
+		return fmt.Sprintf("MessageType(%d)", int(t))
+	}
+}
+
+// buildMessageBody 依消息類型構建內部訊息的 body
+//
+// 抽獎合約的管理操作使用文字評論 (op = 0 + 指令字串)；
+// SetNFTContract 為 Tact 訊息，payload 為 NFT 合約地址；MessageTypeText 直接將 payload 作為評論。
+func buildMessageBody(msgType MessageType, payload []byte) (*cell.Cell, error) {
+	switch msgType {
+	case MessageTypeText:
+		return buildCommentCell(string(payload))
+
+	case MessageTypeDrawWinner, MessageTypeStartNewRound, MessageTypeWithdraw:
+		return buildCommentCell(msgType.String())
+
+	case MessageTypeSetNFTContract:
+		nftContract, err := address.Parse(string(payload))
+		if err != nil {
+			return nil, fmt.Errorf("無效的 NFT 合約地址: %w", err)
+		}
+		return cell.BeginCell().
+			StoreUInt(uint64(ton.OpSetNFTContract), 32).
+			StoreAddress(nftContract).
+			EndCell()
+
+	default:
+		return nil, fmt.Errorf("不支援的消息類型: %s", msgType)
+	}
+}
+
+// buildCommentCell 構建文字評論 Cell (op = 0)
+func buildCommentCell(comment string) (*cell.Cell, error) {
+	return cell.BeginCell().
+		StoreUInt(uint64(ton.OpComment), 32).
+		StoreStringSnake(comment).
+		EndCell()
+}
+
+// buildInternalMessage 構建由錢包發出的內部訊息 (MessageRelaxed)
+func buildInternalMessage(to *address.Address, amount uint64, body *cell.Cell) (*cell.Cell, error) {
+	return cell.BeginCell().
+		StoreUInt(0, 1).                // int_msg_info$0
+		StoreBool(true).                // ihr_disabled
+		StoreBool(to.IsBounceable()).   // bounce
+		StoreBool(false).               // bounced
+		StoreAddress(nil).              // src: 由錢包合約填入
+		StoreAddress(to).               // dest
+		StoreCoins(amount).             // value
+		StoreBool(false).               // extra currencies: 空字典
+		StoreCoins(0).                  // ihr_fee
+		StoreCoins(0).                  // fwd_fee
+		StoreUInt(0, 64).               // created_lt
+		StoreUInt(0, 32).               // created_at
+		StoreBool(false).               // init: 無
+		StoreBool(true).StoreRef(body). // body: 以 ref 形式存放
+		EndCell()
+}
+
+// buildSigningMessage 構建各版本錢包需要簽名的訊息內容
+func buildSigningMessage(version Version, walletID, validUntil, seqno uint32, mode uint8, msg *cell.Cell) (*cell.Cell, error) {
+	switch version {
+	case VersionV3R2:
+		return cell.BeginCell().
+			StoreUInt(uint64(walletID), 32).
+			StoreUInt(uint64(validUntil), 32).
+			StoreUInt(uint64(seqno), 32).
+			StoreUInt(uint64(mode), 8).
+			StoreRef(msg).
+			EndCell()
+
+	case VersionV4R2:
+		return cell.BeginCell().
+			StoreUInt(uint64(walletID), 32).
+			StoreUInt(uint64(validUntil), 32).
+			StoreUInt(uint64(seqno), 32).
+			StoreUInt(uint64(opV4SimpleSend), 8).
+			StoreUInt(uint64(mode), 8).
+			StoreRef(msg).
+			EndCell()
+
+	case VersionV5R1:
+		// out_list$_ prev:^(OutList n) action:OutAction，僅包含一個 action_send_msg
+		empty, err := cell.BeginCell().EndCell()
+		if err != nil {
+			return nil, err
+		}
+		actions, err := cell.BeginCell().
+			StoreRef(empty).
+			StoreUInt(uint64(opV5ActionSendMsg), 32).
+			StoreUInt(uint64(mode), 8).
+			StoreRef(msg).
+			EndCell()
+		if err != nil {
+			return nil, err
+		}
+
+		return cell.BeginCell().
+			StoreUInt(uint64(opV5SignedExternal), 32).
+			StoreUInt(uint64(walletID), 32).
+			StoreUInt(uint64(validUntil), 32).
+			StoreUInt(uint64(seqno), 32).
+			StoreMaybeRef(actions).
+			StoreBool(false). // 無擴充動作
+			EndCell()
+
+	default:
+		return nil, fmt.Errorf("不支援的錢包版本: %s", version)
+	}
+}
+
+// buildSignedBody 組合簽名與簽名內容
+//
+// v3/v4 將簽名放在最前面；v5r1 則將簽名附加在最後。
+func buildSignedBody(version Version, signing *cell.Cell, signature []byte) (*cell.Cell, error) {
+	if version == VersionV5R1 {
+		return cell.BeginCell().
+			StoreCell(signing).
+			StoreBytes(signature).
+			EndCell()
+	}
+
+	return cell.BeginCell().
+		StoreBytes(signature).
+		StoreCell(signing).
+		EndCell()
+}
+
+// buildExternalMessage 構建發送給錢包合約的外部訊息
+//
+// stateInit 不為 nil 時會一併附帶，用於錢包尚未部署 (seqno = 0) 的情況。
+func buildExternalMessage(wallet *address.Address, stateInit, body *cell.Cell) (*cell.Cell, error) {
+	b := cell.BeginCell().
+		StoreUInt(0b10, 2).   // ext_in_msg_info$10
+		StoreAddress(nil).    // src: addr_none
+		StoreAddress(wallet). // dest
+		StoreCoins(0)         // import_fee
+
+	if stateInit != nil {
+		b.StoreBool(true).StoreBool(true).StoreRef(stateInit) // init: Just (Right ^StateInit)
+	} else {
+		b.StoreBool(false)
+	}
+
+	return b.StoreBool(true).StoreRef(body).EndCell() // body: Right ^X
+}
+
+// validUntilFor 計算外部訊息的 valid_until
+//
+// 與官方錢包實作一致，尚未部署的錢包 (seqno = 0) 使用最大值。
+func validUntilFor(seqno uint32, now time.Time, ttl time.Duration) uint32 {
+	if seqno == 0 {
+		return ^uint32(0)
+	}
+	return uint32(now.Add(ttl).Unix())
+}
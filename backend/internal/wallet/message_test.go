@@ -0,0 +1,338 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton/address"
+	"ton-cat-lottery-backend/internal/ton/cell"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// decodedMessage 測試用的外部訊息解析結果
+type decodedMessage struct {
+	wallet     *address.Address
+	stateInit  *cell.Cell
+	signature  []byte
+	signing    *cell.Cell
+	walletID   uint32
+	validUntil uint32
+	seqno      uint32
+	mode       uint8
+
+	bounce      bool
+	destination *address.Address
+	amount      uint64
+	body        *cell.Cell
+}
+
+// decodeExternalMessage 依錢包版本解析外部訊息 BOC
+func decodeExternalMessage(t *testing.T, boc []byte, version Version) *decodedMessage {
+	t.Helper()
+
+	root, err := cell.FromBOC(boc)
+	if err != nil {
+		t.Fatalf("Expected message to be a valid BOC: %v", err)
+	}
+
+	var msg decodedMessage
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+	}
+
+	s := root.BeginParse()
+	tag, err := s.LoadUInt(2)
+	must(err)
+	if tag != 0b10 {
+		t.Fatalf("Expected ext_in_msg_info$10, got %b", tag)
+	}
+	src, err := s.LoadAddress()
+	must(err)
+	if src != nil {
+		t.Errorf("Expected addr_none source, got %s", src)
+	}
+	msg.wallet, err = s.LoadAddress()
+	must(err)
+	importFee, err := s.LoadCoins()
+	must(err)
+	if importFee != 0 {
+		t.Errorf("Expected import_fee=0, got %d", importFee)
+	}
+	hasInit, err := s.LoadBool()
+	must(err)
+	if hasInit {
+		isRef, err := s.LoadBool()
+		must(err)
+		if !isRef {
+			t.Fatal("Expected StateInit to be stored as ref")
+		}
+		msg.stateInit, err = s.LoadRef()
+		must(err)
+	}
+	bodyIsRef, err := s.LoadBool()
+	must(err)
+	if !bodyIsRef {
+		t.Fatal("Expected body to be stored as ref")
+	}
+	signedBody, err := s.LoadRef()
+	must(err)
+
+	// 拆分簽名與簽名內容
+	bs := signedBody.BeginParse()
+	if version == VersionV5R1 {
+		bits := bs.BitsLeft() - ed25519.SignatureSize*8
+		data, err := bs.LoadSlice(bits)
+		must(err)
+		msg.signature, err = bs.LoadBytes(ed25519.SignatureSize)
+		must(err)
+		b := cell.BeginCell().StoreSlice(data, bits)
+		for bs.RefsLeft() > 0 {
+			ref, err := bs.LoadRef()
+			must(err)
+			b.StoreRef(ref)
+		}
+		msg.signing, err = b.EndCell()
+		must(err)
+	} else {
+		msg.signature, err = bs.LoadBytes(ed25519.SignatureSize)
+		must(err)
+		msg.signing, err = bs.ToCell()
+		must(err)
+	}
+
+	ss := msg.signing.BeginParse()
+	if version == VersionV5R1 {
+		op, err := ss.LoadUInt(32)
+		must(err)
+		if uint32(op) != opV5SignedExternal {
+			t.Errorf("Expected signed external op, got %x", op)
+		}
+	}
+	v, err := ss.LoadUInt(32)
+	must(err)
+	msg.walletID = uint32(v)
+	v, err = ss.LoadUInt(32)
+	must(err)
+	msg.validUntil = uint32(v)
+	v, err = ss.LoadUInt(32)
+	must(err)
+	msg.seqno = uint32(v)
+
+	var internal *cell.Cell
+	switch version {
+	case VersionV3R2, VersionV4R2:
+		if version == VersionV4R2 {
+			op, err := ss.LoadUInt(8)
+			must(err)
+			if op != 0 {
+				t.Errorf("Expected v4 simple send op=0, got %d", op)
+			}
+		}
+		v, err = ss.LoadUInt(8)
+		must(err)
+		msg.mode = uint8(v)
+		internal, err = ss.LoadRef()
+		must(err)
+	case VersionV5R1:
+		actions, err := ss.LoadMaybeRef()
+		must(err)
+		if actions == nil {
+			t.Fatal("Expected out actions")
+		}
+		as := actions.BeginParse()
+		prev, err := as.LoadRef()
+		must(err)
+		if prev.BitsSize() != 0 || prev.RefsNum() != 0 {
+			t.Error("Expected a single out action")
+		}
+		op, err := as.LoadUInt(32)
+		must(err)
+		if uint32(op) != opV5ActionSendMsg {
+			t.Errorf("Expected action_send_msg, got %x", op)
+		}
+		v, err = as.LoadUInt(8)
+		must(err)
+		msg.mode = uint8(v)
+		internal, err = as.LoadRef()
+		must(err)
+	}
+
+	// 內部訊息
+	is := internal.BeginParse()
+	if tag, err := is.LoadUInt(1); err != nil || tag != 0 {
+		t.Fatalf("Expected int_msg_info$0, got %d (err=%v)", tag, err)
+	}
+	_, err = is.LoadBool() // ihr_disabled
+	must(err)
+	msg.bounce, err = is.LoadBool()
+	must(err)
+	_, err = is.LoadBool() // bounced
+	must(err)
+	_, err = is.LoadAddress() // src
+	must(err)
+	msg.destination, err = is.LoadAddress()
+	must(err)
+	msg.amount, err = is.LoadCoins()
+	must(err)
+	_, err = is.LoadBool() // extra currencies
+	must(err)
+	_, err = is.LoadCoins() // ihr_fee
+	must(err)
+	_, err = is.LoadCoins() // fwd_fee
+	must(err)
+	_, err = is.LoadUInt(64)
+	must(err)
+	_, err = is.LoadUInt(32)
+	must(err)
+	_, err = is.LoadBool() // init
+	must(err)
+	if isRef, err := is.LoadBool(); err != nil || !isRef {
+		t.Fatalf("Expected internal body as ref (err=%v)", err)
+	}
+	msg.body, err = is.LoadRef()
+	must(err)
+
+	return &msg
+}
+
+// decodeComment 解析文字評論 body
+func decodeComment(t *testing.T, body *cell.Cell) string {
+	t.Helper()
+
+	s := body.BeginParse()
+	if op, err := s.LoadUInt(32); err != nil || op != 0 {
+		t.Fatalf("Expected comment op=0, got %d (err=%v)", op, err)
+	}
+	comment, err := s.LoadStringSnake()
+	if err != nil {
+		t.Fatalf("Failed to load comment: %v", err)
+	}
+	return comment
+}
+
+func TestExternalMessageLayout(t *testing.T) {
+	log := logger.New("debug")
+
+	for _, version := range []Version{VersionV3R2, VersionV4R2, VersionV5R1} {
+		t.Run(string(version), func(t *testing.T) {
+			manager, err := NewManager(&config.Config{
+				WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				WalletVersion:    string(version),
+				TONNetwork:       "testnet",
+			}, log)
+			if err != nil {
+				t.Fatalf("NewManager() failed: %v", err)
+			}
+
+			// seqno = 0：錢包尚未部署，需附帶 StateInit
+			first, err := manager.CreateDrawWinnerTransaction(testLotteryAddress, 0)
+			if err != nil {
+				t.Fatalf("CreateDrawWinnerTransaction() failed: %v", err)
+			}
+
+			msg := decodeExternalMessage(t, first, version)
+
+			if msg.wallet.String() != manager.GetAddress() {
+				t.Errorf("Expected destination wallet %s, got %s", manager.GetAddress(), msg.wallet)
+			}
+			if msg.stateInit == nil || string(msg.stateInit.Hash()) != string(manager.GetStateInit().Hash()) {
+				t.Error("Expected first message to carry wallet StateInit")
+			}
+			if !ed25519.Verify(manager.GetPublicKey(), msg.signing.Hash(), msg.signature) {
+				t.Error("Expected signature to verify against signing message hash")
+			}
+			if msg.walletID != manager.GetWalletID() {
+				t.Errorf("Expected wallet id=%d, got %d", manager.GetWalletID(), msg.walletID)
+			}
+			if msg.seqno != 0 || msg.validUntil != ^uint32(0) {
+				t.Errorf("Expected seqno=0 and max valid_until, got seqno=%d valid_until=%d", msg.seqno, msg.validUntil)
+			}
+			if msg.mode != DefaultSendMode {
+				t.Errorf("Expected mode=%d, got %d", DefaultSendMode, msg.mode)
+			}
+			if !msg.destination.Equals(address.MustParse(testLotteryAddress)) || !msg.bounce {
+				t.Errorf("Expected bounceable message to lottery contract, got %s (bounce=%v)", msg.destination, msg.bounce)
+			}
+			if msg.amount != 50000000 {
+				t.Errorf("Expected amount=50000000, got %d", msg.amount)
+			}
+			if comment := decodeComment(t, msg.body); comment != "drawWinner" {
+				t.Errorf("Expected comment 'drawWinner', got %q", comment)
+			}
+
+			// seqno = 1：不再附帶 StateInit，valid_until 為目前時間加上 TTL
+			second, err := manager.CreateStartNewRoundTransaction(testLotteryAddress, 1)
+			if err != nil {
+				t.Fatalf("CreateStartNewRoundTransaction() failed: %v", err)
+			}
+
+			msg = decodeExternalMessage(t, second, version)
+
+			if msg.stateInit != nil {
+				t.Error("Expected deployed wallet message without StateInit")
+			}
+			if msg.seqno != 1 {
+				t.Errorf("Expected seqno=1, got %d", msg.seqno)
+			}
+			expiry := time.Now().Add(DefaultMessageTTL).Unix()
+			if diff := expiry - int64(msg.validUntil); diff < 0 || diff > 5 {
+				t.Errorf("Expected valid_until close to %d, got %d", expiry, msg.validUntil)
+			}
+			if comment := decodeComment(t, msg.body); comment != "startNewRound" {
+				t.Errorf("Expected comment 'startNewRound', got %q", comment)
+			}
+		})
+	}
+}
+
+func TestBuildMessageBody(t *testing.T) {
+	tests := []struct {
+		msgType MessageType
+		payload []byte
+		comment string
+	}{
+		{MessageTypeText, []byte("喵"), "喵"},
+		{MessageTypeDrawWinner, nil, "drawWinner"},
+		{MessageTypeStartNewRound, nil, "startNewRound"},
+		{MessageTypeWithdraw, nil, "withdraw"},
+	}
+
+	for _, tt := range tests {
+		body, err := buildMessageBody(tt.msgType, tt.payload)
+		if err != nil {
+			t.Fatalf("buildMessageBody(%s) failed: %v", tt.msgType, err)
+		}
+		if comment := decodeComment(t, body); comment != tt.comment {
+			t.Errorf("Expected %s comment %q, got %q", tt.msgType, tt.comment, comment)
+		}
+	}
+
+	t.Run("SetNFTContract", func(t *testing.T) {
+		body, err := buildMessageBody(MessageTypeSetNFTContract, []byte(testNFTAddress))
+		if err != nil {
+			t.Fatalf("buildMessageBody() failed: %v", err)
+		}
+
+		s := body.BeginParse()
+		if op, err := s.LoadUInt(32); err != nil || op != 0xd9d13f8c {
+			t.Errorf("Expected SetNFTContract op=0xd9d13f8c, got %x (err=%v)", op, err)
+		}
+		nft, err := s.LoadAddress()
+		if err != nil || !nft.Equals(address.MustParse(testNFTAddress)) {
+			t.Errorf("Expected NFT address %s, got %v (err=%v)", testNFTAddress, nft, err)
+		}
+
+		if _, err := buildMessageBody(MessageTypeSetNFTContract, []byte("EQInvalid")); err == nil {
+			t.Error("Expected buildMessageBody() to fail with invalid NFT address")
+		}
+	})
+
+	if _, err := buildMessageBody(MessageType(99), nil); err == nil {
+		t.Error("Expected buildMessageBody() to fail with unknown message type")
+	}
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MnemonicWordCount TON 助記詞單字數量（與 Tonkeeper 等錢包匯出的格式一致）
+const MnemonicWordCount = 24
+
+const (
+	// pbkdfIterations 私鑰種子的 PBKDF2 迭代次數
+	pbkdfIterations = 100000
+
+	// 各用途的 PBKDF2 salt
+	saltDefaultSeed     = "TON default seed"
+	saltSeedVersion     = "TON seed version"
+	saltFastSeedVersion = "TON fast seed version"
+)
+
+var (
+	// ErrMnemonicPasswordRequired 助記詞受密碼保護，但未提供密碼
+	ErrMnemonicPasswordRequired = errors.New("此助記詞受密碼保護，請設定 WALLET_MNEMONIC_PASSWORD")
+
+	// ErrMnemonicPasswordUnexpected 助記詞未受密碼保護，卻提供了密碼
+	ErrMnemonicPasswordUnexpected = errors.New("此助記詞未受密碼保護，請移除 WALLET_MNEMONIC_PASSWORD")
+
+	// ErrInvalidMnemonic 助記詞校驗失敗（單字順序錯誤、密碼錯誤或非 TON 助記詞）
+	ErrInvalidMnemonic = errors.New("無效的 TON 助記詞")
+)
+
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(wordlist))
+	for i, word := range wordlist {
+		index[word] = i
+	}
+	return index
+}()
+
+// NormalizeMnemonic 將助記詞字串拆分為單字，並統一為小寫
+func NormalizeMnemonic(mnemonic string) []string {
+	words := strings.Fields(mnemonic)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return words
+}
+
+// ValidateMnemonic 驗證助記詞是否為合法的 TON 助記詞
+//
+// 檢查單字數量與詞表，並依是否提供密碼分別驗證一般助記詞或受密碼保護的助記詞。
+// 錯誤訊息只包含單字位置，不會洩漏助記詞內容。
+func ValidateMnemonic(words []string, password string) error {
+	if len(words) != MnemonicWordCount {
+		return fmt.Errorf("助記詞必須為 %d 個單字，實際 %d 個", MnemonicWordCount, len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := wordIndex[word]; !ok {
+			return fmt.Errorf("助記詞第 %d 個單字不在詞表中", i+1)
+		}
+	}
+
+	passwordNeeded := isPasswordNeeded(words)
+	if password == "" && passwordNeeded {
+		return ErrMnemonicPasswordRequired
+	}
+	if password != "" && !passwordNeeded {
+		return ErrMnemonicPasswordUnexpected
+	}
+
+	if !isBasicSeed(mnemonicToEntropy(words, password)) {
+		return ErrInvalidMnemonic
+	}
+
+	return nil
+}
+
+// MnemonicToPrivateKey 由助記詞（及可選的密碼）推導 Ed25519 私鑰
+func MnemonicToPrivateKey(mnemonic, password string) (ed25519.PrivateKey, error) {
+	words := NormalizeMnemonic(mnemonic)
+	if err := ValidateMnemonic(words, password); err != nil {
+		return nil, err
+	}
+
+	entropy := mnemonicToEntropy(words, password)
+	seed := pbkdf2SHA512(entropy, []byte(saltDefaultSeed), pbkdfIterations, 64)
+
+	return ed25519.NewKeyFromSeed(seed[:ed25519.SeedSize]), nil
+}
+
+// mnemonicToEntropy 以助記詞為金鑰、密碼為訊息計算 HMAC-SHA512 熵
+func mnemonicToEntropy(words []string, password string) []byte {
+	mac := hmac.New(sha512.New, []byte(strings.Join(words, " ")))
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+// isBasicSeed 熵是否對應一般（可直接使用的）助記詞
+func isBasicSeed(entropy []byte) bool {
+	iterations := pbkdfIterations / 256
+	seed := pbkdf2SHA512(entropy, []byte(saltSeedVersion), iterations, 64)
+	return seed[0] == 0
+}
+
+// isPasswordSeed 熵是否對應受密碼保護的助記詞
+func isPasswordSeed(entropy []byte) bool {
+	seed := pbkdf2SHA512(entropy, []byte(saltFastSeedVersion), 1, 64)
+	return seed[0] == 1
+}
+
+// isPasswordNeeded 助記詞是否需要密碼才能使用
+func isPasswordNeeded(words []string) bool {
+	entropy := mnemonicToEntropy(words, "")
+	return isPasswordSeed(entropy) && !isBasicSeed(entropy)
+}
+
+// pbkdf2SHA512 以 HMAC-SHA512 為 PRF 的 PBKDF2 (RFC 8018)
+func pbkdf2SHA512(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha512.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	t := make([]byte, hashLen)
+
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, uint32(block)))
+		u = prf.Sum(u[:0])
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
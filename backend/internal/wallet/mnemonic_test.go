@@ -0,0 +1,124 @@
+package wallet
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 測試用助記詞（僅用於測試，切勿用於真實資產）
+const (
+	testMnemonic     = "couch column park quote prison bullet worth hip economy fox dune captain evolve piece payment jungle few mule sunny upon share submit crouch away"
+	testMnemonicSeed = "bca6fc2fbb3494c3dd605ef7a82609bd334e785ee54c116d1f080d7f34619a99"
+
+	testPasswordMnemonic     = "remind picture dice silent window else window render divide border video small joke inflict drink rate hockey transfer elevator flight best slight brick desk"
+	testMnemonicPassword     = "meow"
+	testPasswordMnemonicSeed = "9894197cf1c65e9e2a663c8b5d3b0dc271a34681eecbb5b77f3a0e43a34a52f3"
+)
+
+func TestMnemonicToPrivateKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		password string
+		seed     string
+	}{
+		{"basic mnemonic", testMnemonic, "", testMnemonicSeed},
+		{"password-protected mnemonic", testPasswordMnemonic, testMnemonicPassword, testPasswordMnemonicSeed},
+		{"extra whitespace and upper case", "  " + strings.ToUpper(testMnemonic) + "\n", "", testMnemonicSeed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, err := MnemonicToPrivateKey(tt.mnemonic, tt.password)
+			if err != nil {
+				t.Fatalf("MnemonicToPrivateKey() failed: %v", err)
+			}
+			if got := hex.EncodeToString(privateKey.Seed()); got != tt.seed {
+				t.Errorf("Expected seed=%s, got %s", tt.seed, got)
+			}
+		})
+	}
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	words := NormalizeMnemonic(testMnemonic)
+	passwordWords := NormalizeMnemonic(testPasswordMnemonic)
+
+	swapped := append([]string(nil), words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	unknown := append([]string(nil), words...)
+	unknown[5] = "meow"
+
+	tests := []struct {
+		name     string
+		words    []string
+		password string
+		wantErr  error
+		contains string
+	}{
+		{name: "valid basic mnemonic", words: words},
+		{name: "valid password mnemonic", words: passwordWords, password: testMnemonicPassword},
+		{name: "wrong word count", words: words[:12], contains: "24 個單字"},
+		{name: "word not in wordlist", words: unknown, contains: "第 6 個單字"},
+		{name: "password missing", words: passwordWords, wantErr: ErrMnemonicPasswordRequired},
+		{name: "unexpected password", words: words, password: "meow", wantErr: ErrMnemonicPasswordUnexpected},
+		{name: "wrong password", words: passwordWords, password: "woof", wantErr: ErrInvalidMnemonic},
+		{name: "wrong word order", words: swapped, wantErr: ErrInvalidMnemonic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMnemonic(tt.words, tt.password)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.contains != "":
+				if err == nil || !strings.Contains(err.Error(), tt.contains) {
+					t.Errorf("Expected error containing %q, got %v", tt.contains, err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("Expected valid mnemonic, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMnemonicDoesNotLeakWords(t *testing.T) {
+	words := NormalizeMnemonic(testMnemonic)
+	words[3] = "notaword"
+
+	err := ValidateMnemonic(words, "")
+	if err == nil {
+		t.Fatal("Expected ValidateMnemonic() to fail")
+	}
+	if strings.Contains(err.Error(), "notaword") {
+		t.Errorf("Expected error not to contain mnemonic words, got %v", err)
+	}
+}
+
+func TestPBKDF2SHA512(t *testing.T) {
+	// RFC 8018 PBKDF2-HMAC-SHA512 測試向量
+	tests := []struct {
+		iterations int
+		expected   string
+	}{
+		{1, "867f70cf1ade02cff3752599a3a53dc4af34c7a669815ae5d513554e1c8cf252c02d470a285a0501bad999bfe943c08f050235d7d68b1da55e63f73b60a57fce"},
+		{2, "e1d9c16aa681708a45f5c7c4e215ceb66e011a2e9f0040713f18aefdb866d53cf76cab2868a39b9f7840edce4fef5a82be67335c77a6068e04112754f27ccf4e"},
+	}
+
+	for _, tt := range tests {
+		got := pbkdf2SHA512([]byte("password"), []byte("salt"), tt.iterations, sha512.Size)
+		if hex.EncodeToString(got) != tt.expected {
+			t.Errorf("pbkdf2SHA512(iterations=%d) = %x, want %s", tt.iterations, got, tt.expected)
+		}
+	}
+}
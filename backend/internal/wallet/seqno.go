@@ -0,0 +1,143 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ton-cat-lottery-backend/internal/ton"
+)
+
+// SeqnoReader 從鏈上讀取錢包合約的 seqno
+type SeqnoReader interface {
+	GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error)
+}
+
+// SeqnoReservation 一次保留的 seqno
+//
+// 同一時間只會有一個保留；訊息被網路接受後呼叫 Commit，
+// 發送失敗時呼叫 Release，兩者都會釋放保留讓下一筆交易使用。
+type SeqnoReservation struct {
+	manager *Manager
+	seqno   uint32
+	once    sync.Once
+}
+
+// SetSeqnoReader 設定 seqno 的鏈上來源，並使目前的快取失效
+func (m *Manager) SetSeqnoReader(reader SeqnoReader) {
+	m.seqnoMu.Lock()
+	defer m.seqnoMu.Unlock()
+
+	m.seqnoReader = reader
+	m.seqnoSynced = false
+}
+
+// SyncSeqno 從鏈上重新讀取 seqno
+func (m *Manager) SyncSeqno(ctx context.Context) (uint32, error) {
+	m.seqnoMu.Lock()
+	reader := m.seqnoReader
+	m.seqnoMu.Unlock()
+
+	if reader == nil {
+		return 0, fmt.Errorf("未設定 seqno 來源")
+	}
+
+	seqno, err := reader.GetWalletSeqno(ctx, m.address)
+	if err != nil {
+		return 0, fmt.Errorf("同步錢包 seqno 失敗: %w", err)
+	}
+
+	m.seqnoMu.Lock()
+	previous, wasSynced := m.seqno, m.seqnoSynced
+	m.seqno = seqno
+	m.seqnoSynced = true
+	m.seqnoMu.Unlock()
+
+	if wasSynced && previous != seqno {
+		m.logger.Warn("錢包 seqno 與鏈上不一致，已重新同步", "local", previous, "chain", seqno)
+	} else {
+		m.logger.Debug("錢包 seqno 已同步", "seqno", seqno)
+	}
+
+	return seqno, nil
+}
+
+// ReserveSeqno 保留下一個可用的 seqno
+//
+// 若前一個保留尚未完成，會等待其 Commit/Release 或 ctx 結束；
+// 本地快取失效時（首次使用或發送失敗後）會先從鏈上同步。
+func (m *Manager) ReserveSeqno(ctx context.Context) (*SeqnoReservation, error) {
+	select {
+	case m.seqnoSlot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("等待 seqno 保留逾時: %w", ctx.Err())
+	}
+
+	m.seqnoMu.Lock()
+	synced, seqno := m.seqnoSynced, m.seqno
+	m.seqnoMu.Unlock()
+
+	if !synced {
+		var err error
+		if seqno, err = m.SyncSeqno(ctx); err != nil {
+			<-m.seqnoSlot
+			return nil, err
+		}
+	}
+
+	m.logger.Debug("保留錢包 seqno", "seqno", seqno)
+	return &SeqnoReservation{manager: m, seqno: seqno}, nil
+}
+
+// InvalidateSeqno 使本地 seqno 快取失效，下一次保留時會從鏈上重新同步
+func (m *Manager) InvalidateSeqno() {
+	m.seqnoMu.Lock()
+	defer m.seqnoMu.Unlock()
+
+	m.seqnoSynced = false
+}
+
+// GetSeqno 獲取本地快取的 seqno，以及快取是否與鏈上同步
+func (m *Manager) GetSeqno() (uint32, bool) {
+	m.seqnoMu.Lock()
+	defer m.seqnoMu.Unlock()
+
+	return m.seqno, m.seqnoSynced
+}
+
+// Seqno 保留的 seqno
+func (r *SeqnoReservation) Seqno() uint32 {
+	return r.seqno
+}
+
+// Commit 訊息已被網路接受，seqno 前進到下一個
+func (r *SeqnoReservation) Commit() {
+	r.once.Do(func() {
+		m := r.manager
+
+		m.seqnoMu.Lock()
+		m.seqno = r.seqno + 1
+		m.seqnoMu.Unlock()
+
+		<-m.seqnoSlot
+	})
+}
+
+// Release 訊息發送失敗，釋放保留並使本地 seqno 失效
+//
+// 無法確定失敗的訊息是否已上鏈，因此一律在下一次保留前重新同步；
+// exit code 33 等 seqno 不符的拒絕會額外記錄警告。
+func (r *SeqnoReservation) Release(sendErr error) {
+	r.once.Do(func() {
+		m := r.manager
+
+		if ton.IsSeqnoMismatch(sendErr) {
+			m.logger.Warn("外部訊息因 seqno 不符被拒絕，將重新同步", "seqno", r.seqno, "error", sendErr)
+		} else {
+			m.logger.Debug("釋放錢包 seqno 保留", "seqno", r.seqno, "error", sendErr)
+		}
+
+		m.InvalidateSeqno()
+		<-m.seqnoSlot
+	})
+}
@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/ton"
+	"ton-cat-lottery-backend/pkg/logger"
+)
+
+// fakeSeqnoReader 測試用的鏈上 seqno 來源
+type fakeSeqnoReader struct {
+	mu    sync.Mutex
+	seqno uint32
+	calls int
+	err   error
+}
+
+func (f *fakeSeqnoReader) GetWalletSeqno(ctx context.Context, walletAddress string) (uint32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	return f.seqno, f.err
+}
+
+func (f *fakeSeqnoReader) set(seqno uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seqno = seqno
+}
+
+func (f *fakeSeqnoReader) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+func newSeqnoTestManager(t *testing.T, reader SeqnoReader) *Manager {
+	t.Helper()
+
+	manager, err := NewManager(&config.Config{
+		WalletPrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}, logger.New("debug"))
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	if reader != nil {
+		manager.SetSeqnoReader(reader)
+	}
+	return manager
+}
+
+func TestReserveSeqnoSyncsFromChain(t *testing.T) {
+	reader := &fakeSeqnoReader{seqno: 42}
+	manager := newSeqnoTestManager(t, reader)
+	ctx := context.Background()
+
+	reservation, err := manager.ReserveSeqno(ctx)
+	if err != nil {
+		t.Fatalf("ReserveSeqno() failed: %v", err)
+	}
+	if reservation.Seqno() != 42 {
+		t.Errorf("Expected seqno=42 from chain, got %d", reservation.Seqno())
+	}
+	reservation.Commit()
+
+	// 提交後使用本地快取，不再查詢鏈上
+	reservation, err = manager.ReserveSeqno(ctx)
+	if err != nil {
+		t.Fatalf("Second ReserveSeqno() failed: %v", err)
+	}
+	if reservation.Seqno() != 43 {
+		t.Errorf("Expected seqno=43 after commit, got %d", reservation.Seqno())
+	}
+	reservation.Commit()
+
+	if reader.callCount() != 1 {
+		t.Errorf("Expected 1 chain query, got %d", reader.callCount())
+	}
+}
+
+func TestReleaseSeqnoResyncs(t *testing.T) {
+	reader := &fakeSeqnoReader{seqno: 5}
+	manager := newSeqnoTestManager(t, reader)
+	ctx := context.Background()
+
+	reservation, err := manager.ReserveSeqno(ctx)
+	if err != nil {
+		t.Fatalf("ReserveSeqno() failed: %v", err)
+	}
+
+	// 模擬另一個程序已使用 seqno 5，訊息因 exit code 33 被拒絕
+	reader.set(6)
+	reservation.Release(&ton.MessageRejectedError{ExitCode: ton.ExitCodeSeqnoMismatch, Reason: "seqno mismatch"})
+
+	// 重複釋放或提交不應影響狀態
+	reservation.Release(nil)
+	reservation.Commit()
+
+	if _, synced := manager.GetSeqno(); synced {
+		t.Error("Expected seqno cache to be invalidated after release")
+	}
+
+	reservation, err = manager.ReserveSeqno(ctx)
+	if err != nil {
+		t.Fatalf("ReserveSeqno() after release failed: %v", err)
+	}
+	defer reservation.Commit()
+
+	if reservation.Seqno() != 6 {
+		t.Errorf("Expected resynced seqno=6, got %d", reservation.Seqno())
+	}
+	if reader.callCount() != 2 {
+		t.Errorf("Expected 2 chain queries, got %d", reader.callCount())
+	}
+}
+
+func TestReserveSeqnoIsExclusive(t *testing.T) {
+	manager := newSeqnoTestManager(t, &fakeSeqnoReader{seqno: 1})
+
+	first, err := manager.ReserveSeqno(context.Background())
+	if err != nil {
+		t.Fatalf("ReserveSeqno() failed: %v", err)
+	}
+
+	// 前一個保留未完成時，第二個保留應等待
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := manager.ReserveSeqno(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected ReserveSeqno() to wait for previous reservation, got %v", err)
+	}
+
+	done := make(chan uint32)
+	go func() {
+		second, err := manager.ReserveSeqno(context.Background())
+		if err != nil {
+			t.Errorf("ReserveSeqno() failed: %v", err)
+			close(done)
+			return
+		}
+		done <- second.Seqno()
+		second.Commit()
+	}()
+
+	first.Commit()
+
+	select {
+	case seqno := <-done:
+		if seqno != 2 {
+			t.Errorf("Expected second reservation seqno=2, got %d", seqno)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected second reservation to proceed after commit")
+	}
+}
+
+func TestReserveSeqnoErrors(t *testing.T) {
+	t.Run("no reader", func(t *testing.T) {
+		manager := newSeqnoTestManager(t, nil)
+		if _, err := manager.ReserveSeqno(context.Background()); err == nil {
+			t.Fatal("Expected ReserveSeqno() to fail without seqno reader")
+		}
+	})
+
+	t.Run("chain query failure releases slot", func(t *testing.T) {
+		reader := &fakeSeqnoReader{err: errors.New("network down")}
+		manager := newSeqnoTestManager(t, reader)
+
+		if _, err := manager.ReserveSeqno(context.Background()); err == nil {
+			t.Fatal("Expected ReserveSeqno() to fail when chain query fails")
+		}
+
+		reader.mu.Lock()
+		reader.err = nil
+		reader.seqno = 9
+		reader.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		reservation, err := manager.ReserveSeqno(ctx)
+		if err != nil {
+			t.Fatalf("Expected slot to be released after failure, got %v", err)
+		}
+		defer reservation.Commit()
+
+		if reservation.Seqno() != 9 {
+			t.Errorf("Expected seqno=9, got %d", reservation.Seqno())
+		}
+	})
+}
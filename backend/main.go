@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"ton-cat-lottery-backend/config"
+	"ton-cat-lottery-backend/internal/api"
+	"ton-cat-lottery-backend/internal/indexer"
 	"ton-cat-lottery-backend/internal/lottery"
 	"ton-cat-lottery-backend/pkg/logger"
 )
@@ -35,12 +39,49 @@ func main() {
 		appLogger.Fatal("啟動抽獎服務失敗", "error", err)
 	}
 
+	// 啟動事件索引器，解碼的合約事件推送給即時事件串流
+	var eventIndexer *indexer.Indexer
+	if cfg.IndexerPollInterval > 0 {
+		source := lotteryService.TransactionSource()
+		if source == nil {
+			lotteryService.Stop()
+			appLogger.Fatal("鏈上介面不支援交易查詢，無法啟動事件索引器")
+		}
+
+		store := indexer.NewFileCursorStore(cfg.IndexerStateFile)
+		eventIndexer = indexer.NewIndexer(cfg, appLogger, source, store, lotteryService.HandleChainEvents)
+		if err := eventIndexer.Start(); err != nil {
+			lotteryService.Stop()
+			appLogger.Fatal("啟動事件索引器失敗", "error", err)
+		}
+	}
+
+	// 啟動 HTTP API 伺服器
+	apiServer := api.NewServer(cfg, appLogger, lotteryService)
+	if err := apiServer.Start(); err != nil {
+		if eventIndexer != nil {
+			eventIndexer.Stop()
+		}
+		lotteryService.Stop()
+		appLogger.Fatal("啟動 HTTP API 伺服器失敗", "error", err)
+	}
+
 	// 等待信號以優雅關閉
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	appLogger.Info("🛑 正在關閉服務...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := apiServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("關閉 HTTP API 伺服器失敗", "error", err)
+	}
+
+	if eventIndexer != nil {
+		eventIndexer.Stop()
+	}
 	lotteryService.Stop()
 	appLogger.Info("✅ 服務已安全關閉")
 }
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
@@ -76,3 +78,78 @@ func (l *Logger) With(args ...any) *Logger {
 func (l *Logger) WithGroup(name string) *Logger {
 	return &Logger{Logger: l.Logger.WithGroup(name)}
 }
+
+// Redacted 取代日誌中敏感字串的文字
+const Redacted = "[REDACTED]"
+
+// WithRedaction 創建會遮蔽 secrets 的日誌器
+//
+// 訊息與所有欄位（包含 error、fmt.Stringer 與群組內的欄位）中出現的 secrets 都以 Redacted 取代，
+// 空字串會被忽略；沒有需要遮蔽的字串時返回原日誌器。
+func (l *Logger) WithRedaction(secrets ...string) *Logger {
+	var pairs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, Redacted)
+		}
+	}
+	if len(pairs) == 0 {
+		return l
+	}
+
+	handler := &redactHandler{inner: l.Logger.Handler(), replacer: strings.NewReplacer(pairs...)}
+	return &Logger{Logger: slog.New(handler)}
+}
+
+// redactHandler 在寫入前遮蔽敏感字串的 slog.Handler
+type redactHandler struct {
+	inner    slog.Handler
+	replacer *strings.Replacer
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.replacer.Replace(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &redactHandler{inner: h.inner.WithAttrs(redacted), replacer: h.replacer}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{inner: h.inner.WithGroup(name), replacer: h.replacer}
+}
+
+// redactAttr 遮蔽欄位值；非字串的值（error、fmt.Stringer 等）先轉為字串再遮蔽
+func (h *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.replacer.Replace(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redactAttr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		s := fmt.Sprint(v.Any())
+		if replaced := h.replacer.Replace(s); replaced != s {
+			return slog.String(a.Key, replaced)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"os"
 	"strings"
@@ -313,3 +314,40 @@ func TestLoggerLevels(t *testing.T) {
 		})
 	}
 }
+
+func TestWithRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	base := &Logger{Logger: slog.New(handler)}
+
+	if base.WithRedaction("", "") != base {
+		t.Error("Expected logger without secrets to be returned unchanged")
+	}
+
+	logger := base.WithRedaction("secret-key-1", "secret-key-2").
+		With("endpoint", "https://api.example/?api_key=secret-key-1").
+		WithGroup("client")
+	logger.Warn("request with secret-key-2 failed",
+		"error", errors.New("Get https://api.example/?api_key=secret-key-2: EOF"),
+		"key", "secret-key-1",
+		"attempt", 2,
+		slog.Group("request", "header", "X-API-Key: secret-key-2"),
+	)
+
+	output := buf.String()
+	if strings.Contains(output, "secret-key") {
+		t.Errorf("Expected secrets to be redacted, got: %s", output)
+	}
+	if strings.Count(output, Redacted) != 5 {
+		t.Errorf("Expected 5 redacted values, got: %s", output)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	client, _ := entry["client"].(map[string]any)
+	if client["attempt"] != float64(2) {
+		t.Errorf("Expected non-secret attributes to keep their type, got: %s", output)
+	}
+}
@@ -0,0 +1,428 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Default 預設的指標註冊表，各套件的指標在初始化時註冊於此，由 /metrics 端點輸出
+var Default = NewRegistry()
+
+// DefaultBuckets 適用於 HTTP 請求延遲的直方圖區間 (秒)
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// collector 可輸出 Prometheus 文字格式的指標
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry 指標註冊表
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry 創建空的指標註冊表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// register 註冊指標，名稱重複代表程式錯誤，直接 panic
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: 指標 %s 重複註冊", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText 以 Prometheus 文字格式 (0.0.4) 輸出所有指標，依名稱排序
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 返回輸出所有指標的 HTTP handler
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// desc 指標的名稱、說明與標籤名稱
+type desc struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (d *desc) name() string { return d.metricName }
+
+// writeHeader 寫入 HELP 與 TYPE 行
+func (d *desc) writeHeader(w io.Writer, metricType string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, metricType)
+	return err
+}
+
+// key 將標籤值組成 map 的鍵，標籤數量不符代表程式錯誤，直接 panic
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: 指標 %s 需要 %d 個標籤，收到 %d 個", d.metricName, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// formatLabels 組成 {name="value",...}，extra 為額外的標籤 (例如直方圖的 le)
+func (d *desc) formatLabels(values []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, label := range d.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, label, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra[i], extra[i+1])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escapeLabel 標籤值中的反斜線、雙引號與換行需跳脫
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys 依鍵排序，讓輸出順序固定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitKey(key string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return strings.SplitN(key, "\xff", n)
+}
+
+// === Counter ===
+
+// CounterVec 依標籤區分的累加計數器
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*atomic.Uint64
+}
+
+// NewCounterVec 在 Default 創建並註冊計數器，名稱應以 _total 結尾
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+// NewCounterVec 創建並註冊計數器，名稱應以 _total 結尾
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{metricName: name, help: help, labels: labels},
+		values: make(map[string]*atomic.Uint64),
+	}
+	r.register(c)
+	return c
+}
+
+// Inc 將指定標籤值的計數加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 將指定標籤值的計數加上 n
+func (c *CounterVec) Add(n uint64, labelValues ...string) {
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	v, ok := c.values[key]
+	if !ok {
+		v = new(atomic.Uint64)
+		c.values[key] = v
+	}
+	c.mu.Unlock()
+
+	v.Add(n)
+}
+
+// Value 返回指定標籤值目前的計數
+func (c *CounterVec) Value(labelValues ...string) uint64 {
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.values[key]; ok {
+		return v.Load()
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	if err := c.writeHeader(w, "counter"); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		labels := c.formatLabels(splitKey(key, len(c.labels)))
+		if _, err := fmt.Fprintf(w, "%s%s %d\n", c.metricName, labels, c.values[key].Load()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// === Gauge ===
+
+// Gauge 可任意設定的數值
+type Gauge struct {
+	desc
+	bits atomic.Uint64
+	set  atomic.Bool
+}
+
+// NewGauge 在 Default 創建並註冊 gauge，設定前不會輸出數值
+func NewGauge(name, help string) *Gauge {
+	return Default.NewGauge(name, help)
+}
+
+// NewGauge 創建並註冊 gauge，設定前不會輸出數值
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{desc: desc{metricName: name, help: help}}
+	r.register(g)
+	return g
+}
+
+// Set 設定目前的數值
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+	g.set.Store(true)
+}
+
+// Value 返回目前的數值，尚未設定時 ok 為 false
+func (g *Gauge) Value() (v float64, ok bool) {
+	return math.Float64frombits(g.bits.Load()), g.set.Load()
+}
+
+func (g *Gauge) write(w io.Writer) error {
+	if err := g.writeHeader(w, "gauge"); err != nil {
+		return err
+	}
+
+	// 尚未取得數值時不輸出樣本，避免以 0 誤導告警
+	v, ok := g.Value()
+	if !ok {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", g.metricName, formatFloat(v))
+	return err
+}
+
+// GaugeVec 依標籤區分的 gauge
+type GaugeVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewGaugeVec 在 Default 創建並註冊依標籤區分的 gauge
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGaugeVec(name, help, labels...)
+}
+
+// NewGaugeVec 創建並註冊依標籤區分的 gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		desc:   desc{metricName: name, help: help, labels: labels},
+		values: make(map[string]float64),
+	}
+	r.register(g)
+	return g
+}
+
+// Set 設定指定標籤值的數值
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	key := g.key(labelValues)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] = v
+}
+
+// Value 返回指定標籤值的數值，尚未設定時 ok 為 false
+func (g *GaugeVec) Value(labelValues ...string) (v float64, ok bool) {
+	key := g.key(labelValues)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	v, ok = g.values[key]
+	return v, ok
+}
+
+func (g *GaugeVec) write(w io.Writer) error {
+	if err := g.writeHeader(w, "gauge"); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range sortedKeys(g.values) {
+		labels := g.formatLabels(splitKey(key, len(g.labels)))
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", g.metricName, labels, formatFloat(g.values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// === Histogram ===
+
+// HistogramVec 依標籤區分的直方圖
+type HistogramVec struct {
+	desc
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram 單一標籤組合的累計值
+type histogram struct {
+	counts []uint64 // 每個區間的數量（非累計），最後一個為 +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec 在 Default 創建並註冊直方圖，buckets 為遞增的區間上限，不包含 +Inf
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// NewHistogramVec 創建並註冊直方圖，buckets 為遞增的區間上限，不包含 +Inf
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: 指標 %s 的區間必須遞增", name))
+	}
+	h := &HistogramVec{
+		desc:    desc{metricName: name, help: help, labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe 記錄一個觀測值
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	i := sort.SearchFloat64s(h.buckets, v) // 第一個 >= v 的區間
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+// Count 返回指定標籤值的觀測次數
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	if err := h.writeHeader(w, "histogram"); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		values := splitKey(key, len(h.labels))
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.formatLabels(values, "le", formatFloat(upper)), cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.formatLabels(values, "le", "+Inf"), s.count); err != nil {
+			return err
+		}
+
+		labels := h.formatLabels(values)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.metricName, labels, formatFloat(s.sum), h.metricName, labels, s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func writeText(t *testing.T, r *Registry) string {
+	t.Helper()
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Requests by method.", "method", "status")
+
+	c.Inc("runGetMethod", "200")
+	c.Add(2, "runGetMethod", "200")
+	c.Inc("sendBoc", `a"b\c`)
+
+	if got := c.Value("runGetMethod", "200"); got != 3 {
+		t.Errorf("Value() = %d, want 3", got)
+	}
+	if got := c.Value("unknown", "200"); got != 0 {
+		t.Errorf("Value() of unused labels = %d, want 0", got)
+	}
+
+	expected := `# HELP requests_total Requests by method.
+# TYPE requests_total counter
+requests_total{method="runGetMethod",status="200"} 3
+requests_total{method="sendBoc",status="a\"b\\c"} 1
+`
+	if got := writeText(t, r); got != expected {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("current_round", "Current round.")
+
+	// 尚未設定時只輸出 HELP 與 TYPE
+	if got := writeText(t, r); strings.Contains(got, "\ncurrent_round ") {
+		t.Errorf("Expected no sample before Set(), got:\n%s", got)
+	}
+
+	g.Set(3)
+	g.Set(1.5e9)
+	if got := writeText(t, r); !strings.Contains(got, "current_round 1.5e+09\n") {
+		t.Errorf("Unexpected output:\n%s", got)
+	}
+}
+
+func TestGaugeVec(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("endpoint_up", "Endpoint availability.", "endpoint")
+
+	g.Set(1, "https://b.example/")
+	g.Set(0, "https://a.example/")
+	if v, ok := g.Value("https://a.example/"); !ok || v != 0 {
+		t.Errorf("Value() = %v, %v", v, ok)
+	}
+	if _, ok := g.Value("https://c.example/"); ok {
+		t.Error("Expected unset labels to report ok=false")
+	}
+
+	expected := `# HELP endpoint_up Endpoint availability.
+# TYPE endpoint_up gauge
+endpoint_up{endpoint="https://a.example/"} 0
+endpoint_up{endpoint="https://b.example/"} 1
+`
+	if got := writeText(t, r); got != expected {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("duration_seconds", "Duration.", []float64{0.1, 1}, "method")
+
+	h.Observe(0.05, "get")
+	h.Observe(0.1, "get") // 等於上限時計入該區間
+	h.Observe(5, "get")
+
+	if got := h.Count("get"); got != 3 {
+		t.Errorf("Count() = %d, want 3", got)
+	}
+
+	expected := `# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{method="get",le="0.1"} 2
+duration_seconds_bucket{method="get",le="1"} 2
+duration_seconds_bucket{method="get",le="+Inf"} 3
+duration_seconds_sum{method="get"} 5.15
+duration_seconds_count{method="get"} 3
+`
+	if got := writeText(t, r); got != expected {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestRegistryOrderAndDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("b_gauge", "B.")
+	r.NewCounterVec("a_total", "A.")
+
+	out := writeText(t, r)
+	if strings.Index(out, "a_total") > strings.Index(out, "b_gauge") {
+		t.Errorf("Expected metrics sorted by name, got:\n%s", out)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected duplicate registration to panic")
+		}
+	}()
+	r.NewGauge("b_gauge", "B.")
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := NewRegistry().NewCounterVec("x_total", "X.", "method")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected wrong label count to panic")
+		}
+	}()
+	c.Inc()
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("hits_total", "Hits.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "hits_total 1\n") {
+		t.Errorf("Unexpected body:\n%s", rec.Body.String())
+	}
+}
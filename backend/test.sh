@@ -8,8 +8,8 @@ echo "========================================"
 # 設定測試環境變數
 export ENVIRONMENT=test
 export LOG_LEVEL=debug
-export LOTTERY_CONTRACT_ADDRESS=EQTestLottery123
-export NFT_CONTRACT_ADDRESS=EQTestNFT456
+export LOTTERY_CONTRACT_ADDRESS=EQBPB6uyNFjIiULCAQaacdUSC9SqSJEMo_M5x8GrHmPhHypd
+export NFT_CONTRACT_ADDRESS=EQCmeex3ZOnbiAxKtgSJf-nV8H86cv-jZjSXWrHrMa76A85A
 export WALLET_PRIVATE_KEY=0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
 export TON_NETWORK=testnet
 export AUTO_DRAW=false